@@ -2,9 +2,12 @@
 package auth
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 // Authenticator is the interface that all authentication methods must implement.
@@ -16,6 +19,21 @@ type Authenticator interface {
 	Type() string
 }
 
+// Identifier is implemented by authenticators that can tell whose credentials
+// a request uses without authenticating it. Identity returns a stable,
+// non-reversible identifier for the credentials used on req. The response
+// cache and request coalescing use it to keep callers apart; without it they
+// have to authenticate a probe request and hash its Authorization header.
+type Identifier interface {
+	Identity(req *http.Request) string
+}
+
+// identityHash returns the hex-encoded SHA-256 hash of parts.
+func identityHash(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
 // APITokenAuth implements API token authentication for Jira Cloud.
 //
 // API tokens are the recommended authentication method for Jira Cloud.
@@ -46,6 +64,11 @@ func (a *APITokenAuth) Type() string {
 	return "api_token"
 }
 
+// Identity returns a hash of the email and token.
+func (a *APITokenAuth) Identity(_ *http.Request) string {
+	return identityHash(a.Type(), a.email, a.token)
+}
+
 // PATAuth implements Personal Access Token authentication for Jira Server/Data Center.
 //
 // PATs use Bearer token authentication and are the recommended method for
@@ -72,6 +95,11 @@ func (a *PATAuth) Type() string {
 	return "pat"
 }
 
+// Identity returns a hash of the token.
+func (a *PATAuth) Identity(_ *http.Request) string {
+	return identityHash(a.Type(), a.token)
+}
+
 // BasicAuth implements HTTP Basic authentication.
 //
 // This is a legacy authentication method and should only be used when
@@ -101,3 +129,8 @@ func (a *BasicAuth) Authenticate(req *http.Request) error {
 func (a *BasicAuth) Type() string {
 	return "basic"
 }
+
+// Identity returns a hash of the username and password.
+func (a *BasicAuth) Identity(_ *http.Request) string {
+	return identityHash(a.Type(), a.username, a.password)
+}
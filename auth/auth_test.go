@@ -105,3 +105,48 @@ func TestBasicAuth(t *testing.T) {
 		})
 	}
 }
+
+func TestIdentity(t *testing.T) {
+	req, err := http.NewRequest("GET", "https://example.com", nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		auth  Identifier
+		same  Identifier
+		other Identifier
+	}{
+		{
+			name:  "api token",
+			auth:  NewAPITokenAuth("user@example.com", "api-token-123"),
+			same:  NewAPITokenAuth("user@example.com", "api-token-123"),
+			other: NewAPITokenAuth("user@example.com", "api-token-456"),
+		},
+		{
+			name:  "pat",
+			auth:  NewPATAuth("pat-token-123"),
+			same:  NewPATAuth("pat-token-123"),
+			other: NewPATAuth("pat-token-456"),
+		},
+		{
+			name:  "basic",
+			auth:  NewBasicAuth("admin", "secret"),
+			same:  NewBasicAuth("admin", "secret"),
+			other: NewBasicAuth("other", "secret"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity := tt.auth.Identity(req)
+			assert.Len(t, identity, 64)
+			assert.Equal(t, identity, tt.same.Identity(req))
+			assert.NotEqual(t, identity, tt.other.Identity(req))
+			assert.NotContains(t, identity, "secret")
+			assert.NotContains(t, identity, "token")
+		})
+	}
+
+	// The same credentials under another scheme are another identity
+	assert.NotEqual(t, NewAPITokenAuth("admin", "secret").Identity(req), NewBasicAuth("admin", "secret").Identity(req))
+}
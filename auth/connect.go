@@ -0,0 +1,274 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/jirasdk/internal/jwt"
+)
+
+// ConnectJWTExpiry is how long the JWT signing a request stays valid.
+const ConnectJWTExpiry = 3 * time.Minute
+
+// ConnectClaims are the claims of an Atlassian Connect JWT.
+type ConnectClaims struct {
+	// Issuer is the app key for requests from the app, or the client key of
+	// the Jira site for requests from Jira
+	Issuer string `json:"iss"`
+
+	// Subject is the Atlassian account ID of the user the request acts for
+	Subject string `json:"sub,omitempty"`
+
+	// IssuedAt is when the token was issued, in Unix seconds
+	IssuedAt int64 `json:"iat"`
+
+	// ExpiresAt is when the token expires, in Unix seconds
+	ExpiresAt int64 `json:"exp"`
+
+	// QSH is the query string hash binding the token to one request
+	QSH string `json:"qsh,omitempty"`
+}
+
+// ConnectJWTAuth implements Atlassian Connect JWT authentication.
+//
+// Every request is signed with a fresh, short-lived JWT whose qsh claim binds
+// it to the request's method, path and query.
+type ConnectJWTAuth struct {
+	issuer   string
+	secret   []byte
+	subject  string
+	basePath string
+	now      func() time.Time
+}
+
+// ConnectOption configures a ConnectJWTAuth.
+type ConnectOption func(*ConnectJWTAuth)
+
+// WithConnectBasePath sets the path of the Jira base URL, which is left out of
+// the query string hash. Use it when Jira is served below a context path, such
+// as https://jira.example.com/jira.
+//
+// jira.NewClient derives the base path from WithBaseURL when it is not set,
+// and rejects a base path that does not match the base URL.
+//
+// Example:
+//
+//	connectAuth := auth.NewConnectJWTAuth(appKey, sharedSecret, auth.WithConnectBasePath("/jira"))
+func WithConnectBasePath(basePath string) ConnectOption {
+	return func(a *ConnectJWTAuth) {
+		a.basePath = basePath
+	}
+}
+
+// NewConnectJWTAuth creates a Connect JWT authenticator for the app with key
+// issuer and the shared secret received in the installed lifecycle callback.
+//
+// Example:
+//
+//	client, err := jira.NewClient(
+//	    jira.WithBaseURL(installation.BaseURL),
+//	    jira.WithAuthenticator(auth.NewConnectJWTAuth("com.example.my-app", installation.SharedSecret)),
+//	)
+func NewConnectJWTAuth(issuer, sharedSecret string, opts ...ConnectOption) *ConnectJWTAuth {
+	a := &ConnectJWTAuth{
+		issuer: issuer,
+		secret: []byte(sharedSecret),
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// WithSubject returns a copy of the authenticator that impersonates the user
+// with accountID through the sub claim.
+//
+// Example:
+//
+//	userAuth := connectAuth.WithSubject("5b10ac8d82e05b22cc7d4ef5")
+func (a *ConnectJWTAuth) WithSubject(accountID string) *ConnectJWTAuth {
+	impersonating := *a
+	impersonating.subject = accountID
+	return &impersonating
+}
+
+// BasePath returns the base path set with WithConnectBasePath.
+func (a *ConnectJWTAuth) BasePath() string {
+	return a.basePath
+}
+
+// WithBasePath returns a copy of the authenticator that leaves basePath out of
+// the query string hash, like WithConnectBasePath.
+//
+// Example:
+//
+//	jiraAuth := connectAuth.WithBasePath("/jira")
+func (a *ConnectJWTAuth) WithBasePath(basePath string) *ConnectJWTAuth {
+	withPath := *a
+	withPath.basePath = basePath
+	return &withPath
+}
+
+// connectSubjectKey is the context key for the per-request subject.
+type connectSubjectKey struct{}
+
+// ContextWithConnectSubject returns a context that makes ConnectJWTAuth
+// impersonate the user with accountID for requests made with it, overriding
+// WithSubject.
+//
+// Example:
+//
+//	ctx = auth.ContextWithConnectSubject(ctx, "5b10ac8d82e05b22cc7d4ef5")
+//	issue, err := client.Issue.Get(ctx, "PROJ-123", nil)
+func ContextWithConnectSubject(ctx context.Context, accountID string) context.Context {
+	return context.WithValue(ctx, connectSubjectKey{}, accountID)
+}
+
+// Authenticate signs the request with a JWT in the Authorization header.
+func (a *ConnectJWTAuth) Authenticate(req *http.Request) error {
+	if a.issuer == "" || len(a.secret) == 0 {
+		return fmt.Errorf("connect JWT requires an issuer and a shared secret")
+	}
+
+	now := a.now()
+	claims := &ConnectClaims{
+		Issuer:    a.issuer,
+		Subject:   a.subjectFor(req),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ConnectJWTExpiry).Unix(),
+		QSH:       QueryStringHash(req.Method, req.URL, a.basePath),
+	}
+
+	token, err := jwt.Sign(claims, a.secret)
+	if err != nil {
+		return fmt.Errorf("failed to sign connect JWT: %w", err)
+	}
+
+	req.Header.Set("Authorization", "JWT "+token)
+	return nil
+}
+
+// Type returns the authentication type.
+func (a *ConnectJWTAuth) Type() string {
+	return "connect_jwt"
+}
+
+// Identity returns a hash of the issuer and the user the request acts for.
+// The signed token changes every time, so it cannot identify the caller.
+func (a *ConnectJWTAuth) Identity(req *http.Request) string {
+	return identityHash(a.Type(), a.issuer, a.subjectFor(req))
+}
+
+// subjectFor returns the sub claim for req: the context subject if set,
+// otherwise the one from WithSubject.
+func (a *ConnectJWTAuth) subjectFor(req *http.Request) string {
+	if s, ok := req.Context().Value(connectSubjectKey{}).(string); ok {
+		return s
+	}
+	return a.subject
+}
+
+// QueryStringHash returns the qsh claim for a request: the hex-encoded
+// SHA-256 hash of its canonical form.
+func QueryStringHash(method string, u *url.URL, basePath string) string {
+	sum := sha256.Sum256([]byte(CanonicalRequest(method, u, basePath)))
+	return hex.EncodeToString(sum[:])
+}
+
+// CanonicalRequest returns the canonical form of a request that the Connect
+// query string hash is computed over: the method, path and query joined by
+// "&".
+//
+// The method is upper-cased. The path is made relative to basePath, the path
+// of the product or app base URL; it always starts with "/", never ends with
+// one, and has "&" encoded as %26. The query omits the jwt parameter, sorts
+// parameters by name, joins repeated values with "," in sorted order, and
+// percent-encodes names and values as in RFC 3986.
+//
+// Example:
+//
+//	u, _ := url.Parse("https://example.atlassian.net/rest/api/3/search?maxResults=10&jql=project%20%3D%20PROJ")
+//	auth.CanonicalRequest("get", u, "")
+//	// GET&/rest/api/3/search&jql=project%20%3D%20PROJ&maxResults=10
+func CanonicalRequest(method string, u *url.URL, basePath string) string {
+	return strings.ToUpper(method) + "&" + canonicalPath(u.EscapedPath(), basePath) + "&" + canonicalQuery(u.Query())
+}
+
+// canonicalPath returns path relative to basePath in canonical form.
+func canonicalPath(path, basePath string) string {
+	basePath = strings.TrimSuffix(basePath, "/")
+	if basePath != "" && (path == basePath || strings.HasPrefix(path, basePath+"/")) {
+		path = path[len(basePath):]
+	}
+
+	path = strings.ReplaceAll(path, "&", "%26")
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return path
+}
+
+// canonicalQuery returns query in canonical form.
+func canonicalQuery(query url.Values) string {
+	params := make([]string, 0, len(query))
+	for name, values := range query {
+		if name == "jwt" {
+			continue
+		}
+
+		encoded := make([]string, len(values))
+		for i, value := range values {
+			encoded[i] = encodeRFC3986(value)
+		}
+		sort.Strings(encoded)
+		params = append(params, encodeRFC3986(name)+"="+strings.Join(encoded, ","))
+	}
+
+	// Names are unique, so sorting the pairs sorts by name
+	sort.Slice(params, func(i, j int) bool {
+		return paramName(params[i]) < paramName(params[j])
+	})
+	return strings.Join(params, "&")
+}
+
+// paramName returns the encoded name of a canonical query parameter.
+func paramName(param string) string {
+	name, _, _ := strings.Cut(param, "=")
+	return name
+}
+
+// encodeRFC3986 percent-encodes everything but the unreserved characters of
+// RFC 3986.
+func encodeRFC3986(s string) string {
+	const hexDigits = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isUnreserved(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hexDigits[c>>4])
+		b.WriteByte(hexDigits[c&0x0f])
+	}
+	return b.String()
+}
+
+// isUnreserved reports whether c is an RFC 3986 unreserved character.
+func isUnreserved(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
@@ -0,0 +1,252 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		url      string
+		basePath string
+		want     string
+	}{
+		{
+			name:   "method is upper-cased",
+			method: "get",
+			url:    "https://example.atlassian.net/rest/api/3/myself",
+			want:   "GET&/rest/api/3/myself&",
+		},
+		{
+			name:   "parameters are sorted by name",
+			method: http.MethodGet,
+			url:    "https://example.atlassian.net/rest/api/3/search?maxResults=10&jql=project%20%3D%20PROJ",
+			want:   "GET&/rest/api/3/search&jql=project%20%3D%20PROJ&maxResults=10",
+		},
+		{
+			name:   "repeated values are sorted and joined",
+			method: http.MethodGet,
+			url:    "https://example.atlassian.net/rest/api/3/issue/PROJ-1?expand=names&fields=summary&fields=assignee",
+			want:   "GET&/rest/api/3/issue/PROJ-1&expand=names&fields=assignee,summary",
+		},
+		{
+			name:   "jwt parameter is ignored",
+			method: http.MethodGet,
+			url:    "https://app.example.com/installed?jwt=abc.def.ghi&lic=active",
+			want:   "GET&/installed&lic=active",
+		},
+		{
+			name:   "RFC 3986 encoding",
+			method: http.MethodGet,
+			url:    "https://example.atlassian.net/rest/api/3/search?jql=a+b*c~d!e'(f)&x%2Cy=1,2",
+			want:   "GET&/rest/api/3/search&jql=a%20b%2Ac~d%21e%27%28f%29&x%2Cy=1%2C2",
+		},
+		{
+			name:   "empty value",
+			method: http.MethodGet,
+			url:    "https://example.atlassian.net/rest/api/3/search?validate&a=",
+			want:   "GET&/rest/api/3/search&a=&validate=",
+		},
+		{
+			name:   "trailing slash is removed",
+			method: http.MethodPost,
+			url:    "https://example.atlassian.net/rest/api/3/issue/",
+			want:   "POST&/rest/api/3/issue&",
+		},
+		{
+			name:   "empty path is the root",
+			method: http.MethodGet,
+			url:    "https://example.atlassian.net",
+			want:   "GET&/&",
+		},
+		{
+			name:   "ampersand in the path is encoded",
+			method: http.MethodGet,
+			url:    "https://example.atlassian.net/rest/api/3/project/R&D",
+			want:   "GET&/rest/api/3/project/R%26D&",
+		},
+		{
+			name:     "base path is removed",
+			method:   http.MethodPut,
+			url:      "https://example.com/wiki/rest/api/content/1?status=current",
+			basePath: "/wiki/",
+			want:     "PUT&/rest/api/content/1&status=current",
+		},
+		{
+			name:     "base path is the whole path",
+			method:   http.MethodGet,
+			url:      "https://example.com/wiki",
+			basePath: "/wiki",
+			want:     "GET&/&",
+		},
+		{
+			name:     "base path matches whole segments only",
+			method:   http.MethodGet,
+			url:      "https://example.com/wikipedia/page",
+			basePath: "/wiki",
+			want:     "GET&/wikipedia/page&",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, CanonicalRequest(tt.method, u, tt.basePath))
+		})
+	}
+}
+
+func TestQueryStringHash(t *testing.T) {
+	tests := []struct {
+		method string
+		url    string
+		want   string
+	}{
+		{
+			method: http.MethodGet,
+			url:    "https://example.atlassian.net/rest/api/3/search?maxResults=10&jql=project%20%3D%20PROJ",
+			want:   "7c0b35626fa995d8cb03b1cea034070523cb175fb35f3daf0bb10c876df28f5e",
+		},
+		{
+			method: http.MethodPost,
+			url:    "https://example.atlassian.net/rest/api/3/issue",
+			want:   "a64b1ba2731272596784da7588c6e16deb1619108576546ab4299429d981f400",
+		},
+		{
+			method: http.MethodGet,
+			url:    "https://example.atlassian.net/",
+			want:   "c88caad15a1c1a900b8ac08aa9686f4e8184539bea1deda36e2f649430df3239",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.url, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, QueryStringHash(tt.method, u, ""))
+		})
+	}
+}
+
+// decodeConnectJWT checks the signature of the JWT in the request and
+// returns its claims.
+func decodeConnectJWT(t *testing.T, req *http.Request, secret string) ConnectClaims {
+	t.Helper()
+
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "JWT ")
+	require.True(t, ok, "Authorization header uses the JWT scheme")
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"alg":"HS256","typ":"JWT"}`, string(header))
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), parts[2], "signature")
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	var claims ConnectClaims
+	require.NoError(t, json.Unmarshal(payload, &claims))
+	return claims
+}
+
+func TestConnectJWTAuth_Authenticate(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	auth := NewConnectJWTAuth("com.example.app", "shared-secret")
+	auth.now = func() time.Time { return now }
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.atlassian.net/rest/api/3/search?maxResults=10&jql=project%20%3D%20PROJ", nil)
+	require.NoError(t, auth.Authenticate(req))
+
+	claims := decodeConnectJWT(t, req, "shared-secret")
+	assert.Equal(t, ConnectClaims{
+		Issuer:    "com.example.app",
+		IssuedAt:  1700000000,
+		ExpiresAt: 1700000180,
+		QSH:       "7c0b35626fa995d8cb03b1cea034070523cb175fb35f3daf0bb10c876df28f5e",
+	}, claims)
+
+	assert.Equal(t, "connect_jwt", auth.Type())
+}
+
+func TestConnectJWTAuth_BasePath(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	auth := NewConnectJWTAuth("com.example.app", "shared-secret", WithConnectBasePath("/jira"))
+	auth.now = func() time.Time { return now }
+
+	// Jira at https://jira.example.com/jira hashes the path without /jira
+	req := httptest.NewRequest(http.MethodGet, "https://jira.example.com/jira/rest/api/3/search?maxResults=10&jql=project%20%3D%20PROJ", nil)
+	require.NoError(t, auth.Authenticate(req))
+
+	claims := decodeConnectJWT(t, req, "shared-secret")
+	assert.Equal(t, "7c0b35626fa995d8cb03b1cea034070523cb175fb35f3daf0bb10c876df28f5e", claims.QSH)
+}
+
+func TestConnectJWTAuth_WithBasePath(t *testing.T) {
+	auth := NewConnectJWTAuth("com.example.app", "shared-secret")
+	jiraAuth := auth.WithBasePath("/jira")
+
+	assert.Equal(t, "/jira", jiraAuth.BasePath())
+	assert.Empty(t, auth.BasePath(), "the original authenticator is left alone")
+}
+
+func TestConnectJWTAuth_Subject(t *testing.T) {
+	auth := NewConnectJWTAuth("com.example.app", "shared-secret")
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.atlassian.net/rest/api/3/myself", nil)
+	require.NoError(t, auth.Authenticate(req))
+	assert.Empty(t, decodeConnectJWT(t, req, "shared-secret").Subject)
+
+	// WithSubject leaves the original authenticator alone
+	userAuth := auth.WithSubject("5b10ac8d82e05b22cc7d4ef5")
+	require.NoError(t, userAuth.Authenticate(req))
+	assert.Equal(t, "5b10ac8d82e05b22cc7d4ef5", decodeConnectJWT(t, req, "shared-secret").Subject)
+	require.NoError(t, auth.Authenticate(req))
+	assert.Empty(t, decodeConnectJWT(t, req, "shared-secret").Subject)
+
+	// The context overrides WithSubject
+	ctx := ContextWithConnectSubject(context.Background(), "712020:other-user")
+	require.NoError(t, userAuth.Authenticate(req.WithContext(ctx)))
+	assert.Equal(t, "712020:other-user", decodeConnectJWT(t, req, "shared-secret").Subject)
+}
+
+func TestConnectJWTAuth_Identity(t *testing.T) {
+	auth := NewConnectJWTAuth("com.example.app", "shared-secret")
+	req := httptest.NewRequest(http.MethodGet, "https://example.atlassian.net/rest/api/3/myself", nil)
+
+	var _ Identifier = auth
+	identity := auth.Identity(req)
+	assert.Equal(t, identity, auth.Identity(req), "stable across signatures")
+	assert.NotContains(t, identity, "com.example.app")
+
+	userAuth := auth.WithSubject("5b10ac8d82e05b22cc7d4ef5")
+	assert.NotEqual(t, identity, userAuth.Identity(req))
+
+	ctx := ContextWithConnectSubject(context.Background(), "5b10ac8d82e05b22cc7d4ef5")
+	assert.Equal(t, userAuth.Identity(req), auth.Identity(req.WithContext(ctx)))
+	assert.NotEqual(t, identity, NewConnectJWTAuth("com.example.other", "shared-secret").Identity(req))
+}
+
+func TestConnectJWTAuth_MissingCredentials(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "https://example.atlassian.net/rest/api/3/myself", nil)
+	assert.Error(t, NewConnectJWTAuth("", "secret").Authenticate(req))
+	assert.Error(t, NewConnectJWTAuth("com.example.app", "").Authenticate(req))
+}
@@ -0,0 +1,269 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// LoginOptions configures InteractiveLogin.
+type LoginOptions struct {
+	// OpenURL sends the user to the authorization URL and returns without
+	// waiting for the login. It defaults to printing the URL to standard
+	// error; use OpenBrowser to open it in the default browser instead.
+	OpenURL func(authURL string) error
+
+	// ListenAddr is the loopback address of the callback listener (defaults
+	// to "127.0.0.1:0", an ephemeral port). Set a fixed port when the
+	// authorization server only accepts a registered callback URL. Hosts
+	// other than a loopback IP or "localhost" are rejected, so the callback
+	// is never reachable from the network.
+	ListenAddr string
+
+	// CallbackPath is the path of the callback (defaults to "/callback")
+	CallbackPath string
+
+	// HTTPClient sends the token request and, set on the authenticator with
+	// SetHTTPClient, later refreshes (defaults to the authenticator's client)
+	HTTPClient *http.Client
+}
+
+// loginShutdownTimeout bounds how long the callback listener waits for the
+// browser to receive the result page.
+const loginShutdownTimeout = 5 * time.Second
+
+// InteractiveLogin runs the OAuth 2.0 authorization code flow with PKCE for
+// command-line tools.
+//
+// It starts a callback listener on the loopback interface, sends the user to
+// the authorization URL with a PKCE challenge and a random state, and waits
+// for the authorization server to redirect back. Callbacks with the wrong
+// state are rejected. The code is then exchanged for a token, which is set on
+// oauth and saved to its token store.
+//
+// The redirect URL is the listener's address, such as
+// http://127.0.0.1:49152/callback, and replaces the configured RedirectURL.
+// Cancel ctx to give up waiting for the user.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+//	defer cancel()
+//
+//	token, err := auth.InteractiveLogin(ctx, oauth, &auth.LoginOptions{
+//	    OpenURL:    auth.OpenBrowser,
+//	    ListenAddr: "127.0.0.1:8080",
+//	})
+func InteractiveLogin(ctx context.Context, oauth *OAuth2Authenticator, opts *LoginOptions) (*oauth2.Token, error) {
+	if oauth == nil {
+		return nil, fmt.Errorf("OAuth 2.0 authenticator is required")
+	}
+	if opts == nil {
+		opts = &LoginOptions{}
+	}
+	openURL := opts.OpenURL
+	if openURL == nil {
+		openURL = printAuthURL
+	}
+	listenAddr := opts.ListenAddr
+	if listenAddr == "" {
+		listenAddr = "127.0.0.1:0"
+	}
+	if err := checkLoopbackAddr(listenAddr); err != nil {
+		return nil, err
+	}
+	callbackPath := opts.CallbackPath
+	if callbackPath == "" {
+		callbackPath = "/callback"
+	}
+
+	state, err := randomState()
+	if err != nil {
+		return nil, err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	var lc net.ListenConfig
+	listener, err := lc.Listen(ctx, "tcp", listenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start callback listener: %w", err)
+	}
+	redirectURL := fmt.Sprintf("http://%s%s", listener.Addr(), callbackPath)
+	redirect := oauth2.SetAuthURLParam("redirect_uri", redirectURL)
+
+	callback := &loginCallback{
+		state:   state,
+		codes:   make(chan loginResult, 1),
+		outcome: make(chan error, 1),
+	}
+	mux := http.NewServeMux()
+	mux.Handle(callbackPath, callback)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go server.Serve(listener) //nolint:errcheck // returns ErrServerClosed on shutdown
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), loginShutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	authURL := oauth.config.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(verifier), redirect)
+	if err := openURL(authURL); err != nil {
+		return nil, fmt.Errorf("failed to open authorization URL: %w", err)
+	}
+
+	var result loginResult
+	select {
+	case result = <-callback.codes:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("login canceled: %w", ctx.Err())
+	}
+
+	if result.err != nil {
+		callback.outcome <- result.err
+		return nil, result.err
+	}
+
+	token, err := exchangeLoginCode(ctx, oauth, opts.HTTPClient, result.code, oauth2.VerifierOption(verifier), redirect)
+	callback.outcome <- err
+	return token, err
+}
+
+// checkLoopbackAddr returns an error unless the host of addr is a loopback IP
+// or localhost.
+func checkLoopbackAddr(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid listen address %q: %w", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("listen address %q is not a loopback address", addr)
+}
+
+// exchangeLoginCode exchanges the authorization code and makes the token
+// current.
+func exchangeLoginCode(ctx context.Context, oauth *OAuth2Authenticator, client *http.Client, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	if client != nil {
+		oauth.SetHTTPClient(client)
+	}
+	ctx, cancel := oauth.tokenContext(ctx)
+	defer cancel()
+
+	token, err := oauth.config.Exchange(ctx, code, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	if err := oauth.rotate(token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// loginResult is what the authorization server sent to the callback.
+type loginResult struct {
+	code string
+	err  error
+}
+
+// loginCallback handles the redirect from the authorization server.
+type loginCallback struct {
+	state string
+
+	// codes receives the first callback with the right state
+	codes chan loginResult
+
+	// outcome receives the result of the login, shown to the user
+	outcome chan error
+}
+
+func (c *loginCallback) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("state") != c.state {
+		// Not a response to our request; keep waiting for one
+		http.Error(w, "Invalid state parameter.", http.StatusBadRequest)
+		return
+	}
+
+	var result loginResult
+	switch {
+	case query.Get("error") != "":
+		result.err = fmt.Errorf("authorization failed: %s: %s", query.Get("error"), query.Get("error_description"))
+	case query.Get("code") == "":
+		result.err = errors.New("authorization failed: no code in callback")
+	default:
+		result.code = query.Get("code")
+	}
+
+	select {
+	case c.codes <- result:
+	default:
+		http.Error(w, "Login already completed.", http.StatusConflict)
+		return
+	}
+
+	// Wait for the exchange so the page tells the user how it went
+	var err error
+	select {
+	case err = <-c.outcome:
+	case <-r.Context().Done():
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "<html><body><h1>Login failed</h1><p>%s</p></body></html>", html.EscapeString(err.Error()))
+		return
+	}
+	w.Write([]byte("<html><body><h1>Login complete</h1><p>You can close this window.</p></body></html>"))
+}
+
+// randomState returns an unguessable state parameter.
+func randomState() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate state: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// printAuthURL asks the user to open authURL.
+func printAuthURL(authURL string) error {
+	_, err := fmt.Fprintf(os.Stderr, "Open this URL in your browser to log in:\n\n  %s\n\n", authURL)
+	return err
+}
+
+// OpenBrowser opens url in the default browser. It is meant for
+// LoginOptions.OpenURL.
+func OpenBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url) //nolint:gosec // G204: the URL is an argument, not a command
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url) //nolint:gosec // G204: the URL is an argument, not a command
+	default:
+		cmd = exec.Command("xdg-open", url) //nolint:gosec // G204: the URL is an argument, not a command
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open browser: %w", err)
+	}
+	// Reap the launcher when it exits
+	go cmd.Wait() //nolint:errcheck // the browser reports its own errors
+	return nil
+}
@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// fakeAuthServer is an authorization server that approves every request
+// and checks the PKCE verifier on the token request.
+type fakeAuthServer struct {
+	*httptest.Server
+	t *testing.T
+
+	// deny makes the authorization endpoint return an error instead of a code
+	deny bool
+
+	challenge   string
+	redirectURI string
+	tokenCalls  int
+}
+
+func newFakeAuthServer(t *testing.T) *fakeAuthServer {
+	t.Helper()
+
+	s := &fakeAuthServer{t: t}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeAuthServer) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	assert.Equal(s.t, "code", query.Get("response_type"))
+	assert.Equal(s.t, "S256", query.Get("code_challenge_method"))
+	assert.NotEmpty(s.t, query.Get("state"))
+	s.challenge = query.Get("code_challenge")
+	s.redirectURI = query.Get("redirect_uri")
+
+	callback, err := url.Parse(s.redirectURI)
+	require.NoError(s.t, err)
+	params := url.Values{"state": {query.Get("state")}}
+	if s.deny {
+		params.Set("error", "access_denied")
+		params.Set("error_description", "User did not authorize the request")
+	} else {
+		params.Set("code", "auth-code")
+	}
+	callback.RawQuery = params.Encode()
+	http.Redirect(w, r, callback.String(), http.StatusFound)
+}
+
+func (s *fakeAuthServer) token(w http.ResponseWriter, r *http.Request) {
+	s.tokenCalls++
+	require.NoError(s.t, r.ParseForm())
+	assert.Equal(s.t, "authorization_code", r.FormValue("grant_type"))
+	assert.Equal(s.t, "auth-code", r.FormValue("code"))
+	assert.Equal(s.t, s.redirectURI, r.FormValue("redirect_uri"))
+
+	if oauth2.S256ChallengeFromVerifier(r.FormValue("code_verifier")) != s.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_grant","error_description":"PKCE verification failed"}`))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"access_token":"login-access-token","refresh_token":"login-refresh-token","token_type":"Bearer","expires_in":3600}`))
+}
+
+func (s *fakeAuthServer) authenticator() *OAuth2Authenticator {
+	return NewOAuth2Authenticator(&OAuth2Config{
+		ClientID:     "test-client-id",
+		ClientSecret: "test-client-secret",
+		Scopes:       []string{"read:jira-work"},
+		AuthURL:      s.URL + "/authorize",
+		TokenURL:     s.URL + "/token",
+	})
+}
+
+// browse returns an OpenURL function that follows the authorization URL like
+// a browser, reporting the final page on pages.
+func browse(t *testing.T, pages chan<- string) func(string) error {
+	return func(authURL string) error {
+		go func() {
+			resp, err := http.Get(authURL)
+			if !assert.NoError(t, err) {
+				pages <- ""
+				return
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			pages <- fmt.Sprintf("%d %s", resp.StatusCode, body)
+		}()
+		return nil
+	}
+}
+
+func TestInteractiveLogin(t *testing.T) {
+	server := newFakeAuthServer(t)
+	oauth := server.authenticator()
+	store := NewMemoryTokenStore()
+	require.NoError(t, oauth.SetTokenStore(store))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pages := make(chan string, 1)
+	token, err := InteractiveLogin(ctx, oauth, &LoginOptions{
+		OpenURL:    browse(t, pages),
+		HTTPClient: server.Client(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "login-access-token", token.AccessToken)
+	assert.Equal(t, token, oauth.GetToken())
+
+	page := <-pages
+	assert.True(t, strings.HasPrefix(page, "200 "), page)
+	assert.Contains(t, page, "Login complete")
+
+	assert.Regexp(t, `^http://127\.0\.0\.1:\d+/callback$`, server.redirectURI)
+	assert.Equal(t, 1, server.tokenCalls)
+
+	saved, err := store.LoadToken()
+	require.NoError(t, err)
+	assert.Equal(t, "login-refresh-token", saved.RefreshToken)
+}
+
+func TestInteractiveLogin_Denied(t *testing.T) {
+	server := newFakeAuthServer(t)
+	server.deny = true
+
+	pages := make(chan string, 1)
+	_, err := InteractiveLogin(context.Background(), server.authenticator(), &LoginOptions{
+		OpenURL:      browse(t, pages),
+		CallbackPath: "/oauth/done",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "access_denied")
+
+	page := <-pages
+	assert.Contains(t, page, "Login failed")
+	assert.Contains(t, server.redirectURI, "/oauth/done")
+	assert.Zero(t, server.tokenCalls)
+}
+
+func TestInteractiveLogin_RejectsWrongState(t *testing.T) {
+	oauth := NewOAuth2Authenticator(&OAuth2Config{ClientID: "test-client-id"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	statuses := make(chan int, 1)
+	_, err := InteractiveLogin(ctx, oauth, &LoginOptions{
+		OpenURL: func(authURL string) error {
+			u, err := url.Parse(authURL)
+			require.NoError(t, err)
+			callback := u.Query().Get("redirect_uri") + "?code=stolen&state=forged"
+
+			go func() {
+				resp, err := http.Get(callback)
+				if assert.NoError(t, err) {
+					resp.Body.Close()
+					statuses <- resp.StatusCode
+				}
+				// Give up on the login once the forged callback is rejected
+				cancel()
+			}()
+			return nil
+		},
+	})
+
+	assert.Equal(t, http.StatusBadRequest, <-statuses)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, oauth.GetToken())
+}
+
+func TestInteractiveLogin_Errors(t *testing.T) {
+	_, err := InteractiveLogin(context.Background(), nil, nil)
+	assert.Error(t, err)
+
+	oauth := NewOAuth2Authenticator(&OAuth2Config{ClientID: "test-client-id"})
+	_, err = InteractiveLogin(context.Background(), oauth, &LoginOptions{ListenAddr: "127.0.0.1:99999"})
+	assert.ErrorContains(t, err, "failed to start callback listener")
+
+	for _, addr := range []string{":8080", "0.0.0.0:8080", "[::]:8080", "192.168.1.10:8080", "example.com:8080", "127.0.0.1"} {
+		_, err = InteractiveLogin(context.Background(), oauth, &LoginOptions{ListenAddr: addr})
+		assert.Error(t, err, addr)
+		assert.NotContains(t, err.Error(), "failed to start callback listener", addr)
+	}
+
+	_, err = InteractiveLogin(context.Background(), oauth, &LoginOptions{
+		OpenURL: func(string) error { return fmt.Errorf("no browser") },
+	})
+	assert.ErrorContains(t, err, "no browser")
+}
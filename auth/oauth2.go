@@ -2,16 +2,41 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
 	"net/http"
+	"sync"
+	"time"
 
 	"golang.org/x/oauth2"
 )
 
+// DefaultOAuth2TokenTimeout bounds a token request, such as a refresh, when
+// OAuth2Config.TokenTimeout is not set.
+const DefaultOAuth2TokenTimeout = 30 * time.Second
+
 // OAuth2Authenticator implements OAuth 2.0 authentication for Jira.
+//
+// It is safe for concurrent use. Expired access tokens are refreshed once,
+// however many requests are waiting, and every new token is saved to the
+// token store set with SetTokenStore.
 type OAuth2Authenticator struct {
-	config *oauth2.Config
-	token  *oauth2.Token
+	config       *oauth2.Config
+	resourcesURL string
+	tokenTimeout time.Duration
+
+	// instance identifies the authenticator when its token store cannot
+	instance string
+
+	mu         sync.Mutex
+	token      *oauth2.Token
+	source     oauth2.TokenSource
+	store      OAuth2TokenStore
+	httpClient *http.Client
+
+	// refreshMu serializes refreshes
+	refreshMu sync.Mutex
 }
 
 // OAuth2Config contains configuration for OAuth 2.0 authentication.
@@ -33,6 +58,19 @@ type OAuth2Config struct {
 
 	// TokenURL is the token endpoint (defaults to Jira Cloud)
 	TokenURL string
+
+	// AccessibleResourcesURL lists the sites a token can reach (defaults to
+	// Jira Cloud)
+	AccessibleResourcesURL string
+
+	// HTTPClient sends token requests, including refreshes (defaults to
+	// http.DefaultClient)
+	HTTPClient *http.Client
+
+	// TokenTimeout bounds each token request (defaults to
+	// DefaultOAuth2TokenTimeout). A refresh is shared by every waiting
+	// request, so it cannot be bounded by any one request's context.
+	TokenTimeout time.Duration
 }
 
 // NewOAuth2Authenticator creates a new OAuth 2.0 authenticator.
@@ -52,6 +90,12 @@ func NewOAuth2Authenticator(config *OAuth2Config) *OAuth2Authenticator {
 	if config.TokenURL == "" {
 		config.TokenURL = "https://auth.atlassian.com/oauth/token"
 	}
+	if config.AccessibleResourcesURL == "" {
+		config.AccessibleResourcesURL = AccessibleResourcesURL
+	}
+	if config.TokenTimeout <= 0 {
+		config.TokenTimeout = DefaultOAuth2TokenTimeout
+	}
 
 	oauthConfig := &oauth2.Config{
 		ClientID:     config.ClientID,
@@ -65,8 +109,37 @@ func NewOAuth2Authenticator(config *OAuth2Config) *OAuth2Authenticator {
 	}
 
 	return &OAuth2Authenticator{
-		config: oauthConfig,
+		config:       oauthConfig,
+		resourcesURL: config.AccessibleResourcesURL,
+		tokenTimeout: config.TokenTimeout,
+		instance:     rand.Text(),
+		httpClient:   config.HTTPClient,
+	}
+}
+
+// SetHTTPClient replaces the HTTP client that sends token requests,
+// including refreshes.
+//
+// Example:
+//
+//	oauth.SetHTTPClient(&http.Client{Transport: proxyTransport})
+func (a *OAuth2Authenticator) SetHTTPClient(client *http.Client) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.httpClient = client
+}
+
+// tokenContext returns ctx bounded by the token timeout and carrying the
+// HTTP client for the oauth2 package.
+func (a *OAuth2Authenticator) tokenContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	a.mu.Lock()
+	client := a.httpClient
+	a.mu.Unlock()
+
+	if client != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, client)
 	}
+	return context.WithTimeout(ctx, a.tokenTimeout)
 }
 
 // GetAuthURL returns the authorization URL for the OAuth 2.0 flow.
@@ -79,22 +152,29 @@ func (a *OAuth2Authenticator) GetAuthURL(state string) string {
 	return a.config.AuthCodeURL(state, oauth2.AccessTypeOffline)
 }
 
-// Exchange exchanges an authorization code for an access token.
+// Exchange exchanges an authorization code for an access token and saves it
+// to the token store.
 //
 // Example:
 //
 //	token, err := auth.Exchange(ctx, "authorization-code")
 func (a *OAuth2Authenticator) Exchange(ctx context.Context, code string) (*oauth2.Token, error) {
+	ctx, cancel := a.tokenContext(ctx)
+	defer cancel()
+
 	token, err := a.config.Exchange(ctx, code)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange code: %w", err)
 	}
 
-	a.token = token
+	if err := a.rotate(token); err != nil {
+		return nil, err
+	}
 	return token, nil
 }
 
-// SetToken sets the OAuth 2.0 token for subsequent requests.
+// SetToken sets the OAuth 2.0 token for subsequent requests. The token is
+// not saved to the token store.
 //
 // Example:
 //
@@ -103,50 +183,145 @@ func (a *OAuth2Authenticator) Exchange(ctx context.Context, code string) (*oauth
 //	    RefreshToken: "refresh-token",
 //	})
 func (a *OAuth2Authenticator) SetToken(token *oauth2.Token) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
 	a.token = token
+	a.source = nil
+	if token != nil {
+		a.source = oauth2.ReuseTokenSource(token, oauth2Refresher{auth: a})
+	}
 }
 
 // GetToken returns the current OAuth 2.0 token.
 func (a *OAuth2Authenticator) GetToken() *oauth2.Token {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	return a.token
 }
 
-// RefreshToken refreshes the OAuth 2.0 access token.
+// SetTokenStore loads the stored token, if there is one, and saves every
+// token obtained afterwards to store.
+//
+// Example:
+//
+//	store, err := auth.NewFileTokenStore("/var/lib/myapp/jira-token", key)
+//	if err != nil {
+//	    return err
+//	}
+//	if err := oauth.SetTokenStore(store); err != nil {
+//	    return err
+//	}
+//	if oauth.GetToken() == nil {
+//	    // First run: send the user to oauth.GetAuthURL(state)
+//	}
+func (a *OAuth2Authenticator) SetTokenStore(store OAuth2TokenStore) error {
+	if store == nil {
+		return fmt.Errorf("token store is required")
+	}
+
+	token, err := store.LoadToken()
+	if err != nil && !errors.Is(err, ErrTokenNotFound) {
+		return fmt.Errorf("failed to load token: %w", err)
+	}
+
+	a.mu.Lock()
+	a.store = store
+	a.mu.Unlock()
+
+	if token != nil {
+		a.SetToken(token)
+	}
+	return nil
+}
+
+// RefreshToken refreshes the OAuth 2.0 access token, even if it is still
+// valid, and saves the new token to the token store.
 //
 // Example:
 //
 //	newToken, err := auth.RefreshToken(ctx)
 func (a *OAuth2Authenticator) RefreshToken(ctx context.Context) (*oauth2.Token, error) {
-	if a.token == nil {
+	return a.refresh(ctx, true)
+}
+
+// refresh exchanges the refresh token for a new token. Unless force is set,
+// a token that another caller refreshed in the meantime is returned as is.
+func (a *OAuth2Authenticator) refresh(ctx context.Context, force bool) (*oauth2.Token, error) {
+	a.refreshMu.Lock()
+	defer a.refreshMu.Unlock()
+
+	current := a.GetToken()
+	if current == nil {
 		return nil, fmt.Errorf("no token to refresh")
 	}
+	if !force && current.Valid() {
+		return current, nil
+	}
+
+	ctx, cancel := a.tokenContext(ctx)
+	defer cancel()
 
-	tokenSource := a.config.TokenSource(ctx, a.token)
-	newToken, err := tokenSource.Token()
+	// Jira rotates refresh tokens; the token source keeps the old one when no
+	// new one is returned
+	newToken, err := a.config.TokenSource(ctx, &oauth2.Token{RefreshToken: current.RefreshToken}).Token()
 	if err != nil {
 		return nil, fmt.Errorf("failed to refresh token: %w", err)
 	}
 
-	a.token = newToken
+	if err := a.rotate(newToken); err != nil {
+		return nil, err
+	}
 	return newToken, nil
 }
 
+// rotate makes token current and saves it to the token store. The token is
+// used even if saving fails.
+func (a *OAuth2Authenticator) rotate(token *oauth2.Token) error {
+	a.SetToken(token)
+
+	a.mu.Lock()
+	store := a.store
+	a.mu.Unlock()
+
+	if store == nil {
+		return nil
+	}
+	if err := store.SaveToken(token); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	return nil
+}
+
+// Token returns a valid token, refreshing it first if it has expired.
+// It implements oauth2.TokenSource.
+func (a *OAuth2Authenticator) Token() (*oauth2.Token, error) {
+	a.mu.Lock()
+	source := a.source
+	a.mu.Unlock()
+
+	if source == nil {
+		return nil, errNoOAuth2Token
+	}
+	return source.Token()
+}
+
 // Authenticate adds OAuth 2.0 authentication to the request.
+//
+// An expired token is refreshed first. Concurrent requests share a single
+// refresh, which is not canceled with any one request but is bounded by the
+// token timeout.
 func (a *OAuth2Authenticator) Authenticate(req *http.Request) error {
-	if a.token == nil {
-		return fmt.Errorf("no OAuth 2.0 token available")
+	token, err := a.Token()
+	if errors.Is(err, errNoOAuth2Token) {
+		return err
 	}
-
-	// Check if token needs refresh
-	if !a.token.Valid() {
-		ctx := req.Context()
-		if _, err := a.RefreshToken(ctx); err != nil {
-			return fmt.Errorf("failed to refresh token: %w", err)
-		}
+	if err != nil {
+		return fmt.Errorf("failed to refresh token: %w", err)
 	}
 
 	// Add Authorization header
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", a.token.AccessToken))
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token.AccessToken))
 	return nil
 }
 
@@ -155,6 +330,22 @@ func (a *OAuth2Authenticator) Type() string {
 	return "oauth2"
 }
 
+// Identity returns a hash of the client ID and the token store, so it stays
+// the same when the access token is refreshed. A FileTokenStore is identified
+// by its file, shared across restarts; otherwise each authenticator is its own
+// identity. Use one authenticator per user.
+func (a *OAuth2Authenticator) Identity(_ *http.Request) string {
+	a.mu.Lock()
+	store := a.store
+	a.mu.Unlock()
+
+	owner := a.instance
+	if located, ok := store.(interface{ location() string }); ok {
+		owner = located.location()
+	}
+	return identityHash(a.Type(), a.config.ClientID, owner)
+}
+
 // Client returns an HTTP client that automatically handles OAuth 2.0 authentication.
 //
 // Example:
@@ -162,13 +353,38 @@ func (a *OAuth2Authenticator) Type() string {
 //	httpClient := auth.Client(ctx)
 //	resp, err := httpClient.Get("https://api.atlassian.com/...")
 func (a *OAuth2Authenticator) Client(ctx context.Context) *http.Client {
-	if a.token == nil {
+	if a.GetToken() == nil {
 		return http.DefaultClient
 	}
-	return a.config.Client(ctx, a.token)
+
+	a.mu.Lock()
+	client := a.httpClient
+	a.mu.Unlock()
+	if client != nil && ctx.Value(oauth2.HTTPClient) == nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, client)
+	}
+	return oauth2.NewClient(ctx, a)
+}
+
+// errNoOAuth2Token is returned by Token before a token has been set.
+var errNoOAuth2Token = errors.New("no OAuth 2.0 token available")
+
+// oauth2Refresher refreshes expired tokens for oauth2.ReuseTokenSource.
+type oauth2Refresher struct {
+	auth *OAuth2Authenticator
+}
+
+// Token implements oauth2.TokenSource.
+func (r oauth2Refresher) Token() (*oauth2.Token, error) {
+	// Shared by every waiting request, so bound to none of them; refresh
+	// applies the token timeout
+	return r.auth.refresh(context.Background(), false)
 }
 
 // OAuth2TokenStore defines an interface for storing and retrieving OAuth 2.0 tokens.
+//
+// Implementations must be safe for concurrent use. LoadToken returns an error
+// matching ErrTokenNotFound when no token has been saved.
 type OAuth2TokenStore interface {
 	// SaveToken saves an OAuth 2.0 token
 	SaveToken(token *oauth2.Token) error
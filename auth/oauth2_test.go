@@ -1,9 +1,15 @@
 package auth
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -267,3 +273,249 @@ func TestRefreshToken_Success(t *testing.T) {
 	assert.Equal(t, "new-access-token", newToken.AccessToken)
 	assert.Equal(t, newToken, auth.GetToken())
 }
+
+// newTokenServer returns a token endpoint that issues numbered tokens,
+// counting the refreshes.
+func newTokenServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var refreshes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "refresh_token", r.FormValue("grant_type"))
+
+		n := atomic.AddInt32(&refreshes, 1)
+		// Slow enough for concurrent callers to pile up
+		time.Sleep(20 * time.Millisecond)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"access-%d","refresh_token":"refresh-%d","token_type":"Bearer","expires_in":3600}`, n, n)
+	}))
+	t.Cleanup(server.Close)
+	return server, &refreshes
+}
+
+func TestAuthenticate_ConcurrentRefresh(t *testing.T) {
+	server, refreshes := newTokenServer(t)
+
+	store := NewMemoryTokenStore()
+	auth := NewOAuth2Authenticator(&OAuth2Config{
+		ClientID:     "test-client-id",
+		ClientSecret: "test-client-secret",
+		TokenURL:     server.URL,
+	})
+	require.NoError(t, auth.SetTokenStore(store))
+	auth.SetToken(&oauth2.Token{
+		AccessToken:  "expired-access-token",
+		RefreshToken: "refresh-0",
+		Expiry:       time.Now().Add(-time.Hour),
+	})
+
+	var wg sync.WaitGroup
+	headers := make([]string, 20)
+	for i := range headers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodGet, "https://api.atlassian.com/test", nil)
+			assert.NoError(t, auth.Authenticate(req))
+			headers[i] = req.Header.Get("Authorization")
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(refreshes), "one refresh for all requests")
+	for _, header := range headers {
+		assert.Equal(t, "Bearer access-1", header)
+	}
+
+	// The rotated token was saved
+	saved, err := store.LoadToken()
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", saved.AccessToken)
+	assert.Equal(t, "refresh-1", saved.RefreshToken)
+}
+
+// countingRoundTripper counts the requests it sends.
+type countingRoundTripper struct {
+	requests atomic.Int32
+}
+
+func (c *countingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestAuthenticate_RefreshUsesHTTPClient(t *testing.T) {
+	server, _ := newTokenServer(t)
+
+	transport := &countingRoundTripper{}
+	auth := NewOAuth2Authenticator(&OAuth2Config{
+		ClientID:   "test-client-id",
+		TokenURL:   server.URL,
+		HTTPClient: &http.Client{Transport: transport},
+	})
+	auth.SetToken(&oauth2.Token{AccessToken: "expired", RefreshToken: "refresh-0", Expiry: time.Now().Add(-time.Hour)})
+
+	req := httptest.NewRequest(http.MethodGet, "https://api.atlassian.com/test", nil)
+	require.NoError(t, auth.Authenticate(req))
+	assert.Equal(t, int32(1), transport.requests.Load())
+
+	// SetHTTPClient replaces it for later refreshes
+	replacement := &countingRoundTripper{}
+	auth.SetHTTPClient(&http.Client{Transport: replacement})
+	_, err := auth.RefreshToken(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), replacement.requests.Load())
+}
+
+func TestAuthenticate_RefreshTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	auth := NewOAuth2Authenticator(&OAuth2Config{
+		ClientID:     "test-client-id",
+		TokenURL:     server.URL,
+		TokenTimeout: 50 * time.Millisecond,
+	})
+	auth.SetToken(&oauth2.Token{AccessToken: "expired", RefreshToken: "refresh-0", Expiry: time.Now().Add(-time.Hour)})
+
+	start := time.Now()
+	req := httptest.NewRequest(http.MethodGet, "https://api.atlassian.com/test", nil)
+	err := auth.Authenticate(req)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second, "a hung token endpoint does not block requests")
+}
+
+func TestAuthenticate_RefreshWithoutRefreshToken(t *testing.T) {
+	auth := NewOAuth2Authenticator(&OAuth2Config{
+		ClientID:     "test-client-id",
+		ClientSecret: "test-client-secret",
+	})
+	auth.SetToken(&oauth2.Token{AccessToken: "expired", Expiry: time.Now().Add(-time.Hour)})
+
+	req := httptest.NewRequest(http.MethodGet, "https://api.atlassian.com/test", nil)
+	err := auth.Authenticate(req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to refresh token")
+}
+
+func TestRefreshToken_SaveFailure(t *testing.T) {
+	server, _ := newTokenServer(t)
+
+	auth := NewOAuth2Authenticator(&OAuth2Config{
+		ClientID:     "test-client-id",
+		ClientSecret: "test-client-secret",
+		TokenURL:     server.URL,
+	})
+	require.NoError(t, auth.SetTokenStore(failingTokenStore{}))
+	auth.SetToken(&oauth2.Token{AccessToken: "old", RefreshToken: "refresh-0"})
+
+	_, err := auth.RefreshToken(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to save token")
+
+	// The new token is still used
+	assert.Equal(t, "access-1", auth.GetToken().AccessToken)
+}
+
+func TestSetTokenStore(t *testing.T) {
+	auth := NewOAuth2Authenticator(&OAuth2Config{ClientID: "test-client-id"})
+	assert.Error(t, auth.SetTokenStore(nil))
+
+	// An empty store leaves the authenticator without a token
+	store := NewMemoryTokenStore()
+	require.NoError(t, auth.SetTokenStore(store))
+	assert.Nil(t, auth.GetToken())
+
+	require.NoError(t, store.SaveToken(&oauth2.Token{AccessToken: "stored"}))
+	require.NoError(t, auth.SetTokenStore(store))
+	assert.Equal(t, "stored", auth.GetToken().AccessToken)
+
+	assert.Error(t, auth.SetTokenStore(failingTokenStore{loadErr: errors.New("disk on fire")}))
+}
+
+func TestOAuth2Identity(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	newAuth := func() *OAuth2Authenticator {
+		return NewOAuth2Authenticator(&OAuth2Config{ClientID: "test-client-id", TokenURL: server.URL})
+	}
+	req := httptest.NewRequest(http.MethodGet, "https://api.atlassian.com/test", nil)
+
+	// An expired token is not refreshed to compute the identity, and a new
+	// token keeps it
+	auth := newAuth()
+	auth.SetToken(&oauth2.Token{AccessToken: "old", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)})
+	identity := auth.Identity(req)
+	auth.SetToken(&oauth2.Token{AccessToken: "new", Expiry: time.Now().Add(time.Hour)})
+	assert.Equal(t, identity, auth.Identity(req))
+	assert.Zero(t, calls.Load())
+
+	// Authenticators without a shared store are kept apart
+	assert.NotEqual(t, identity, newAuth().Identity(req))
+
+	// Authenticators sharing a token file are the same caller
+	path := filepath.Join(t.TempDir(), "jira-token")
+	key := bytes.Repeat([]byte{7}, 32)
+	identities := make([]string, 2)
+	for i := range identities {
+		store, err := NewFileTokenStore(path, key)
+		require.NoError(t, err)
+		a := newAuth()
+		require.NoError(t, a.SetTokenStore(store))
+		identities[i] = a.Identity(req)
+	}
+	assert.Equal(t, identities[0], identities[1])
+	assert.NotEqual(t, identity, identities[0])
+}
+
+func TestExchange_SavesToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"exchanged","refresh_token":"refresh","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	store := NewMemoryTokenStore()
+	auth := NewOAuth2Authenticator(&OAuth2Config{
+		ClientID:     "test-client-id",
+		ClientSecret: "test-client-secret",
+		TokenURL:     server.URL,
+	})
+	require.NoError(t, auth.SetTokenStore(store))
+
+	_, err := auth.Exchange(context.Background(), "code")
+	require.NoError(t, err)
+
+	saved, err := store.LoadToken()
+	require.NoError(t, err)
+	assert.Equal(t, "exchanged", saved.AccessToken)
+}
+
+// failingTokenStore fails to save, and to load with loadErr.
+type failingTokenStore struct {
+	loadErr error
+}
+
+func (failingTokenStore) SaveToken(*oauth2.Token) error { return errors.New("disk full") }
+func (s failingTokenStore) LoadToken() (*oauth2.Token, error) {
+	if s.loadErr != nil {
+		return nil, s.loadErr
+	}
+	return nil, ErrTokenNotFound
+}
+func (failingTokenStore) DeleteToken() error { return errors.New("disk full") }
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// AccessibleResourcesURL is the Atlassian endpoint that lists the sites an
+// OAuth 2.0 (3LO) token can reach.
+const AccessibleResourcesURL = "https://api.atlassian.com/oauth/token/accessible-resources"
+
+// GatewayURL is the Atlassian API gateway. OAuth 2.0 (3LO) requests go to
+// GatewayURL + "/ex/jira/{cloudId}" rather than to the site URL.
+const GatewayURL = "https://api.atlassian.com"
+
+// AccessibleResource is an Atlassian site an OAuth 2.0 token can reach.
+type AccessibleResource struct {
+	// ID is the cloud ID of the site
+	ID string `json:"id"`
+
+	// URL is the site URL, such as https://your-domain.atlassian.net
+	URL string `json:"url"`
+
+	// Name is the site name
+	Name string `json:"name"`
+
+	// Scopes are the scopes granted for the site
+	Scopes []string `json:"scopes"`
+
+	// AvatarURL is the site avatar
+	AvatarURL string `json:"avatarUrl,omitempty"`
+}
+
+// IsJira reports whether the token was granted any Jira scope for the site.
+// Tokens for Confluence or other products list their sites too.
+func (r *AccessibleResource) IsJira() bool {
+	for _, scope := range r.Scopes {
+		if strings.Contains(scope, "jira") {
+			return true
+		}
+	}
+	return false
+}
+
+// GatewayURL returns the base URL for Jira requests to the site through the
+// Atlassian API gateway.
+func (r *AccessibleResource) GatewayURL() string {
+	return JiraGatewayURL(r.ID)
+}
+
+// JiraGatewayURL returns the base URL for Jira requests to the site with
+// cloudID through the Atlassian API gateway.
+func JiraGatewayURL(cloudID string) string {
+	return GatewayURL + "/ex/jira/" + cloudID
+}
+
+// AccessibleResources lists the sites the current token can reach, with the
+// scopes granted for each.
+//
+// Example:
+//
+//	resources, err := oauth.AccessibleResources(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	for _, r := range resources {
+//	    fmt.Printf("%s (%s): %s\n", r.Name, r.URL, r.ID)
+//	}
+func (a *OAuth2Authenticator) AccessibleResources(ctx context.Context) ([]AccessibleResource, error) {
+	if a.GetToken() == nil {
+		return nil, errNoOAuth2Token
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.resourcesURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.Client(ctx).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accessible resources: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("failed to list accessible resources: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var resources []AccessibleResource
+	if err := json.NewDecoder(resp.Body).Decode(&resources); err != nil {
+		return nil, fmt.Errorf("failed to decode accessible resources: %w", err)
+	}
+	return resources, nil
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestAccessibleResources(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/oauth/token/accessible-resources", r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer test-access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"code":401,"message":"Unauthorized"}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"id":"1324a887-45db-1bf4-1e99-ef0ff456d421","url":"https://your-domain.atlassian.net","name":"your-domain","scopes":["read:jira-work","write:jira-work"],"avatarUrl":"https://site-admin-avatar-cdn.prod.public.atl-paas.net/avatars/240/flag.png"},
+			{"id":"77f2b1a5-8c8d-4c21-a3f4-0a1b2c3d4e5f","url":"https://wiki.atlassian.net","name":"wiki","scopes":["read:confluence-content.all"]}
+		]`))
+	}))
+	defer server.Close()
+
+	auth := NewOAuth2Authenticator(&OAuth2Config{
+		ClientID:               "test-client-id",
+		ClientSecret:           "test-client-secret",
+		AccessibleResourcesURL: server.URL + "/oauth/token/accessible-resources",
+	})
+
+	_, err := auth.AccessibleResources(context.Background())
+	assert.ErrorContains(t, err, "no OAuth 2.0 token available")
+
+	auth.SetToken(&oauth2.Token{AccessToken: "test-access-token", TokenType: "Bearer", Expiry: time.Now().Add(time.Hour)})
+	resources, err := auth.AccessibleResources(context.Background())
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+
+	jira := resources[0]
+	assert.Equal(t, "1324a887-45db-1bf4-1e99-ef0ff456d421", jira.ID)
+	assert.Equal(t, "https://your-domain.atlassian.net", jira.URL)
+	assert.Equal(t, "your-domain", jira.Name)
+	assert.Equal(t, []string{"read:jira-work", "write:jira-work"}, jira.Scopes)
+	assert.True(t, jira.IsJira())
+	assert.Equal(t, "https://api.atlassian.com/ex/jira/1324a887-45db-1bf4-1e99-ef0ff456d421", jira.GatewayURL())
+
+	assert.False(t, resources[1].IsJira())
+
+	auth.SetToken(&oauth2.Token{AccessToken: "revoked", Expiry: time.Now().Add(time.Hour)})
+	_, err = auth.AccessibleResources(context.Background())
+	assert.ErrorContains(t, err, "status 401")
+}
+
+func TestNewOAuth2Authenticator_DefaultResourcesURL(t *testing.T) {
+	auth := NewOAuth2Authenticator(&OAuth2Config{ClientID: "test-client-id"})
+	assert.Equal(t, AccessibleResourcesURL, auth.resourcesURL)
+}
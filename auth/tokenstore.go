@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrTokenNotFound is returned by OAuth2TokenStore.LoadToken when no token
+// has been saved.
+var ErrTokenNotFound = errors.New("no stored OAuth 2.0 token")
+
+// MemoryTokenStore keeps an OAuth 2.0 token in memory.
+//
+// It suits short-lived processes and tests; use FileTokenStore to keep
+// tokens across restarts.
+type MemoryTokenStore struct {
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+// NewMemoryTokenStore creates an empty in-memory token store.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{}
+}
+
+// SaveToken saves a copy of token.
+func (s *MemoryTokenStore) SaveToken(token *oauth2.Token) error {
+	if token == nil {
+		return fmt.Errorf("token is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saved := *token
+	s.token = &saved
+	return nil
+}
+
+// LoadToken returns a copy of the saved token.
+func (s *MemoryTokenStore) LoadToken() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == nil {
+		return nil, ErrTokenNotFound
+	}
+	loaded := *s.token
+	return &loaded, nil
+}
+
+// DeleteToken deletes the saved token.
+func (s *MemoryTokenStore) DeleteToken() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = nil
+	return nil
+}
+
+// FileTokenStore keeps an OAuth 2.0 token in a file, encrypted with
+// AES-GCM.
+//
+// The file is only readable by the current user and is replaced atomically,
+// so a crash while saving never leaves a partial token behind.
+type FileTokenStore struct {
+	path string
+	aead cipher.AEAD
+	mu   sync.Mutex
+}
+
+// NewFileTokenStore creates a token store that keeps the token at path,
+// encrypted with key. The key must be 16, 24 or 32 bytes long, selecting
+// AES-128, AES-192 or AES-256.
+//
+// Example:
+//
+//	key, err := hex.DecodeString(os.Getenv("TOKEN_KEY")) // 64 hex digits
+//	if err != nil {
+//	    return err
+//	}
+//	store, err := auth.NewFileTokenStore("/var/lib/myapp/jira-token", key)
+func NewFileTokenStore(path string, key []byte) (*FileTokenStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("token file path is required")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token encryption key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token encryption key: %w", err)
+	}
+
+	return &FileTokenStore{path: path, aead: aead}, nil
+}
+
+// location identifies the token file for OAuth2Authenticator.Identity.
+func (s *FileTokenStore) location() string {
+	if abs, err := filepath.Abs(s.path); err == nil {
+		return "file:" + abs
+	}
+	return "file:" + s.path
+}
+
+// SaveToken encrypts token and writes it to the file.
+func (s *FileTokenStore) SaveToken(token *oauth2.Token) error {
+	if token == nil {
+		return fmt.Errorf("token is required")
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := s.aead.Seal(nonce, nonce, data, nil)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create token directory: %w", err)
+	}
+
+	// Write to a temporary file first so the rename replaces the token
+	// atomically; CreateTemp makes the file readable by the owner only
+	tmp, err := os.CreateTemp(dir, ".jira-token-*")
+	if err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // gone after a successful rename
+
+	if _, err := tmp.Write(sealed); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	return nil
+}
+
+// LoadToken reads and decrypts the token from the file.
+func (s *FileTokenStore) LoadToken() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sealed, err := os.ReadFile(s.path) //nolint:gosec // G304: path is chosen by the caller
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token: %w", err)
+	}
+
+	nonceSize := s.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, fmt.Errorf("failed to decrypt token: file is truncated")
+	}
+	data, err := s.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt token: wrong key or corrupted file: %w", err)
+	}
+
+	var token oauth2.Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("failed to decode token: %w", err)
+	}
+	return &token, nil
+}
+
+// DeleteToken removes the token file. Deleting a missing token is not an
+// error.
+func (s *FileTokenStore) DeleteToken() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete token: %w", err)
+	}
+	return nil
+}
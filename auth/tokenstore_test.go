@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func testTokenStore(t *testing.T, store OAuth2TokenStore) {
+	t.Helper()
+
+	_, err := store.LoadToken()
+	assert.ErrorIs(t, err, ErrTokenNotFound)
+
+	token := &oauth2.Token{
+		AccessToken:  "access-token",
+		TokenType:    "Bearer",
+		RefreshToken: "refresh-token",
+		Expiry:       time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	require.NoError(t, store.SaveToken(token))
+
+	loaded, err := store.LoadToken()
+	require.NoError(t, err)
+	assert.Equal(t, token.AccessToken, loaded.AccessToken)
+	assert.Equal(t, token.TokenType, loaded.TokenType)
+	assert.Equal(t, token.RefreshToken, loaded.RefreshToken)
+	assert.True(t, token.Expiry.Equal(loaded.Expiry))
+
+	// Saving replaces the token
+	require.NoError(t, store.SaveToken(&oauth2.Token{AccessToken: "rotated"}))
+	loaded, err = store.LoadToken()
+	require.NoError(t, err)
+	assert.Equal(t, "rotated", loaded.AccessToken)
+
+	require.NoError(t, store.DeleteToken())
+	_, err = store.LoadToken()
+	assert.ErrorIs(t, err, ErrTokenNotFound)
+	assert.NoError(t, store.DeleteToken(), "deleting twice is not an error")
+
+	assert.Error(t, store.SaveToken(nil))
+}
+
+func TestMemoryTokenStore(t *testing.T) {
+	store := NewMemoryTokenStore()
+	testTokenStore(t, store)
+
+	// The store keeps its own copy
+	token := &oauth2.Token{AccessToken: "original"}
+	require.NoError(t, store.SaveToken(token))
+	token.AccessToken = "changed"
+	loaded, err := store.LoadToken()
+	require.NoError(t, err)
+	assert.Equal(t, "original", loaded.AccessToken)
+}
+
+func TestFileTokenStore(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	path := filepath.Join(t.TempDir(), "tokens", "jira-token")
+
+	store, err := NewFileTokenStore(path, key)
+	require.NoError(t, err)
+	testTokenStore(t, store)
+
+	require.NoError(t, store.SaveToken(&oauth2.Token{AccessToken: "secret-access-token"}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret-access-token", "the token is encrypted")
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	}
+
+	// Another store with the same key reads the token
+	reopened, err := NewFileTokenStore(path, key)
+	require.NoError(t, err)
+	loaded, err := reopened.LoadToken()
+	require.NoError(t, err)
+	assert.Equal(t, "secret-access-token", loaded.AccessToken)
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestFileTokenStore_Errors(t *testing.T) {
+	_, err := NewFileTokenStore("", bytes.Repeat([]byte{7}, 32))
+	assert.Error(t, err)
+
+	_, err = NewFileTokenStore("token", []byte("short"))
+	assert.ErrorContains(t, err, "invalid token encryption key")
+
+	path := filepath.Join(t.TempDir(), "jira-token")
+	store, err := NewFileTokenStore(path, bytes.Repeat([]byte{7}, 32))
+	require.NoError(t, err)
+	require.NoError(t, store.SaveToken(&oauth2.Token{AccessToken: "access-token"}))
+
+	wrongKey, err := NewFileTokenStore(path, bytes.Repeat([]byte{8}, 32))
+	require.NoError(t, err)
+	_, err = wrongKey.LoadToken()
+	assert.ErrorContains(t, err, "wrong key or corrupted file")
+
+	require.NoError(t, os.WriteFile(path, []byte("x"), 0o600))
+	_, err = store.LoadToken()
+	assert.ErrorContains(t, err, "truncated")
+}
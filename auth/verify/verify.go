@@ -0,0 +1,388 @@
+// Package verify checks the JWTs that Jira sends to Atlassian Connect apps.
+//
+// Webhooks, module requests and other calls after installation are signed
+// with HS256 using the shared secret Jira gave the app in the installed
+// callback. The middleware looks the secret up by the token's issuer, the
+// client key of the Jira site, then checks the signature, the expiry and the
+// query string hash before passing the request on with the verified claims on
+// its context.
+//
+// The installed and uninstalled lifecycle callbacks are signed with RS256
+// instead, using a key published on the Connect install-keys CDN under the
+// token's kid, and carry an aud claim naming the app's base URL. They are
+// verified when the Verifier is created with WithInstallKeys, and rejected as
+// invalid tokens otherwise.
+//
+// Example:
+//
+//	verifier := verify.New(func(ctx context.Context, clientKey string) (string, error) {
+//		installation, err := db.Installation(ctx, clientKey)
+//		if err != nil {
+//			return "", err
+//		}
+//		return installation.SharedSecret, nil
+//	})
+//
+//	http.Handle("/webhooks/issue-updated", verifier.Middleware(http.HandlerFunc(
+//		func(w http.ResponseWriter, r *http.Request) {
+//			claims, _ := verify.ClaimsFromContext(r.Context())
+//			log.Printf("webhook from %s", claims.Issuer)
+//		})))
+package verify
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/felixgeelhaar/jirasdk/auth"
+	"github.com/felixgeelhaar/jirasdk/internal/jwt"
+)
+
+// DefaultLeeway is the clock skew tolerated when checking iat and exp.
+const DefaultLeeway = 30 * time.Second
+
+// Errors returned by Verify. They all wrap ErrUnauthorized.
+var (
+	ErrUnauthorized = errors.New("jwt verification failed")
+	ErrMissingToken = fmt.Errorf("%w: no JWT in request", ErrUnauthorized)
+	ErrInvalidToken = fmt.Errorf("%w: invalid JWT", ErrUnauthorized)
+	ErrExpired      = fmt.Errorf("%w: JWT expired", ErrUnauthorized)
+	ErrQSHMismatch  = fmt.Errorf("%w: query string hash does not match the request", ErrUnauthorized)
+)
+
+// DefaultInstallKeysURL is the Connect install-keys CDN, which serves the
+// public key with ID kid at DefaultInstallKeysURL + "/" + kid.
+const DefaultInstallKeysURL = "https://connect-install-keys.atlassian.com"
+
+// maxKeySize bounds the public key responses read from the CDN.
+const maxKeySize = 64 * 1024
+
+// SecretFunc returns the shared secret of the installation with clientKey,
+// the iss claim of the token. Returning an error rejects the request.
+type SecretFunc func(ctx context.Context, clientKey string) (string, error)
+
+// KeyFunc returns the public key with ID kid, the kid header of an RS256
+// token. Returning an error rejects the request.
+type KeyFunc func(ctx context.Context, kid string) (*rsa.PublicKey, error)
+
+// Verifier verifies incoming Atlassian JWTs.
+type Verifier struct {
+	secret       SecretFunc
+	keys         KeyFunc
+	audience     string
+	leeway       time.Duration
+	basePath     string
+	errorHandler func(w http.ResponseWriter, r *http.Request, err error)
+	now          func() time.Time
+}
+
+// Option configures a Verifier.
+type Option func(*Verifier)
+
+// WithLeeway sets the clock skew tolerated when checking iat and exp. The
+// default is DefaultLeeway.
+func WithLeeway(leeway time.Duration) Option {
+	return func(v *Verifier) {
+		v.leeway = leeway
+	}
+}
+
+// WithBasePath sets the path of the app's base URL, which Jira leaves out of
+// the query string hash. Use it when the app is served below a path, such as
+// https://example.com/my-app.
+func WithBasePath(basePath string) Option {
+	return func(v *Verifier) {
+		v.basePath = basePath
+	}
+}
+
+// WithInstallKeys enables the RS256 tokens of the installed and uninstalled
+// lifecycle callbacks. Their signing key is looked up by kid with keys, and
+// their aud claim must be appBaseURL, the baseUrl of the app descriptor.
+//
+// Example:
+//
+//	verify.WithInstallKeys(verify.CDNKeys(nil, ""), "https://my-app.example.com")
+func WithInstallKeys(keys KeyFunc, appBaseURL string) Option {
+	return func(v *Verifier) {
+		v.keys = keys
+		v.audience = appBaseURL
+	}
+}
+
+// CDNKeys returns a KeyFunc that fetches public keys from the install-keys
+// CDN at baseURL (DefaultInstallKeysURL when empty) with client
+// (http.DefaultClient when nil). Fetched keys are cached, since a key ID
+// always names the same key.
+func CDNKeys(client *http.Client, baseURL string) KeyFunc {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if baseURL == "" {
+		baseURL = DefaultInstallKeysURL
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	var cache sync.Map // kid -> *rsa.PublicKey
+	return func(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+		if kid == "" || kid == "." || kid == ".." {
+			return nil, fmt.Errorf("invalid key ID %q", kid)
+		}
+		if key, ok := cache.Load(kid); ok {
+			return key.(*rsa.PublicKey), nil
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/"+url.PathEscape(kid), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch key %q: %w", kid, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch key %q: HTTP %d", kid, resp.StatusCode)
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxKeySize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key %q: %w", kid, err)
+		}
+
+		key, err := parsePublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", kid, err)
+		}
+		cache.Store(kid, key)
+		return key, nil
+	}
+}
+
+// parsePublicKey parses a PEM encoded RSA public key.
+func parsePublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data")
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("not an RSA key: %T", parsed)
+	}
+	return key, nil
+}
+
+// WithErrorHandler replaces the response sent for requests that fail
+// verification. The default responds 401 Unauthorized.
+//
+// Example:
+//
+//	verify.WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+//		log.Printf("rejected %s: %v", r.URL.Path, err)
+//		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+//	})
+func WithErrorHandler(handler func(w http.ResponseWriter, r *http.Request, err error)) Option {
+	return func(v *Verifier) {
+		v.errorHandler = handler
+	}
+}
+
+// New creates a Verifier that looks shared secrets up with secret.
+func New(secret SecretFunc, opts ...Option) *Verifier {
+	v := &Verifier{
+		secret:       secret,
+		leeway:       DefaultLeeway,
+		errorHandler: unauthorized,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Middleware passes requests with a valid JWT on to next, with the claims
+// on the request context, and rejects the others.
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, err := v.Verify(r)
+		if err != nil {
+			v.errorHandler(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ContextWithClaims(r.Context(), claims)))
+	})
+}
+
+// Verify checks the JWT of r and returns its claims.
+//
+// The token is read from the Authorization header ("JWT <token>") or the
+// jwt query parameter. It must be signed with HS256 using the shared secret
+// of its issuer, or, with WithInstallKeys, with RS256 using the key named by
+// its kid and carry the app's base URL in its aud claim. It must not be
+// expired or issued in the future beyond the leeway, and its qsh claim must
+// match the request.
+func (v *Verifier) Verify(r *http.Request) (*auth.ConnectClaims, error) {
+	raw := tokenFromRequest(r)
+	if raw == "" {
+		return nil, ErrMissingToken
+	}
+
+	token, err := jwt.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	// The claims are unverified until the signature has been checked; only
+	// the issuer is used to find the secret
+	var claims auth.ConnectClaims
+	if err := json.Unmarshal(token.Claims, &claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.Issuer == "" {
+		return nil, fmt.Errorf("%w: no iss claim", ErrInvalidToken)
+	}
+
+	if token.Algorithm == jwt.RS256 {
+		err = v.verifyRS256(r.Context(), token)
+	} else {
+		err = v.verifyHS256(r.Context(), token, claims.Issuer)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := v.now()
+	if claims.ExpiresAt == 0 {
+		return nil, fmt.Errorf("%w: no exp claim", ErrInvalidToken)
+	}
+	if now.After(time.Unix(claims.ExpiresAt, 0).Add(v.leeway)) {
+		return nil, ErrExpired
+	}
+	if now.Add(v.leeway).Before(time.Unix(claims.IssuedAt, 0)) {
+		return nil, fmt.Errorf("%w: issued in the future", ErrInvalidToken)
+	}
+
+	if claims.QSH != auth.QueryStringHash(r.Method, r.URL, v.basePath) {
+		return nil, ErrQSHMismatch
+	}
+
+	return &claims, nil
+}
+
+// verifyHS256 checks the signature of token with the shared secret of issuer.
+func (v *Verifier) verifyHS256(ctx context.Context, token *jwt.Token, issuer string) error {
+	secret, err := v.secret(ctx, issuer)
+	if err != nil {
+		return fmt.Errorf("%w: unknown issuer %q: %v", ErrInvalidToken, issuer, err)
+	}
+	if secret == "" {
+		return fmt.Errorf("%w: no shared secret for issuer %q", ErrInvalidToken, issuer)
+	}
+	if err := token.Verify([]byte(secret)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return nil
+}
+
+// verifyRS256 checks the signature of a lifecycle callback token with the
+// install key named by its kid, and its audience.
+func (v *Verifier) verifyRS256(ctx context.Context, token *jwt.Token) error {
+	if v.keys == nil {
+		return fmt.Errorf("%w: RS256 tokens need WithInstallKeys", ErrInvalidToken)
+	}
+
+	key, err := v.keys(ctx, token.KeyID)
+	if err != nil {
+		return fmt.Errorf("%w: unknown key %q: %v", ErrInvalidToken, token.KeyID, err)
+	}
+	if err := token.VerifyRS256(key); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var claims struct {
+		Audience audience `json:"aud"`
+	}
+	if err := json.Unmarshal(token.Claims, &claims); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if v.audience == "" || !claims.Audience.contains(v.audience) {
+		return fmt.Errorf("%w: aud claim does not name the app", ErrInvalidToken)
+	}
+	return nil
+}
+
+// audience is an aud claim, which is a string or an array of strings.
+type audience []string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("invalid aud claim: %w", err)
+	}
+	*a = list
+	return nil
+}
+
+// contains reports whether the audience names baseURL, ignoring a trailing
+// slash.
+func (a audience) contains(baseURL string) bool {
+	want := strings.TrimSuffix(baseURL, "/")
+	for _, aud := range a {
+		if strings.TrimSuffix(aud, "/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenFromRequest returns the JWT of r, or "" if it has none.
+func tokenFromRequest(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		scheme, token, ok := strings.Cut(header, " ")
+		if ok && strings.EqualFold(scheme, "JWT") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return r.URL.Query().Get("jwt")
+}
+
+// unauthorized is the default error handler.
+func unauthorized(w http.ResponseWriter, _ *http.Request, _ error) {
+	http.Error(w, "Unauthorized", http.StatusUnauthorized)
+}
+
+// claimsKey is the context key for verified claims.
+type claimsKey struct{}
+
+// ContextWithClaims returns a context carrying verified claims.
+func ContextWithClaims(ctx context.Context, claims *auth.ConnectClaims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims the middleware verified.
+func ClaimsFromContext(ctx context.Context) (*auth.ConnectClaims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*auth.ConnectClaims)
+	return claims, ok
+}
@@ -0,0 +1,412 @@
+package verify
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/jirasdk/auth"
+	"github.com/felixgeelhaar/jirasdk/internal/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	clientKey    = "jira:6c9e1b7a-0b2f-4a8c-9d3e-2f1a0b9c8d7e"
+	sharedSecret = "shared-secret"
+)
+
+var testNow = time.Unix(1700000000, 0)
+
+// secrets knows one installation.
+func secrets(_ context.Context, key string) (string, error) {
+	if key != clientKey {
+		return "", errors.New("not installed")
+	}
+	return sharedSecret, nil
+}
+
+func newTestVerifier(opts ...Option) *Verifier {
+	v := New(secrets, opts...)
+	v.now = func() time.Time { return testNow }
+	return v
+}
+
+// signedRequest returns a request to target with a JWT for it in the
+// Authorization header.
+func signedRequest(t *testing.T, method, target string, edit func(*auth.ConnectClaims)) *http.Request {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, nil)
+	claims := &auth.ConnectClaims{
+		Issuer:    clientKey,
+		IssuedAt:  testNow.Add(-10 * time.Second).Unix(),
+		ExpiresAt: testNow.Add(3 * time.Minute).Unix(),
+		QSH:       auth.QueryStringHash(method, req.URL, ""),
+	}
+	if edit != nil {
+		edit(claims)
+	}
+
+	token, err := jwt.Sign(claims, []byte(sharedSecret))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "JWT "+token)
+	return req
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name    string
+		request func(t *testing.T) *http.Request
+		opts    []Option
+		wantErr error
+	}{
+		{
+			name: "valid token",
+			request: func(t *testing.T) *http.Request {
+				return signedRequest(t, http.MethodPost, "/webhooks/issue-updated?issueKey=PROJ-1&user_id=admin", nil)
+			},
+		},
+		{
+			name: "token in the query",
+			request: func(t *testing.T) *http.Request {
+				req := signedRequest(t, http.MethodGet, "/panel?xdm_e=https%3A%2F%2Fexample.atlassian.net&lic=active", nil)
+				token := req.Header.Get("Authorization")[len("JWT "):]
+				req.Header.Del("Authorization")
+				req.URL.RawQuery += "&jwt=" + url.QueryEscape(token)
+				return req
+			},
+		},
+		{
+			name: "no token",
+			request: func(t *testing.T) *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/webhooks/issue-created", nil)
+			},
+			wantErr: ErrMissingToken,
+		},
+		{
+			name: "other scheme",
+			request: func(t *testing.T) *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/panel", nil)
+				req.Header.Set("Authorization", "Bearer token")
+				return req
+			},
+			wantErr: ErrMissingToken,
+		},
+		{
+			name: "malformed token",
+			request: func(t *testing.T) *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/panel", nil)
+				req.Header.Set("Authorization", "JWT not-a-token")
+				return req
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "unsigned token",
+			request: func(t *testing.T) *http.Request {
+				req := signedRequest(t, http.MethodGet, "/panel", nil)
+				none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+				token := req.Header.Get("Authorization")[len("JWT "):]
+				parts := strings.Split(token, ".")
+				req.Header.Set("Authorization", "JWT "+none+"."+parts[1]+".")
+				return req
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "RS256 without install keys",
+			request: func(t *testing.T) *http.Request {
+				req := signedRequest(t, http.MethodPost, "/installed", nil)
+				rs256 := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT","kid":"0e50fccb-239d-4991-a5db-dc850ba3f236"}`))
+				token := req.Header.Get("Authorization")[len("JWT "):]
+				parts := strings.Split(token, ".")
+				req.Header.Set("Authorization", "JWT "+rs256+"."+parts[1]+"."+parts[2])
+				return req
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "unknown issuer",
+			request: func(t *testing.T) *http.Request {
+				return signedRequest(t, http.MethodGet, "/panel", func(c *auth.ConnectClaims) { c.Issuer = "jira:other" })
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "wrong secret",
+			request: func(t *testing.T) *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/panel", nil)
+				token, err := jwt.Sign(&auth.ConnectClaims{
+					Issuer:    clientKey,
+					IssuedAt:  testNow.Unix(),
+					ExpiresAt: testNow.Add(time.Minute).Unix(),
+					QSH:       auth.QueryStringHash(http.MethodGet, req.URL, ""),
+				}, []byte("guessed-secret"))
+				require.NoError(t, err)
+				req.Header.Set("Authorization", "JWT "+token)
+				return req
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "expired",
+			request: func(t *testing.T) *http.Request {
+				return signedRequest(t, http.MethodGet, "/panel", func(c *auth.ConnectClaims) {
+					c.ExpiresAt = testNow.Add(-time.Minute).Unix()
+				})
+			},
+			wantErr: ErrExpired,
+		},
+		{
+			name: "expired within the leeway",
+			request: func(t *testing.T) *http.Request {
+				return signedRequest(t, http.MethodGet, "/panel", func(c *auth.ConnectClaims) {
+					c.ExpiresAt = testNow.Add(-10 * time.Second).Unix()
+				})
+			},
+		},
+		{
+			name: "expired beyond a smaller leeway",
+			request: func(t *testing.T) *http.Request {
+				return signedRequest(t, http.MethodGet, "/panel", func(c *auth.ConnectClaims) {
+					c.ExpiresAt = testNow.Add(-10 * time.Second).Unix()
+				})
+			},
+			opts:    []Option{WithLeeway(5 * time.Second)},
+			wantErr: ErrExpired,
+		},
+		{
+			name: "no expiry",
+			request: func(t *testing.T) *http.Request {
+				return signedRequest(t, http.MethodGet, "/panel", func(c *auth.ConnectClaims) { c.ExpiresAt = 0 })
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "issued in the future",
+			request: func(t *testing.T) *http.Request {
+				return signedRequest(t, http.MethodGet, "/panel", func(c *auth.ConnectClaims) {
+					c.IssuedAt = testNow.Add(time.Hour).Unix()
+				})
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "tampered query",
+			request: func(t *testing.T) *http.Request {
+				req := signedRequest(t, http.MethodGet, "/panel?projectKey=PROJ", nil)
+				req.URL.RawQuery = "projectKey=SECRET"
+				return req
+			},
+			wantErr: ErrQSHMismatch,
+		},
+		{
+			name: "token for another method",
+			request: func(t *testing.T) *http.Request {
+				req := signedRequest(t, http.MethodGet, "/webhooks/issue-deleted", nil)
+				req.Method = http.MethodPost
+				return req
+			},
+			wantErr: ErrQSHMismatch,
+		},
+		{
+			name: "base path",
+			request: func(t *testing.T) *http.Request {
+				// Jira hashes the path relative to the app's base URL
+				req := signedRequest(t, http.MethodPost, "/webhooks/issue-created", nil)
+				req.URL.Path = "/my-app/webhooks/issue-created"
+				return req
+			},
+			opts: []Option{WithBasePath("/my-app")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := newTestVerifier(tt.opts...).Verify(tt.request(t))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.ErrorIs(t, err, ErrUnauthorized)
+				assert.Nil(t, claims)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, clientKey, claims.Issuer)
+		})
+	}
+}
+
+func TestVerify_SignedByConnectJWTAuth(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "https://app.example.com/rest/data?b=2&a=1", nil)
+	require.NoError(t, auth.NewConnectJWTAuth(clientKey, sharedSecret).WithSubject("5b10ac8d82e05b22cc7d4ef5").Authenticate(req))
+
+	claims, err := New(secrets).Verify(req)
+	require.NoError(t, err)
+	assert.Equal(t, "5b10ac8d82e05b22cc7d4ef5", claims.Subject)
+
+	req = httptest.NewRequest(http.MethodGet, "https://example.com/my-app/rest/data?a=1", nil)
+	require.NoError(t, auth.NewConnectJWTAuth(clientKey, sharedSecret, auth.WithConnectBasePath("/my-app")).Authenticate(req))
+
+	_, err = New(secrets, WithBasePath("/my-app")).Verify(req)
+	require.NoError(t, err)
+}
+
+func TestMiddleware(t *testing.T) {
+	var got *auth.ConnectClaims
+	handler := newTestVerifier().Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = ClaimsFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, signedRequest(t, http.MethodPost, "/webhooks/issue-created", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	require.NotNil(t, got)
+	assert.Equal(t, clientKey, got.Issuer)
+
+	got = nil
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhooks/issue-created", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Nil(t, got, "the handler is not called")
+
+	_, ok := ClaimsFromContext(context.Background())
+	assert.False(t, ok)
+}
+
+func TestMiddleware_ErrorHandler(t *testing.T) {
+	var handled error
+	verifier := newTestVerifier(WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		handled = err
+		http.Error(w, "go away", http.StatusForbidden)
+	}))
+	handler := verifier.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler called for an unverified request")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, signedRequest(t, http.MethodGet, "/panel", func(c *auth.ConnectClaims) { c.QSH = "forged" }))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.ErrorIs(t, handled, ErrQSHMismatch)
+}
+
+// installKeys serves one RSA public key like the install-keys CDN.
+type installKeys struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	kid    string
+	calls  int
+}
+
+func newInstallKeys(t *testing.T) *installKeys {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	keys := &installKeys{key: key, kid: "0e50fccb-239d-4991-a5db-dc850ba3f236"}
+	keys.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys.calls++
+		if r.URL.Path != "/"+keys.kid {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(pemKey)
+	}))
+	t.Cleanup(keys.server.Close)
+	return keys
+}
+
+// lifecycleRequest returns an installed callback signed with RS256 by key.
+func lifecycleRequest(t *testing.T, key *rsa.PrivateKey, kid string, aud interface{}) *http.Request {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/installed", nil)
+	claims := map[string]interface{}{
+		"iss": clientKey,
+		"iat": testNow.Add(-10 * time.Second).Unix(),
+		"exp": testNow.Add(3 * time.Minute).Unix(),
+		"qsh": auth.QueryStringHash(http.MethodPost, req.URL, ""),
+		"aud": aud,
+	}
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	req.Header.Set("Authorization", "JWT "+signingInput+"."+base64.RawURLEncoding.EncodeToString(sig))
+	return req
+}
+
+func TestVerify_InstallKeys(t *testing.T) {
+	const appBaseURL = "https://my-app.example.com"
+	keys := newInstallKeys(t)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	verifier := newTestVerifier(WithInstallKeys(CDNKeys(keys.server.Client(), keys.server.URL), appBaseURL))
+
+	tests := []struct {
+		name    string
+		request *http.Request
+		wantErr error
+	}{
+		{name: "installed callback", request: lifecycleRequest(t, keys.key, keys.kid, appBaseURL)},
+		{name: "audience list", request: lifecycleRequest(t, keys.key, keys.kid, []string{"https://other.example.com", appBaseURL + "/"})},
+		{name: "other audience", request: lifecycleRequest(t, keys.key, keys.kid, "https://other.example.com"), wantErr: ErrInvalidToken},
+		{name: "no audience", request: lifecycleRequest(t, keys.key, keys.kid, nil), wantErr: ErrInvalidToken},
+		{name: "unknown key", request: lifecycleRequest(t, keys.key, "unknown", appBaseURL), wantErr: ErrInvalidToken},
+		{name: "signed with another key", request: lifecycleRequest(t, other, keys.kid, appBaseURL), wantErr: ErrInvalidToken},
+		{name: "HS256 still verified", request: signedRequest(t, http.MethodGet, "/panel", nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := verifier.Verify(tt.request)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, clientKey, claims.Issuer)
+		})
+	}
+
+	assert.Equal(t, 2, keys.calls, "a fetched key is cached")
+}
+
+func TestCDNKeys(t *testing.T) {
+	keys := newInstallKeys(t)
+	fetch := CDNKeys(keys.server.Client(), keys.server.URL+"/")
+
+	key, err := fetch(context.Background(), keys.kid)
+	require.NoError(t, err)
+	assert.True(t, key.Equal(&keys.key.PublicKey))
+
+	for _, kid := range []string{"", "..", "missing"} {
+		_, err := fetch(context.Background(), kid)
+		assert.Error(t, err, kid)
+	}
+}
@@ -0,0 +1,78 @@
+package jirasdk
+
+import (
+	"context"
+	"time"
+
+	"github.com/felixgeelhaar/jirasdk/transport"
+)
+
+// CallOption overrides a client setting for the calls made with a context.
+type CallOption = transport.CallOption
+
+// CachePolicy controls how a single call uses the response cache.
+type CachePolicy = transport.CachePolicy
+
+// Cache policies for CallCachePolicy.
+const (
+	CacheDefault = transport.CacheDefault
+	CacheBypass  = transport.CacheBypass
+	CacheRefresh = transport.CacheRefresh
+)
+
+// WithCallOptions returns a context whose calls override the client's
+// timeout, retry count, headers or cache policy.
+//
+// One client can then serve calls with different needs: a latency-sensitive
+// UI call can fail fast while a background sync on the same client retries
+// aggressively. Options already attached to ctx are kept unless overridden.
+//
+// Example:
+//
+//	ctx := jira.WithCallOptions(ctx,
+//		jira.CallTimeout(2*time.Second),
+//		jira.CallMaxRetries(1),
+//		jira.CallHeader("Accept-Language", "de-DE"),
+//	)
+//	issue, err := client.Issue.Get(ctx, "PROJ-123", nil)
+func WithCallOptions(ctx context.Context, opts ...CallOption) context.Context {
+	return transport.ContextWithCallOptions(ctx, opts...)
+}
+
+// CallTimeout limits the call, including retries and reading the response,
+// to timeout. It cannot extend the client's HTTP timeout.
+//
+// Example:
+//
+//	CallTimeout(2 * time.Second)
+func CallTimeout(timeout time.Duration) CallOption {
+	return transport.CallTimeout(timeout)
+}
+
+// CallMaxRetries sets the maximum number of retries for the call. It has no
+// effect when the Resilience layer handles retries.
+//
+// Example:
+//
+//	CallMaxRetries(10)
+func CallMaxRetries(maxRetries int) CallOption {
+	return transport.CallMaxRetries(maxRetries)
+}
+
+// CallHeader sets a request header for the call.
+//
+// Example:
+//
+//	CallHeader("X-Atlassian-Force-Account-Id", "true")
+func CallHeader(key, value string) CallOption {
+	return transport.CallHeader(key, value)
+}
+
+// CallCachePolicy sets how the call uses the response cache.
+//
+// Example:
+//
+//	CallCachePolicy(CacheRefresh)
+func CallCachePolicy(policy CachePolicy) CallOption {
+	return transport.CallCachePolicy(policy)
+}
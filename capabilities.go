@@ -0,0 +1,51 @@
+package jirasdk
+
+import (
+	"context"
+
+	"github.com/felixgeelhaar/jirasdk/transport"
+)
+
+// Capabilities describes the deployment type and version of the Jira
+// instance a client talks to.
+type Capabilities = transport.Capabilities
+
+// Feature is an API feature whose availability depends on the Jira
+// deployment and version.
+type Feature = transport.Feature
+
+// Features for Capabilities.Supports.
+const (
+	FeatureEnhancedSearch       = transport.FeatureEnhancedSearch
+	FeatureLegacySearch         = transport.FeatureLegacySearch
+	FeatureExpressionEvaluate   = transport.FeatureExpressionEvaluate
+	FeatureLegacyExpressionEval = transport.FeatureLegacyExpressionEval
+)
+
+// Deployment types for Capabilities.DeploymentType.
+const (
+	DeploymentCloud      = transport.DeploymentCloud
+	DeploymentServer     = transport.DeploymentServer
+	DeploymentDataCenter = transport.DeploymentDataCenter
+)
+
+// Capabilities detects whether the client talks to Jira Cloud or
+// Server/Data Center, and which version, using serverInfo.
+//
+// The result is cached, so only the first call sends a request. Services
+// consult the same result to pick endpoints, such as search/jql on Cloud and
+// search on Server/Data Center, and fail calls the instance cannot serve with
+// an error matching ErrUnsupported before sending them.
+//
+// Example:
+//
+//	caps, err := client.Capabilities(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	if !caps.IsCloud() && !caps.VersionAtLeast(9, 12) {
+//		log.Printf("Jira %s is older than 9.12", caps.Version)
+//	}
+func (c *Client) Capabilities(ctx context.Context) (*Capabilities, error) {
+	return c.Transport.Capabilities(ctx)
+}
@@ -0,0 +1,64 @@
+package jirasdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/felixgeelhaar/jirasdk/core/expression"
+	"github.com/felixgeelhaar/jirasdk/core/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Capabilities(t *testing.T) {
+	var serverInfoCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/api/3/serverInfo":
+			atomic.AddInt32(&serverInfoCalls, 1)
+			_, _ = w.Write([]byte(`{"deploymentType":"Server","version":"8.20.10","versionNumbers":[8,20,10]}`))
+		case "/rest/api/3/search":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "project = PROJ", body["jql"])
+			assert.Equal(t, float64(0), body["startAt"])
+			_, _ = w.Write([]byte(`{"startAt":0,"maxResults":1,"total":2,"issues":[{"key":"PROJ-1"}]}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(
+		WithBaseURL(server.URL),
+		WithPAT("token"),
+		WithMaxRetries(0),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	caps, err := client.Capabilities(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, DeploymentServer, caps.DeploymentType)
+	assert.False(t, caps.IsCloud())
+	assert.True(t, caps.VersionAtLeast(8, 20))
+	assert.False(t, caps.Supports(FeatureEnhancedSearch))
+
+	// SearchJQL falls back to the offset-paginated endpoint
+	result, err := client.Search.SearchJQL(ctx, &search.SearchJQLOptions{JQL: "project = PROJ", MaxResults: 1})
+	require.NoError(t, err)
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, "PROJ-1", result.Issues[0].Key)
+	assert.Equal(t, "1", result.NextPageToken)
+
+	// Expressions are Cloud only and fail before a request is sent
+	_, err = client.Expression.EvaluateExpression(ctx, &expression.EvaluationInput{Expression: "issue.summary"})
+	assert.ErrorIs(t, err, ErrUnsupported)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&serverInfoCalls), "capabilities are detected once")
+}
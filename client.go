@@ -124,6 +124,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/felixgeelhaar/jirasdk/auth"
@@ -155,6 +156,10 @@ import (
 	"github.com/felixgeelhaar/jirasdk/core/webhook"
 	"github.com/felixgeelhaar/jirasdk/core/workflow"
 	"github.com/felixgeelhaar/jirasdk/transport"
+	"github.com/felixgeelhaar/jirasdk/transport/recorder"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -216,6 +221,7 @@ type Client struct {
 type Config struct {
 	baseURL           *url.URL
 	authenticator     auth.Authenticator
+	tokenStore        auth.OAuth2TokenStore
 	httpClient        *http.Client
 	timeout           time.Duration
 	maxRetries        int
@@ -223,8 +229,23 @@ type Config struct {
 	middlewares       []transport.Middleware
 	userAgent         string
 	enableCompression bool
+	minGzipSize       int
 	logger            Logger
 	resilience        Resilience
+	governor          *transport.RateLimitGovernor
+	retryPolicy       transport.RetryPolicy
+	cache             transport.Cache
+	cacheRules        []transport.CacheRule
+	coalesce          bool
+	maxResponseSize   int64
+	apiFlavor         APIFlavor
+	usernameResolver  UsernameResolver
+	recorder          *recorder.Recorder
+	debug             []transport.DebugOption
+	dryRun            *transport.Plan
+	metrics           Metrics
+	tracerProvider    trace.TracerProvider
+	propagator        propagation.TextMapPropagator
 }
 
 // Option is a functional option for configuring the Client.
@@ -269,6 +290,28 @@ func NewClient(opts ...Option) (*Client, error) {
 		return nil, fmt.Errorf("authentication method is required")
 	}
 
+	// Connect JWTs hash request paths relative to the base URL's path
+	if connect, ok := cfg.authenticator.(*auth.ConnectJWTAuth); ok {
+		basePath := strings.TrimSuffix(cfg.baseURL.EscapedPath(), "/")
+		switch set := strings.TrimSuffix(connect.BasePath(), "/"); {
+		case set == "" && basePath != "":
+			cfg.authenticator = connect.WithBasePath(basePath)
+		case set != basePath:
+			return nil, fmt.Errorf("connect base path %q does not match the base URL path %q", connect.BasePath(), basePath)
+		}
+	}
+
+	// Load the stored OAuth 2.0 token before the first request
+	if cfg.tokenStore != nil {
+		oauth, ok := cfg.authenticator.(*auth.OAuth2Authenticator)
+		if !ok {
+			return nil, fmt.Errorf("token store requires OAuth 2.0 authentication")
+		}
+		if err := oauth.SetTokenStore(cfg.tokenStore); err != nil {
+			return nil, fmt.Errorf("failed to configure token store: %w", err)
+		}
+	}
+
 	// Create HTTP client if not provided
 	if cfg.httpClient == nil {
 		cfg.httpClient = &http.Client{
@@ -276,17 +319,39 @@ func NewClient(opts ...Option) (*Client, error) {
 		}
 	}
 
+	// Route requests through the recorder, after all middleware has run
+	if cfg.recorder != nil {
+		cfg.httpClient = cfg.recorder.Client(cfg.httpClient)
+	}
+
 	// Create transport with middleware
-	tr := transport.New(
-		cfg.httpClient,
-		cfg.baseURL,
+	transportOpts := []transport.TransportOption{
 		transport.WithAuthenticator(cfg.authenticator),
 		transport.WithMaxRetries(cfg.maxRetries),
 		transport.WithRateLimitBuffer(cfg.rateLimitBuffer),
 		transport.WithUserAgent(cfg.userAgent),
-		transport.WithLogger(cfg.logger),
+		transport.WithLogger(transportLogger{logger: cfg.logger}),
+		transport.WithResilience(cfg.resilience),
+		transport.WithRateLimitGovernor(cfg.governor),
+		transport.WithRetryPolicy(cfg.retryPolicy),
+		transport.WithCompression(cfg.enableCompression),
+		transport.WithRequestCompression(cfg.minGzipSize),
+		transport.WithCache(cfg.cache, cfg.cacheRules...),
+		transport.WithCoalescing(cfg.coalesce),
+		transport.WithMaxResponseSize(cfg.maxResponseSize),
+		transport.WithAPIFlavor(cfg.apiFlavor),
+		transport.WithADFRenderer(renderWiki),
+		transport.WithUsernameResolver(cfg.usernameResolver),
+		transport.WithDryRun(cfg.dryRun),
+		transport.WithMetrics(cfg.metrics),
+		transport.WithTracing(cfg.tracerProvider),
+		transport.WithPropagator(cfg.propagator),
 		transport.WithMiddlewares(cfg.middlewares...),
-	)
+	}
+	if cfg.debug != nil {
+		transportOpts = append(transportOpts, transport.WithDebug(cfg.debug...))
+	}
+	tr := transport.New(cfg.httpClient, cfg.baseURL, transportOpts...)
 
 	client := &Client{
 		BaseURL:       cfg.baseURL,
@@ -348,6 +413,28 @@ func WithBaseURL(baseURL string) Option {
 	}
 }
 
+// WithCloudID routes requests for the Jira Cloud site with cloudID through
+// the Atlassian API gateway, https://api.atlassian.com/ex/jira/{cloudId}.
+//
+// OAuth 2.0 (3LO) tokens are only accepted there, not at the site URL. Find
+// the cloud ID of each site with auth.OAuth2Authenticator.AccessibleResources.
+// WithCloudID replaces any base URL set with WithBaseURL.
+//
+// Example:
+//
+//	client, err := jira.NewClient(
+//		jira.WithCloudID(resources[0].ID),
+//		jira.WithOAuth2(oauth),
+//	)
+func WithCloudID(cloudID string) Option {
+	return func(cfg *Config) error {
+		if cloudID == "" || strings.ContainsAny(cloudID, "/?#") {
+			return fmt.Errorf("invalid cloud ID: %q", cloudID)
+		}
+		return WithBaseURL(auth.JiraGatewayURL(cloudID))(cfg)
+	}
+}
+
 // WithAPIToken configures API token authentication for Jira Cloud.
 //
 // This is the recommended authentication method for Jira Cloud.
@@ -368,6 +455,9 @@ func WithAPIToken(email, token string) Option {
 
 // WithPAT configures Personal Access Token authentication for Jira Server/Data Center.
 //
+// Combine it with WithAPIFlavor(APIFlavorDataCenter) so that services use
+// REST API v2.
+//
 // Example:
 //
 //	WithPAT("your-personal-access-token")
@@ -402,6 +492,32 @@ func WithOAuth2(oauth *auth.OAuth2Authenticator) Option {
 	}
 }
 
+// WithTokenStore keeps the OAuth 2.0 token in store.
+//
+// NewClient loads the stored token, if there is one, and every token obtained
+// later by Exchange or a refresh is saved back. It requires WithOAuth2.
+//
+// Example:
+//
+//	store, err := auth.NewFileTokenStore("/var/lib/myapp/jira-token", key)
+//	if err != nil {
+//	    return err
+//	}
+//	client, err := jira.NewClient(
+//	    jira.WithBaseURL("https://api.atlassian.com/ex/jira/your-cloud-id"),
+//	    jira.WithOAuth2(oauth),
+//	    jira.WithTokenStore(store),
+//	)
+func WithTokenStore(store auth.OAuth2TokenStore) Option {
+	return func(cfg *Config) error {
+		if store == nil {
+			return fmt.Errorf("token store is required")
+		}
+		cfg.tokenStore = store
+		return nil
+	}
+}
+
 // WithBasicAuth configures basic authentication (legacy, not recommended).
 //
 // Example:
@@ -417,6 +533,24 @@ func WithBasicAuth(username, password string) Option {
 	}
 }
 
+// WithAuthenticator configures a custom authenticator.
+//
+// Use it for authentication schemes without a dedicated option, or when the
+// authenticator is built elsewhere, for example by a TenantConfigProvider.
+//
+// Example:
+//
+//	WithAuthenticator(auth.NewPATAuth("your-personal-access-token"))
+func WithAuthenticator(authenticator auth.Authenticator) Option {
+	return func(cfg *Config) error {
+		if authenticator == nil {
+			return fmt.Errorf("authenticator cannot be nil")
+		}
+		cfg.authenticator = authenticator
+		return nil
+	}
+}
+
 // WithTimeout sets the HTTP client timeout.
 //
 // Example:
@@ -447,7 +581,8 @@ func WithMaxRetries(maxRetries int) Option {
 	}
 }
 
-// WithRateLimitBuffer sets the buffer time before rate limit reset.
+// WithRateLimitBuffer sets how long requests are held after a 429, on top of
+// its Retry-After delay.
 //
 // Example:
 //
@@ -511,6 +646,258 @@ func WithUserAgent(userAgent string) Option {
 	}
 }
 
+// WithCompression enables or disables response compression.
+//
+// Compression is enabled by default: requests advertise gzip and deflate and
+// compressed responses are decoded while they are read, which keeps large
+// search and bulk responses small on the wire.
+//
+// Example:
+//
+//	WithCompression(false)
+func WithCompression(enabled bool) Option {
+	return func(cfg *Config) error {
+		cfg.enableCompression = enabled
+		return nil
+	}
+}
+
+// WithRequestCompression gzips request bodies of at least minSize bytes.
+//
+// This is off by default. It is useful for large bulk create payloads; a
+// minSize of zero turns it off again. It has no effect when compression is
+// disabled with WithCompression(false).
+//
+// Example:
+//
+//	WithRequestCompression(64 * 1024)
+func WithRequestCompression(minSize int) Option {
+	return func(cfg *Config) error {
+		if minSize < 0 {
+			return fmt.Errorf("request compression size must be non-negative")
+		}
+		cfg.minGzipSize = minSize
+		return nil
+	}
+}
+
+// WithCache caches GET responses in cache.
+//
+// Reference data such as fields, priorities, statuses and issue types is
+// served from the cache for the TTL of the matching rule (DefaultCacheRules
+// from the transport package when no rules are given). Other responses that
+// carry an ETag or Last-Modified header are revalidated with a conditional
+// request, which Jira answers with a small 304 when nothing changed.
+//
+// Cache keys include the client's credentials, so one cache can be shared by
+// clients that authenticate as different users.
+//
+// Example:
+//
+//	WithCache(transport.NewMemoryCache(1000),
+//		transport.CacheRule{Endpoint: "/project/{projectIdOrKey}", TTL: 10 * time.Minute},
+//	)
+func WithCache(cache transport.Cache, rules ...transport.CacheRule) Option {
+	return func(cfg *Config) error {
+		if cache == nil {
+			return fmt.Errorf("cache cannot be nil")
+		}
+		cfg.cache = cache
+		cfg.cacheRules = rules
+		return nil
+	}
+}
+
+// WithCoalescing merges identical concurrent GET requests into one upstream call.
+//
+// Worker pools that fetch the same issue, user or project from many goroutines
+// at once then consume one request of rate limit quota instead of one per
+// goroutine. Requests are only merged when they have the same URL and
+// credentials, and every caller receives its own copy of the response body.
+//
+// Example:
+//
+//	WithCoalescing(true)
+func WithCoalescing(enabled bool) Option {
+	return func(cfg *Config) error {
+		cfg.coalesce = enabled
+		return nil
+	}
+}
+
+// WithMaxResponseSize limits response bodies to limit bytes.
+//
+// A larger response fails with an error matching ErrResponseTooLarge instead
+// of being read into memory, which guards against unexpectedly large search
+// or audit pages. The limit applies to the decompressed body. Zero, the
+// default, means no limit.
+//
+// Example:
+//
+//	WithMaxResponseSize(64 << 20) // 64 MiB
+func WithMaxResponseSize(limit int64) Option {
+	return func(cfg *Config) error {
+		if limit < 0 {
+			return fmt.Errorf("max response size must be non-negative")
+		}
+		cfg.maxResponseSize = limit
+		return nil
+	}
+}
+
+// WithRecorder records requests to, or replays responses from, a cassette file.
+//
+// The recorder sits below the middleware chain, so it sees requests exactly as
+// they are sent to Jira (with Authorization redacted) and replayed responses
+// go through retry, rate limit and error handling like real ones.
+//
+// Example:
+//
+//	rec, err := recorder.New("testdata/issue.json", recorder.ModeReplay)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer rec.Stop()
+//
+//	client, err := NewClient(
+//		WithBaseURL("https://example.atlassian.net"),
+//		WithAPIToken("user@example.com", "token"),
+//		WithRecorder(rec),
+//	)
+func WithRecorder(rec *recorder.Recorder) Option {
+	return func(cfg *Config) error {
+		if rec == nil {
+			return fmt.Errorf("recorder cannot be nil")
+		}
+		cfg.recorder = rec
+		return nil
+	}
+}
+
+// WithDebug dumps the full HTTP traffic, including request and response
+// bodies, for debugging.
+//
+// Each request attempt and its response are written to the configured Logger
+// at debug level, or to the writer given with transport.DebugToWriter.
+// Authorization headers, cookies and OAuth tokens are always redacted; use
+// transport.DebugRedactFields to redact JSON fields such as emailAddress.
+// Large bodies are truncated, and transport.DebugSampleRate dumps only a
+// share of successful calls. Failed calls are always dumped.
+//
+// Example:
+//
+//	WithDebug(
+//		transport.DebugToWriter(os.Stderr),
+//		transport.DebugRedactFields("emailAddress", "fields.reporter.displayName"),
+//		transport.DebugSampleRate(0.1),
+//	)
+func WithDebug(opts ...transport.DebugOption) Option {
+	return func(cfg *Config) error {
+		cfg.debug = append([]transport.DebugOption{}, opts...)
+		return nil
+	}
+}
+
+// WithDryRun puts the client in dry-run mode.
+//
+// Mutating requests (POST, PUT, PATCH and DELETE) are not sent to Jira.
+// Instead they are recorded into plan with their method, path and decoded
+// JSON body, and a synthetic success is returned. GET requests and read-only
+// POSTs such as searches still go through. Inspect the plan afterwards, or
+// export it with plan.WriteJSON or plan.Diff.
+//
+// To enable dry-run mode for individual calls only, pass a context from
+// transport.ContextWithDryRun instead.
+//
+// Example:
+//
+//	plan := transport.NewPlan()
+//	client, err := NewClient(
+//		WithBaseURL("https://example.atlassian.net"),
+//		WithAPIToken("user@example.com", "token"),
+//		WithDryRun(plan),
+//	)
+//	// ... run the clean-up script ...
+//	fmt.Print(plan.Diff())
+func WithDryRun(plan *transport.Plan) Option {
+	return func(cfg *Config) error {
+		if plan == nil {
+			return fmt.Errorf("dry-run plan cannot be nil")
+		}
+		cfg.dryRun = plan
+		return nil
+	}
+}
+
+// WithMetrics records request counts, errors, latency, retries and rate
+// limit waits for every API call.
+//
+// Each call is reported once, after all retries, with labels for the Jira
+// service, operation (HTTP method), endpoint template and status class. Raw
+// paths such as /rest/api/3/issue/PROJ-123 are reported as their template
+// /rest/api/3/issue/{issueIdOrKey}, so label cardinality stays bounded.
+//
+// By default no metrics are recorded. Use the OpenTelemetry implementation
+// in metrics/otel or provide your own.
+//
+// Example:
+//
+//	import otelmetrics "github.com/felixgeelhaar/jirasdk/metrics/otel"
+//
+//	metrics, err := otelmetrics.New(meterProvider)
+//	WithMetrics(metrics)
+func WithMetrics(metrics Metrics) Option {
+	return func(cfg *Config) error {
+		if metrics == nil {
+			return fmt.Errorf("metrics cannot be nil")
+		}
+		cfg.metrics = metrics
+		return nil
+	}
+}
+
+// WithTracing creates an OpenTelemetry client span for every API call.
+//
+// Spans are named after the endpoint template, e.g.
+// "GET /rest/api/3/issue/{issueIdOrKey}", so they group well in tracing
+// backends. Besides the HTTP client attributes they carry jira.service,
+// jira.issue.key, jira.retry.count, jira.rate_limit.wait_ms and
+// jira.request_id; retries and rate limit waits are recorded as span events.
+// The trace context is propagated to Jira in the request headers.
+//
+// A nil provider uses the global tracer provider from otel.GetTracerProvider.
+//
+// Example:
+//
+//	WithTracing(sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter)))
+func WithTracing(provider trace.TracerProvider) Option {
+	return func(cfg *Config) error {
+		if provider == nil {
+			provider = otel.GetTracerProvider()
+		}
+		cfg.tracerProvider = provider
+		return nil
+	}
+}
+
+// WithPropagator sets the propagator used to inject trace context into
+// requests when tracing is enabled.
+//
+// By default the global propagator from otel.GetTextMapPropagator is used.
+//
+// Example:
+//
+//	WithPropagator(propagation.TraceContext{})
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(cfg *Config) error {
+		if propagator == nil {
+			return fmt.Errorf("propagator cannot be nil")
+		}
+		cfg.propagator = propagator
+		return nil
+	}
+}
+
 // WithLogger sets a custom logger for structured logging.
 //
 // By default, a no-op logger is used. Use the bolt adapter for
@@ -539,6 +926,11 @@ func WithLogger(logger Logger) Option {
 // production-grade resilience patterns including circuit breakers, advanced retries,
 // rate limiting, timeouts, and bulkheads.
 //
+// The resilience layer wraps every request attempt and runs inside the built-in
+// retry middleware. If the implementation retries on its own (see
+// transport.RetryingResilience), the built-in retries are disabled so the two
+// do not stack. Handling of 429 responses and Retry-After is unaffected.
+//
 // Example:
 //
 //	import "github.com/felixgeelhaar/jirasdk/resilience/fortify"
@@ -555,10 +947,67 @@ func WithResilience(resilience Resilience) Option {
 	}
 }
 
+// WithRetryPolicy sets the policy that decides which failed requests are retried.
+//
+// By default a transport.DefaultRetryPolicy built from WithMaxRetries is used.
+// It retries idempotent requests on network errors and 5xx responses, retries
+// non-idempotent POSTs such as issue creation only on 429, and honours
+// Retry-After. A custom policy replaces WithMaxRetries.
+//
+// Example:
+//
+//	policy := transport.NewDefaultRetryPolicy(5)
+//	policy.Budget = transport.NewRetryBudget(10, 0.1)
+//	WithRetryPolicy(policy)
+func WithRetryPolicy(policy transport.RetryPolicy) Option {
+	return func(cfg *Config) error {
+		if policy == nil {
+			return fmt.Errorf("retry policy cannot be nil")
+		}
+		cfg.retryPolicy = policy
+		return nil
+	}
+}
+
+// WithRateLimitGovernor shares a rate limit governor between clients.
+//
+// The governor learns Jira's quota from the Beta-RateLimit and X-RateLimit
+// response headers and delays requests before the quota runs out. Each client
+// gets its own governor by default; pass the same one to several clients that
+// call the same Jira site so they throttle against a single budget.
+//
+// Example:
+//
+//	governor := transport.NewRateLimitGovernor()
+//	WithRateLimitGovernor(governor)
+func WithRateLimitGovernor(governor *transport.RateLimitGovernor) Option {
+	return func(cfg *Config) error {
+		if governor == nil {
+			return fmt.Errorf("rate limit governor cannot be nil")
+		}
+		cfg.governor = governor
+		return nil
+	}
+}
+
+// RateLimitBudget returns the rate limit quota learned from recent responses.
+//
+// Budget.Known is false until Jira has returned rate limit headers.
+//
+// Example:
+//
+//	if b := client.RateLimitBudget(); b.Known && b.Remaining < 100 {
+//		time.Sleep(b.Window / 2)
+//	}
+func (c *Client) RateLimitBudget() transport.RateLimitBudget {
+	return c.Transport.RateLimitBudget()
+}
+
 // Do executes an HTTP request with context.
 //
 // This is a low-level method for advanced use cases. Most users should
-// use the domain-specific service methods instead.
+// use the domain-specific service methods instead, or Get, Post and List for
+// endpoints that have none.
 func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
 	return c.Transport.Do(ctx, req)
 }
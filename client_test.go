@@ -1,13 +1,28 @@
 package jirasdk
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/felixgeelhaar/jirasdk/auth"
+	"github.com/felixgeelhaar/jirasdk/core/issue"
 	"github.com/felixgeelhaar/jirasdk/transport"
+	"github.com/felixgeelhaar/jirasdk/transport/recorder"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"golang.org/x/oauth2"
 )
 
 func TestNewClient(t *testing.T) {
@@ -494,3 +509,493 @@ func TestWithMiddleware(t *testing.T) {
 		})
 	}
 }
+
+// recordingResilience counts the requests routed through it.
+type recordingResilience struct {
+	calls int
+}
+
+func (r *recordingResilience) ExecuteRequest(ctx context.Context, req *http.Request, do func(context.Context, *http.Request) (*http.Response, error)) (*http.Response, error) {
+	r.calls++
+	return do(ctx, req)
+}
+
+func TestWithResilience(t *testing.T) {
+	t.Run("nil resilience", func(t *testing.T) {
+		cfg := &Config{}
+		err := WithResilience(nil)(cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("requests pass through resilience", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"version":"1001.0.0"}`))
+		}))
+		defer server.Close()
+
+		resilience := &recordingResilience{}
+		client, err := NewClient(
+			WithBaseURL(server.URL),
+			WithAPIToken("user@example.com", "token"),
+			WithResilience(resilience),
+		)
+		require.NoError(t, err)
+
+		_, err = client.ServerInfo.Get(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, resilience.calls)
+	})
+}
+
+func TestWithRateLimitGovernor(t *testing.T) {
+	t.Run("nil governor", func(t *testing.T) {
+		cfg := &Config{}
+		err := WithRateLimitGovernor(nil)(cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("budget learned from responses", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-RateLimit-Limit", "500")
+			w.Header().Set("X-RateLimit-Remaining", "420")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{}`))
+		}))
+		defer server.Close()
+
+		governor := transport.NewRateLimitGovernor()
+		client, err := NewClient(
+			WithBaseURL(server.URL),
+			WithAPIToken("user@example.com", "token"),
+			WithRateLimitGovernor(governor),
+		)
+		require.NoError(t, err)
+		assert.False(t, client.RateLimitBudget().Known)
+
+		_, err = client.ServerInfo.Get(context.Background())
+		require.NoError(t, err)
+
+		budget := client.RateLimitBudget()
+		assert.True(t, budget.Known)
+		assert.Equal(t, 500, budget.Limit)
+		assert.Equal(t, 420, budget.Remaining)
+		assert.Equal(t, budget, governor.Budget())
+	})
+}
+
+func TestWithRetryPolicy(t *testing.T) {
+	cfg := &Config{}
+	assert.Error(t, WithRetryPolicy(nil)(cfg))
+
+	policy := transport.NewDefaultRetryPolicy(1)
+	require.NoError(t, WithRetryPolicy(policy)(cfg))
+	assert.Equal(t, policy, cfg.retryPolicy)
+}
+
+func TestWithCompression(t *testing.T) {
+	cfg := &Config{enableCompression: true}
+	require.NoError(t, WithCompression(false)(cfg))
+	assert.False(t, cfg.enableCompression)
+
+	assert.Error(t, WithRequestCompression(-1)(cfg))
+	require.NoError(t, WithRequestCompression(4096)(cfg))
+	assert.Equal(t, 4096, cfg.minGzipSize)
+}
+
+func TestWithCache(t *testing.T) {
+	cfg := &Config{}
+	assert.Error(t, WithCache(nil)(cfg))
+
+	cache := transport.NewMemoryCache(10)
+	rule := transport.CacheRule{Endpoint: "/project/{projectIdOrKey}", TTL: time.Minute}
+	require.NoError(t, WithCache(cache, rule)(cfg))
+	assert.Same(t, cache, cfg.cache)
+	assert.Equal(t, []transport.CacheRule{rule}, cfg.cacheRules)
+}
+
+func TestWithCoalescing(t *testing.T) {
+	cfg := &Config{}
+	require.NoError(t, WithCoalescing(true)(cfg))
+	assert.True(t, cfg.coalesce)
+}
+
+func TestWithMaxResponseSize(t *testing.T) {
+	t.Run("negative limit", func(t *testing.T) {
+		cfg := &Config{}
+		assert.Error(t, WithMaxResponseSize(-1)(cfg))
+	})
+
+	t.Run("large responses fail", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"accountId":"abc","displayName":"` + strings.Repeat("x", 100) + `"}`))
+		}))
+		defer server.Close()
+
+		client, err := NewClient(
+			WithBaseURL(server.URL),
+			WithAPIToken("user@example.com", "token"),
+			WithMaxResponseSize(64),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Myself.Get(context.Background())
+		assert.ErrorIs(t, err, ErrResponseTooLarge)
+	})
+}
+
+func TestWithCallOptions(t *testing.T) {
+	var calls int
+	var language string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		language = r.Header.Get("Accept-Language")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(
+		WithBaseURL(server.URL),
+		WithAPIToken("user@example.com", "token"),
+		WithMaxRetries(3),
+	)
+	require.NoError(t, err)
+
+	ctx := WithCallOptions(context.Background(),
+		CallMaxRetries(0),
+		CallTimeout(5*time.Second),
+		CallHeader("Accept-Language", "de-DE"),
+	)
+	_, err = client.Myself.Get(ctx)
+	assert.ErrorIs(t, err, ErrServer)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "de-DE", language)
+}
+
+func TestWithDryRun(t *testing.T) {
+	t.Run("nil plan", func(t *testing.T) {
+		cfg := &Config{}
+		assert.Error(t, WithDryRun(nil)(cfg))
+	})
+
+	t.Run("mutating calls are planned", func(t *testing.T) {
+		var sent []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sent = append(sent, r.Method+" "+r.URL.Path)
+			_, _ = w.Write([]byte(`{"id":"10001","key":"PROJ-1"}`))
+		}))
+		defer server.Close()
+
+		plan := transport.NewPlan()
+		client, err := NewClient(
+			WithBaseURL(server.URL),
+			WithAPIToken("user@example.com", "token"),
+			WithDryRun(plan),
+		)
+		require.NoError(t, err)
+		ctx := context.Background()
+
+		_, err = client.Issue.Get(ctx, "PROJ-1", nil)
+		require.NoError(t, err)
+
+		_, err = client.Issue.Create(ctx, &issue.CreateInput{
+			Fields: &issue.IssueFields{
+				Project:   &issue.Project{Key: "PROJ"},
+				Summary:   "Planned",
+				IssueType: &issue.IssueType{Name: "Task"},
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, client.Issue.Delete(ctx, "PROJ-1"))
+		require.NoError(t, client.Issue.CreateIssueLink(ctx, &issue.CreateIssueLinkInput{
+			Type:         &issue.IssueLinkType{Name: "Blocks"},
+			InwardIssue:  &issue.IssueRef{Key: "PROJ-1"},
+			OutwardIssue: &issue.IssueRef{Key: "PROJ-2"},
+		}))
+		require.NoError(t, client.Project.Restore(ctx, "PROJ"))
+
+		assert.Equal(t, []string{"GET /rest/api/3/issue/PROJ-1"}, sent)
+
+		var planned []string
+		for _, r := range plan.Requests() {
+			planned = append(planned, r.Method+" "+r.Path)
+		}
+		assert.Equal(t, []string{
+			"POST /rest/api/3/issue",
+			"DELETE /rest/api/3/issue/PROJ-1",
+			"POST /rest/api/3/issueLink",
+			"POST /rest/api/3/project/PROJ/restore",
+		}, planned)
+	})
+}
+
+// messageLogger keeps the messages it receives.
+type messageLogger struct {
+	noopLogger
+	messages []string
+}
+
+func (l *messageLogger) Debug(_ context.Context, msg string, _ ...Field) {
+	l.messages = append(l.messages, msg)
+}
+
+func (l *messageLogger) Info(_ context.Context, msg string, _ ...Field) {
+	l.messages = append(l.messages, msg)
+}
+
+func TestWithDebug(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"10001","key":"PROJ-1","fields":{"reporter":{"emailAddress":"alice@example.com"}}}`))
+	}))
+	defer server.Close()
+
+	t.Run("dumps to the configured logger", func(t *testing.T) {
+		logger := &messageLogger{}
+		client, err := NewClient(
+			WithBaseURL(server.URL),
+			WithAPIToken("user@example.com", "token"),
+			WithLogger(logger),
+			WithDebug(),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Issue.Get(context.Background(), "PROJ-1", nil)
+		require.NoError(t, err)
+
+		assert.Contains(t, logger.messages, "jira_http_dump")
+		assert.Contains(t, logger.messages, "jira_request_completed")
+	})
+
+	t.Run("dumps to a writer with redaction", func(t *testing.T) {
+		var out bytes.Buffer
+		client, err := NewClient(
+			WithBaseURL(server.URL),
+			WithAPIToken("user@example.com", "token"),
+			WithDebug(transport.DebugToWriter(&out), transport.DebugRedactFields("emailAddress")),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Issue.Get(context.Background(), "PROJ-1", nil)
+		require.NoError(t, err)
+
+		assert.Contains(t, out.String(), "GET "+server.URL+"/rest/api/3/issue/PROJ-1")
+		assert.Contains(t, out.String(), "Authorization: REDACTED")
+		assert.NotContains(t, out.String(), "alice@example.com")
+	})
+}
+
+// metricsRecorder keeps every RequestMetrics it is given.
+type metricsRecorder struct {
+	records []RequestMetrics
+}
+
+func (m *metricsRecorder) RecordRequest(_ context.Context, rm RequestMetrics) {
+	m.records = append(m.records, rm)
+}
+
+func TestWithMetrics(t *testing.T) {
+	t.Run("nil metrics", func(t *testing.T) {
+		cfg := &Config{}
+		assert.Error(t, WithMetrics(nil)(cfg))
+	})
+
+	t.Run("records issue calls with endpoint template", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"id":"10001","key":"PROJ-1"}`))
+		}))
+		defer server.Close()
+
+		metrics := &metricsRecorder{}
+		client, err := NewClient(
+			WithBaseURL(server.URL),
+			WithAPIToken("user@example.com", "token"),
+			WithMetrics(metrics),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Issue.Get(context.Background(), "PROJ-1", nil)
+		require.NoError(t, err)
+
+		require.Len(t, metrics.records, 1)
+		assert.Equal(t, "issue", metrics.records[0].Service)
+		assert.Equal(t, "/rest/api/3/issue/{issueIdOrKey}", metrics.records[0].Endpoint)
+		assert.Equal(t, "2xx", metrics.records[0].StatusClass)
+	})
+}
+
+func TestWithTracing(t *testing.T) {
+	t.Run("nil provider uses global provider", func(t *testing.T) {
+		cfg := &Config{}
+		require.NoError(t, WithTracing(nil)(cfg))
+		assert.Equal(t, otel.GetTracerProvider(), cfg.tracerProvider)
+	})
+
+	t.Run("spans issue calls", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.NotEmpty(t, r.Header.Get("traceparent"))
+			_, _ = w.Write([]byte(`{"id":"10001","key":"PROJ-1"}`))
+		}))
+		defer server.Close()
+
+		spans := tracetest.NewSpanRecorder()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+
+		client, err := NewClient(
+			WithBaseURL(server.URL),
+			WithAPIToken("user@example.com", "token"),
+			WithTracing(provider),
+			WithPropagator(propagation.TraceContext{}),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Issue.Get(context.Background(), "PROJ-1", nil)
+		require.NoError(t, err)
+
+		ended := spans.Ended()
+		require.Len(t, ended, 1)
+		assert.Equal(t, "GET /rest/api/3/issue/{issueIdOrKey}", ended[0].Name())
+	})
+}
+
+func TestWithPropagator(t *testing.T) {
+	cfg := &Config{}
+	assert.Error(t, WithPropagator(nil)(cfg))
+	require.NoError(t, WithPropagator(propagation.Baggage{})(cfg))
+	assert.Equal(t, propagation.Baggage{}, cfg.propagator)
+}
+
+func TestWithRecorder(t *testing.T) {
+	t.Run("nil recorder", func(t *testing.T) {
+		cfg := &Config{}
+		assert.Error(t, WithRecorder(nil)(cfg))
+	})
+
+	t.Run("record then replay", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"accountId":"abc","emailAddress":"alice@example.com"}`))
+		}))
+
+		path := filepath.Join(t.TempDir(), "myself.json")
+		rec, err := recorder.New(path, recorder.ModeRecord, recorder.WithRedactedFields("emailAddress"))
+		require.NoError(t, err)
+
+		client, err := NewClient(
+			WithBaseURL(server.URL),
+			WithAPIToken("user@example.com", "token"),
+			WithRecorder(rec),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Myself.Get(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, rec.Stop())
+		server.Close()
+
+		rec, err = recorder.New(path, recorder.ModeReplay)
+		require.NoError(t, err)
+
+		client, err = NewClient(
+			WithBaseURL(server.URL),
+			WithAPIToken("user@example.com", "token"),
+			WithRecorder(rec),
+		)
+		require.NoError(t, err)
+
+		me, err := client.Myself.Get(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "abc", me.AccountID)
+		assert.Equal(t, recorder.Redacted, me.EmailAddress)
+	})
+}
+
+func TestWithTokenStore(t *testing.T) {
+	t.Run("nil store", func(t *testing.T) {
+		assert.Error(t, WithTokenStore(nil)(&Config{}))
+	})
+
+	t.Run("requires OAuth 2.0", func(t *testing.T) {
+		_, err := NewClient(
+			WithBaseURL("https://example.atlassian.net"),
+			WithPAT("token"),
+			WithTokenStore(auth.NewMemoryTokenStore()),
+		)
+		assert.ErrorContains(t, err, "requires OAuth 2.0")
+	})
+
+	t.Run("loads the stored token", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer stored-token", r.Header.Get("Authorization"))
+			_, _ = w.Write([]byte(`{"accountId":"abc"}`))
+		}))
+		defer server.Close()
+
+		store := auth.NewMemoryTokenStore()
+		require.NoError(t, store.SaveToken(&oauth2.Token{AccessToken: "stored-token", Expiry: time.Now().Add(time.Hour)}))
+
+		oauth := auth.NewOAuth2Authenticator(&auth.OAuth2Config{ClientID: "id", ClientSecret: "secret"})
+		client, err := NewClient(
+			WithTokenStore(store),
+			WithBaseURL(server.URL),
+			WithOAuth2(oauth),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "stored-token", oauth.GetToken().AccessToken)
+
+		_, err = client.Myself.Get(context.Background())
+		require.NoError(t, err)
+	})
+}
+
+func TestConnectBasePath(t *testing.T) {
+	var qsh, want string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "JWT ")
+		parts := strings.Split(token, ".")
+		require.Len(t, parts, 3)
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, err)
+		var claims auth.ConnectClaims
+		require.NoError(t, json.Unmarshal(payload, &claims))
+
+		assert.True(t, strings.HasPrefix(r.URL.Path, "/jira/rest/"), r.URL.Path)
+		qsh = claims.QSH
+		want = auth.QueryStringHash(r.Method, r.URL, "/jira")
+		_, _ = w.Write([]byte(`{"accountId":"abc"}`))
+	}))
+	defer server.Close()
+
+	t.Run("derived from the base URL", func(t *testing.T) {
+		client, err := NewClient(
+			WithBaseURL(server.URL+"/jira/"),
+			WithAuthenticator(auth.NewConnectJWTAuth("com.example.app", "shared-secret")),
+		)
+		require.NoError(t, err)
+
+		_, err = client.Myself.Get(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, want, qsh, "the path is hashed without /jira")
+	})
+
+	t.Run("matching base path", func(t *testing.T) {
+		_, err := NewClient(
+			WithBaseURL(server.URL+"/jira"),
+			WithAuthenticator(auth.NewConnectJWTAuth("com.example.app", "shared-secret", auth.WithConnectBasePath("/jira/"))),
+		)
+		assert.NoError(t, err)
+	})
+
+	t.Run("conflicting base path", func(t *testing.T) {
+		_, err := NewClient(
+			WithBaseURL(server.URL+"/jira"),
+			WithAuthenticator(auth.NewConnectJWTAuth("com.example.app", "shared-secret", auth.WithConnectBasePath("/other"))),
+		)
+		assert.ErrorContains(t, err, "does not match the base URL path")
+
+		_, err = NewClient(
+			WithBaseURL(server.URL),
+			WithAuthenticator(auth.NewConnectJWTAuth("com.example.app", "shared-secret", auth.WithConnectBasePath("/jira"))),
+		)
+		assert.ErrorContains(t, err, "does not match the base URL path")
+	})
+}
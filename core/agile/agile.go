@@ -19,6 +19,41 @@ type RoundTripper interface {
 	DecodeResponse(resp *http.Response, target interface{}) error
 }
 
+// streamDecoder is implemented by transports that can decode a response
+// without buffering it first, such as *transport.Transport.
+type streamDecoder interface {
+	DecodeResponseStream(resp *http.Response, field string, rest interface{}, item func(decode func(target interface{}) error) error) error
+}
+
+// decodeValues decodes the values of a page response. The values are decoded
+// one at a time when the transport can stream, so a large page is not
+// buffered as raw bytes as well.
+func decodeValues[T any](transport RoundTripper, resp *http.Response) ([]*T, error) {
+	if stream, ok := transport.(streamDecoder); ok {
+		var values []*T
+		err := stream.DecodeResponseStream(resp, "values", nil, func(decode func(target interface{}) error) error {
+			var value T
+			if err := decode(&value); err != nil {
+				return err
+			}
+			values = append(values, &value)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return values, nil
+	}
+
+	var page struct {
+		Values []*T `json:"values"`
+	}
+	if err := transport.DecodeResponse(resp, &page); err != nil {
+		return nil, err
+	}
+	return page.Values, nil
+}
+
 // NewService creates a new Agile service.
 func NewService(transport RoundTripper) *Service {
 	return &Service{
@@ -151,19 +186,12 @@ func (s *Service) GetBoards(ctx context.Context, opts *BoardsOptions) ([]*Board,
 	}
 
 	// Decode response
-	var result struct {
-		MaxResults int      `json:"maxResults"`
-		StartAt    int      `json:"startAt"`
-		Total      int      `json:"total"`
-		IsLast     bool     `json:"isLast"`
-		Values     []*Board `json:"values"`
-	}
-
-	if err := s.transport.DecodeResponse(resp, &result); err != nil {
+	values, err := decodeValues[Board](s.transport, resp)
+	if err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return result.Values, nil
+	return values, nil
 }
 
 // GetBoard retrieves a board by ID.
@@ -274,11 +302,15 @@ func (s *Service) DeleteBoard(ctx context.Context, boardID int64) error {
 	}
 
 	// Execute request
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -341,18 +373,12 @@ func (s *Service) GetBoardSprints(ctx context.Context, boardID int64, opts *Spri
 	}
 
 	// Decode response
-	var result struct {
-		MaxResults int       `json:"maxResults"`
-		StartAt    int       `json:"startAt"`
-		IsLast     bool      `json:"isLast"`
-		Values     []*Sprint `json:"values"`
-	}
-
-	if err := s.transport.DecodeResponse(resp, &result); err != nil {
+	values, err := decodeValues[Sprint](s.transport, resp)
+	if err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return result.Values, nil
+	return values, nil
 }
 
 // GetSprint retrieves a sprint by ID.
@@ -512,11 +538,15 @@ func (s *Service) DeleteSprint(ctx context.Context, sprintID int64) error {
 	}
 
 	// Execute request
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -578,19 +608,12 @@ func (s *Service) GetBoardEpics(ctx context.Context, boardID int64, opts *EpicsO
 	}
 
 	// Decode response
-	var result struct {
-		MaxResults int     `json:"maxResults"`
-		StartAt    int     `json:"startAt"`
-		Total      int     `json:"total"`
-		IsLast     bool    `json:"isLast"`
-		Values     []*Epic `json:"values"`
-	}
-
-	if err := s.transport.DecodeResponse(resp, &result); err != nil {
+	values, err := decodeValues[Epic](s.transport, resp)
+	if err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return result.Values, nil
+	return values, nil
 }
 
 // GetEpic retrieves an epic by ID.
@@ -656,11 +679,15 @@ func (s *Service) MoveIssuesToSprint(ctx context.Context, sprintID int64, input
 	}
 
 	// Execute request
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
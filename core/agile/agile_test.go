@@ -54,6 +54,9 @@ func (m *mockTransport) Do(ctx context.Context, req *http.Request) (*http.Respon
 
 func (m *mockTransport) DecodeResponse(resp *http.Response, target interface{}) error {
 	defer resp.Body.Close()
+	if target == nil {
+		return nil
+	}
 	return json.NewDecoder(resp.Body).Decode(target)
 }
 
@@ -61,6 +64,37 @@ func (m *mockTransport) Close() {
 	m.server.Close()
 }
 
+// streamingMockTransport adds streaming decoding to mockTransport and counts
+// the items it has decoded.
+type streamingMockTransport struct {
+	*mockTransport
+	decoded int
+}
+
+func (m *streamingMockTransport) DecodeResponseStream(resp *http.Response, field string, rest interface{}, item func(decode func(target interface{}) error) error) error {
+	defer resp.Body.Close()
+
+	var page map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return err
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(page[field], &items); err != nil {
+		return err
+	}
+	for _, raw := range items {
+		err := item(func(target interface{}) error {
+			m.decoded++
+			return json.Unmarshal(raw, target)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func TestGetBoards(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -1037,3 +1071,16 @@ func TestGetBacklog(t *testing.T) {
 		})
 	}
 }
+
+func TestGetBoards_Streaming(t *testing.T) {
+	transport := &streamingMockTransport{mockTransport: newMockTransport(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"maxResults":50,"startAt":0,"isLast":true,"values":[{"id":1,"name":"Scrum","type":"scrum"},{"id":2,"name":"Kanban","type":"kanban"}]}`))
+	})}
+	defer transport.Close()
+
+	boards, err := NewService(transport).GetBoards(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, boards, 2)
+	assert.Equal(t, "Kanban", boards[1].Name)
+	assert.Equal(t, 2, transport.decoded)
+}
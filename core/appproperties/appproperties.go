@@ -139,10 +139,14 @@ func (s *Service) SetApplicationProperty(ctx context.Context, input *SetApplicat
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
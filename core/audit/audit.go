@@ -24,6 +24,12 @@ type RoundTripper interface {
 	DecodeResponse(resp *http.Response, v interface{}) error
 }
 
+// streamDecoder is implemented by transports that can decode a response
+// without buffering it first, such as *transport.Transport.
+type streamDecoder interface {
+	DecodeResponseStream(resp *http.Response, field string, rest interface{}, item func(decode func(target interface{}) error) error) error
+}
+
 // NewService creates a new Audit Log service.
 func NewService(transport RoundTripper) *Service {
 	return &Service{
@@ -129,6 +135,23 @@ func (s *Service) List(ctx context.Context, opts *ListOptions) ([]*AuditRecord,
 		Records []*AuditRecord `json:"records"`
 	}
 
+	// Decode records one at a time when the transport can stream, so a large
+	// page is not buffered as raw bytes as well
+	if stream, ok := s.transport.(streamDecoder); ok {
+		err = stream.DecodeResponseStream(resp, "records", &result, func(decode func(target interface{}) error) error {
+			var record AuditRecord
+			if err := decode(&record); err != nil {
+				return err
+			}
+			result.Records = append(result.Records, &record)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		return result.Records, nil
+	}
+
 	if err := s.transport.DecodeResponse(resp, &result); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
@@ -198,6 +198,8 @@ type BulkOperationResult struct {
 // User represents a minimal user reference.
 type User struct {
 	AccountID    string `json:"accountId,omitempty"`
+	Name         string `json:"name,omitempty"` // Username on Jira Server/Data Center
+	Key          string `json:"key,omitempty"`  // User key on Jira Server/Data Center
 	DisplayName  string `json:"displayName,omitempty"`
 	EmailAddress string `json:"emailAddress,omitempty"`
 }
@@ -307,6 +309,9 @@ func (s *Service) DeleteIssues(ctx context.Context, input *DeleteIssuesInput) er
 
 	// Check for successful deletion (204 No Content)
 	if resp.StatusCode != http.StatusNoContent {
+		if err := s.transport.DecodeResponse(resp, nil); err != nil {
+			return err
+		}
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
@@ -55,6 +55,9 @@ func (m *mockTransport) Do(ctx context.Context, req *http.Request) (*http.Respon
 
 func (m *mockTransport) DecodeResponse(resp *http.Response, target interface{}) error {
 	defer resp.Body.Close()
+	if target == nil {
+		return nil
+	}
 	return json.NewDecoder(resp.Body).Decode(target)
 }
 
@@ -49,6 +49,8 @@ type Dashboard struct {
 // User represents a Jira user.
 type User struct {
 	AccountID   string `json:"accountId,omitempty"`
+	Name        string `json:"name,omitempty"` // Username on Jira Server/Data Center
+	Key         string `json:"key,omitempty"`  // User key on Jira Server/Data Center
 	DisplayName string `json:"displayName,omitempty"`
 	Active      bool   `json:"active,omitempty"`
 	Self        string `json:"self,omitempty"`
@@ -284,11 +286,15 @@ func (s *Service) Delete(ctx context.Context, dashboardID string) error {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -462,10 +468,14 @@ func (s *Service) RemoveGadget(ctx context.Context, dashboardID string, gadgetID
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
@@ -21,6 +21,19 @@ type RoundTripper interface {
 	DecodeResponse(resp *http.Response, v interface{}) error
 }
 
+// featureChecker is implemented by transports that know which features the
+// Jira instance supports, such as *transport.Transport. CheckFeature returns
+// an error matching transport.ErrUnsupported for unsupported features.
+type featureChecker interface {
+	CheckFeature(ctx context.Context, feature string) error
+}
+
+// Features consulted by this service, as named by the transport.
+const (
+	featureEvaluate   = "expression/evaluate"
+	featureLegacyEval = "expression/eval"
+)
+
 // NewService creates a new Expression service.
 func NewService(transport RoundTripper) *Service {
 	return &Service{
@@ -28,6 +41,15 @@ func NewService(transport RoundTripper) *Service {
 	}
 }
 
+// checkFeature returns the transport's verdict on feature, or nil when the
+// transport cannot tell.
+func (s *Service) checkFeature(ctx context.Context, feature string) error {
+	if checker, ok := s.transport.(featureChecker); ok {
+		return checker.CheckFeature(ctx, feature)
+	}
+	return nil
+}
+
 // EvaluationInput represents input for evaluating an expression.
 type EvaluationInput struct {
 	Expression string                 `json:"expression"`
@@ -70,6 +92,10 @@ type EvaluationError struct {
 // search API for better performance and scalability (eventual consistency instead of
 // strong consistency).
 //
+// When the Jira instance no longer serves expression/eval, Evaluate uses
+// expression/evaluate instead. Jira Server/Data Center has neither, so there
+// it fails with an error matching ErrUnsupported without sending a request.
+//
 // Example:
 //
 //	result, err := client.Expression.Evaluate(ctx, &expression.EvaluationInput{
@@ -86,6 +112,12 @@ func (s *Service) Evaluate(ctx context.Context, input *EvaluationInput) (*Evalua
 	}
 
 	path := "/rest/api/3/expression/eval"
+	if err := s.checkFeature(ctx, featureLegacyEval); err != nil {
+		if s.checkFeature(ctx, featureEvaluate) != nil {
+			return nil, err
+		}
+		path = "/rest/api/3/expression/evaluate"
+	}
 
 	req, err := s.transport.NewRequest(ctx, http.MethodPost, path, input)
 	if err != nil {
@@ -113,6 +145,10 @@ func (s *Service) Evaluate(ctx context.Context, input *EvaluationInput) (*Evalua
 //   - Better performance and scalability
 //   - Same input/output structures
 //
+// Jira expressions are not available on Jira Server/Data Center; there
+// EvaluateExpression fails with an error matching ErrUnsupported without
+// sending a request.
+//
 // Example:
 //
 //	result, err := client.Expression.EvaluateExpression(ctx, &expression.EvaluationInput{
@@ -128,6 +164,10 @@ func (s *Service) EvaluateExpression(ctx context.Context, input *EvaluationInput
 		return nil, fmt.Errorf("expression is required")
 	}
 
+	if err := s.checkFeature(ctx, featureEvaluate); err != nil {
+		return nil, err
+	}
+
 	path := "/rest/api/3/expression/evaluate"
 
 	req, err := s.transport.NewRequest(ctx, http.MethodPost, path, input)
@@ -3,6 +3,8 @@ package expression
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -487,3 +489,83 @@ func TestAnalyze(t *testing.T) {
 		})
 	}
 }
+
+// featureMockTransport adds feature checks to mockTransport, rejecting the
+// features in unsupported.
+type featureMockTransport struct {
+	*mockTransport
+	unsupported map[string]bool
+}
+
+var errFeatureUnsupported = errors.New("unsupported")
+
+func (m *featureMockTransport) CheckFeature(_ context.Context, feature string) error {
+	if m.unsupported[feature] {
+		return fmt.Errorf("%s: %w", feature, errFeatureUnsupported)
+	}
+	return nil
+}
+
+func TestEvaluate_FeatureNegotiation(t *testing.T) {
+	tests := []struct {
+		name        string
+		unsupported map[string]bool
+		wantPath    string
+	}{
+		{
+			name:        "legacy endpoint when supported",
+			unsupported: map[string]bool{},
+			wantPath:    "/rest/api/3/expression/eval",
+		},
+		{
+			name:        "falls back to evaluate",
+			unsupported: map[string]bool{featureLegacyEval: true},
+			wantPath:    "/rest/api/3/expression/evaluate",
+		},
+		{
+			name:        "fails early when neither is supported",
+			unsupported: map[string]bool{featureLegacyEval: true, featureEvaluate: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			transport := &featureMockTransport{
+				mockTransport: newMockTransport(func(w http.ResponseWriter, r *http.Request) {
+					gotPath = r.URL.Path
+					json.NewEncoder(w).Encode(&EvaluationResult{Value: "ok"})
+				}),
+				unsupported: tt.unsupported,
+			}
+			defer transport.Close()
+
+			service := NewService(transport)
+			result, err := service.Evaluate(context.Background(), &EvaluationInput{Expression: "issue.summary"})
+
+			if tt.wantPath == "" {
+				assert.ErrorIs(t, err, errFeatureUnsupported)
+				assert.Empty(t, gotPath, "no request is sent")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "ok", result.Value)
+			assert.Equal(t, tt.wantPath, gotPath)
+		})
+	}
+}
+
+func TestEvaluateExpression_Unsupported(t *testing.T) {
+	called := false
+	transport := &featureMockTransport{
+		mockTransport: newMockTransport(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}),
+		unsupported: map[string]bool{featureEvaluate: true},
+	}
+	defer transport.Close()
+
+	_, err := NewService(transport).EvaluateExpression(context.Background(), &EvaluationInput{Expression: "issue.summary"})
+	assert.ErrorIs(t, err, errFeatureUnsupported)
+	assert.False(t, called, "no request is sent")
+}
@@ -321,11 +321,15 @@ func (s *Service) Delete(ctx context.Context, fieldID string) error {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -466,11 +470,15 @@ func (s *Service) DeleteContext(ctx context.Context, fieldID, contextID string)
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -585,11 +593,15 @@ func (s *Service) AssociateContextProjects(ctx context.Context, fieldID, context
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -620,11 +632,15 @@ func (s *Service) RemoveContextProjects(ctx context.Context, fieldID, contextID
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -53,6 +53,8 @@ type Filter struct {
 // User represents a Jira user (simplified for filter context).
 type User struct {
 	AccountID    string `json:"accountId,omitempty"`
+	Name         string `json:"name,omitempty"` // Username on Jira Server/Data Center
+	Key          string `json:"key,omitempty"`  // User key on Jira Server/Data Center
 	DisplayName  string `json:"displayName,omitempty"`
 	EmailAddress string `json:"emailAddress,omitempty"`
 	Active       bool   `json:"active,omitempty"`
@@ -321,11 +323,15 @@ func (s *Service) Delete(ctx context.Context, filterID string) error {
 	}
 
 	// Execute request
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -591,11 +597,15 @@ func (s *Service) SetDefaultShareScope(ctx context.Context, scope string) error
 	}
 
 	// Execute request
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -703,10 +713,14 @@ func (s *Service) DeleteSharePermission(ctx context.Context, filterID string, pe
 	}
 
 	// Execute request
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
@@ -45,6 +45,9 @@ func (m *mockRoundTripper) DecodeResponse(resp *http.Response, v interface{}) er
 		return nil
 	}
 	defer resp.Body.Close()
+	if v == nil {
+		return nil
+	}
 	return json.NewDecoder(resp.Body).Decode(v)
 }
 
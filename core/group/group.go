@@ -54,6 +54,8 @@ type GroupUsers struct {
 // User represents a Jira user.
 type User struct {
 	AccountID    string `json:"accountId,omitempty"`
+	Name         string `json:"name,omitempty"` // Username on Jira Server/Data Center
+	Key          string `json:"key,omitempty"`  // User key on Jira Server/Data Center
 	AccountType  string `json:"accountType,omitempty"`
 	EmailAddress string `json:"emailAddress,omitempty"`
 	DisplayName  string `json:"displayName,omitempty"`
@@ -246,11 +248,15 @@ func (s *Service) Delete(ctx context.Context, opts *DeleteOptions) error {
 	}
 	req.URL.RawQuery = q.Encode()
 
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -422,11 +428,15 @@ func (s *Service) RemoveUser(ctx context.Context, opts *RemoveUserOptions) error
 	}
 	req.URL.RawQuery = q.Encode()
 
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -43,6 +43,25 @@ type ADFMark struct {
 	Attrs map[string]interface{} `json:"attrs,omitempty"`
 }
 
+// UnmarshalJSON decodes an ADF document.
+//
+// Jira Server/Data Center and REST API v2 return rich text fields as wiki
+// markup strings instead of ADF. Such strings are converted with ADFFromWiki,
+// so the same models work with both API versions.
+func (a *ADF) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		*a = *ADFFromWiki(text)
+		return nil
+	}
+
+	type alias ADF
+	return json.Unmarshal(data, (*alias)(a))
+}
+
 // NewADF creates a new ADF document.
 func NewADF() *ADF {
 	return &ADF{
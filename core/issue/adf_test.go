@@ -622,3 +622,31 @@ func TestADFRoundTrip(t *testing.T) {
 		t.Errorf("Text content not preserved.\nOriginal: %s\nDecoded: %s", originalText, decodedText)
 	}
 }
+
+// TestADF_UnmarshalJSONString verifies that REST API v2 string fields decode as ADF
+func TestADF_UnmarshalJSONString(t *testing.T) {
+	var comment Comment
+	data := `{"id":"1","body":"First paragraph\n\nSecond paragraph","author":{"name":"jdoe","key":"JIRAUSER10000"}}`
+	if err := json.Unmarshal([]byte(data), &comment); err != nil {
+		t.Fatalf("Failed to unmarshal comment: %v", err)
+	}
+
+	if comment.Body == nil || comment.Body.Type != "doc" {
+		t.Fatalf("Expected an ADF document, got %+v", comment.Body)
+	}
+	if len(comment.Body.Content) != 2 {
+		t.Errorf("Expected 2 paragraphs, got %d", len(comment.Body.Content))
+	}
+	if comment.Author.Name != "jdoe" || comment.Author.Key != "JIRAUSER10000" {
+		t.Errorf("Expected Data Center user identifiers, got %+v", comment.Author)
+	}
+
+	// ADF documents still decode as before
+	var adf ADF
+	if err := json.Unmarshal([]byte(`{"type":"doc","version":1,"content":[]}`), &adf); err != nil {
+		t.Fatalf("Failed to unmarshal ADF: %v", err)
+	}
+	if adf.Type != "doc" || adf.Version != 1 {
+		t.Errorf("Unexpected ADF: %+v", adf)
+	}
+}
@@ -205,6 +205,9 @@ func (s *Service) DownloadAttachment(ctx context.Context, attachmentID string) (
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
+		if err := s.transport.DecodeResponse(resp, nil); err != nil {
+			return nil, err
+		}
 		_ = resp.Body.Close() // Explicit ignore in error path
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
@@ -242,6 +245,9 @@ func (s *Service) DeleteAttachment(ctx context.Context, attachmentID string) err
 
 	// Delete returns 204 No Content on success
 	if resp.StatusCode != http.StatusNoContent {
+		if err := s.transport.DecodeResponse(resp, nil); err != nil {
+			return err
+		}
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
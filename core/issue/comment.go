@@ -291,6 +291,9 @@ func (s *Service) DeleteComment(ctx context.Context, issueKeyOrID, commentID str
 
 	// Delete returns 204 No Content on success
 	if resp.StatusCode != http.StatusNoContent {
+		if err := s.transport.DecodeResponse(resp, nil); err != nil {
+			return err
+		}
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
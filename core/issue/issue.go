@@ -1052,6 +1052,8 @@ type Priority struct {
 // User represents a Jira user.
 type User struct {
 	AccountID    string `json:"accountId,omitempty"`
+	Name         string `json:"name,omitempty"` // Username on Jira Server/Data Center
+	Key          string `json:"key,omitempty"`  // User key on Jira Server/Data Center
 	EmailAddress string `json:"emailAddress,omitempty"`
 	DisplayName  string `json:"displayName,omitempty"`
 	Active       bool   `json:"active,omitempty"`
@@ -1507,6 +1509,9 @@ func (s *Service) Update(ctx context.Context, issueKeyOrID string, input *Update
 
 	// Update returns 204 No Content on success
 	if resp.StatusCode != http.StatusNoContent {
+		if err := s.transport.DecodeResponse(resp, nil); err != nil {
+			return err
+		}
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
@@ -1542,6 +1547,9 @@ func (s *Service) Delete(ctx context.Context, issueKeyOrID string) error {
 
 	// Delete returns 204 No Content on success
 	if resp.StatusCode != http.StatusNoContent {
+		if err := s.transport.DecodeResponse(resp, nil); err != nil {
+			return err
+		}
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
@@ -1600,6 +1608,9 @@ func (s *Service) DoTransition(ctx context.Context, issueKeyOrID string, input *
 
 	// Transition returns 204 No Content on success
 	if resp.StatusCode != http.StatusNoContent {
+		if err := s.transport.DecodeResponse(resp, nil); err != nil {
+			return err
+		}
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
@@ -155,6 +155,9 @@ func (s *Service) CreateIssueLink(ctx context.Context, input *CreateIssueLinkInp
 
 	// Create returns 201 Created on success
 	if resp.StatusCode != http.StatusCreated {
+		if err := s.transport.DecodeResponse(resp, nil); err != nil {
+			return err
+		}
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
@@ -190,6 +193,9 @@ func (s *Service) DeleteIssueLink(ctx context.Context, linkID string) error {
 
 	// Delete returns 204 No Content on success
 	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		if err := s.transport.DecodeResponse(resp, nil); err != nil {
+			return err
+		}
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
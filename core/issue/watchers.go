@@ -88,6 +88,9 @@ func (s *Service) AddWatcher(ctx context.Context, issueKeyOrID, accountID string
 
 	// Add watcher returns 204 No Content on success
 	if resp.StatusCode != http.StatusNoContent {
+		if err := s.transport.DecodeResponse(resp, nil); err != nil {
+			return err
+		}
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
@@ -127,6 +130,9 @@ func (s *Service) RemoveWatcher(ctx context.Context, issueKeyOrID, accountID str
 
 	// Remove watcher returns 204 No Content on success
 	if resp.StatusCode != http.StatusNoContent {
+		if err := s.transport.DecodeResponse(resp, nil); err != nil {
+			return err
+		}
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
@@ -203,6 +209,9 @@ func (s *Service) AddVote(ctx context.Context, issueKeyOrID string) error {
 
 	// Add vote returns 204 No Content on success
 	if resp.StatusCode != http.StatusNoContent {
+		if err := s.transport.DecodeResponse(resp, nil); err != nil {
+			return err
+		}
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
@@ -238,6 +247,9 @@ func (s *Service) RemoveVote(ctx context.Context, issueKeyOrID string) error {
 
 	// Remove vote returns 204 No Content on success
 	if resp.StatusCode != http.StatusNoContent {
+		if err := s.transport.DecodeResponse(resp, nil); err != nil {
+			return err
+		}
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
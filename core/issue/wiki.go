@@ -0,0 +1,1046 @@
+package issue
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// ADFFromWiki converts Jira wiki markup to an ADF document.
+//
+// Jira Server/Data Center, REST API v2 and much legacy tooling use wiki
+// markup for rich text. Supported markup:
+//   - Headings (h1. to h6.), paragraphs and line breaks (\\)
+//   - Text effects: *strong*, _emphasis_, -strike-, +underline+, ^super^,
+//     ~sub~, {{monospace}} and {color:red}color{color}
+//   - Bullet (*, -) and numbered (#) lists, nested and mixed
+//   - Tables with ||header|| and |cell| rows
+//   - {code:language}, {noformat}, {quote} and bq. blocks, and ---- rules
+//   - Panels: {panel}, {info}, {note}, {tip} and {warning}
+//   - Links ([text|url], [url]), mentions ([~username], [~accountid:id])
+//     and images (!image.png!, !https://example.com/a.png|alt=Diagram!,
+//     !image.png|thumbnail!)
+//
+// Unrecognized markup is kept as text. ToWiki converts back.
+//
+// Example:
+//
+//	adf := issue.ADFFromWiki("h1. Summary\n\nThe *build* fails on [~jdoe]'s branch.")
+func ADFFromWiki(markup string) *ADF {
+	adf := NewADF()
+	p := &wikiParser{src: strings.ReplaceAll(markup, "\r\n", "\n")}
+	adf.Content = append(adf.Content, p.blocks()...)
+	return adf
+}
+
+// ToWiki converts the document to Jira wiki markup.
+//
+// Wiki markup cannot express everything ADF can, so the conversion is lossy
+// for some content: code blocks without a language become {noformat}, list
+// items and table cells hold a single line, and panel titles become a strong
+// paragraph. ADFFromWiki(adf.ToWiki()) otherwise reproduces the document.
+//
+// Example:
+//
+//	markup := issue.NewADF().AddHeading("Steps", 2).AddOrderedList([]string{"Build", "Deploy"}).ToWiki()
+//	// "h2. Steps\n\n# Build\n# Deploy"
+func (a *ADF) ToWiki() string {
+	if a == nil {
+		return ""
+	}
+	return renderWikiBlocks(a.Content)
+}
+
+var (
+	wikiHeadingPattern = regexp.MustCompile(`^\s*h([1-6])\.(?:\s+(.*))?$`)
+	wikiQuotePattern   = regexp.MustCompile(`^\s*bq\.(?:\s+(.*))?$`)
+	wikiListPattern    = regexp.MustCompile(`^\s*([*#]+|-) +(.*)$`)
+	wikiMacroPattern   = regexp.MustCompile(`^\s*\{(code|noformat|quote|panel|info|note|tip|warning)(?::([^}]*))?\}`)
+	wikiColorPattern   = regexp.MustCompile(`(?s)^\{color:([^}]+)\}(.*?)\{color\}`)
+)
+
+// wikiPanelTypes maps panel macros to ADF panel types.
+var wikiPanelTypes = map[string]string{
+	"panel":   "note",
+	"info":    "info",
+	"tip":     "success",
+	"note":    "warning",
+	"warning": "error",
+}
+
+// wikiColors maps the color names wiki markup commonly uses to the hex
+// colors ADF requires.
+var wikiColors = map[string]string{
+	"black":  "#000000",
+	"blue":   "#0000ff",
+	"gray":   "#808080",
+	"green":  "#008000",
+	"grey":   "#808080",
+	"orange": "#ffa500",
+	"purple": "#800080",
+	"red":    "#ff0000",
+	"white":  "#ffffff",
+	"yellow": "#ffff00",
+}
+
+// wikiMarkChars are the characters that delimit text effects.
+const wikiMarkChars = "*_-+^~"
+
+// wikiMarks maps text effect delimiters to ADF marks.
+var wikiMarks = map[byte]ADFMark{
+	'*': {Type: "strong"},
+	'_': {Type: "em"},
+	'-': {Type: "strike"},
+	'+': {Type: "underline"},
+	'^': {Type: "subsup", Attrs: map[string]interface{}{"type": "sup"}},
+	'~': {Type: "subsup", Attrs: map[string]interface{}{"type": "sub"}},
+}
+
+// wikiParser parses wiki markup into ADF block nodes.
+type wikiParser struct {
+	src string
+	pos int
+}
+
+// parseWikiBlocks parses wiki markup into ADF block nodes.
+func parseWikiBlocks(markup string) []ADFNode {
+	p := &wikiParser{src: markup}
+	return p.blocks()
+}
+
+func (p *wikiParser) done() bool {
+	return p.pos >= len(p.src)
+}
+
+// peekLine returns the current line without consuming it.
+func (p *wikiParser) peekLine() string {
+	rest := p.src[p.pos:]
+	if i := strings.IndexByte(rest, '\n'); i >= 0 {
+		return rest[:i]
+	}
+	return rest
+}
+
+// skipLine consumes the current line.
+func (p *wikiParser) skipLine() {
+	p.pos += len(p.peekLine())
+	if p.pos < len(p.src) {
+		p.pos++
+	}
+}
+
+// blocks parses block nodes until the end of the source.
+func (p *wikiParser) blocks() []ADFNode {
+	var nodes []ADFNode
+	for !p.done() {
+		line := p.peekLine()
+		if strings.TrimSpace(line) == "" {
+			p.skipLine()
+			continue
+		}
+
+		if macro, ok := p.macro(line); ok {
+			nodes = append(nodes, macro...)
+			continue
+		}
+
+		switch {
+		case wikiHeadingPattern.MatchString(line):
+			m := wikiHeadingPattern.FindStringSubmatch(line)
+			p.skipLine()
+			nodes = append(nodes, ADFNode{
+				Type:    "heading",
+				Attrs:   map[string]interface{}{"level": int(m[1][0] - '0')},
+				Content: parseWikiInline(m[2], nil),
+			})
+		case wikiQuotePattern.MatchString(line):
+			m := wikiQuotePattern.FindStringSubmatch(line)
+			p.skipLine()
+			nodes = append(nodes, ADFNode{Type: "blockquote", Content: wikiInlineBlocks(m[1])})
+		case strings.TrimSpace(line) == "----":
+			p.skipLine()
+			nodes = append(nodes, ADFNode{Type: "rule"})
+		case wikiListPattern.MatchString(line):
+			nodes = append(nodes, p.list()...)
+		case isWikiTableRow(line):
+			nodes = append(nodes, p.table())
+		default:
+			nodes = append(nodes, p.paragraph()...)
+		}
+	}
+	return nodes
+}
+
+// isWikiBlockStart reports whether line starts a block other than a paragraph.
+func isWikiBlockStart(line string) bool {
+	return wikiHeadingPattern.MatchString(line) ||
+		wikiQuotePattern.MatchString(line) ||
+		wikiListPattern.MatchString(line) ||
+		wikiMacroPattern.MatchString(line) ||
+		strings.TrimSpace(line) == "----" ||
+		isWikiTableRow(line)
+}
+
+// paragraph parses lines up to the next blank line or block.
+func (p *wikiParser) paragraph() []ADFNode {
+	// The first line is always consumed, so that markup that looks like a
+	// block but is not one (such as an unclosed {code}) is kept as text.
+	lines := []string{p.peekLine()}
+	p.skipLine()
+	for !p.done() {
+		line := p.peekLine()
+		if strings.TrimSpace(line) == "" || isWikiBlockStart(line) {
+			break
+		}
+		lines = append(lines, line)
+		p.skipLine()
+	}
+	return wikiInlineBlocks(strings.Join(lines, "\n"))
+}
+
+// macro parses a {code}, {noformat}, {quote} or panel macro starting at line.
+// It reports false when line does not start a closed macro.
+func (p *wikiParser) macro(line string) ([]ADFNode, bool) {
+	m := wikiMacroPattern.FindStringSubmatch(line)
+	if m == nil {
+		return nil, false
+	}
+
+	name, params := m[1], m[2]
+	start := p.pos + len(m[0])
+	closing := "{" + name + "}"
+	end := strings.Index(p.src[start:], closing)
+	if end < 0 {
+		return nil, false
+	}
+
+	body := p.src[start : start+end]
+	p.pos = start + end + len(closing)
+	if strings.TrimSpace(p.peekLine()) == "" {
+		p.skipLine()
+	}
+
+	switch name {
+	case "code", "noformat":
+		node := ADFNode{Type: "codeBlock"}
+		if language := wikiCodeLanguage(params); name == "code" && language != "" {
+			node.Attrs = map[string]interface{}{"language": language}
+		}
+		text := strings.TrimSuffix(strings.TrimPrefix(body, "\n"), "\n")
+		if text != "" {
+			node.Content = []ADFNode{{Type: "text", Text: text}}
+		}
+		return []ADFNode{node}, true
+
+	case "quote":
+		return []ADFNode{{Type: "blockquote", Content: nonEmptyBlocks(parseWikiBlocks(body))}}, true
+
+	default:
+		content := parseWikiBlocks(body)
+		if title := wikiMacroParam(params, "title"); title != "" {
+			heading := ADFNode{Type: "paragraph", Content: []ADFNode{{Type: "text", Text: title, Marks: []ADFMark{{Type: "strong"}}}}}
+			content = append([]ADFNode{heading}, content...)
+		}
+		return []ADFNode{{
+			Type:    "panel",
+			Attrs:   map[string]interface{}{"panelType": wikiPanelTypes[name]},
+			Content: nonEmptyBlocks(content),
+		}}, true
+	}
+}
+
+// wikiCodeLanguage returns the language of a {code} macro, which is its first
+// parameter without a name.
+func wikiCodeLanguage(params string) string {
+	for _, param := range strings.Split(params, "|") {
+		if param = strings.TrimSpace(param); param != "" && !strings.Contains(param, "=") {
+			return param
+		}
+	}
+	return ""
+}
+
+// wikiMacroParam returns the value of the named macro or image parameter.
+func wikiMacroParam(params, name string) string {
+	for _, param := range strings.Split(params, "|") {
+		if key, value, ok := strings.Cut(param, "="); ok && strings.TrimSpace(key) == name {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// nonEmptyBlocks returns blocks, or an empty paragraph when there are none,
+// for nodes that ADF requires to have content.
+func nonEmptyBlocks(blocks []ADFNode) []ADFNode {
+	if len(blocks) == 0 {
+		return []ADFNode{{Type: "paragraph"}}
+	}
+	return blocks
+}
+
+// wikiListLine is a list item line.
+type wikiListLine struct {
+	markers string
+	text    string
+}
+
+// list parses consecutive list item lines.
+func (p *wikiParser) list() []ADFNode {
+	var items []wikiListLine
+	for !p.done() {
+		m := wikiListPattern.FindStringSubmatch(p.peekLine())
+		if m == nil {
+			break
+		}
+		items = append(items, wikiListLine{markers: strings.ReplaceAll(m[1], "-", "*"), text: m[2]})
+		p.skipLine()
+	}
+	return buildWikiLists(items, 0)
+}
+
+// buildWikiLists builds the lists at depth from items, whose markers are all
+// longer than depth. Deeper items are nested in the item before them.
+func buildWikiLists(items []wikiListLine, depth int) []ADFNode {
+	var lists []ADFNode
+	for i := 0; i < len(items); {
+		kind := items[i].markers[depth]
+		list := ADFNode{Type: "bulletList"}
+		if kind == '#' {
+			list.Type = "orderedList"
+		}
+
+		for i < len(items) && items[i].markers[depth] == kind {
+			if len(items[i].markers) == depth+1 {
+				list.Content = append(list.Content, ADFNode{Type: "listItem", Content: wikiInlineBlocks(items[i].text)})
+				i++
+				continue
+			}
+
+			j := i
+			for j < len(items) && len(items[j].markers) > depth+1 && items[j].markers[depth] == kind {
+				j++
+			}
+			if len(list.Content) == 0 {
+				list.Content = append(list.Content, ADFNode{Type: "listItem", Content: []ADFNode{{Type: "paragraph"}}})
+			}
+			last := &list.Content[len(list.Content)-1]
+			last.Content = append(last.Content, buildWikiLists(items[i:j], depth+1)...)
+			i = j
+		}
+
+		lists = append(lists, list)
+	}
+	return lists
+}
+
+// table parses consecutive table row lines.
+func (p *wikiParser) table() ADFNode {
+	table := ADFNode{Type: "table"}
+	for !p.done() {
+		line := p.peekLine()
+		if !isWikiTableRow(line) {
+			break
+		}
+		table.Content = append(table.Content, wikiTableRow(strings.TrimSpace(line)))
+		p.skipLine()
+	}
+	return table
+}
+
+// isWikiTableRow reports whether line is a table row with at least one cell.
+// Lines of bare pipes, such as "|" or "||", are text: ADF rejects empty rows.
+func isWikiTableRow(line string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, "|") && len(wikiTableRow(line).Content) > 0
+}
+
+// wikiTableRow parses a row such as "||Header||Header||" or "|Cell|Cell|".
+func wikiTableRow(line string) ADFNode {
+	row := ADFNode{Type: "tableRow"}
+	for i := 0; i < len(line); {
+		cellType := "tableCell"
+		i++
+		if i < len(line) && line[i] == '|' {
+			cellType = "tableHeader"
+			i++
+		}
+		if i >= len(line) {
+			break
+		}
+
+		end := wikiCellEnd(line, i)
+		row.Content = append(row.Content, ADFNode{
+			Type:    cellType,
+			Content: wikiInlineBlocks(strings.TrimSpace(line[i:end])),
+		})
+		i = end
+	}
+	return row
+}
+
+// wikiCellEnd returns the index of the pipe that ends the cell starting at i,
+// skipping escaped pipes and pipes inside links and monospace text.
+func wikiCellEnd(line string, i int) int {
+	for ; i < len(line); i++ {
+		switch {
+		case line[i] == '\\':
+			i++
+		case line[i] == '[':
+			if end := strings.IndexByte(line[i:], ']'); end > 0 {
+				i += end
+			}
+		case strings.HasPrefix(line[i:], "{{"):
+			if end := strings.Index(line[i+2:], "}}"); end >= 0 {
+				i += end + 3
+			}
+		case line[i] == '|':
+			return i
+		}
+	}
+	return len(line)
+}
+
+// wikiInlineBlocks parses inline markup into a paragraph. Images become media
+// blocks of their own, splitting the paragraph around them.
+func wikiInlineBlocks(markup string) []ADFNode {
+	var blocks []ADFNode
+	paragraph := ADFNode{Type: "paragraph"}
+	flush := func() {
+		if !isBlankInline(paragraph.Content) {
+			blocks = append(blocks, paragraph)
+		}
+		paragraph = ADFNode{Type: "paragraph"}
+	}
+
+	for _, node := range parseWikiInline(markup, nil) {
+		if node.Type == "mediaSingle" || node.Type == "mediaGroup" {
+			flush()
+			blocks = append(blocks, node)
+			continue
+		}
+		paragraph.Content = append(paragraph.Content, node)
+	}
+	if len(paragraph.Content) > 0 || len(blocks) == 0 {
+		flush()
+	}
+	if len(blocks) == 0 {
+		blocks = append(blocks, ADFNode{Type: "paragraph"})
+	}
+	return blocks
+}
+
+// isBlankInline reports whether nodes hold nothing but whitespace.
+func isBlankInline(nodes []ADFNode) bool {
+	for _, node := range nodes {
+		if node.Type != "text" || strings.TrimSpace(node.Text) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseWikiInline parses inline markup into text and inline nodes, with marks
+// applied to all text.
+func parseWikiInline(s string, marks []ADFMark) []ADFNode {
+	var nodes []ADFNode
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, ADFNode{Type: "text", Text: text.String(), Marks: marks})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case strings.HasPrefix(s[i:], `\\`):
+			flush()
+			nodes = append(nodes, ADFNode{Type: "hardBreak"})
+			i += 2
+			continue
+
+		case c == '\\' && i+1 < len(s) && isWikiPunct(s[i+1]):
+			text.WriteByte(s[i+1])
+			i += 2
+			continue
+
+		case c == '\n':
+			flush()
+			nodes = append(nodes, ADFNode{Type: "hardBreak"})
+			i++
+			continue
+
+		case strings.HasPrefix(s[i:], "{{"):
+			if end := strings.Index(s[i+2:], "}}"); end > 0 {
+				flush()
+				nodes = append(nodes, ADFNode{Type: "text", Text: s[i+2 : i+2+end], Marks: withMark(marks, ADFMark{Type: "code"})})
+				i += end + 4
+				continue
+			}
+
+		case strings.HasPrefix(s[i:], "{color:"):
+			if m := wikiColorPattern.FindStringSubmatch(s[i:]); m != nil {
+				flush()
+				color := ADFMark{Type: "textColor", Attrs: map[string]interface{}{"color": wikiColor(m[1])}}
+				nodes = append(nodes, parseWikiInline(m[2], withMark(marks, color))...)
+				i += len(m[0])
+				continue
+			}
+
+		case c == '[':
+			if end := strings.IndexByte(s[i:], ']'); end > 0 {
+				if link, ok := wikiLink(s[i+1:i+end], marks); ok {
+					flush()
+					nodes = append(nodes, link...)
+					i += end + 1
+					continue
+				}
+			}
+
+		case c == '!':
+			if image, n, ok := wikiImage(s[i:]); ok {
+				flush()
+				nodes = append(nodes, image)
+				i += n
+				continue
+			}
+
+		case strings.IndexByte(wikiMarkChars, c) >= 0:
+			if end, ok := wikiMarkEnd(s, i); ok {
+				flush()
+				nodes = append(nodes, parseWikiInline(s[i+1:end], withMark(marks, wikiMarks[c]))...)
+				i = end + 1
+				continue
+			}
+		}
+
+		text.WriteByte(c)
+		i++
+	}
+
+	flush()
+	return nodes
+}
+
+// withMark returns marks with mark appended, without modifying marks.
+func withMark(marks []ADFMark, mark ADFMark) []ADFMark {
+	result := make([]ADFMark, len(marks), len(marks)+1)
+	copy(result, marks)
+	return append(result, mark)
+}
+
+// wikiMarkEnd returns the index of the delimiter that closes the text effect
+// opened at s[i]. An effect opens at a word boundary before a non-space and
+// closes after a non-space before a word boundary, on the same line.
+func wikiMarkEnd(s string, i int) (int, bool) {
+	c := s[i]
+	if i > 0 && isWikiWordChar(s[i-1]) {
+		return 0, false
+	}
+	if i+1 >= len(s) || isWikiSpace(s[i+1]) || s[i+1] == c {
+		return 0, false
+	}
+
+	for j := i + 2; j < len(s); j++ {
+		switch {
+		case s[j] == '\n':
+			return 0, false
+		case s[j] == '\\':
+			j++
+		case s[j] == c && !isWikiSpace(s[j-1]) && (j+1 == len(s) || !isWikiWordChar(s[j+1])):
+			return j, true
+		}
+	}
+	return 0, false
+}
+
+// wikiLink parses the content of [...] as a mention or link.
+func wikiLink(content string, marks []ADFMark) ([]ADFNode, bool) {
+	if strings.HasPrefix(content, "~") {
+		id := strings.TrimPrefix(content[1:], "accountid:")
+		if id == "" || strings.ContainsAny(id, " \n") {
+			return nil, false
+		}
+		return []ADFNode{{Type: "mention", Attrs: map[string]interface{}{"id": id, "text": "@" + id}}}, true
+	}
+
+	text, href, hasText := wikiCutUnescaped(content, '|')
+	if !hasText {
+		href = content
+	}
+	href = strings.TrimSpace(href)
+	if !isWikiURL(href) {
+		return nil, false
+	}
+
+	link := withMark(marks, ADFMark{Type: "link", Attrs: map[string]interface{}{"href": href}})
+	if !hasText {
+		return []ADFNode{{Type: "text", Text: href, Marks: link}}, true
+	}
+	return parseWikiInline(text, link), true
+}
+
+// wikiCutUnescaped cuts s around the first unescaped sep.
+func wikiCutUnescaped(s string, sep byte) (before, after string, found bool) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			return s[:i], s[i+1:], true
+		}
+	}
+	return s, "", false
+}
+
+// isWikiURL reports whether s is a link target rather than, for example, a
+// bracketed issue key.
+func isWikiURL(s string) bool {
+	return strings.Contains(s, "://") || strings.HasPrefix(s, "mailto:") ||
+		strings.HasPrefix(s, "/") || strings.HasPrefix(s, "#")
+}
+
+// wikiImage parses an image such as !diagram.png!, !url|alt=Text! or
+// !diagram.png|thumbnail! at the start of s, returning a media block and the
+// length of the markup.
+func wikiImage(s string) (ADFNode, int, bool) {
+	end := strings.IndexByte(s[1:], '!')
+	if end <= 0 {
+		return ADFNode{}, 0, false
+	}
+
+	content := s[1 : 1+end]
+	if strings.Contains(content, "\n") || isWikiSpace(content[0]) || isWikiSpace(content[len(content)-1]) {
+		return ADFNode{}, 0, false
+	}
+
+	src, params, _ := strings.Cut(content, "|")
+	if !strings.Contains(src, ".") && !isWikiURL(src) {
+		return ADFNode{}, 0, false
+	}
+
+	// Thumbnails become media groups, which Jira shows as small previews
+	thumbnail := false
+	if first, rest, _ := strings.Cut(params, ","); strings.TrimSpace(first) == "thumbnail" {
+		thumbnail, params = true, rest
+	}
+
+	attrs := map[string]interface{}{"type": "external", "url": src}
+	if alt := wikiMacroParam(params, "alt"); alt != "" {
+		attrs["alt"] = alt
+	}
+	media := []ADFNode{{Type: "media", Attrs: attrs}}
+	if thumbnail {
+		return ADFNode{Type: "mediaGroup", Content: media}, end + 2, true
+	}
+	return ADFNode{
+		Type:    "mediaSingle",
+		Attrs:   map[string]interface{}{"layout": "center"},
+		Content: media,
+	}, end + 2, true
+}
+
+// wikiColor converts a wiki markup color to the hex color ADF uses.
+func wikiColor(color string) string {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if hex, ok := wikiColors[color]; ok {
+		return hex
+	}
+	return color
+}
+
+func isWikiSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+// isWikiWordChar reports whether c is part of a word. Bytes of multi-byte
+// UTF-8 characters count as word characters.
+func isWikiWordChar(c byte) bool {
+	return c >= 0x80 || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// isWikiPunct reports whether c is ASCII punctuation, which a backslash
+// escapes.
+func isWikiPunct(c byte) bool {
+	return c > ' ' && c < 0x7f && !isWikiWordChar(c)
+}
+
+// renderWikiBlocks renders block nodes separated by blank lines.
+func renderWikiBlocks(nodes []ADFNode) string {
+	parts := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		if part := renderWikiBlock(node); strings.TrimSpace(part) != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// renderWikiBlock renders a block node.
+func renderWikiBlock(node ADFNode) string {
+	switch node.Type {
+	case "paragraph":
+		return escapeWikiLineStarts(renderWikiInline(node.Content, false))
+
+	case "heading":
+		return fmt.Sprintf("h%d. %s", adfIntAttr(node.Attrs, "level", 1), renderWikiInline(node.Content, true))
+
+	case "bulletList", "orderedList":
+		var b strings.Builder
+		writeWikiList(&b, node, "")
+		return strings.TrimSuffix(b.String(), "\n")
+
+	case "codeBlock":
+		var text strings.Builder
+		for _, child := range node.Content {
+			text.WriteString(child.Text)
+		}
+		if language := adfStringAttr(node.Attrs, "language"); language != "" {
+			return "{code:" + language + "}\n" + text.String() + "\n{code}"
+		}
+		return "{noformat}\n" + text.String() + "\n{noformat}"
+
+	case "blockquote":
+		if len(node.Content) == 1 && node.Content[0].Type == "paragraph" {
+			return "bq. " + renderWikiInline(node.Content[0].Content, true)
+		}
+		return "{quote}\n" + renderWikiBlocks(node.Content) + "\n{quote}"
+
+	case "panel":
+		name := "panel"
+		for macro, panelType := range wikiPanelTypes {
+			if panelType == adfStringAttr(node.Attrs, "panelType") {
+				name = macro
+			}
+		}
+		return "{" + name + "}\n" + renderWikiBlocks(node.Content) + "\n{" + name + "}"
+
+	case "rule":
+		return "----"
+
+	case "table":
+		rows := make([]string, 0, len(node.Content))
+		for _, row := range node.Content {
+			rows = append(rows, renderWikiTableRow(row))
+		}
+		return strings.Join(rows, "\n")
+
+	case "mediaSingle", "mediaGroup":
+		images := make([]string, 0, len(node.Content))
+		for _, media := range node.Content {
+			images = append(images, renderWikiImage(media, node.Type == "mediaGroup"))
+		}
+		return strings.Join(images, " ")
+
+	default:
+		if len(node.Content) > 0 && isADFInline(node.Content[0]) {
+			return escapeWikiLineStarts(renderWikiInline(node.Content, false))
+		}
+		return renderWikiBlocks(node.Content)
+	}
+}
+
+// writeWikiList writes one line per list item, prefixed with the markers of
+// the enclosing lists.
+func writeWikiList(b *strings.Builder, list ADFNode, prefix string) {
+	marker := "*"
+	if list.Type == "orderedList" {
+		marker = "#"
+	}
+
+	for _, item := range list.Content {
+		var blocks, nested []ADFNode
+		for _, child := range item.Content {
+			if child.Type == "bulletList" || child.Type == "orderedList" {
+				nested = append(nested, child)
+			} else {
+				blocks = append(blocks, child)
+			}
+		}
+
+		b.WriteString(prefix + marker + " " + renderWikiLine(blocks) + "\n")
+		for _, child := range nested {
+			writeWikiList(b, child, prefix+marker)
+		}
+	}
+}
+
+// renderWikiTableRow renders a table row on one line.
+func renderWikiTableRow(row ADFNode) string {
+	var b strings.Builder
+	delimiter := "|"
+	for _, cell := range row.Content {
+		delimiter = "|"
+		if cell.Type == "tableHeader" {
+			delimiter = "||"
+		}
+
+		content := renderWikiLine(cell.Content)
+		if content == "" {
+			// An empty cell would read as a header delimiter
+			content = " "
+		}
+		b.WriteString(delimiter + content)
+	}
+	b.WriteString(delimiter)
+	return b.String()
+}
+
+// renderWikiLine renders block nodes on a single line, for list items and
+// table cells. Paragraphs are separated by line breaks.
+func renderWikiLine(nodes []ADFNode) string {
+	parts := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		switch node.Type {
+		case "paragraph":
+			parts = append(parts, renderWikiInline(node.Content, true))
+		case "mediaSingle", "mediaGroup":
+			parts = append(parts, renderWikiBlock(node))
+		default:
+			parts = append(parts, escapeWiki(nodeToText(node), true))
+		}
+	}
+	return strings.Join(parts, `\\`)
+}
+
+// renderWikiImage renders a media node as an image, or as a thumbnail for
+// the media of a group.
+func renderWikiImage(media ADFNode, thumbnail bool) string {
+	src := adfStringAttr(media.Attrs, "url")
+	alt := adfStringAttr(media.Attrs, "alt")
+	if src == "" {
+		// Attachments are referenced by file name
+		src, alt = alt, ""
+	}
+	if src == "" {
+		return ""
+	}
+
+	var params []string
+	if thumbnail {
+		params = append(params, "thumbnail")
+	}
+	if alt != "" {
+		params = append(params, "alt="+alt)
+	}
+	if len(params) > 0 {
+		return "!" + src + "|" + strings.Join(params, ", ") + "!"
+	}
+	return "!" + src + "!"
+}
+
+// wikiAccountIDPattern matches Atlassian account IDs, such as
+// 5b10a2844c20165700ede21g or 557058:f58131cb-b67d-43c7-b30d-6b58d40bd077.
+var wikiAccountIDPattern = regexp.MustCompile(`^([0-9a-z]{24}|[0-9]+:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})$`)
+
+// renderWikiMention renders a mention. Account IDs keep the accountid: prefix
+// that Jira Cloud requires; other IDs are Server/Data Center user names.
+func renderWikiMention(id string) string {
+	if wikiAccountIDPattern.MatchString(id) {
+		return "[~accountid:" + id + "]"
+	}
+	return "[~" + id + "]"
+}
+
+// renderWikiInline renders inline nodes. Marks shared by adjacent text nodes
+// are opened once, so "*bold _both_*" survives a round trip. In single-line
+// mode hard breaks are written as \\ rather than newlines.
+func renderWikiInline(nodes []ADFNode, singleLine bool) string {
+	var b bytes.Buffer
+	var open []ADFMark
+	var starts []int
+
+	closeTo := func(n int) {
+		for len(open) > n {
+			mark := open[len(open)-1]
+			start := starts[len(starts)-1]
+			open, starts = open[:len(open)-1], starts[:len(starts)-1]
+
+			if mark.Type == "link" {
+				href := adfStringAttr(mark.Attrs, "href")
+				if string(b.Bytes()[start:]) == href {
+					b.WriteString("]")
+				} else {
+					b.WriteString("|" + href + "]")
+				}
+				continue
+			}
+			b.WriteString(wikiMarkClose(mark))
+		}
+	}
+
+	for _, node := range nodes {
+		common := 0
+		for common < len(open) && common < len(node.Marks) && sameADFMark(open[common], node.Marks[common]) {
+			common++
+		}
+		closeTo(common)
+		for _, mark := range node.Marks[common:] {
+			b.WriteString(wikiMarkOpen(mark))
+			open = append(open, mark)
+			starts = append(starts, b.Len())
+		}
+
+		switch node.Type {
+		case "text":
+			if hasADFMark(node.Marks, "code") {
+				b.WriteString(node.Text)
+			} else {
+				b.WriteString(escapeWiki(node.Text, singleLine))
+			}
+		case "hardBreak":
+			if singleLine {
+				b.WriteString(`\\`)
+			} else {
+				b.WriteString("\n")
+			}
+		case "mention":
+			b.WriteString(renderWikiMention(adfStringAttr(node.Attrs, "id")))
+		case "emoji":
+			if text := adfStringAttr(node.Attrs, "text"); text != "" {
+				b.WriteString(text)
+			} else {
+				b.WriteString(adfStringAttr(node.Attrs, "shortName"))
+			}
+		case "inlineCard":
+			b.WriteString("[" + adfStringAttr(node.Attrs, "url") + "]")
+		case "mediaInline", "media":
+			b.WriteString(renderWikiImage(node, false))
+		default:
+			b.WriteString(escapeWiki(nodeToText(node), singleLine))
+		}
+	}
+	closeTo(0)
+
+	return b.String()
+}
+
+// wikiMarkOpen returns the markup that opens mark.
+func wikiMarkOpen(mark ADFMark) string {
+	switch mark.Type {
+	case "code":
+		return "{{"
+	case "textColor":
+		return "{color:" + adfStringAttr(mark.Attrs, "color") + "}"
+	case "link":
+		return "["
+	default:
+		return wikiMarkClose(mark)
+	}
+}
+
+// wikiMarkClose returns the markup that closes mark. Links are closed by
+// renderWikiInline.
+func wikiMarkClose(mark ADFMark) string {
+	switch mark.Type {
+	case "strong":
+		return "*"
+	case "em":
+		return "_"
+	case "strike":
+		return "-"
+	case "underline":
+		return "+"
+	case "subsup":
+		if adfStringAttr(mark.Attrs, "type") == "sub" {
+			return "~"
+		}
+		return "^"
+	case "code":
+		return "}}"
+	case "textColor":
+		return "{color}"
+	default:
+		return ""
+	}
+}
+
+// escapeWiki escapes the characters of text that would otherwise be read as
+// markup.
+func escapeWiki(text string, singleLine bool) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\n' && singleLine:
+			b.WriteString(`\\`)
+			continue
+		case c == '[' || c == '{' || c == '|':
+			b.WriteByte('\\')
+		case c == '!':
+			if _, _, ok := wikiImage(text[i:]); ok {
+				b.WriteByte('\\')
+			}
+		case strings.IndexByte(wikiMarkChars, c) >= 0:
+			if _, ok := wikiMarkEnd(text, i); ok {
+				b.WriteByte('\\')
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// escapeWikiLineStarts escapes paragraph lines that would otherwise start a
+// heading, quote, list or rule.
+func escapeWikiLineStarts(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		rest := strings.TrimLeft(line, " \t")
+		indent := line[:len(line)-len(rest)]
+		switch {
+		case wikiHeadingPattern.MatchString(rest), wikiQuotePattern.MatchString(rest):
+			dot := strings.IndexByte(rest, '.')
+			lines[i] = indent + rest[:dot] + `\` + rest[dot:]
+		case wikiListPattern.MatchString(rest), rest == "----":
+			lines[i] = indent + `\` + rest
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isADFInline reports whether node is an inline node.
+func isADFInline(node ADFNode) bool {
+	switch node.Type {
+	case "text", "hardBreak", "mention", "emoji", "inlineCard", "mediaInline", "date", "status":
+		return true
+	default:
+		return false
+	}
+}
+
+func hasADFMark(marks []ADFMark, markType string) bool {
+	for _, mark := range marks {
+		if mark.Type == markType {
+			return true
+		}
+	}
+	return false
+}
+
+func sameADFMark(a, b ADFMark) bool {
+	return a.Type == b.Type && reflect.DeepEqual(a.Attrs, b.Attrs)
+}
+
+// adfStringAttr returns a string attribute, or "" when it is missing.
+func adfStringAttr(attrs map[string]interface{}, name string) string {
+	value, _ := attrs[name].(string)
+	return value
+}
+
+// adfIntAttr returns an integer attribute, which is a float64 when the
+// document was decoded from JSON.
+func adfIntAttr(attrs map[string]interface{}, name string, fallback int) int {
+	switch value := attrs[name].(type) {
+	case int:
+		return value
+	case float64:
+		return int(value)
+	default:
+		return fallback
+	}
+}
@@ -0,0 +1,229 @@
+package issue
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wikiCorpus is wiki markup that converts to ADF and back unchanged.
+var wikiCorpus = []struct {
+	name   string
+	markup string
+}{
+	{name: "paragraphs", markup: "First paragraph.\n\nSecond paragraph,\nwith a line break."},
+	{name: "headings", markup: "h1. Title\n\nh2. Section\n\nh6. Small print"},
+	{name: "text effects", markup: "*strong* _emphasis_ -deleted- +inserted+ ^super^ ~sub~ {{code()}}"},
+	{name: "nested effects", markup: "*bold _and italic_ text* after"},
+	{name: "color", markup: "{color:#ff5630}Red alert{color} and {color:#36b37e}*green*{color}"},
+	{name: "punctuation", markup: "C++ and snake_case_names, 5 - 3 = 2, x*y*z, Hello!"},
+	{name: "escaped markup", markup: `Not \*bold* or \[a link], a \{macro} or a \| pipe`},
+	{name: "escaped line starts", markup: "\\* not a list\n\\- nor this\nh1\\. not a heading"},
+	{name: "links", markup: "See [the docs|https://example.com/docs] or [https://example.com] and [*bold link*|mailto:team@example.com]"},
+	{name: "mention", markup: "Assigned to [~jdoe], reviewed by [~JIRAUSER10100]."},
+	{name: "cloud mention", markup: "Assigned to [~accountid:5b10a2844c20165700ede21g], reviewed by [~accountid:557058:f58131cb-b67d-43c7-b30d-6b58d40bd077]."},
+	{name: "image", markup: "!diagram.png!"},
+	{name: "image with alt", markup: "Before\n\n!https://example.com/chart.png|alt=Chart!\n\nAfter"},
+	{name: "thumbnail", markup: "!diagram.png|thumbnail!"},
+	{name: "thumbnail with alt", markup: "Before\n\n!https://example.com/chart.png|thumbnail, alt=Chart!\n\nAfter"},
+	{name: "bullet list", markup: "* One\n* Two\n* Three"},
+	{name: "numbered list", markup: "# First\n# Second"},
+	{name: "nested lists", markup: "* Fruit\n** Apple\n** Pear\n*# Ranked\n* Vegetables\n\n# Step\n## Sub-step"},
+	{name: "list item effects", markup: "* *Bold* item with [a link|https://example.com]\n* Item with a\\\\break"},
+	{name: "table", markup: "||Name||Status||\n|Build|*Passing*|\n|Deploy|[Logs|https://ci.example.com/1]|"},
+	{name: "table with empty cell", markup: "||Key||Value||\n|a| |\n|b\\|c|d|"},
+	{name: "code", markup: "{code:go}\nfunc main() {\n\tfmt.Println(\"*not bold*\")\n}\n{code}"},
+	{name: "noformat", markup: "{noformat}\n[not a link] and _not italic_\n{noformat}"},
+	{name: "quote", markup: "bq. Quoted *text*"},
+	{name: "multi-paragraph quote", markup: "{quote}\nFirst\n\nSecond\n{quote}"},
+	{name: "panels", markup: "{info}\nInformation\n{info}\n\n{tip}\nA tip\n{tip}\n\n{note}\nA note\n{note}\n\n{warning}\n* Careful\n* Now\n{warning}\n\n{panel}\nPlain panel\n{panel}"},
+	{name: "rule", markup: "Above\n\n----\n\nBelow"},
+	{name: "mixed document", markup: "h1. Release notes\n\nThe *2.0* release adds [~jdoe]'s work.\n\n# Upgrade\n# Restart\n\n||Component||Version||\n|api|2.0|\n\n{code:bash}\nmake deploy\n{code}"},
+}
+
+func TestWiki_RoundTrip(t *testing.T) {
+	for _, tt := range wikiCorpus {
+		t.Run(tt.name, func(t *testing.T) {
+			adf := ADFFromWiki(tt.markup)
+			assert.Equal(t, tt.markup, adf.ToWiki(), "markup survives a round trip")
+
+			// The document survives a round trip through JSON and markup
+			data, err := json.Marshal(adf)
+			require.NoError(t, err)
+			var decoded ADF
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			assert.Equal(t, tt.markup, decoded.ToWiki())
+		})
+	}
+}
+
+func TestADFFromWiki(t *testing.T) {
+	tests := []struct {
+		name   string
+		markup string
+		want   []ADFNode
+	}{
+		{
+			name:   "heading",
+			markup: "h3. Title",
+			want: []ADFNode{
+				{Type: "heading", Attrs: map[string]interface{}{"level": 3}, Content: []ADFNode{{Type: "text", Text: "Title"}}},
+			},
+		},
+		{
+			name:   "nested marks",
+			markup: "*a _b_*",
+			want: []ADFNode{
+				{Type: "paragraph", Content: []ADFNode{
+					{Type: "text", Text: "a ", Marks: []ADFMark{{Type: "strong"}}},
+					{Type: "text", Text: "b", Marks: []ADFMark{{Type: "strong"}, {Type: "em"}}},
+				}},
+			},
+		},
+		{
+			name:   "named color",
+			markup: "{color:red}x{color}",
+			want: []ADFNode{
+				{Type: "paragraph", Content: []ADFNode{
+					{Type: "text", Text: "x", Marks: []ADFMark{{Type: "textColor", Attrs: map[string]interface{}{"color": "#ff0000"}}}},
+				}},
+			},
+		},
+		{
+			name:   "cloud mention",
+			markup: "[~accountid:5b10a2844c20165700ede21g]",
+			want: []ADFNode{
+				{Type: "paragraph", Content: []ADFNode{
+					{Type: "mention", Attrs: map[string]interface{}{"id": "5b10a2844c20165700ede21g", "text": "@5b10a2844c20165700ede21g"}},
+				}},
+			},
+		},
+		{
+			name:   "issue key in brackets is text",
+			markup: "[PROJ-123]",
+			want: []ADFNode{
+				{Type: "paragraph", Content: []ADFNode{{Type: "text", Text: "[PROJ-123]"}}},
+			},
+		},
+		{
+			name:   "code without language",
+			markup: "{code}\nx := 1\n{code}",
+			want: []ADFNode{
+				{Type: "codeBlock", Content: []ADFNode{{Type: "text", Text: "x := 1"}}},
+			},
+		},
+		{
+			name:   "unclosed macro is text",
+			markup: "{code}\nx := 1",
+			want: []ADFNode{
+				{Type: "paragraph", Content: []ADFNode{
+					{Type: "text", Text: "{code}"},
+					{Type: "hardBreak"},
+					{Type: "text", Text: "x := 1"},
+				}},
+			},
+		},
+		{
+			name:   "forced line break",
+			markup: `one\\two`,
+			want: []ADFNode{
+				{Type: "paragraph", Content: []ADFNode{
+					{Type: "text", Text: "one"},
+					{Type: "hardBreak"},
+					{Type: "text", Text: "two"},
+				}},
+			},
+		},
+		{
+			name:   "dash bullets",
+			markup: "- one\n- two",
+			want: []ADFNode{
+				{Type: "bulletList", Content: []ADFNode{
+					{Type: "listItem", Content: []ADFNode{{Type: "paragraph", Content: []ADFNode{{Type: "text", Text: "one"}}}}},
+					{Type: "listItem", Content: []ADFNode{{Type: "paragraph", Content: []ADFNode{{Type: "text", Text: "two"}}}}},
+				}},
+			},
+		},
+		{
+			name:   "panel title",
+			markup: "{panel:title=Heads up|borderStyle=dashed}\nBody\n{panel}",
+			want: []ADFNode{
+				{Type: "panel", Attrs: map[string]interface{}{"panelType": "note"}, Content: []ADFNode{
+					{Type: "paragraph", Content: []ADFNode{{Type: "text", Text: "Heads up", Marks: []ADFMark{{Type: "strong"}}}}},
+					{Type: "paragraph", Content: []ADFNode{{Type: "text", Text: "Body"}}},
+				}},
+			},
+		},
+		{
+			name:   "table",
+			markup: "||H||\n| c |",
+			want: []ADFNode{
+				{Type: "table", Content: []ADFNode{
+					{Type: "tableRow", Content: []ADFNode{
+						{Type: "tableHeader", Content: []ADFNode{{Type: "paragraph", Content: []ADFNode{{Type: "text", Text: "H"}}}}},
+					}},
+					{Type: "tableRow", Content: []ADFNode{
+						{Type: "tableCell", Content: []ADFNode{{Type: "paragraph", Content: []ADFNode{{Type: "text", Text: "c"}}}}},
+					}},
+				}},
+			},
+		},
+		{
+			name:   "pipes without cells",
+			markup: "|\n||",
+			want: []ADFNode{
+				{Type: "paragraph", Content: []ADFNode{
+					{Type: "text", Text: "|"},
+					{Type: "hardBreak"},
+					{Type: "text", Text: "||"},
+				}},
+			},
+		},
+		{
+			name:   "pipes after a table",
+			markup: "|a|\n||",
+			want: []ADFNode{
+				{Type: "table", Content: []ADFNode{
+					{Type: "tableRow", Content: []ADFNode{
+						{Type: "tableCell", Content: []ADFNode{{Type: "paragraph", Content: []ADFNode{{Type: "text", Text: "a"}}}}},
+					}},
+				}},
+				{Type: "paragraph", Content: []ADFNode{{Type: "text", Text: "||"}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adf := ADFFromWiki(tt.markup)
+			assert.Equal(t, "doc", adf.Type)
+			assert.Equal(t, 1, adf.Version)
+			assert.Equal(t, tt.want, adf.Content)
+
+			// The markup ToWiki writes reads back as the same document
+			assert.Equal(t, tt.want, ADFFromWiki(adf.ToWiki()).Content)
+		})
+	}
+}
+
+func TestADF_ToWiki(t *testing.T) {
+	adf := NewADF().
+		AddHeading("Steps", 2).
+		AddOrderedList([]string{"Build", "Deploy"}).
+		AddBulletList([]string{"Fast"}).
+		AddCodeBlock("make", "bash").
+		AddCodeBlock("plain", "").
+		AddParagraph("* literal star and [brackets]")
+
+	want := "h2. Steps\n\n# Build\n# Deploy\n\n* Fast\n\n{code:bash}\nmake\n{code}\n\n{noformat}\nplain\n{noformat}\n\n\\* literal star and \\[brackets]"
+	assert.Equal(t, want, adf.ToWiki())
+
+	// Converting back gives the same text
+	back := ADFFromWiki(adf.ToWiki())
+	assert.Equal(t, adf.ToText(), back.ToText())
+
+	assert.Empty(t, (*ADF)(nil).ToWiki())
+	assert.Empty(t, NewADF().ToWiki())
+}
@@ -366,6 +366,9 @@ func (s *Service) DeleteWorklog(ctx context.Context, issueKeyOrID, worklogID str
 
 	// Delete returns 204 No Content on success
 	if resp.StatusCode != http.StatusNoContent {
+		if err := s.transport.DecodeResponse(resp, nil); err != nil {
+			return err
+		}
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
@@ -272,11 +272,15 @@ func (s *Service) Delete(ctx context.Context, issueTypeID, alternativeIssueTypeI
 		req.URL.RawQuery = q.Encode()
 	}
 
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -389,11 +393,15 @@ func (s *Service) UpdateIssueTypeScheme(ctx context.Context, schemeID string, in
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -414,11 +422,15 @@ func (s *Service) DeleteIssueTypeScheme(ctx context.Context, schemeID string) er
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -445,11 +457,15 @@ func (s *Service) AddIssueTypesToScheme(ctx context.Context, schemeID string, in
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -474,11 +490,15 @@ func (s *Service) RemoveIssueTypeFromScheme(ctx context.Context, schemeID, issue
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -36,6 +36,8 @@ const (
 type User struct {
 	Self         string      `json:"self,omitempty"`
 	AccountID    string      `json:"accountId,omitempty"`
+	Name         string      `json:"name,omitempty"` // Username on Jira Server/Data Center
+	Key          string      `json:"key,omitempty"`  // User key on Jira Server/Data Center
 	AccountType  string      `json:"accountType,omitempty"`
 	EmailAddress string      `json:"emailAddress,omitempty"`
 	DisplayName  string      `json:"displayName,omitempty"`
@@ -145,11 +147,15 @@ func (s *Service) SetPreferences(ctx context.Context, prefs *Preferences) error
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -217,11 +223,15 @@ func (s *Service) SetPreference(ctx context.Context, key, value string) error {
 	q.Set("key", key)
 	req.URL.RawQuery = q.Encode()
 
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -247,10 +257,14 @@ func (s *Service) DeletePreference(ctx context.Context, key string) error {
 	q.Set("key", key)
 	req.URL.RawQuery = q.Encode()
 
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
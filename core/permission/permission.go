@@ -434,11 +434,15 @@ func (s *Service) DeletePermissionScheme(ctx context.Context, schemeID int64) er
 	}
 
 	// Execute request
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -602,10 +606,14 @@ func (s *Service) RemoveActorFromProjectRole(ctx context.Context, projectKeyOrID
 	req.URL.RawQuery = q.Encode()
 
 	// Execute request
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
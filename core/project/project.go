@@ -22,6 +22,41 @@ type RoundTripper interface {
 	DecodeResponse(resp *http.Response, target interface{}) error
 }
 
+// streamDecoder is implemented by transports that can decode a response
+// without buffering it first, such as *transport.Transport.
+type streamDecoder interface {
+	DecodeResponseStream(resp *http.Response, field string, rest interface{}, item func(decode func(target interface{}) error) error) error
+}
+
+// decodeValues decodes the values of a page response. The values are decoded
+// one at a time when the transport can stream, so a large page is not
+// buffered as raw bytes as well.
+func decodeValues[T any](transport RoundTripper, resp *http.Response) ([]*T, error) {
+	if stream, ok := transport.(streamDecoder); ok {
+		var values []*T
+		err := stream.DecodeResponseStream(resp, "values", nil, func(decode func(target interface{}) error) error {
+			var value T
+			if err := decode(&value); err != nil {
+				return err
+			}
+			values = append(values, &value)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return values, nil
+	}
+
+	var page struct {
+		Values []*T `json:"values"`
+	}
+	if err := transport.DecodeResponse(resp, &page); err != nil {
+		return nil, err
+	}
+	return page.Values, nil
+}
+
 // NewService creates a new Project service.
 func NewService(transport RoundTripper) *Service {
 	return &Service{
@@ -53,6 +88,8 @@ type Project struct {
 type User struct {
 	Self         string      `json:"self,omitempty"`
 	AccountID    string      `json:"accountId,omitempty"`
+	Name         string      `json:"name,omitempty"` // Username on Jira Server/Data Center
+	Key          string      `json:"key,omitempty"`  // User key on Jira Server/Data Center
 	EmailAddress string      `json:"emailAddress,omitempty"`
 	DisplayName  string      `json:"displayName,omitempty"`
 	Active       bool        `json:"active,omitempty"`
@@ -214,19 +251,12 @@ func (s *Service) List(ctx context.Context, opts *ListOptions) ([]*Project, erro
 	}
 
 	// Decode response
-	var result struct {
-		Values     []*Project `json:"values"`
-		StartAt    int        `json:"startAt"`
-		MaxResults int        `json:"maxResults"`
-		Total      int        `json:"total"`
-		IsLast     bool       `json:"isLast"`
-	}
-
-	if err := s.transport.DecodeResponse(resp, &result); err != nil {
+	values, err := decodeValues[Project](s.transport, resp)
+	if err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return result.Values, nil
+	return values, nil
 }
 
 // CreateInput contains the data for creating a project.
@@ -369,6 +399,9 @@ func (s *Service) Delete(ctx context.Context, projectKeyOrID string) error {
 
 	// Delete returns 204 No Content on success
 	if resp.StatusCode != http.StatusNoContent {
+		if err := s.transport.DecodeResponse(resp, nil); err != nil {
+			return err
+		}
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
@@ -404,6 +437,9 @@ func (s *Service) Archive(ctx context.Context, projectKeyOrID string) error {
 
 	// Archive returns 204 No Content on success
 	if resp.StatusCode != http.StatusNoContent {
+		if err := s.transport.DecodeResponse(resp, nil); err != nil {
+			return err
+		}
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
@@ -439,6 +475,9 @@ func (s *Service) Restore(ctx context.Context, projectKeyOrID string) error {
 
 	// Restore returns 200 OK on success
 	if resp.StatusCode != http.StatusOK {
+		if err := s.transport.DecodeResponse(resp, nil); err != nil {
+			return err
+		}
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
@@ -611,6 +650,9 @@ func (s *Service) DeleteComponent(ctx context.Context, componentID string) error
 
 	// Delete returns 204 No Content on success
 	if resp.StatusCode != http.StatusNoContent {
+		if err := s.transport.DecodeResponse(resp, nil); err != nil {
+			return err
+		}
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
@@ -790,6 +832,9 @@ func (s *Service) DeleteVersion(ctx context.Context, versionID string) error {
 
 	// Delete returns 204 No Content on success
 	if resp.StatusCode != http.StatusNoContent {
+		if err := s.transport.DecodeResponse(resp, nil); err != nil {
+			return err
+		}
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
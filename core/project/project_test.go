@@ -84,6 +84,37 @@ func (r *bytesReader) Read(p []byte) (n int, err error) {
 	return n, nil
 }
 
+// streamingMockTransport adds streaming decoding to mockTransport and counts
+// the items it has decoded.
+type streamingMockTransport struct {
+	*mockTransport
+	decoded int
+}
+
+func (m *streamingMockTransport) DecodeResponseStream(resp *http.Response, field string, rest interface{}, item func(decode func(target interface{}) error) error) error {
+	defer resp.Body.Close()
+
+	var page map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return err
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(page[field], &items); err != nil {
+		return err
+	}
+	for _, raw := range items {
+		err := item(func(target interface{}) error {
+			m.decoded++
+			return json.Unmarshal(raw, target)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func TestProjectGet(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -624,3 +655,16 @@ func TestProjectRestore(t *testing.T) {
 		})
 	}
 }
+
+func TestProjectList_Streaming(t *testing.T) {
+	transport := &streamingMockTransport{mockTransport: newMockTransport(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"startAt":0,"maxResults":50,"total":2,"isLast":true,"values":[{"id":"10000","key":"PROJ1"},{"id":"10001","key":"PROJ2"}]}`))
+	})}
+	defer transport.Close()
+
+	projects, err := NewService(transport).List(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, projects, 2)
+	assert.Equal(t, "PROJ2", projects[1].Key)
+	assert.Equal(t, 2, transport.decoded)
+}
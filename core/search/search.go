@@ -3,10 +3,13 @@ package search
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"iter"
 	"net/http"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/felixgeelhaar/jirasdk/core/issue"
@@ -25,6 +28,29 @@ type RoundTripper interface {
 	DecodeResponse(resp *http.Response, target interface{}) error
 }
 
+// streamDecoder is implemented by transports that can decode a response
+// without buffering it first, such as *transport.Transport. Search results are
+// streamed issue by issue when the transport supports it.
+type streamDecoder interface {
+	DecodeResponseStream(resp *http.Response, field string, rest interface{}, item func(decode func(target interface{}) error) error) error
+}
+
+// featureChecker is implemented by transports that know which features the
+// Jira instance supports, such as *transport.Transport. CheckFeature returns
+// an error matching transport.ErrUnsupported for unsupported features.
+type featureChecker interface {
+	CheckFeature(ctx context.Context, feature string) error
+}
+
+// Features consulted by this service, as named by the transport.
+const (
+	featureEnhancedSearch = "search/jql"
+	featureLegacySearch   = "search"
+)
+
+// errStopped stops a streamed page when the caller stops ranging over it.
+var errStopped = errors.New("iteration stopped")
+
 // NewService creates a new Search service.
 func NewService(transport RoundTripper) *Service {
 	return &Service{
@@ -32,6 +58,15 @@ func NewService(transport RoundTripper) *Service {
 	}
 }
 
+// checkFeature returns the transport's verdict on feature, or nil when the
+// transport cannot tell.
+func (s *Service) checkFeature(ctx context.Context, feature string) error {
+	if checker, ok := s.transport.(featureChecker); ok {
+		return checker.CheckFeature(ctx, feature)
+	}
+	return nil
+}
+
 // SearchOptions configures the search operation for the legacy /rest/api/3/search endpoint.
 //
 // Deprecated: Use SearchJQLOptions with SearchJQL method instead.
@@ -114,6 +149,9 @@ type SearchJQLResult struct {
 // by Atlassian on October 31, 2025. Migrate to SearchJQL which uses the new
 // /rest/api/3/search/jql endpoint with token-based pagination.
 //
+// On Jira Cloud, Search fails with an error matching ErrUnsupported without
+// sending a request. SearchJQL works on both Cloud and Server/Data Center.
+//
 // Example:
 //
 //	results, err := client.Search.Search(ctx, &search.SearchOptions{
@@ -125,6 +163,10 @@ func (s *Service) Search(ctx context.Context, opts *SearchOptions) (*SearchResul
 		return nil, fmt.Errorf("JQL query is required")
 	}
 
+	if err := s.checkFeature(ctx, featureLegacySearch); err != nil {
+		return nil, err
+	}
+
 	path := "/rest/api/3/search"
 
 	// Build request body
@@ -184,6 +226,10 @@ func (s *Service) Search(ctx context.Context, opts *SearchOptions) (*SearchResul
 //   - Default fields is ["id"] instead of ["*navigable"]
 //   - Supports up to 5000 results per page (vs 100 in legacy endpoint)
 //
+// Jira Server/Data Center has no search/jql endpoint, so there SearchJQL uses
+// the legacy search endpoint, and NextPageToken carries the offset of the next
+// page. Callers paginate the same way on both.
+//
 // Example:
 //
 //	results, err := client.Search.SearchJQL(ctx, &search.SearchJQLOptions{
@@ -203,6 +249,23 @@ func (s *Service) Search(ctx context.Context, opts *SearchOptions) (*SearchResul
 //		// Process results...
 //	}
 func (s *Service) SearchJQL(ctx context.Context, opts *SearchJQLOptions) (*SearchJQLResult, error) {
+	issues := []*issue.Issue{}
+	result, err := s.searchJQL(ctx, opts, func(i *issue.Issue) error {
+		issues = append(issues, i)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Issues = issues
+	return result, nil
+}
+
+// searchJQL executes a JQL search and hands the issues of the page to fn one
+// at a time, decoding them as they are read if the transport supports it. The
+// returned result carries the page's metadata but no issues.
+func (s *Service) searchJQL(ctx context.Context, opts *SearchJQLOptions, fn func(*issue.Issue) error) (*SearchJQLResult, error) {
 	if opts == nil || opts.JQL == "" {
 		return nil, fmt.Errorf("JQL query is required")
 	}
@@ -224,6 +287,22 @@ func (s *Service) SearchJQL(ctx context.Context, opts *SearchJQLOptions) (*Searc
 		body["nextPageToken"] = opts.NextPageToken
 	}
 
+	// Server/Data Center only has the legacy endpoint; page tokens carry the
+	// offset there
+	legacy := s.checkFeature(ctx, featureEnhancedSearch) != nil
+	if legacy {
+		path = "/rest/api/3/search"
+		startAt := 0
+		if opts.NextPageToken != "" {
+			var err error
+			if startAt, err = strconv.Atoi(opts.NextPageToken); err != nil || startAt < 0 {
+				return nil, fmt.Errorf("invalid page token %q", opts.NextPageToken)
+			}
+		}
+		delete(body, "nextPageToken")
+		body["startAt"] = startAt
+	}
+
 	// Add fields if specified
 	if len(opts.Fields) > 0 {
 		body["fields"] = opts.Fields
@@ -261,10 +340,47 @@ func (s *Service) SearchJQL(ctx context.Context, opts *SearchJQLOptions) (*Searc
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
 
-	// Decode response
-	var result SearchJQLResult
-	if err := s.transport.DecodeResponse(resp, &result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	// Decode response; the offset fields are only set by the legacy endpoint
+	var page struct {
+		SearchJQLResult
+		StartAt int `json:"startAt"`
+		Total   int `json:"total"`
+	}
+	count := 0
+	handle := func(i *issue.Issue) error {
+		count++
+		return fn(i)
+	}
+
+	if stream, ok := s.transport.(streamDecoder); ok {
+		err = stream.DecodeResponseStream(resp, "issues", &page, func(decode func(target interface{}) error) error {
+			var i issue.Issue
+			if err := decode(&i); err != nil {
+				return err
+			}
+			return handle(&i)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+	} else {
+		if err := s.transport.DecodeResponse(resp, &page); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		for _, i := range page.Issues {
+			if err := handle(i); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	result := page.SearchJQLResult
+	result.Issues = nil
+	if legacy {
+		result.NextPageToken = ""
+		if next := page.StartAt + count; count > 0 && next < page.Total {
+			result.NextPageToken = strconv.Itoa(next)
+		}
 	}
 
 	return &result, nil
@@ -570,12 +686,20 @@ func (it *SearchIterator) Err() error {
 
 // SearchJQLIterator provides an iterator for paginated search results using the new JQL endpoint.
 // This iterator automatically handles token-based pagination.
+//
+// Pages are fetched one at a time as the previous one is used up. Issues are
+// decoded one at a time as the page is read, so a page is never held in memory
+// as raw bytes as well as decoded issues when the transport supports
+// streaming. No response is left open between calls to Next, so stopping
+// early needs no cleanup. Next holds the decoded issues of the current page;
+// ranging over All instead hands each issue over as soon as it is decoded.
 type SearchJQLIterator struct {
 	service   *Service
 	opts      *SearchJQLOptions
-	nextToken string // Internal pagination state
-	current   *SearchJQLResult
-	index     int
+	nextToken string         // Internal pagination state
+	page      []*issue.Issue // Issues of the current page not yet returned
+	done      bool           // No pages left to fetch
+	current   *issue.Issue
 	ctx       context.Context
 	err       error
 }
@@ -615,54 +739,121 @@ func (s *Service) NewSearchJQLIterator(ctx context.Context, opts *SearchJQLOptio
 		opts:      &optsCopy,
 		nextToken: opts.NextPageToken, // Preserve initial token if provided
 		ctx:       ctx,
-		index:     -1,
 	}
 }
 
 // Next advances the iterator to the next issue.
 // Returns true if an issue is available, false if iteration is complete or an error occurred.
 func (it *SearchJQLIterator) Next() bool {
-	it.index++
-
-	// Check if we need to fetch the next page
-	if it.current == nil || it.index >= len(it.current.Issues) {
-		// Check if there are more pages
-		if it.current != nil && !it.current.HasNextPage() {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			it.current = nil
 			return false
 		}
+		it.fetchPage()
+	}
 
-		// Create options copy with current pagination token
-		// This avoids mutating the original caller-provided options
-		optsForRequest := *it.opts
-		optsForRequest.NextPageToken = it.nextToken
+	it.current = it.page[0]
+	it.page[0] = nil // Let returned issues be collected
+	it.page = it.page[1:]
+	return true
+}
 
-		result, err := it.service.SearchJQL(it.ctx, &optsForRequest)
-		if err != nil {
-			it.err = err
-			return false
-		}
+// fetchPage reads the next page of issues.
+func (it *SearchJQLIterator) fetchPage() {
+	// Create options copy with current pagination token
+	// This avoids mutating the original caller-provided options
+	optsForRequest := *it.opts
+	optsForRequest.NextPageToken = it.nextToken
 
-		// Update internal pagination state
-		it.current = result
-		it.nextToken = result.NextPageToken
-		it.index = 0
+	var issues []*issue.Issue
+	result, err := it.service.searchJQL(it.ctx, &optsForRequest, func(i *issue.Issue) error {
+		issues = append(issues, i)
+		return nil
+	})
+	if err != nil {
+		it.err = err
+		return
+	}
 
-		// Check if we got any results
-		if len(result.Issues) == 0 {
-			return false
-		}
+	// Update internal pagination state
+	it.page = issues
+	it.nextToken = result.NextPageToken
+
+	// Stop after the last page, or a page without results
+	if len(issues) == 0 || !result.HasNextPage() {
+		it.done = true
 	}
+}
 
-	return it.index < len(it.current.Issues)
+// All returns the remaining issues as a sequence for a range loop.
+//
+// Each issue is yielded from inside the decoding of its page, so no more than
+// one decoded issue is held at a time when the transport supports streaming.
+// Breaking out of the loop closes the response being read and ends the
+// iteration, like Close. Check Err after the loop.
+//
+// Example:
+//
+//	iter := client.Search.NewSearchJQLIterator(ctx, &search.SearchJQLOptions{
+//		JQL: "project = PROJ",
+//	})
+//	for issue := range iter.All() {
+//		fmt.Println(issue.Key)
+//	}
+//	if err := iter.Err(); err != nil {
+//		log.Fatal(err)
+//	}
+func (it *SearchJQLIterator) All() iter.Seq[*issue.Issue] {
+	return func(yield func(*issue.Issue) bool) {
+		// Issues of a page already fetched by Next come first
+		for len(it.page) > 0 {
+			it.current = it.page[0]
+			it.page[0] = nil // Let returned issues be collected
+			it.page = it.page[1:]
+			if !yield(it.current) {
+				it.Close()
+				return
+			}
+		}
+
+		for !it.done && it.err == nil {
+			optsForRequest := *it.opts
+			optsForRequest.NextPageToken = it.nextToken
+
+			count := 0
+			stopped := false
+			result, err := it.service.searchJQL(it.ctx, &optsForRequest, func(i *issue.Issue) error {
+				count++
+				it.current = i
+				if !yield(i) {
+					stopped = true
+					return errStopped
+				}
+				return nil
+			})
+			if stopped {
+				it.Close()
+				return
+			}
+			if err != nil {
+				it.err = err
+				break
+			}
+
+			it.nextToken = result.NextPageToken
+			if count == 0 || !result.HasNextPage() {
+				it.done = true
+			}
+		}
+		it.current = nil
+	}
 }
 
 // Issue returns the current issue.
 // Returns nil if there is no current issue (before first Next() call or after iteration completes).
 func (it *SearchJQLIterator) Issue() *issue.Issue {
-	if it.current == nil || it.index < 0 || it.index >= len(it.current.Issues) {
-		return nil
-	}
-	return it.current.Issues[it.index]
+	return it.current
 }
 
 // Err returns any error encountered during iteration.
@@ -670,6 +861,14 @@ func (it *SearchJQLIterator) Err() error {
 	return it.err
 }
 
+// Close stops the iteration and drops the rest of the current page. It is
+// safe to call more than once, and never required.
+func (it *SearchJQLIterator) Close() {
+	it.done = true
+	it.page = nil
+	it.current = nil
+}
+
 // ParseURL parses a Jira issue URL and extracts the issue key.
 //
 // Example:
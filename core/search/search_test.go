@@ -3,6 +3,8 @@ package search
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -991,6 +993,174 @@ func TestSearchJQLIterator(t *testing.T) {
 	})
 }
 
+// streamingMockTransport adds streaming decoding to mockTransport and counts
+// the items it has decoded.
+type streamingMockTransport struct {
+	*mockTransport
+	decoded int
+	closed  int
+}
+
+func (m *streamingMockTransport) DecodeResponseStream(resp *http.Response, field string, rest interface{}, item func(decode func(target interface{}) error) error) error {
+	defer func() {
+		m.closed++
+		resp.Body.Close()
+	}()
+
+	var page map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return err
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(page[field], &items); err != nil {
+		return err
+	}
+	for _, raw := range items {
+		err := item(func(target interface{}) error {
+			m.decoded++
+			return json.Unmarshal(raw, target)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	delete(page, field)
+	data, _ := json.Marshal(page)
+	return json.Unmarshal(data, rest)
+}
+
+func TestSearchJQLIterator_Streaming(t *testing.T) {
+	newTransport := func() *streamingMockTransport {
+		callCount := 0
+		return &streamingMockTransport{mockTransport: newMockTransport(func(w http.ResponseWriter, r *http.Request) {
+			callCount++
+			result := SearchJQLResult{
+				Issues:        []*issue.Issue{{Key: "PROJ-1"}, {Key: "PROJ-2"}, {Key: "PROJ-3"}},
+				NextPageToken: "token-page2",
+			}
+			if callCount > 1 {
+				result = SearchJQLResult{Issues: []*issue.Issue{{Key: "PROJ-4"}}}
+			}
+			json.NewEncoder(w).Encode(result)
+		})}
+	}
+
+	t.Run("decodes page by page", func(t *testing.T) {
+		transport := newTransport()
+		defer transport.Close()
+
+		iter := NewService(transport).NewSearchJQLIterator(context.Background(), &SearchJQLOptions{JQL: "project = PROJ"})
+
+		require.True(t, iter.Next())
+		assert.Equal(t, "PROJ-1", iter.Issue().Key)
+		assert.Equal(t, 3, transport.decoded, "the first page is decoded")
+		assert.Equal(t, 1, transport.closed, "no response is left open")
+
+		var keys []string
+		for iter.Next() {
+			keys = append(keys, iter.Issue().Key)
+		}
+		assert.NoError(t, iter.Err())
+		assert.Equal(t, []string{"PROJ-2", "PROJ-3", "PROJ-4"}, keys)
+		assert.Equal(t, 2, transport.closed)
+		assert.Nil(t, iter.Issue())
+	})
+
+	t.Run("stopping early leaves nothing open", func(t *testing.T) {
+		transport := newTransport()
+		defer transport.Close()
+
+		iter := NewService(transport).NewSearchJQLIterator(context.Background(), &SearchJQLOptions{JQL: "project = PROJ"})
+		for iter.Next() {
+			if iter.Issue().Key == "PROJ-2" {
+				break
+			}
+		}
+
+		assert.Equal(t, 1, transport.closed)
+		assert.Equal(t, 3, transport.decoded)
+	})
+
+	t.Run("close stops the iteration", func(t *testing.T) {
+		transport := newTransport()
+		defer transport.Close()
+
+		iter := NewService(transport).NewSearchJQLIterator(context.Background(), &SearchJQLOptions{JQL: "project = PROJ"})
+		require.True(t, iter.Next())
+
+		iter.Close()
+		iter.Close()
+
+		assert.False(t, iter.Next())
+		assert.Nil(t, iter.Issue())
+		assert.NoError(t, iter.Err())
+		assert.Equal(t, 1, transport.closed, "no further page is fetched")
+	})
+
+	t.Run("all yields issues while decoding", func(t *testing.T) {
+		transport := newTransport()
+		defer transport.Close()
+
+		iter := NewService(transport).NewSearchJQLIterator(context.Background(), &SearchJQLOptions{JQL: "project = PROJ"})
+
+		var keys []string
+		var decodedAt []int
+		for i := range iter.All() {
+			keys = append(keys, i.Key)
+			decodedAt = append(decodedAt, transport.decoded)
+		}
+		assert.NoError(t, iter.Err())
+		assert.Equal(t, []string{"PROJ-1", "PROJ-2", "PROJ-3", "PROJ-4"}, keys)
+		assert.Equal(t, []int{1, 2, 3, 4}, decodedAt, "each issue is yielded as soon as it is decoded")
+		assert.Equal(t, 2, transport.closed)
+		assert.Nil(t, iter.Issue())
+	})
+
+	t.Run("all continues after next", func(t *testing.T) {
+		transport := newTransport()
+		defer transport.Close()
+
+		iter := NewService(transport).NewSearchJQLIterator(context.Background(), &SearchJQLOptions{JQL: "project = PROJ"})
+		require.True(t, iter.Next())
+
+		var keys []string
+		for i := range iter.All() {
+			keys = append(keys, i.Key)
+		}
+		assert.NoError(t, iter.Err())
+		assert.Equal(t, []string{"PROJ-2", "PROJ-3", "PROJ-4"}, keys)
+	})
+
+	t.Run("breaking out of all stops the iteration", func(t *testing.T) {
+		transport := newTransport()
+		defer transport.Close()
+
+		iter := NewService(transport).NewSearchJQLIterator(context.Background(), &SearchJQLOptions{JQL: "project = PROJ"})
+		for i := range iter.All() {
+			if i.Key == "PROJ-2" {
+				break
+			}
+		}
+
+		assert.Equal(t, 2, transport.decoded, "the rest of the page is not decoded")
+		assert.Equal(t, 1, transport.closed, "the response is closed")
+		assert.NoError(t, iter.Err())
+		assert.False(t, iter.Next())
+	})
+
+	t.Run("search collects streamed issues", func(t *testing.T) {
+		transport := newTransport()
+		defer transport.Close()
+
+		result, err := NewService(transport).SearchJQL(context.Background(), &SearchJQLOptions{JQL: "project = PROJ"})
+		require.NoError(t, err)
+		assert.Len(t, result.Issues, 3)
+		assert.Equal(t, "token-page2", result.NextPageToken)
+	})
+}
+
 func TestParseURL(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -1111,3 +1281,90 @@ func TestQueryBuilderOrderByRejectsUnsafeFieldNames(t *testing.T) {
 		assert.Equal(t, "ORDER BY created ASC", qb.Build())
 	})
 }
+
+// featureMockTransport adds feature checks to mockTransport, rejecting the
+// features in unsupported.
+type featureMockTransport struct {
+	*mockTransport
+	unsupported map[string]bool
+}
+
+var errFeatureUnsupported = errors.New("unsupported")
+
+func (m *featureMockTransport) CheckFeature(_ context.Context, feature string) error {
+	if m.unsupported[feature] {
+		return fmt.Errorf("%s: %w", feature, errFeatureUnsupported)
+	}
+	return nil
+}
+
+func TestSearchJQL_LegacyEndpoint(t *testing.T) {
+	var bodies []map[string]interface{}
+	transport := &featureMockTransport{
+		mockTransport: newMockTransport(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/rest/api/3/search", r.URL.Path)
+
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			bodies = append(bodies, body)
+
+			keys := []string{"PROJ-1", "PROJ-2"}
+			if body["startAt"] == float64(2) {
+				keys = []string{"PROJ-3"}
+			}
+			issues := make([]*issue.Issue, len(keys))
+			for i, key := range keys {
+				issues[i] = &issue.Issue{Key: key}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"issues": issues, "startAt": body["startAt"], "maxResults": 2, "total": 3,
+			})
+		}),
+		unsupported: map[string]bool{featureEnhancedSearch: true},
+	}
+	defer transport.Close()
+
+	service := NewService(transport)
+
+	result, err := service.SearchJQL(context.Background(), &SearchJQLOptions{JQL: "project = PROJ", MaxResults: 2})
+	require.NoError(t, err)
+	assert.Len(t, result.Issues, 2)
+	assert.Equal(t, "2", result.NextPageToken, "the token carries the next offset")
+
+	result, err = service.SearchJQL(context.Background(), &SearchJQLOptions{JQL: "project = PROJ", MaxResults: 2, NextPageToken: result.NextPageToken})
+	require.NoError(t, err)
+	assert.Len(t, result.Issues, 1)
+	assert.False(t, result.HasNextPage())
+
+	require.Len(t, bodies, 2)
+	assert.Equal(t, float64(0), bodies[0]["startAt"])
+	assert.NotContains(t, bodies[1], "nextPageToken")
+
+	// The iterator pages through the legacy endpoint the same way
+	iter := service.NewSearchJQLIterator(context.Background(), &SearchJQLOptions{JQL: "project = PROJ", MaxResults: 2})
+	defer iter.Close()
+	var keys []string
+	for iter.Next() {
+		keys = append(keys, iter.Issue().Key)
+	}
+	require.NoError(t, iter.Err())
+	assert.Equal(t, []string{"PROJ-1", "PROJ-2", "PROJ-3"}, keys)
+
+	_, err = service.SearchJQL(context.Background(), &SearchJQLOptions{JQL: "project = PROJ", NextPageToken: "not-an-offset"})
+	assert.Error(t, err)
+}
+
+func TestSearch_Unsupported(t *testing.T) {
+	called := false
+	transport := &featureMockTransport{
+		mockTransport: newMockTransport(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}),
+		unsupported: map[string]bool{featureLegacySearch: true},
+	}
+	defer transport.Close()
+
+	_, err := NewService(transport).Search(context.Background(), &SearchOptions{JQL: "project = PROJ"})
+	assert.ErrorIs(t, err, errFeatureUnsupported)
+	assert.False(t, called, "no request is sent")
+}
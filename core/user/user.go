@@ -37,6 +37,8 @@ const (
 type User struct {
 	Self         string      `json:"self,omitempty"`
 	AccountID    string      `json:"accountId,omitempty"`
+	Name         string      `json:"name,omitempty"` // Username on Jira Server/Data Center
+	Key          string      `json:"key,omitempty"`  // User key on Jira Server/Data Center
 	AccountType  string      `json:"accountType,omitempty"`
 	EmailAddress string      `json:"emailAddress,omitempty"`
 	DisplayName  string      `json:"displayName,omitempty"`
@@ -516,11 +518,15 @@ func (s *Service) SetDefaultColumns(ctx context.Context, columns []string) error
 	}
 
 	// Execute request
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -548,11 +554,15 @@ func (s *Service) ResetDefaultColumns(ctx context.Context, accountID string) err
 	req.URL.RawQuery = q.Encode()
 
 	// Execute request
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -636,11 +646,15 @@ func (s *Service) SetUserProperty(ctx context.Context, accountID, propertyKey st
 	req.URL.RawQuery = q.Encode()
 
 	// Execute request
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -672,11 +686,15 @@ func (s *Service) DeleteUserProperty(ctx context.Context, accountID, propertyKey
 	req.URL.RawQuery = q.Encode()
 
 	// Execute request
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
@@ -283,11 +283,15 @@ func (s *Service) Delete(ctx context.Context, webhookIDs []int64) error {
 	}
 	req.URL.RawQuery = q.Encode()
 
-	_, err = s.transport.Do(ctx, req)
+	resp, err := s.transport.Do(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 
+	if err := s.transport.DecodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	return nil
 }
 
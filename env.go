@@ -35,6 +35,7 @@ const (
 	EnvMaxRetries   = "JIRA_MAX_RETRIES"       // Max retry attempts (default: 3)
 	EnvRateLimitBuf = "JIRA_RATE_LIMIT_BUFFER" // Rate limit buffer in seconds (default: 5)
 	EnvUserAgent    = "JIRA_USER_AGENT"        // Custom user agent string
+	EnvAPIFlavor    = "JIRA_API_FLAVOR"        // API flavor: cloud or datacenter (default: cloud)
 )
 
 // WithEnv configures the client from environment variables.
@@ -55,6 +56,7 @@ const (
 //   - JIRA_MAX_RETRIES: Maximum retry attempts (default: 3)
 //   - JIRA_RATE_LIMIT_BUFFER: Rate limit buffer in seconds (default: 5)
 //   - JIRA_USER_AGENT: Custom user agent string
+//   - JIRA_API_FLAVOR: "cloud" (default) or "datacenter" for Jira Server/Data Center
 //
 // Example:
 //
@@ -192,6 +194,17 @@ func configureOptionalFromEnv(cfg *Config) error {
 		}
 	}
 
+	// API flavor
+	if flavorStr := os.Getenv(EnvAPIFlavor); flavorStr != "" {
+		flavor, err := ParseAPIFlavor(flavorStr)
+		if err != nil {
+			return fmt.Errorf("invalid %s: must be cloud or datacenter", EnvAPIFlavor)
+		}
+		if err := WithAPIFlavor(flavor)(cfg); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
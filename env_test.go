@@ -140,6 +140,23 @@ func TestWithEnv_OptionalUserAgent(t *testing.T) {
 	// User agent is internal to transport, can't directly assert
 }
 
+func TestWithEnv_APIFlavor(t *testing.T) {
+	// Setup environment for Jira Data Center
+	os.Setenv(EnvBaseURL, "https://jira.example.com")
+	os.Setenv(EnvPAT, "test-pat")
+	os.Setenv(EnvAPIFlavor, "datacenter")
+	defer cleanupEnv()
+
+	client, err := NewClient(WithEnv())
+	require.NoError(t, err)
+	assert.Equal(t, APIFlavorDataCenter, client.Transport.APIFlavor())
+
+	os.Setenv(EnvAPIFlavor, "mainframe")
+	_, err = NewClient(WithEnv())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), EnvAPIFlavor)
+}
+
 func TestWithEnv_InvalidTimeout(t *testing.T) {
 	// Setup environment with invalid timeout
 	os.Setenv(EnvBaseURL, "https://test.atlassian.net")
@@ -249,6 +266,7 @@ func cleanupEnv() {
 		EnvMaxRetries,
 		EnvRateLimitBuf,
 		EnvUserAgent,
+		EnvAPIFlavor,
 	}
 
 	for _, env := range envVars {
@@ -0,0 +1,54 @@
+package jirasdk
+
+import "github.com/felixgeelhaar/jirasdk/transport"
+
+// Sentinel errors for classifying Jira API failures with errors.Is.
+//
+// Every service method wraps the underlying *ErrorResponse, so these match
+// regardless of how many layers of context were added:
+//
+//	issue, err := client.Issue.Get(ctx, "PROJ-123", nil)
+//	if errors.Is(err, jirasdk.ErrNotFound) {
+//		// handle missing issue
+//	}
+var (
+	ErrBadRequest   = transport.ErrBadRequest
+	ErrValidation   = transport.ErrValidation
+	ErrUnauthorized = transport.ErrUnauthorized
+	ErrForbidden    = transport.ErrForbidden
+	ErrNotFound     = transport.ErrNotFound
+	ErrConflict     = transport.ErrConflict
+	ErrRateLimited  = transport.ErrRateLimited
+	ErrServer       = transport.ErrServer
+)
+
+// ErrResponseTooLarge is matched by errors for responses larger than the
+// limit set with WithMaxResponseSize.
+var ErrResponseTooLarge = transport.ErrResponseTooLarge
+
+// ResponseTooLargeError is the error returned for responses larger than the
+// limit set with WithMaxResponseSize.
+type ResponseTooLargeError = transport.ResponseTooLargeError
+
+// ErrUnsupported is matched by errors for calls the Jira instance cannot
+// serve, such as Jira expressions on Server/Data Center.
+var ErrUnsupported = transport.ErrUnsupported
+
+// ErrUnknownUsername is matched by errors for Jira Server/Data Center requests
+// that refer to a user by account ID when no username is known for it. See
+// WithUsernameResolver.
+var ErrUnknownUsername = transport.ErrUnknownUsername
+
+// UnsupportedError is the error returned for calls the Jira instance cannot
+// serve. It is returned before any request is sent.
+type UnsupportedError = transport.UnsupportedError
+
+// ErrorResponse is the error returned for Jira API error responses.
+// Use errors.As to inspect the status code, request ID and response headers.
+type ErrorResponse = transport.ErrorResponse
+
+// ValidationErrors holds the field-level errors of a rejected request.
+type ValidationErrors = transport.ValidationErrors
+
+// FieldError is the validation failure for a single field.
+type FieldError = transport.FieldError
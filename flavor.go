@@ -0,0 +1,91 @@
+package jirasdk
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/felixgeelhaar/jirasdk/core/issue"
+	"github.com/felixgeelhaar/jirasdk/transport"
+)
+
+// APIFlavor selects the Jira REST API the client talks to.
+type APIFlavor = transport.APIFlavor
+
+// API flavors for WithAPIFlavor.
+const (
+	APIFlavorCloud      = transport.APIFlavorCloud
+	APIFlavorDataCenter = transport.APIFlavorDataCenter
+)
+
+// UsernameResolver returns the Jira Server/Data Center username for an
+// account ID. See WithUsernameResolver.
+type UsernameResolver = transport.UsernameResolver
+
+// ParseAPIFlavor parses "cloud" or "datacenter" (also "dc" and "server").
+func ParseAPIFlavor(s string) (APIFlavor, error) {
+	return transport.ParseAPIFlavor(s)
+}
+
+// WithAPIFlavor selects the Jira REST API every service talks to.
+//
+// Services are written against the Jira Cloud REST API v3. With
+// APIFlavorDataCenter the client talks to Jira Server/Data Center instead:
+// requests go to /rest/api/2, descriptions and comments are sent as
+// wiki-markup strings rather than ADF documents, and users are referenced by
+// name rather than accountId (see WithUsernameResolver). Responses from REST
+// API v2 decode into the same types; wiki-markup descriptions and comments
+// are converted to ADF, and users carry Name and Key. The default is
+// APIFlavorCloud.
+//
+// Example:
+//
+//	client, err := jira.NewClient(
+//		jira.WithBaseURL("https://jira.example.com"),
+//		jira.WithPAT("your-personal-access-token"),
+//		jira.WithAPIFlavor(jira.APIFlavorDataCenter),
+//	)
+func WithAPIFlavor(flavor APIFlavor) Option {
+	return func(cfg *Config) error {
+		switch flavor {
+		case APIFlavorCloud, APIFlavorDataCenter:
+			cfg.apiFlavor = flavor
+			return nil
+		default:
+			return fmt.Errorf("unknown API flavor: %s", flavor)
+		}
+	}
+}
+
+// WithUsernameResolver sets how a client with APIFlavorDataCenter finds the
+// username for the account IDs that service methods take.
+//
+// User references in the query, the user fields of request bodies (assignee,
+// reporter, watchers and the like, and user picker custom fields) and added
+// watchers are sent with the username it returns. Without a resolver such
+// requests fail with ErrUnknownUsername, since an account ID is never a valid
+// username. Code that already passes usernames where account IDs are expected
+// can return its argument unchanged.
+//
+// Example:
+//
+//	jira.WithUsernameResolver(func(ctx context.Context, accountID string) (string, error) {
+//		return directory.Username(ctx, accountID)
+//	})
+func WithUsernameResolver(resolve UsernameResolver) Option {
+	return func(cfg *Config) error {
+		if resolve == nil {
+			return fmt.Errorf("username resolver cannot be nil")
+		}
+		cfg.usernameResolver = resolve
+		return nil
+	}
+}
+
+// renderWiki renders an ADF document as wiki markup for REST API v2.
+func renderWiki(doc []byte) (string, error) {
+	var adf issue.ADF
+	if err := json.Unmarshal(doc, &adf); err != nil {
+		return "", err
+	}
+	return adf.ToWiki(), nil
+}
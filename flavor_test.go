@@ -0,0 +1,57 @@
+package jirasdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/felixgeelhaar/jirasdk/core/issue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAPIFlavor(t *testing.T) {
+	t.Run("unknown flavor", func(t *testing.T) {
+		cfg := &Config{}
+		assert.Error(t, WithAPIFlavor(APIFlavor(42))(cfg))
+	})
+
+	t.Run("nil username resolver", func(t *testing.T) {
+		cfg := &Config{}
+		assert.Error(t, WithUsernameResolver(nil)(cfg))
+	})
+
+	t.Run("data center", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/rest/api/2/issue/PROJ-1/comment", r.URL.Path)
+
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "*Looks* good", body["body"], "comment is sent as wiki markup")
+
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"10000","body":"*Looks* good","author":{"name":"jdoe","key":"JIRAUSER10000","displayName":"Jane Doe"}}`))
+		}))
+		defer server.Close()
+
+		client, err := NewClient(
+			WithBaseURL(server.URL),
+			WithPAT("token"),
+			WithAPIFlavor(APIFlavorDataCenter),
+			WithMaxRetries(0),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, APIFlavorDataCenter, client.Transport.APIFlavor())
+
+		input := &issue.AddCommentInput{}
+		input.SetBody(issue.ADFFromWiki("*Looks* good"))
+		comment, err := client.Issue.AddComment(context.Background(), "PROJ-1", input)
+		require.NoError(t, err)
+
+		assert.Equal(t, "*Looks* good", comment.Body.ToWiki())
+		assert.Equal(t, "jdoe", comment.Author.Name)
+		assert.Equal(t, "JIRAUSER10000", comment.Author.Key)
+	})
+}
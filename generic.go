@@ -0,0 +1,247 @@
+package jirasdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/felixgeelhaar/jirasdk/internal/pagination"
+)
+
+// Path builds an API path from format, escaping every argument as a single
+// path segment so that keys and names cannot change the path's structure.
+// Arguments are formatted as strings, so format must use %s verbs.
+//
+// Example:
+//
+//	Path("/rest/api/3/issue/%s/properties/%s", "PROJ-1", "my/prop")
+//	// "/rest/api/3/issue/PROJ-1/properties/my%2Fprop"
+func Path(format string, args ...interface{}) string {
+	escaped := make([]interface{}, len(args))
+	for i, arg := range args {
+		escaped[i] = url.PathEscape(fmt.Sprint(arg))
+	}
+	return fmt.Sprintf(format, escaped...)
+}
+
+// Get calls a GET endpoint and decodes the JSON response into a T.
+//
+// It is an escape hatch for endpoints that have no service method yet. The
+// request goes through the client's full middleware chain, and error
+// responses are returned as *ErrorResponse.
+//
+// Example:
+//
+//	type Property struct {
+//		Key   string          `json:"key"`
+//		Value json.RawMessage `json:"value"`
+//	}
+//	prop, err := jira.Get[Property](ctx, client,
+//		jira.Path("/rest/api/3/issue/%s/properties/%s", "PROJ-1", "my.prop"), nil)
+func Get[T any](ctx context.Context, c *Client, path string, query url.Values) (T, error) {
+	var result T
+	err := call(ctx, c, http.MethodGet, path, query, nil, &result)
+	return result, err
+}
+
+// Post calls a POST endpoint with body encoded as JSON and decodes the JSON
+// response into a T.
+//
+// Example:
+//
+//	type Count struct {
+//		Count int `json:"count"`
+//	}
+//	count, err := jira.Post[Count](ctx, client, "/rest/api/3/search/approximate-count",
+//		map[string]string{"jql": "project = PROJ"})
+func Post[T any](ctx context.Context, c *Client, path string, body interface{}) (T, error) {
+	var result T
+	err := call(ctx, c, http.MethodPost, path, nil, body, &result)
+	return result, err
+}
+
+// Put calls a PUT endpoint with body encoded as JSON and decodes the JSON
+// response into a T. Use struct{} as T for endpoints without a response body.
+//
+// Example:
+//
+//	_, err := jira.Put[struct{}](ctx, client,
+//		jira.Path("/rest/api/3/issue/%s/properties/%s", "PROJ-1", "my.prop"),
+//		map[string]bool{"reviewed": true})
+func Put[T any](ctx context.Context, c *Client, path string, body interface{}) (T, error) {
+	var result T
+	err := call(ctx, c, http.MethodPut, path, nil, body, &result)
+	return result, err
+}
+
+// Delete calls a DELETE endpoint.
+//
+// Example:
+//
+//	err := jira.Delete(ctx, client,
+//		jira.Path("/rest/api/3/issue/%s/properties/%s", "PROJ-1", "my.prop"), nil)
+func Delete(ctx context.Context, c *Client, path string, query url.Values) error {
+	return call(ctx, c, http.MethodDelete, path, query, nil, nil)
+}
+
+// List calls a paginated GET endpoint and returns the items of all pages.
+//
+// Items are read from the "values" array, which most paginated endpoints
+// use; ListField reads another field. Pages are followed with nextPageToken
+// when the response carries one, and with startAt otherwise, until Jira
+// reports the last page. A nextPageToken that was already followed is
+// returned as an error instead of looping forever. An endpoint that returns
+// a plain JSON array is read as a single page. Items are decoded one at a
+// time as each page is read.
+//
+// Example:
+//
+//	type Dashboard struct {
+//		ID   string `json:"id"`
+//		Name string `json:"name"`
+//	}
+//	dashboards, err := jira.List[Dashboard](ctx, client, "/rest/api/3/dashboard/search",
+//		url.Values{"maxResults": {"100"}})
+func List[T any](ctx context.Context, c *Client, path string, query url.Values) ([]T, error) {
+	return ListField[T](ctx, c, path, "values", query)
+}
+
+// ListField is List for endpoints that return their items in field, such as
+// "issues" or "records".
+//
+// Example:
+//
+//	records, err := jira.ListField[audit.AuditRecord](ctx, client,
+//		"/rest/api/3/auditing/record", "records", nil)
+func ListField[T any](ctx context.Context, c *Client, path, field string, query url.Values) ([]T, error) {
+	items := []T{}
+	params := cloneValues(query)
+	seenTokens := make(map[string]bool)
+
+	for {
+		req, err := newRequest(ctx, c, http.MethodGet, path, params, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.Transport.Do(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute request: %w", err)
+		}
+
+		var page listPage
+		count := 0
+		err = c.Transport.DecodeResponseStream(resp, field, &page, func(decode func(target interface{}) error) error {
+			var item T
+			if err := decode(&item); err != nil {
+				return err
+			}
+			items = append(items, item)
+			count++
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		if count == 0 {
+			return items, nil
+		}
+
+		switch {
+		case page.NextPageToken != "":
+			if seenTokens[page.NextPageToken] {
+				return nil, fmt.Errorf("pagination did not advance: nextPageToken %q was already returned", page.NextPageToken)
+			}
+			seenTokens[page.NextPageToken] = true
+			params.Set("nextPageToken", page.NextPageToken)
+		case page.hasNextOffset(count):
+			params.Set("startAt", strconv.Itoa(page.StartAt+count))
+		default:
+			return items, nil
+		}
+	}
+}
+
+// listPage holds the pagination fields of a paginated response.
+type listPage struct {
+	StartAt       int    `json:"startAt"`
+	Total         *int   `json:"total"`
+	IsLast        *bool  `json:"isLast"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// hasNextOffset reports whether an offset-paginated response with count items
+// has a next page.
+func (p *listPage) hasNextOffset(count int) bool {
+	if p.IsLast != nil {
+		return !*p.IsLast
+	}
+	if p.Total == nil {
+		return false
+	}
+
+	info := pagination.PageInfo{StartAt: p.StartAt, MaxResults: count, Total: *p.Total}
+	return info.HasNextPage()
+}
+
+// call sends a request and decodes its JSON response into target.
+func call(ctx context.Context, c *Client, method, path string, query url.Values, body, target interface{}) error {
+	req, err := newRequest(ctx, c, method, path, query, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Transport.Do(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	// 204 No Content has no body to decode
+	if resp.StatusCode == http.StatusNoContent {
+		target = nil
+	}
+
+	if err := c.Transport.DecodeResponse(resp, target); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// newRequest creates a request for path with query added to its query string.
+func newRequest(ctx context.Context, c *Client, method, path string, query url.Values, body interface{}) (*http.Request, error) {
+	if c == nil || c.Transport == nil {
+		return nil, fmt.Errorf("client is required")
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("path must be absolute: %q", path)
+	}
+
+	req, err := c.Transport.NewRequest(ctx, method, path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if len(query) > 0 {
+		q := req.URL.Query()
+		for key, values := range query {
+			q[key] = values
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+
+	return req, nil
+}
+
+// cloneValues returns a copy of values that can be modified.
+func cloneValues(values url.Values) url.Values {
+	clone := make(url.Values, len(values))
+	for key, v := range values {
+		clone[key] = append([]string(nil), v...)
+	}
+	return clone
+}
@@ -0,0 +1,189 @@
+package jirasdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type genericItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func newGenericClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(
+		WithBaseURL(server.URL),
+		WithAPIToken("user@example.com", "token"),
+		WithMaxRetries(0),
+	)
+	require.NoError(t, err)
+	return client
+}
+
+func TestPath(t *testing.T) {
+	assert.Equal(t, "/rest/api/3/issue/PROJ-1/properties/my%2Fprop",
+		Path("/rest/api/3/issue/%s/properties/%s", "PROJ-1", "my/prop"))
+	assert.Equal(t, "/rest/api/3/project/10000", Path("/rest/api/3/project/%s", 10000))
+	assert.Equal(t, "/rest/api/3/user/a%20b%3Fc", Path("/rest/api/3/user/%s", "a b?c"))
+}
+
+func TestGet(t *testing.T) {
+	client := newGenericClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/rest/api/3/thing/a%2Fb", r.URL.EscapedPath())
+		assert.Equal(t, "names", r.URL.Query().Get("expand"))
+		if r.URL.Query().Get("missing") != "" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errorMessages":["Thing does not exist"]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"1","name":"Thing"}`))
+	})
+
+	item, err := Get[genericItem](context.Background(), client, Path("/rest/api/3/thing/%s", "a/b"), url.Values{"expand": {"names"}})
+	require.NoError(t, err)
+	assert.Equal(t, genericItem{ID: "1", Name: "Thing"}, item)
+
+	_, err = Get[genericItem](context.Background(), client, Path("/rest/api/3/thing/%s", "a/b"), url.Values{"expand": {"names"}, "missing": {"1"}})
+	assert.ErrorIs(t, err, ErrNotFound)
+	var apiErr *ErrorResponse
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, []string{"Thing does not exist"}, apiErr.ErrorMessages)
+
+	_, err = Get[genericItem](context.Background(), client, "rest/api/3/thing", nil)
+	assert.Error(t, err)
+}
+
+func TestPostPutDelete(t *testing.T) {
+	client := newGenericClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			_, _ = w.Write([]byte(`{"id":"2","name":"` + body["name"] + `"}`))
+		case http.MethodPut, http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	ctx := context.Background()
+	item, err := Post[genericItem](ctx, client, "/rest/api/3/thing", map[string]string{"name": "New"})
+	require.NoError(t, err)
+	assert.Equal(t, "New", item.Name)
+
+	_, err = Put[struct{}](ctx, client, "/rest/api/3/thing/2", map[string]string{"name": "Renamed"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, Delete(ctx, client, "/rest/api/3/thing/2", nil))
+}
+
+func TestList(t *testing.T) {
+	items := make([]genericItem, 5)
+	for i := range items {
+		items[i] = genericItem{ID: strconv.Itoa(i + 1)}
+	}
+
+	tests := []struct {
+		name  string
+		field string
+		page  func(r *http.Request) map[string]interface{}
+	}{
+		{
+			name:  "offset with total",
+			field: "values",
+			page: func(r *http.Request) map[string]interface{} {
+				startAt, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+				end := min(startAt+2, len(items))
+				return map[string]interface{}{"values": items[startAt:end], "startAt": startAt, "maxResults": 2, "total": len(items)}
+			},
+		},
+		{
+			name:  "offset with isLast",
+			field: "values",
+			page: func(r *http.Request) map[string]interface{} {
+				startAt, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+				end := min(startAt+2, len(items))
+				return map[string]interface{}{"values": items[startAt:end], "startAt": startAt, "isLast": end == len(items)}
+			},
+		},
+		{
+			name:  "next page token",
+			field: "issues",
+			page: func(r *http.Request) map[string]interface{} {
+				startAt, _ := strconv.Atoi(r.URL.Query().Get("nextPageToken"))
+				end := min(startAt+2, len(items))
+				page := map[string]interface{}{"issues": items[startAt:end]}
+				if end < len(items) {
+					page["nextPageToken"] = strconv.Itoa(end)
+				}
+				return page
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			client := newGenericClient(t, func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				assert.Equal(t, "open", r.URL.Query().Get("status"), "query is kept on every page")
+				_ = json.NewEncoder(w).Encode(tt.page(r))
+			})
+
+			query := url.Values{"status": {"open"}}
+			got, err := ListField[genericItem](context.Background(), client, "/rest/api/3/thing/search", tt.field, query)
+			require.NoError(t, err)
+			assert.Equal(t, items, got)
+			assert.Equal(t, 3, calls)
+			assert.Equal(t, url.Values{"status": {"open"}}, query, "caller's query is not modified")
+		})
+	}
+}
+
+func TestList_RepeatedPageToken(t *testing.T) {
+	calls := 0
+	client := newGenericClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(`{"values":[{"id":"1"}],"nextPageToken":"same"}`))
+	})
+
+	_, err := List[genericItem](context.Background(), client, "/rest/api/3/thing/search", nil)
+	assert.ErrorContains(t, err, `nextPageToken "same" was already returned`)
+	assert.Equal(t, 2, calls)
+}
+
+func TestList_Array(t *testing.T) {
+	calls := 0
+	client := newGenericClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(`[{"id":"1"},{"id":"2"}]`))
+	})
+
+	got, err := List[genericItem](context.Background(), client, "/rest/api/3/thing", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []genericItem{{ID: "1"}, {ID: "2"}}, got)
+	assert.Equal(t, 1, calls)
+}
+
+func TestList_Empty(t *testing.T) {
+	client := newGenericClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"values":[],"startAt":0,"total":10}`))
+	})
+
+	got, err := List[genericItem](context.Background(), client, "/rest/api/3/thing/search", nil)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
@@ -0,0 +1,133 @@
+// Package jwt signs and verifies JSON Web Tokens with HMAC SHA-256, as used
+// by Atlassian Connect, and verifies the RS256 tokens of Connect lifecycle
+// callbacks.
+package jwt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// header is the JOSE header of every token this package signs.
+var header = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// Sign encodes claims as a JWT signed with secret using HS256.
+func Sign(claims interface{}, secret []byte) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode claims: %w", err)
+	}
+
+	signingInput := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature(signingInput, secret)), nil
+}
+
+// signature returns the HS256 signature of signingInput.
+func signature(signingInput string, secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingInput))
+	return mac.Sum(nil)
+}
+
+// Signing algorithms accepted by Parse.
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+)
+
+// ErrMalformed is returned by Parse for strings that are not HS256 or RS256
+// JWTs.
+var ErrMalformed = errors.New("malformed JWT")
+
+// ErrSignature is returned by Token.Verify for a signature that does not
+// match.
+var ErrSignature = errors.New("invalid JWT signature")
+
+// Token is a parsed, not yet verified, JWT.
+type Token struct {
+	// Claims is the JSON payload
+	Claims json.RawMessage
+
+	// Algorithm is the alg header, HS256 or RS256
+	Algorithm string
+
+	// KeyID is the kid header naming the key that signed the token, if any
+	KeyID string
+
+	signingInput string
+	signature    []byte
+}
+
+// Parse splits a JWT signed with HS256 or RS256. Tokens using any other
+// algorithm, including "none", are rejected.
+func Parse(token string) (*Token, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: expected 3 parts, got %d", ErrMalformed, len(parts))
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrMalformed, err)
+	}
+	var hdr struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(headerJSON, &hdr); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrMalformed, err)
+	}
+	if hdr.Alg != HS256 && hdr.Alg != RS256 {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrMalformed, hdr.Alg)
+	}
+
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ErrMalformed, err)
+	}
+	if !json.Valid(claims) {
+		return nil, fmt.Errorf("%w: claims are not JSON", ErrMalformed)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %v", ErrMalformed, err)
+	}
+
+	return &Token{
+		Claims:       claims,
+		Algorithm:    hdr.Alg,
+		KeyID:        hdr.Kid,
+		signingInput: parts[0] + "." + parts[1],
+		signature:    sig,
+	}, nil
+}
+
+// Verify checks the HS256 signature against secret in constant time. A token
+// signed with another algorithm fails with ErrSignature.
+func (t *Token) Verify(secret []byte) error {
+	if t.Algorithm != HS256 || !hmac.Equal(t.signature, signature(t.signingInput, secret)) {
+		return ErrSignature
+	}
+	return nil
+}
+
+// VerifyRS256 checks the RS256 signature against key. A token signed with
+// another algorithm fails with ErrSignature.
+func (t *Token) VerifyRS256(key *rsa.PublicKey) error {
+	if t.Algorithm != RS256 || key == nil {
+		return ErrSignature
+	}
+	digest := sha256.Sum256([]byte(t.signingInput))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], t.signature); err != nil {
+		return ErrSignature
+	}
+	return nil
+}
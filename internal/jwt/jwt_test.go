@@ -0,0 +1,92 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	// The example token from jwt.io
+	claims := struct {
+		Sub  string `json:"sub"`
+		Name string `json:"name"`
+		Iat  int64  `json:"iat"`
+	}{Sub: "1234567890", Name: "John Doe", Iat: 1516239022}
+
+	token, err := Sign(claims, []byte("your-256-bit-secret"))
+	require.NoError(t, err)
+	assert.Equal(t, "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9."+
+		"eyJzdWIiOiIxMjM0NTY3ODkwIiwibmFtZSI6IkpvaG4gRG9lIiwiaWF0IjoxNTE2MjM5MDIyfQ."+
+		"SflKxwRJSMeKKF2QT4fwpMeJf36POk6yJV_adQssw5c", token)
+
+	_, err = Sign(func() {}, []byte("secret"))
+	assert.Error(t, err)
+}
+
+func TestParse(t *testing.T) {
+	const jwtIO = "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9." +
+		"eyJzdWIiOiIxMjM0NTY3ODkwIiwibmFtZSI6IkpvaG4gRG9lIiwiaWF0IjoxNTE2MjM5MDIyfQ." +
+		"SflKxwRJSMeKKF2QT4fwpMeJf36POk6yJV_adQssw5c"
+
+	token, err := Parse(jwtIO)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"sub":"1234567890","name":"John Doe","iat":1516239022}`, string(token.Claims))
+	assert.NoError(t, token.Verify([]byte("your-256-bit-secret")))
+	assert.ErrorIs(t, token.Verify([]byte("another-secret")), ErrSignature)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "empty", token: ""},
+		{name: "two parts", token: "eyJhbGciOiJIUzI1NiJ9.e30"},
+		{name: "alg none", token: "eyJhbGciOiJub25lIn0.e30."},
+		{name: "alg RS512", token: "eyJhbGciOiJSUzUxMiJ9.e30.c2ln"},
+		{name: "header not base64", token: "!!!.e30.c2ln"},
+		{name: "claims not JSON", token: "eyJhbGciOiJIUzI1NiJ9.bm90IGpzb24.c2ln"},
+		{name: "signature not base64", token: "eyJhbGciOiJIUzI1NiJ9.e30.!!!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.token)
+			assert.ErrorIs(t, err, ErrMalformed)
+		})
+	}
+}
+
+func TestVerifyRS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	signingInput := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT","kid":"key-1"}`)) +
+		"." + base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"jira"}`))
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	token, err := Parse(signingInput + "." + base64.RawURLEncoding.EncodeToString(sig))
+	require.NoError(t, err)
+	assert.Equal(t, RS256, token.Algorithm)
+	assert.Equal(t, "key-1", token.KeyID)
+
+	assert.NoError(t, token.VerifyRS256(&key.PublicKey))
+	assert.ErrorIs(t, token.VerifyRS256(&other.PublicKey), ErrSignature)
+	assert.ErrorIs(t, token.VerifyRS256(nil), ErrSignature)
+	assert.ErrorIs(t, token.Verify([]byte("secret")), ErrSignature, "an RS256 token is never checked as HS256")
+
+	hs256, err := Sign(map[string]string{"iss": "jira"}, []byte("secret"))
+	require.NoError(t, err)
+	token, err = Parse(hs256)
+	require.NoError(t, err)
+	assert.ErrorIs(t, token.VerifyRS256(&key.PublicKey), ErrSignature, "an HS256 token is never checked as RS256")
+}
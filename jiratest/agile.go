@@ -0,0 +1,417 @@
+package jiratest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/jirasdk/core/agile"
+)
+
+// Messages Jira Software returns for missing resources.
+const (
+	msgBoardNotFound  = "Board does not exist or you do not have permission to see it."
+	msgSprintNotFound = "Sprint does not exist or you do not have permission to see it."
+)
+
+// AddBoard stores a board and returns it with its ID and self link filled in.
+//
+// If the board has a Location with a ProjectKey, its backlog contains the
+// project's issues that are not in an open sprint.
+func (s *Server) AddBoard(b *agile.Board) *agile.Board {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	board := *b
+	if board.Type == "" {
+		board.Type = "scrum"
+	}
+	return s.addBoardLocked(&board)
+}
+
+// AddSprint stores a sprint and returns it with its ID and self link filled in.
+// OriginBoardID must name an existing board.
+func (s *Server) AddSprint(sp *agile.Sprint) *agile.Sprint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.boardLocked(sp.OriginBoardID) == nil {
+		panic(fmt.Sprintf("jiratest: board %d does not exist", sp.OriginBoardID))
+	}
+
+	sprint := *sp
+	if sprint.State == "" {
+		sprint.State = "future"
+	}
+	return s.addSprintLocked(&sprint)
+}
+
+// addBoardLocked stores a board. s.mu must be held.
+func (s *Server) addBoardLocked(b *agile.Board) *agile.Board {
+	b.ID = int64(len(s.boards) + 1)
+	for _, existing := range s.boards {
+		if existing.ID >= b.ID {
+			b.ID = existing.ID + 1
+		}
+	}
+	b.Self = s.selfURL("/rest/agile/1.0/board/%d", b.ID)
+	s.boards = append(s.boards, b)
+	return b
+}
+
+// addSprintLocked stores a sprint. s.mu must be held.
+func (s *Server) addSprintLocked(sp *agile.Sprint) *agile.Sprint {
+	sp.ID = int64(len(s.sprints) + 1)
+	for _, existing := range s.sprints {
+		if existing.ID >= sp.ID {
+			sp.ID = existing.ID + 1
+		}
+	}
+	sp.Self = s.selfURL("/rest/agile/1.0/sprint/%d", sp.ID)
+	s.sprints = append(s.sprints, sp)
+	return sp
+}
+
+// boardLocked finds a board by ID. s.mu must be held.
+func (s *Server) boardLocked(id int64) *agile.Board {
+	for _, b := range s.boards {
+		if b.ID == id {
+			return b
+		}
+	}
+	return nil
+}
+
+// sprintLocked finds a sprint by ID. s.mu must be held.
+func (s *Server) sprintLocked(id int64) (int, *agile.Sprint) {
+	for i, sp := range s.sprints {
+		if sp.ID == id {
+			return i, sp
+		}
+	}
+	return -1, nil
+}
+
+func (s *Server) registerAgileRoutes() {
+	s.handle(http.MethodGet, "/rest/agile/1.0/board", s.listBoards)
+	s.handle(http.MethodPost, "/rest/agile/1.0/board", s.createBoard)
+	s.handle(http.MethodGet, "/rest/agile/1.0/board/{boardId}", s.getBoard)
+	s.handle(http.MethodDelete, "/rest/agile/1.0/board/{boardId}", s.deleteBoard)
+	s.handle(http.MethodGet, "/rest/agile/1.0/board/{boardId}/sprint", s.listBoardSprints)
+	s.handle(http.MethodGet, "/rest/agile/1.0/board/{boardId}/backlog", s.getBacklog)
+
+	s.handle(http.MethodPost, "/rest/agile/1.0/sprint", s.createSprint)
+	s.handle(http.MethodGet, "/rest/agile/1.0/sprint/{sprintId}", s.getSprint)
+	s.handle(http.MethodPut, "/rest/agile/1.0/sprint/{sprintId}", s.updateSprint)
+	s.handle(http.MethodPost, "/rest/agile/1.0/sprint/{sprintId}", s.updateSprint)
+	s.handle(http.MethodDelete, "/rest/agile/1.0/sprint/{sprintId}", s.deleteSprint)
+	s.handle(http.MethodPost, "/rest/agile/1.0/sprint/{sprintId}/issue", s.moveIssuesToSprint)
+}
+
+// withBoard looks up the board named in the path, writing a 404 if it does not
+// exist. The handler runs with s.mu held.
+func (s *Server) withBoard(w http.ResponseWriter, params map[string]string, fn func(b *agile.Board)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, _ := strconv.ParseInt(params["boardId"], 10, 64)
+	b := s.boardLocked(id)
+	if b == nil {
+		writeError(w, http.StatusNotFound, msgBoardNotFound)
+		return
+	}
+	fn(b)
+}
+
+// withSprint looks up the sprint named in the path, writing a 404 if it does not
+// exist. The handler runs with s.mu held.
+func (s *Server) withSprint(w http.ResponseWriter, params map[string]string, fn func(i int, sp *agile.Sprint)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, _ := strconv.ParseInt(params["sprintId"], 10, 64)
+	i, sp := s.sprintLocked(id)
+	if sp == nil {
+		writeError(w, http.StatusNotFound, msgSprintNotFound)
+		return
+	}
+	fn(i, sp)
+}
+
+// writeValuesPage writes an Agile API page of values.
+func writeValuesPage(w http.ResponseWriter, startAt, maxResults, total int, values interface{}, isLast bool) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"maxResults": maxResults,
+		"startAt":    startAt,
+		"total":      total,
+		"isLast":     isLast,
+		"values":     values,
+	})
+}
+
+func (s *Server) listBoards(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	startAt, maxResults := pageParams(r, 50)
+	query := r.URL.Query()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matched := []*agile.Board{}
+	for _, b := range s.boards {
+		if t := query.Get("type"); t != "" && b.Type != t {
+			continue
+		}
+		if name := query.Get("name"); name != "" && !strings.Contains(strings.ToLower(b.Name), strings.ToLower(name)) {
+			continue
+		}
+		if ref := query.Get("projectKeyOrId"); ref != "" {
+			if b.Location == nil || (!strings.EqualFold(b.Location.ProjectKey, ref) && strconv.FormatInt(b.Location.ProjectID, 10) != ref) {
+				continue
+			}
+		}
+		matched = append(matched, b)
+	}
+
+	start, end := pageBounds(len(matched), startAt, maxResults)
+	writeValuesPage(w, start, maxResults, len(matched), matched[start:end], end == len(matched))
+}
+
+func (s *Server) createBoard(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	var body agile.CreateBoardInput
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	errs := map[string]string{}
+	if strings.TrimSpace(body.Name) == "" {
+		errs["name"] = "Board name is required."
+	}
+	if body.Type != "scrum" && body.Type != "kanban" {
+		errs["type"] = "Board type must be 'scrum' or 'kanban'."
+	}
+	if body.FilterID == 0 {
+		errs["filterId"] = "Filter id is required."
+	}
+	if len(errs) > 0 {
+		writeFieldErrors(w, errs)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.addBoardLocked(&agile.Board{
+		Name:    body.Name,
+		Type:    body.Type,
+		Filter:  &agile.Filter{ID: body.FilterID},
+		CanEdit: true,
+	})
+	writeJSON(w, http.StatusCreated, b)
+}
+
+func (s *Server) getBoard(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	s.withBoard(w, params, func(b *agile.Board) {
+		writeJSON(w, http.StatusOK, b)
+	})
+}
+
+func (s *Server) deleteBoard(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	s.withBoard(w, params, func(b *agile.Board) {
+		for i, candidate := range s.boards {
+			if candidate == b {
+				s.boards = append(s.boards[:i], s.boards[i+1:]...)
+				break
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (s *Server) listBoardSprints(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	startAt, maxResults := pageParams(r, 50)
+	var states []string
+	if raw := r.URL.Query().Get("state"); raw != "" {
+		states = strings.Split(raw, ",")
+	}
+
+	s.withBoard(w, params, func(b *agile.Board) {
+		if b.Type != "scrum" {
+			writeError(w, http.StatusBadRequest, "The board does not support sprints")
+			return
+		}
+
+		matched := []*agile.Sprint{}
+		for _, sp := range s.sprints {
+			if sp.OriginBoardID == b.ID && (states == nil || containsString(states, sp.State)) {
+				matched = append(matched, sp)
+			}
+		}
+
+		start, end := pageBounds(len(matched), startAt, maxResults)
+		writeValuesPage(w, start, maxResults, len(matched), matched[start:end], end == len(matched))
+	})
+}
+
+func (s *Server) getBacklog(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	startAt, maxResults := pageParams(r, 50)
+
+	s.withBoard(w, params, func(b *agile.Board) {
+		var matched []*storedIssue
+		for _, stored := range s.issues {
+			if b.Location != nil && b.Location.ProjectKey != "" && projectRef(stored.fields) != b.Location.ProjectKey {
+				continue
+			}
+			if _, sp := s.sprintLocked(stored.sprintID); sp != nil && sp.State != "closed" {
+				continue
+			}
+			matched = append(matched, stored)
+		}
+
+		start, end := pageBounds(len(matched), startAt, maxResults)
+		issues := make([]map[string]interface{}, 0, end-start)
+		for _, stored := range matched[start:end] {
+			issues = append(issues, s.issueJSONLocked(stored, nil))
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"maxResults": maxResults,
+			"startAt":    start,
+			"total":      len(matched),
+			"issues":     issues,
+		})
+	})
+}
+
+func (s *Server) createSprint(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	var body agile.CreateSprintInput
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	errs := map[string]string{}
+	if strings.TrimSpace(body.Name) == "" {
+		errs["name"] = "Sprint name is required."
+	}
+	if b := s.boardLocked(body.OriginBoardID); b == nil {
+		errs["originBoardId"] = msgBoardNotFound
+	} else if b.Type != "scrum" {
+		errs["originBoardId"] = "The board does not support sprints"
+	}
+	if len(errs) > 0 {
+		writeFieldErrors(w, errs)
+		return
+	}
+
+	sp := s.addSprintLocked(&agile.Sprint{
+		Name:          body.Name,
+		State:         "future",
+		StartDate:     body.StartDate,
+		EndDate:       body.EndDate,
+		OriginBoardID: body.OriginBoardID,
+		Goal:          body.Goal,
+	})
+	writeJSON(w, http.StatusCreated, sp)
+}
+
+func (s *Server) getSprint(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	s.withSprint(w, params, func(_ int, sp *agile.Sprint) {
+		writeJSON(w, http.StatusOK, sp)
+	})
+}
+
+// updateSprint handles both full (PUT) and partial (POST) updates; only the
+// fields present in the body are changed.
+func (s *Server) updateSprint(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var body agile.UpdateSprintInput
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.withSprint(w, params, func(_ int, sp *agile.Sprint) {
+		updated := *sp
+		if body.Name != "" {
+			updated.Name = body.Name
+		}
+		if body.StartDate != "" {
+			updated.StartDate = body.StartDate
+		}
+		if body.EndDate != "" {
+			updated.EndDate = body.EndDate
+		}
+		if body.Goal != "" {
+			updated.Goal = body.Goal
+		}
+
+		if body.State != "" && body.State != sp.State {
+			switch {
+			case sp.State == "future" && body.State == "active":
+				if updated.StartDate == "" || updated.EndDate == "" {
+					writeError(w, http.StatusBadRequest, "A sprint cannot be started without a start date and an end date.")
+					return
+				}
+			case sp.State == "active" && body.State == "closed":
+				updated.CompleteDate = timestamp(time.Now())
+			default:
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("A sprint cannot move from '%s' to '%s'.", sp.State, body.State))
+				return
+			}
+			updated.State = body.State
+		}
+
+		*sp = updated
+		writeJSON(w, http.StatusOK, sp)
+	})
+}
+
+func (s *Server) deleteSprint(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	s.withSprint(w, params, func(i int, sp *agile.Sprint) {
+		if sp.State == "active" {
+			writeError(w, http.StatusBadRequest, "An active sprint cannot be deleted.")
+			return
+		}
+		for _, stored := range s.issues {
+			if stored.sprintID == sp.ID {
+				stored.sprintID = 0
+			}
+		}
+		s.sprints = append(s.sprints[:i], s.sprints[i+1:]...)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (s *Server) moveIssuesToSprint(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var body agile.MoveIssuesToSprintInput
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.withSprint(w, params, func(_ int, sp *agile.Sprint) {
+		if sp.State == "closed" {
+			writeError(w, http.StatusBadRequest, "Issues cannot be moved to a closed sprint.")
+			return
+		}
+		if len(body.Issues) > 50 {
+			writeError(w, http.StatusBadRequest, "At most 50 issues can be moved in one operation.")
+			return
+		}
+
+		issues := make([]*storedIssue, 0, len(body.Issues))
+		for _, keyOrID := range body.Issues {
+			stored := s.issueLocked(keyOrID)
+			if stored == nil {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("Issue '%s' does not exist or you do not have permission to see it.", keyOrID))
+				return
+			}
+			issues = append(issues, stored)
+		}
+		for _, stored := range issues {
+			stored.sprintID = sp.ID
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,978 @@
+package jiratest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/jirasdk/core/issue"
+	"github.com/felixgeelhaar/jirasdk/core/project"
+	"github.com/felixgeelhaar/jirasdk/core/user"
+)
+
+// storedIssue is an issue held by the fake. Fields are kept as decoded JSON
+// so that updates can be merged the way Jira merges them.
+type storedIssue struct {
+	id       int64
+	key      string
+	fields   map[string]interface{}
+	comments []*issue.Comment
+	worklogs []*issue.Worklog
+	sprintID int64
+}
+
+// storedLink is an issue link. The link reads "outward <type.Outward> inward"
+// from the outward issue's side.
+type storedLink struct {
+	id       int64
+	linkType *issue.IssueLinkType
+	inward   *storedIssue
+	outward  *storedIssue
+}
+
+// workflowStep is a status of the fake workflow and the global transition into it.
+type workflowStep struct {
+	transitionID string
+	status       *issue.Status
+}
+
+// workflow is shared by every issue. Each status can be reached from any other.
+var workflow = []workflowStep{
+	{transitionID: "11", status: &issue.Status{ID: "10000", Name: "To Do",
+		Category: &issue.StatusCategory{ID: 2, Key: "new", Name: "To Do", ColorName: "blue-gray"}}},
+	{transitionID: "21", status: &issue.Status{ID: "3", Name: "In Progress",
+		Category: &issue.StatusCategory{ID: 4, Key: "indeterminate", Name: "In Progress", ColorName: "yellow"}}},
+	{transitionID: "31", status: &issue.Status{ID: "10001", Name: "Done",
+		Category: &issue.StatusCategory{ID: 3, Key: "done", Name: "Done", ColorName: "green"}}},
+}
+
+// defaultLinkTypes returns the link types of a new Jira Cloud site.
+func defaultLinkTypes() []*issue.IssueLinkType {
+	return []*issue.IssueLinkType{
+		{ID: "10000", Name: "Blocks", Inward: "is blocked by", Outward: "blocks"},
+		{ID: "10001", Name: "Cloners", Inward: "is cloned by", Outward: "clones"},
+		{ID: "10002", Name: "Duplicate", Inward: "is duplicated by", Outward: "duplicates"},
+		{ID: "10003", Name: "Relates", Inward: "relates to", Outward: "relates to"},
+	}
+}
+
+// issueKeyPattern matches an issue key such as PROJ-123.
+var issueKeyPattern = regexp.MustCompile(`^([A-Z][A-Z0-9_]*)-([0-9]+)$`)
+
+// Messages Jira returns for missing resources.
+const (
+	msgIssueNotFound = "Issue does not exist or you do not have permission to see it."
+)
+
+// AddIssue stores an issue and returns it with its ID, key and defaults filled in.
+//
+// Fields.Project must name a project by key; the project is created if it
+// does not exist. If Key is set it is kept, otherwise the next key in the
+// project is assigned. Status defaults to "To Do" and the reporter to the
+// current user.
+func (s *Server) AddIssue(i *issue.Issue) *issue.Issue {
+	fields := map[string]interface{}{}
+	if i.Fields != nil {
+		data, err := json.Marshal(i.Fields)
+		if err != nil {
+			panic(fmt.Sprintf("jiratest: cannot encode issue fields: %v", err))
+		}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			panic(fmt.Sprintf("jiratest: cannot decode issue fields: %v", err))
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key := projectRef(fields); key != "" && s.projectLocked(key) == nil {
+		s.addProjectLocked(&project.Project{Key: key, Name: key, ProjectTypeKey: "software"})
+	}
+
+	stored, errs := s.createIssueLocked(fields, i.Key)
+	if len(errs) > 0 {
+		panic(fmt.Sprintf("jiratest: invalid seed issue: %v", errs))
+	}
+
+	return s.issueValueLocked(stored)
+}
+
+// Issue returns the stored state of an issue, for assertions after a test run.
+func (s *Server) Issue(keyOrID string) (*issue.Issue, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := s.issueLocked(keyOrID)
+	if stored == nil {
+		return nil, false
+	}
+	return s.issueValueLocked(stored), true
+}
+
+// AddComment stores a comment on an issue and returns it with its ID filled in.
+func (s *Server) AddComment(issueKey string, c *issue.Comment) *issue.Comment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := s.issueLocked(issueKey)
+	if stored == nil {
+		panic(fmt.Sprintf("jiratest: issue %s does not exist", issueKey))
+	}
+	return s.addCommentLocked(stored, c)
+}
+
+// AddWorklog stores a worklog on an issue and returns it with its ID filled in.
+func (s *Server) AddWorklog(issueKey string, wl *issue.Worklog) *issue.Worklog {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := s.issueLocked(issueKey)
+	if stored == nil {
+		panic(fmt.Sprintf("jiratest: issue %s does not exist", issueKey))
+	}
+	return s.addWorklogLocked(stored, wl)
+}
+
+// createIssueLocked validates fields and stores a new issue. s.mu must be held.
+func (s *Server) createIssueLocked(fields map[string]interface{}, key string) (*storedIssue, map[string]string) {
+	errs := map[string]string{}
+
+	proj := s.projectLocked(projectRef(fields))
+	if proj == nil {
+		errs["project"] = "valid project is required"
+	}
+	if summary, _ := fields["summary"].(string); strings.TrimSpace(summary) == "" {
+		errs["summary"] = "You must specify a summary of the issue."
+	}
+	if issueType, _ := fields["issuetype"].(map[string]interface{}); issueType["name"] == nil && issueType["id"] == nil {
+		errs["issuetype"] = "Specify an issue type"
+	}
+	if parentKey := refKey(fields["parent"]); parentKey != "" && s.issueLocked(parentKey) == nil {
+		errs["parent"] = "Could not find issue by id or key."
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if key == "" {
+		s.issueSeq[proj.Key]++
+		key = fmt.Sprintf("%s-%d", proj.Key, s.issueSeq[proj.Key])
+	} else if m := issueKeyPattern.FindStringSubmatch(key); m != nil {
+		if n, _ := strconv.Atoi(m[2]); n > s.issueSeq[m[1]] {
+			s.issueSeq[m[1]] = n
+		}
+	}
+
+	now := timestamp(time.Now())
+	fields["project"] = map[string]interface{}{"id": proj.ID, "key": proj.Key, "name": proj.Name, "self": proj.Self}
+	if parentKey := refKey(fields["parent"]); parentKey != "" {
+		parent := s.issueLocked(parentKey)
+		fields["parent"] = map[string]interface{}{"id": strconv.FormatInt(parent.id, 10), "key": parent.key}
+	}
+	if fields["status"] == nil {
+		fields["status"] = toMap(workflow[0].status)
+	}
+	if fields["reporter"] == nil {
+		if u := s.userLocked(s.currentUser); u != nil {
+			fields["reporter"] = toMap(issueUser(u))
+		}
+	}
+	if fields["created"] == nil {
+		fields["created"] = now
+	}
+	if fields["updated"] == nil {
+		fields["updated"] = now
+	}
+
+	stored := &storedIssue{
+		id:     s.newIDLocked(),
+		key:    key,
+		fields: fields,
+	}
+	s.issues = append(s.issues, stored)
+	s.issueKeys[key] = stored
+
+	return stored, nil
+}
+
+// issueLocked finds an issue by key or ID. s.mu must be held.
+func (s *Server) issueLocked(keyOrID string) *storedIssue {
+	if stored, ok := s.issueKeys[strings.ToUpper(keyOrID)]; ok {
+		return stored
+	}
+	for _, stored := range s.issues {
+		if strconv.FormatInt(stored.id, 10) == keyOrID {
+			return stored
+		}
+	}
+	return nil
+}
+
+// deleteIssueLocked removes an issue and its links. s.mu must be held.
+func (s *Server) deleteIssueLocked(stored *storedIssue) {
+	for i, candidate := range s.issues {
+		if candidate == stored {
+			s.issues = append(s.issues[:i], s.issues[i+1:]...)
+			break
+		}
+	}
+	delete(s.issueKeys, stored.key)
+
+	links := s.links[:0]
+	for _, link := range s.links {
+		if link.inward != stored && link.outward != stored {
+			links = append(links, link)
+		}
+	}
+	s.links = links
+}
+
+// issueJSON renders an issue. A nil or "*all" fields list returns every field.
+func (s *Server) issueJSONLocked(stored *storedIssue, fields []string) map[string]interface{} {
+	all := map[string]interface{}{}
+	for name, value := range stored.fields {
+		all[name] = value
+	}
+	all["issuelinks"] = s.issueLinksJSONLocked(stored)
+
+	selected := all
+	if len(fields) > 0 && !containsString(fields, "*all") && !containsString(fields, "*navigable") {
+		selected = map[string]interface{}{}
+		for _, name := range fields {
+			if value, ok := all[name]; ok {
+				selected[name] = value
+			}
+		}
+	}
+
+	result := map[string]interface{}{
+		"id":   strconv.FormatInt(stored.id, 10),
+		"key":  stored.key,
+		"self": s.selfURL("/rest/api/3/issue/%d", stored.id),
+	}
+	if fields == nil || len(selected) > 0 {
+		result["fields"] = selected
+	}
+	return result
+}
+
+// issueValueLocked converts a stored issue to the SDK type. s.mu must be held.
+func (s *Server) issueValueLocked(stored *storedIssue) *issue.Issue {
+	var value issue.Issue
+	data, _ := json.Marshal(s.issueJSONLocked(stored, nil))
+	_ = json.Unmarshal(data, &value) // The stored fields were produced by the same types
+	return &value
+}
+
+// issueLinksJSONLocked renders the issuelinks field of an issue.
+func (s *Server) issueLinksJSONLocked(stored *storedIssue) []interface{} {
+	links := []interface{}{}
+	for _, link := range s.links {
+		entry := map[string]interface{}{
+			"id":   strconv.FormatInt(link.id, 10),
+			"self": s.selfURL("/rest/api/3/issueLink/%d", link.id),
+			"type": link.linkType,
+		}
+		switch stored {
+		case link.outward:
+			entry["inwardIssue"] = linkedIssueJSON(s, link.inward)
+		case link.inward:
+			entry["outwardIssue"] = linkedIssueJSON(s, link.outward)
+		default:
+			continue
+		}
+		links = append(links, entry)
+	}
+	return links
+}
+
+// linkedIssueJSON renders the summary of an issue shown in a link.
+func linkedIssueJSON(s *Server, stored *storedIssue) map[string]interface{} {
+	fields := map[string]interface{}{}
+	for _, name := range []string{"summary", "status", "priority", "issuetype"} {
+		if value, ok := stored.fields[name]; ok {
+			fields[name] = value
+		}
+	}
+	return map[string]interface{}{
+		"id":     strconv.FormatInt(stored.id, 10),
+		"key":    stored.key,
+		"self":   s.selfURL("/rest/api/3/issue/%d", stored.id),
+		"fields": fields,
+	}
+}
+
+func (s *Server) registerIssueRoutes() {
+	s.handle(http.MethodPost, "/rest/api/3/issue", s.createIssue)
+	s.handle(http.MethodGet, "/rest/api/3/issue/{issueIdOrKey}", s.getIssue)
+	s.handle(http.MethodPut, "/rest/api/3/issue/{issueIdOrKey}", s.updateIssue)
+	s.handle(http.MethodDelete, "/rest/api/3/issue/{issueIdOrKey}", s.deleteIssue)
+
+	s.handle(http.MethodGet, "/rest/api/3/issue/{issueIdOrKey}/transitions", s.getTransitions)
+	s.handle(http.MethodPost, "/rest/api/3/issue/{issueIdOrKey}/transitions", s.doTransition)
+
+	s.handle(http.MethodGet, "/rest/api/3/issue/{issueIdOrKey}/comment", s.listComments)
+	s.handle(http.MethodPost, "/rest/api/3/issue/{issueIdOrKey}/comment", s.addComment)
+	s.handle(http.MethodGet, "/rest/api/3/issue/{issueIdOrKey}/comment/{id}", s.getComment)
+	s.handle(http.MethodPut, "/rest/api/3/issue/{issueIdOrKey}/comment/{id}", s.updateComment)
+	s.handle(http.MethodDelete, "/rest/api/3/issue/{issueIdOrKey}/comment/{id}", s.deleteComment)
+
+	s.handle(http.MethodGet, "/rest/api/3/issue/{issueIdOrKey}/worklog", s.listWorklogs)
+	s.handle(http.MethodPost, "/rest/api/3/issue/{issueIdOrKey}/worklog", s.addWorklog)
+	s.handle(http.MethodGet, "/rest/api/3/issue/{issueIdOrKey}/worklog/{id}", s.getWorklog)
+	s.handle(http.MethodPut, "/rest/api/3/issue/{issueIdOrKey}/worklog/{id}", s.updateWorklog)
+	s.handle(http.MethodDelete, "/rest/api/3/issue/{issueIdOrKey}/worklog/{id}", s.deleteWorklog)
+
+	s.handle(http.MethodPost, "/rest/api/3/issueLink", s.createLink)
+	s.handle(http.MethodGet, "/rest/api/3/issueLink/{linkId}", s.getLink)
+	s.handle(http.MethodDelete, "/rest/api/3/issueLink/{linkId}", s.deleteLink)
+	s.handle(http.MethodGet, "/rest/api/3/issueLinkType", s.listLinkTypes)
+	s.handle(http.MethodGet, "/rest/api/3/issueLinkType/{issueLinkTypeId}", s.getLinkType)
+}
+
+// withIssue looks up the issue named in the path, writing a 404 if it does not exist.
+// The handler runs with s.mu held.
+func (s *Server) withIssue(w http.ResponseWriter, params map[string]string, fn func(stored *storedIssue)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := s.issueLocked(params["issueIdOrKey"])
+	if stored == nil {
+		writeError(w, http.StatusNotFound, msgIssueNotFound)
+		return
+	}
+	fn(stored)
+}
+
+func (s *Server) createIssue(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	var body struct {
+		Fields map[string]interface{} `json:"fields"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	if body.Fields == nil {
+		body.Fields = map[string]interface{}{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, errs := s.createIssueLocked(body.Fields, "")
+	if len(errs) > 0 {
+		writeFieldErrors(w, errs)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"id":   strconv.FormatInt(stored.id, 10),
+		"key":  stored.key,
+		"self": s.selfURL("/rest/api/3/issue/%d", stored.id),
+	})
+}
+
+func (s *Server) getIssue(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var fields []string
+	if raw := r.URL.Query().Get("fields"); raw != "" {
+		fields = strings.Split(raw, ",")
+	}
+
+	s.withIssue(w, params, func(stored *storedIssue) {
+		writeJSON(w, http.StatusOK, s.issueJSONLocked(stored, fields))
+	})
+}
+
+// readOnlyFields cannot be set through an issue update.
+var readOnlyFields = map[string]bool{
+	"status": true, "created": true, "updated": true, "project": true, "issuelinks": true, "resolutiondate": true,
+}
+
+func (s *Server) updateIssue(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var body struct {
+		Fields map[string]interface{} `json:"fields"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.withIssue(w, params, func(stored *storedIssue) {
+		errs := map[string]string{}
+		for name, value := range body.Fields {
+			if readOnlyFields[name] {
+				errs[name] = fmt.Sprintf("Field '%s' cannot be set. It is not on the appropriate screen, or unknown.", name)
+			}
+			if name == "summary" {
+				if summary, _ := value.(string); strings.TrimSpace(summary) == "" {
+					errs["summary"] = "You must specify a summary of the issue."
+				}
+			}
+		}
+		if len(errs) > 0 {
+			writeFieldErrors(w, errs)
+			return
+		}
+
+		for name, value := range body.Fields {
+			if value == nil {
+				delete(stored.fields, name)
+			} else {
+				stored.fields[name] = value
+			}
+		}
+		stored.fields["updated"] = timestamp(time.Now())
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (s *Server) deleteIssue(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	deleteSubtasks := r.URL.Query().Get("deleteSubtasks") == "true"
+
+	s.withIssue(w, params, func(stored *storedIssue) {
+		var subtasks []*storedIssue
+		for _, candidate := range s.issues {
+			if refKey(candidate.fields["parent"]) == stored.key {
+				subtasks = append(subtasks, candidate)
+			}
+		}
+		if len(subtasks) > 0 && !deleteSubtasks {
+			writeError(w, http.StatusBadRequest,
+				fmt.Sprintf("The issue '%s' has subtasks. You must specify the 'deleteSubtasks' parameter to delete this issue and all its subtasks.", stored.key))
+			return
+		}
+
+		for _, subtask := range subtasks {
+			s.deleteIssueLocked(subtask)
+		}
+		s.deleteIssueLocked(stored)
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (s *Server) getTransitions(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	s.withIssue(w, params, func(_ *storedIssue) {
+		transitions := make([]map[string]interface{}, 0, len(workflow))
+		for _, step := range workflow {
+			transitions = append(transitions, map[string]interface{}{
+				"id":            step.transitionID,
+				"name":          step.status.Name,
+				"to":            step.status,
+				"hasScreen":     false,
+				"isGlobal":      true,
+				"isInitial":     false,
+				"isConditional": false,
+			})
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"expand":      "transitions",
+			"transitions": transitions,
+		})
+	})
+}
+
+func (s *Server) doTransition(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var body issue.TransitionInput
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.withIssue(w, params, func(stored *storedIssue) {
+		var target *workflowStep
+		for i := range workflow {
+			if body.Transition != nil && workflow[i].transitionID == body.Transition.ID {
+				target = &workflow[i]
+			}
+		}
+		if target == nil {
+			id := ""
+			if body.Transition != nil {
+				id = body.Transition.ID
+			}
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Transition id '%s' is not valid for this issue.", id))
+			return
+		}
+
+		now := timestamp(time.Now())
+		stored.fields["status"] = toMap(target.status)
+		if target.status.Category.Key == "done" {
+			stored.fields["resolution"] = map[string]interface{}{"id": "10000", "name": "Done"}
+			stored.fields["resolutiondate"] = now
+		} else {
+			delete(stored.fields, "resolution")
+			delete(stored.fields, "resolutiondate")
+		}
+		for name, value := range body.Fields {
+			stored.fields[name] = value
+		}
+		stored.fields["updated"] = now
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// addCommentLocked stores a comment. s.mu must be held.
+func (s *Server) addCommentLocked(stored *storedIssue, c *issue.Comment) *issue.Comment {
+	comment := *c
+	comment.ID = strconv.FormatInt(s.newIDLocked(), 10)
+	comment.Self = s.selfURL("/rest/api/3/issue/%d/comment/%s", stored.id, comment.ID)
+	if comment.Author == nil {
+		if u := s.userLocked(s.currentUser); u != nil {
+			comment.Author = issueUser(u)
+		}
+	}
+	now := time.Now().UTC()
+	if comment.Created == nil {
+		comment.Created = &now
+	}
+	if comment.Updated == nil {
+		comment.Updated = &now
+	}
+	stored.comments = append(stored.comments, &comment)
+	return &comment
+}
+
+// commentLocked finds a comment by ID. s.mu must be held.
+func commentLocked(stored *storedIssue, id string) (int, *issue.Comment) {
+	for i, comment := range stored.comments {
+		if comment.ID == id {
+			return i, comment
+		}
+	}
+	return -1, nil
+}
+
+func (s *Server) listComments(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	startAt, maxResults := pageParams(r, 5000)
+
+	s.withIssue(w, params, func(stored *storedIssue) {
+		start, end := pageBounds(len(stored.comments), startAt, maxResults)
+		writeJSON(w, http.StatusOK, issue.CommentsResult{
+			Comments:   stored.comments[start:end],
+			StartAt:    start,
+			MaxResults: maxResults,
+			Total:      len(stored.comments),
+		})
+	})
+}
+
+func (s *Server) addComment(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var body issue.AddCommentInput
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.withIssue(w, params, func(stored *storedIssue) {
+		if body.Body.IsEmpty() {
+			writeFieldErrors(w, map[string]string{"comment": "Comment body can not be empty!"})
+			return
+		}
+		writeJSON(w, http.StatusCreated, s.addCommentLocked(stored, &issue.Comment{Body: body.Body}))
+	})
+}
+
+func (s *Server) getComment(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	s.withIssue(w, params, func(stored *storedIssue) {
+		_, comment := commentLocked(stored, params["id"])
+		if comment == nil {
+			writeError(w, http.StatusNotFound, "Can not find a comment for the id: "+params["id"]+".")
+			return
+		}
+		writeJSON(w, http.StatusOK, comment)
+	})
+}
+
+func (s *Server) updateComment(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var body issue.UpdateCommentInput
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.withIssue(w, params, func(stored *storedIssue) {
+		_, comment := commentLocked(stored, params["id"])
+		if comment == nil {
+			writeError(w, http.StatusNotFound, "Can not find a comment for the id: "+params["id"]+".")
+			return
+		}
+		if body.Body.IsEmpty() {
+			writeFieldErrors(w, map[string]string{"comment": "Comment body can not be empty!"})
+			return
+		}
+		now := time.Now().UTC()
+		comment.Body = body.Body
+		comment.Updated = &now
+		writeJSON(w, http.StatusOK, comment)
+	})
+}
+
+func (s *Server) deleteComment(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	s.withIssue(w, params, func(stored *storedIssue) {
+		i, comment := commentLocked(stored, params["id"])
+		if comment == nil {
+			writeError(w, http.StatusNotFound, "Can not find a comment for the id: "+params["id"]+".")
+			return
+		}
+		stored.comments = append(stored.comments[:i], stored.comments[i+1:]...)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// addWorklogLocked stores a worklog. s.mu must be held.
+func (s *Server) addWorklogLocked(stored *storedIssue, wl *issue.Worklog) *issue.Worklog {
+	worklog := *wl
+	worklog.ID = strconv.FormatInt(s.newIDLocked(), 10)
+	worklog.IssueID = strconv.FormatInt(stored.id, 10)
+	worklog.Self = s.selfURL("/rest/api/3/issue/%d/worklog/%s", stored.id, worklog.ID)
+	normalizeTimeSpent(&worklog)
+	if worklog.Author == nil {
+		if u := s.userLocked(s.currentUser); u != nil {
+			worklog.Author = issueUser(u)
+			worklog.UpdateAuthor = issueUser(u)
+		}
+	}
+	now := time.Now().UTC()
+	if worklog.Created == nil {
+		worklog.Created = &now
+	}
+	if worklog.Updated == nil {
+		worklog.Updated = &now
+	}
+	if worklog.Started == nil {
+		worklog.Started = &now
+	}
+	stored.worklogs = append(stored.worklogs, &worklog)
+	return &worklog
+}
+
+// worklogLocked finds a worklog by ID. s.mu must be held.
+func worklogLocked(stored *storedIssue, id string) (int, *issue.Worklog) {
+	for i, worklog := range stored.worklogs {
+		if worklog.ID == id {
+			return i, worklog
+		}
+	}
+	return -1, nil
+}
+
+func (s *Server) listWorklogs(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	startAt, maxResults := pageParams(r, 5000)
+
+	s.withIssue(w, params, func(stored *storedIssue) {
+		start, end := pageBounds(len(stored.worklogs), startAt, maxResults)
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"startAt":    start,
+			"maxResults": maxResults,
+			"total":      len(stored.worklogs),
+			"worklogs":   stored.worklogs[start:end],
+		})
+	})
+}
+
+func (s *Server) addWorklog(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var body issue.AddWorklogInput
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.withIssue(w, params, func(stored *storedIssue) {
+		if body.TimeSpent == "" && body.TimeSpentSeconds == 0 {
+			writeFieldErrors(w, map[string]string{"timeLogged": "You must indicate the time spent working."})
+			return
+		}
+		if body.TimeSpent != "" && parseTimeSpent(body.TimeSpent) <= 0 {
+			writeFieldErrors(w, map[string]string{"timeLogged": "Invalid time duration entered."})
+			return
+		}
+		writeJSON(w, http.StatusCreated, s.addWorklogLocked(stored, &issue.Worklog{
+			TimeSpent:        body.TimeSpent,
+			TimeSpentSeconds: body.TimeSpentSeconds,
+			Started:          body.Started,
+			Comment:          body.Comment,
+			Visibility:       body.Visibility,
+		}))
+	})
+}
+
+func (s *Server) getWorklog(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	s.withIssue(w, params, func(stored *storedIssue) {
+		_, worklog := worklogLocked(stored, params["id"])
+		if worklog == nil {
+			writeError(w, http.StatusNotFound, "Cannot find worklog with id: '"+params["id"]+"'.")
+			return
+		}
+		writeJSON(w, http.StatusOK, worklog)
+	})
+}
+
+func (s *Server) updateWorklog(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var body issue.UpdateWorklogInput
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.withIssue(w, params, func(stored *storedIssue) {
+		_, worklog := worklogLocked(stored, params["id"])
+		if worklog == nil {
+			writeError(w, http.StatusNotFound, "Cannot find worklog with id: '"+params["id"]+"'.")
+			return
+		}
+		if body.TimeSpent != "" || body.TimeSpentSeconds != 0 {
+			worklog.TimeSpent = body.TimeSpent
+			worklog.TimeSpentSeconds = body.TimeSpentSeconds
+			normalizeTimeSpent(worklog)
+		}
+		if body.Started != nil {
+			worklog.Started = body.Started
+		}
+		if body.Comment != "" {
+			worklog.Comment = body.Comment
+		}
+		if body.Visibility != nil {
+			worklog.Visibility = body.Visibility
+		}
+		now := time.Now().UTC()
+		worklog.Updated = &now
+		if u := s.userLocked(s.currentUser); u != nil {
+			worklog.UpdateAuthor = issueUser(u)
+		}
+		writeJSON(w, http.StatusOK, worklog)
+	})
+}
+
+func (s *Server) deleteWorklog(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	s.withIssue(w, params, func(stored *storedIssue) {
+		i, worklog := worklogLocked(stored, params["id"])
+		if worklog == nil {
+			writeError(w, http.StatusNotFound, "Cannot find worklog with id: '"+params["id"]+"'.")
+			return
+		}
+		stored.worklogs = append(stored.worklogs[:i], stored.worklogs[i+1:]...)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// linkTypeLocked finds a link type by ID or name. s.mu must be held.
+func (s *Server) linkTypeLocked(ref *issue.IssueLinkType) *issue.IssueLinkType {
+	if ref == nil {
+		return nil
+	}
+	for _, lt := range s.linkTypes {
+		if (ref.ID != "" && lt.ID == ref.ID) || (ref.ID == "" && strings.EqualFold(lt.Name, ref.Name)) {
+			return lt
+		}
+	}
+	return nil
+}
+
+func (s *Server) createLink(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	var body issue.CreateIssueLinkInput
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	linkType := s.linkTypeLocked(body.Type)
+	if linkType == nil {
+		name := ""
+		if body.Type != nil {
+			name = body.Type.Name + body.Type.ID
+		}
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No issue link type with name '%s' found.", name))
+		return
+	}
+
+	var ends [2]*storedIssue
+	for i, ref := range []*issue.IssueRef{body.InwardIssue, body.OutwardIssue} {
+		if ref != nil {
+			keyOrID := ref.Key
+			if keyOrID == "" {
+				keyOrID = ref.ID
+			}
+			ends[i] = s.issueLocked(keyOrID)
+		}
+		if ends[i] == nil {
+			writeError(w, http.StatusNotFound, "Issue Does Not Exist")
+			return
+		}
+	}
+
+	s.links = append(s.links, &storedLink{
+		id:       s.newIDLocked(),
+		linkType: linkType,
+		inward:   ends[0],
+		outward:  ends[1],
+	})
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+// linkLocked finds a link by ID. s.mu must be held.
+func (s *Server) linkLocked(id string) (int, *storedLink) {
+	for i, link := range s.links {
+		if strconv.FormatInt(link.id, 10) == id {
+			return i, link
+		}
+	}
+	return -1, nil
+}
+
+func (s *Server) getLink(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, link := s.linkLocked(params["linkId"])
+	if link == nil {
+		writeError(w, http.StatusNotFound, "No issue link with id '"+params["linkId"]+"' exists.")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":           strconv.FormatInt(link.id, 10),
+		"self":         s.selfURL("/rest/api/3/issueLink/%d", link.id),
+		"type":         link.linkType,
+		"inwardIssue":  linkedIssueJSON(s, link.inward),
+		"outwardIssue": linkedIssueJSON(s, link.outward),
+	})
+}
+
+func (s *Server) deleteLink(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, link := s.linkLocked(params["linkId"])
+	if link == nil {
+		writeError(w, http.StatusNotFound, "No issue link with id '"+params["linkId"]+"' exists.")
+		return
+	}
+	s.links = append(s.links[:i], s.links[i+1:]...)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listLinkTypes(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"issueLinkTypes": s.linkTypes})
+}
+
+func (s *Server) getLinkType(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	linkType := s.linkTypeLocked(&issue.IssueLinkType{ID: params["issueLinkTypeId"]})
+	if linkType == nil {
+		writeError(w, http.StatusNotFound, "No issue link type with id '"+params["issueLinkTypeId"]+"' found.")
+		return
+	}
+	writeJSON(w, http.StatusOK, linkType)
+}
+
+// projectRef returns the key or ID of the project referenced in issue fields.
+func projectRef(fields map[string]interface{}) string {
+	ref, _ := fields["project"].(map[string]interface{})
+	if key, ok := ref["key"].(string); ok && key != "" {
+		return key
+	}
+	id, _ := ref["id"].(string)
+	return id
+}
+
+// refKey returns the key (or ID) of an issue reference such as the parent field.
+func refKey(value interface{}) string {
+	ref, _ := value.(map[string]interface{})
+	if key, ok := ref["key"].(string); ok && key != "" {
+		return key
+	}
+	id, _ := ref["id"].(string)
+	return id
+}
+
+// issueUser converts a site user to the user type embedded in issues.
+func issueUser(u *user.User) *issue.User {
+	return &issue.User{
+		AccountID:    u.AccountID,
+		EmailAddress: u.EmailAddress,
+		DisplayName:  u.DisplayName,
+		Active:       u.Active,
+		TimeZone:     u.TimeZone,
+		Self:         u.Self,
+	}
+}
+
+// toMap converts a value to its decoded JSON form.
+func toMap(v interface{}) map[string]interface{} {
+	data, _ := json.Marshal(v)
+	var m map[string]interface{}
+	_ = json.Unmarshal(data, &m) // A marshalled struct always decodes into a map
+	return m
+}
+
+// timestamp formats a time the way the SDK decodes issue dates.
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// containsString reports whether values contains value.
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// timeUnits are the Jira duration units with the default 8h day and 5d week.
+var timeUnits = map[byte]int64{
+	'w': 5 * 8 * 3600,
+	'd': 8 * 3600,
+	'h': 3600,
+	'm': 60,
+}
+
+// parseTimeSpent parses a Jira duration such as "1d 3h 20m" into seconds.
+func parseTimeSpent(value string) int64 {
+	var total int64
+	for _, part := range strings.Fields(value) {
+		unit, ok := timeUnits[part[len(part)-1]]
+		if !ok {
+			return 0
+		}
+		n, err := strconv.ParseInt(part[:len(part)-1], 10, 64)
+		if err != nil || n < 0 {
+			return 0
+		}
+		total += n * unit
+	}
+	return total
+}
+
+// formatTimeSpent formats seconds as a Jira duration such as "1d 3h 20m".
+func formatTimeSpent(seconds int64) string {
+	var parts []string
+	for _, unit := range []byte{'w', 'd', 'h', 'm'} {
+		if n := seconds / timeUnits[unit]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d%c", n, unit))
+			seconds -= n * timeUnits[unit]
+		}
+	}
+	if len(parts) == 0 {
+		return "0m"
+	}
+	return strings.Join(parts, " ")
+}
+
+// normalizeTimeSpent fills in whichever of TimeSpent and TimeSpentSeconds is missing.
+func normalizeTimeSpent(wl *issue.Worklog) {
+	if wl.TimeSpent != "" {
+		wl.TimeSpentSeconds = parseTimeSpent(wl.TimeSpent)
+	}
+	wl.TimeSpent = formatTimeSpent(wl.TimeSpentSeconds)
+}
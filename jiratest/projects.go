@@ -0,0 +1,381 @@
+package jiratest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/felixgeelhaar/jirasdk/core/project"
+)
+
+// storedVersion is a project version together with the project it belongs to.
+type storedVersion struct {
+	projectKey string
+	*project.Version
+}
+
+// AddProject stores a project and returns it with its ID and self link filled in.
+func (s *Server) AddProject(p *project.Project) *project.Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p.Key == "" {
+		panic("jiratest: project key is required")
+	}
+	if s.projectLocked(p.Key) != nil {
+		panic(fmt.Sprintf("jiratest: project %s already exists", p.Key))
+	}
+
+	stored := *p
+	return s.addProjectLocked(&stored)
+}
+
+// AddVersion stores a version in a project and returns it with its ID filled in.
+func (s *Server) AddVersion(projectKey string, v *project.Version) *project.Version {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	proj := s.projectLocked(projectKey)
+	if proj == nil {
+		panic(fmt.Sprintf("jiratest: project %s does not exist", projectKey))
+	}
+
+	version := *v
+	version.ID = strconv.FormatInt(s.newIDLocked(), 10)
+	s.versions = append(s.versions, &storedVersion{projectKey: proj.Key, Version: &version})
+
+	return &version
+}
+
+// addProjectLocked stores a project, assigning its ID and self link. s.mu must be held.
+func (s *Server) addProjectLocked(p *project.Project) *project.Project {
+	if p.ID == "" {
+		p.ID = strconv.FormatInt(s.newIDLocked(), 10)
+	}
+	if p.Name == "" {
+		p.Name = p.Key
+	}
+	if p.ProjectTypeKey == "" {
+		p.ProjectTypeKey = "software"
+	}
+	p.Self = s.selfURL("/rest/api/3/project/%s", p.ID)
+	s.projects = append(s.projects, p)
+	return p
+}
+
+// projectLocked finds a project by key or ID. s.mu must be held.
+func (s *Server) projectLocked(keyOrID string) *project.Project {
+	if keyOrID == "" {
+		return nil
+	}
+	for _, p := range s.projects {
+		if strings.EqualFold(p.Key, keyOrID) || p.ID == keyOrID {
+			return p
+		}
+	}
+	return nil
+}
+
+// versionLocked finds a version by ID. s.mu must be held.
+func (s *Server) versionLocked(id string) (int, *storedVersion) {
+	for i, v := range s.versions {
+		if v.ID == id {
+			return i, v
+		}
+	}
+	return -1, nil
+}
+
+func (s *Server) registerProjectRoutes() {
+	s.handle(http.MethodGet, "/rest/api/3/project/search", s.searchProjects)
+	s.handle(http.MethodPost, "/rest/api/3/project", s.createProject)
+	s.handle(http.MethodGet, "/rest/api/3/project/{projectIdOrKey}", s.getProject)
+	s.handle(http.MethodPut, "/rest/api/3/project/{projectIdOrKey}", s.updateProject)
+	s.handle(http.MethodDelete, "/rest/api/3/project/{projectIdOrKey}", s.deleteProject)
+	s.handle(http.MethodGet, "/rest/api/3/project/{projectIdOrKey}/versions", s.listProjectVersions)
+	s.handle(http.MethodGet, "/rest/api/3/project/{projectIdOrKey}/components", s.listProjectComponents)
+
+	s.handle(http.MethodPost, "/rest/api/3/version", s.createVersion)
+	s.handle(http.MethodGet, "/rest/api/3/version/{id}", s.getVersion)
+	s.handle(http.MethodPut, "/rest/api/3/version/{id}", s.updateVersion)
+	s.handle(http.MethodDelete, "/rest/api/3/version/{id}", s.deleteVersion)
+}
+
+// withProject looks up the project named in the path, writing a 404 if it does
+// not exist. The handler runs with s.mu held.
+func (s *Server) withProject(w http.ResponseWriter, params map[string]string, fn func(p *project.Project)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.projectLocked(params["projectIdOrKey"])
+	if p == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No project could be found with key '%s'.", params["projectIdOrKey"]))
+		return
+	}
+	fn(p)
+}
+
+func (s *Server) searchProjects(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	startAt, maxResults := pageParams(r, 50)
+	query := strings.ToLower(r.URL.Query().Get("query"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matched []*project.Project
+	for _, p := range s.projects {
+		if query == "" || strings.Contains(strings.ToLower(p.Key), query) || strings.Contains(strings.ToLower(p.Name), query) {
+			matched = append(matched, p)
+		}
+	}
+
+	start, end := pageBounds(len(matched), startAt, maxResults)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"self":       s.selfURL("%s", r.URL.RequestURI()),
+		"values":     append([]*project.Project{}, matched[start:end]...),
+		"startAt":    start,
+		"maxResults": maxResults,
+		"total":      len(matched),
+		"isLast":     end == len(matched),
+	})
+}
+
+func (s *Server) createProject(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	var body project.CreateInput
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	errs := map[string]string{}
+	switch {
+	case body.Key == "":
+		errs["projectKey"] = "You must specify a valid project key."
+	case !issueKeyPattern.MatchString(body.Key + "-1"):
+		errs["projectKey"] = "Project keys must start with an uppercase letter, followed by one or more uppercase alphanumeric characters."
+	case s.projectLocked(body.Key) != nil:
+		errs["projectKey"] = fmt.Sprintf("Project '%s' uses this project key.", s.projectLocked(body.Key).Name)
+	}
+	if body.Name == "" {
+		errs["projectName"] = "You must specify a valid project name."
+	}
+	if body.ProjectTypeKey == "" {
+		errs["projectTypeKey"] = "You must specify a valid project type."
+	}
+	if len(errs) > 0 {
+		writeFieldErrors(w, errs)
+		return
+	}
+
+	p := s.addProjectLocked(&project.Project{
+		Key:            body.Key,
+		Name:           body.Name,
+		Description:    body.Description,
+		ProjectTypeKey: body.ProjectTypeKey,
+	})
+	if body.LeadAccountID != "" {
+		if u := s.userLocked(body.LeadAccountID); u != nil {
+			p.Lead = &project.User{Self: u.Self, AccountID: u.AccountID, EmailAddress: u.EmailAddress, DisplayName: u.DisplayName, Active: u.Active}
+		}
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"id":   p.ID,
+		"key":  p.Key,
+		"self": p.Self,
+	})
+}
+
+func (s *Server) getProject(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	s.withProject(w, params, func(p *project.Project) {
+		writeJSON(w, http.StatusOK, p)
+	})
+}
+
+func (s *Server) updateProject(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var body project.UpdateInput
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.withProject(w, params, func(p *project.Project) {
+		if body.Name != "" {
+			p.Name = body.Name
+		}
+		if body.Description != "" {
+			p.Description = body.Description
+		}
+		if body.LeadAccountID != "" {
+			u := s.userLocked(body.LeadAccountID)
+			if u == nil {
+				writeFieldErrors(w, map[string]string{"projectLead": "The project lead must be a valid user."})
+				return
+			}
+			p.Lead = &project.User{Self: u.Self, AccountID: u.AccountID, EmailAddress: u.EmailAddress, DisplayName: u.DisplayName, Active: u.Active}
+		}
+		writeJSON(w, http.StatusOK, p)
+	})
+}
+
+func (s *Server) deleteProject(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	s.withProject(w, params, func(p *project.Project) {
+		for i, candidate := range s.projects {
+			if candidate == p {
+				s.projects = append(s.projects[:i], s.projects[i+1:]...)
+				break
+			}
+		}
+		for _, stored := range append([]*storedIssue{}, s.issues...) {
+			if projectRef(stored.fields) == p.Key {
+				s.deleteIssueLocked(stored)
+			}
+		}
+		versions := s.versions[:0]
+		for _, v := range s.versions {
+			if v.projectKey != p.Key {
+				versions = append(versions, v)
+			}
+		}
+		s.versions = versions
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (s *Server) listProjectVersions(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	s.withProject(w, params, func(p *project.Project) {
+		versions := []*project.Version{}
+		for _, v := range s.versions {
+			if v.projectKey == p.Key {
+				versions = append(versions, v.Version)
+			}
+		}
+		writeJSON(w, http.StatusOK, versions)
+	})
+}
+
+func (s *Server) listProjectComponents(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	s.withProject(w, params, func(p *project.Project) {
+		components := p.Components
+		if components == nil {
+			components = []*project.Component{}
+		}
+		writeJSON(w, http.StatusOK, components)
+	})
+}
+
+// versionJSON renders a version with the projectId Jira includes in responses.
+func versionJSON(p *project.Project, v *project.Version) map[string]interface{} {
+	m := toMap(v)
+	if id, err := strconv.ParseInt(p.ID, 10, 64); err == nil {
+		m["projectId"] = id
+	}
+	return m
+}
+
+func (s *Server) createVersion(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	var body project.CreateVersionInput
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ref := body.Project
+	if ref == "" && body.ProjectID != 0 {
+		ref = strconv.FormatInt(body.ProjectID, 10)
+	}
+	p := s.projectLocked(ref)
+
+	errs := map[string]string{}
+	if p == nil {
+		errs["project"] = "Project must be specified to create a version."
+	}
+	if strings.TrimSpace(body.Name) == "" {
+		errs["name"] = "You must specify a valid version name"
+	}
+	if p != nil {
+		for _, v := range s.versions {
+			if v.projectKey == p.Key && strings.EqualFold(v.Name, body.Name) {
+				errs["name"] = "A version with this name already exists in this project."
+			}
+		}
+	}
+	if len(errs) > 0 {
+		writeFieldErrors(w, errs)
+		return
+	}
+
+	version := &project.Version{
+		ID:          strconv.FormatInt(s.newIDLocked(), 10),
+		Name:        body.Name,
+		Description: body.Description,
+		Archived:    body.Archived,
+		Released:    body.Released,
+		StartDate:   body.StartDate,
+		ReleaseDate: body.ReleaseDate,
+	}
+	s.versions = append(s.versions, &storedVersion{projectKey: p.Key, Version: version})
+
+	writeJSON(w, http.StatusCreated, versionJSON(p, version))
+}
+
+// withVersion looks up the version named in the path, writing a 404 if it does
+// not exist. The handler runs with s.mu held.
+func (s *Server) withVersion(w http.ResponseWriter, params map[string]string, fn func(i int, v *storedVersion)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, v := s.versionLocked(params["id"])
+	if v == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Could not find version for id '%s'", params["id"]))
+		return
+	}
+	fn(i, v)
+}
+
+func (s *Server) getVersion(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	s.withVersion(w, params, func(_ int, v *storedVersion) {
+		writeJSON(w, http.StatusOK, versionJSON(s.projectLocked(v.projectKey), v.Version))
+	})
+}
+
+func (s *Server) updateVersion(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var body project.UpdateVersionInput
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.withVersion(w, params, func(_ int, v *storedVersion) {
+		if body.Name != "" {
+			v.Name = body.Name
+		}
+		if body.Description != "" {
+			v.Description = body.Description
+		}
+		if body.Archived != nil {
+			v.Archived = *body.Archived
+		}
+		if body.Released != nil {
+			v.Released = *body.Released
+		}
+		if body.StartDate != "" {
+			v.StartDate = body.StartDate
+		}
+		if body.ReleaseDate != "" {
+			v.ReleaseDate = body.ReleaseDate
+		}
+		writeJSON(w, http.StatusOK, versionJSON(s.projectLocked(v.projectKey), v.Version))
+	})
+}
+
+func (s *Server) deleteVersion(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	s.withVersion(w, params, func(i int, _ *storedVersion) {
+		s.versions = append(s.versions[:i], s.versions[i+1:]...)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
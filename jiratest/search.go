@@ -0,0 +1,452 @@
+package jiratest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/felixgeelhaar/jirasdk/core/issue"
+)
+
+func (s *Server) registerSearchRoutes() {
+	s.handle(http.MethodPost, "/rest/api/3/search/jql", s.searchJQL)
+}
+
+func (s *Server) searchJQL(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	var body struct {
+		JQL           string   `json:"jql"`
+		MaxResults    int      `json:"maxResults"`
+		NextPageToken string   `json:"nextPageToken"`
+		Fields        []string `json:"fields"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	query, err := parseJQL(body.JQL)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	startAt := 0
+	if body.NextPageToken != "" {
+		startAt, err = decodePageToken(body.NextPageToken)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "The provided next page token is invalid or expired.")
+			return
+		}
+	}
+	maxResults := body.MaxResults
+	if maxResults <= 0 {
+		maxResults = 50
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matched []*storedIssue
+	for _, stored := range s.issues {
+		if query.matches(s, stored) {
+			matched = append(matched, stored)
+		}
+	}
+
+	// search/jql returns only issue IDs unless fields are requested
+	fields := body.Fields
+	if len(fields) == 0 {
+		fields = []string{"id"}
+	}
+
+	start, end := pageBounds(len(matched), startAt, maxResults)
+	issues := make([]map[string]interface{}, 0, end-start)
+	for _, stored := range matched[start:end] {
+		issues = append(issues, s.issueJSONLocked(stored, fields))
+	}
+
+	result := map[string]interface{}{
+		"issues":     issues,
+		"maxResults": maxResults,
+	}
+	if end < len(matched) {
+		result["nextPageToken"] = encodePageToken(end)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// encodePageToken returns an opaque token for the result offset.
+func encodePageToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+// decodePageToken reverses encodePageToken.
+func decodePageToken(token string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(data), "offset:"))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid page token %q", token)
+	}
+	return offset, nil
+}
+
+// jqlQuery is a parsed JQL query: clauses that must all match.
+type jqlQuery struct {
+	clauses []jqlClause
+}
+
+// jqlClause is a single condition such as status IN ("To Do", Done).
+type jqlClause struct {
+	field    string
+	operator string // "=", "!=", "~", "!~", "in", "not in", "is empty", "is not empty"
+	values   []string
+}
+
+// jqlFields maps each supported field name to its canonical form.
+var jqlFields = map[string]string{
+	"project":   "project",
+	"key":       "key",
+	"issuekey":  "key",
+	"id":        "key",
+	"status":    "status",
+	"issuetype": "issuetype",
+	"type":      "issuetype",
+	"assignee":  "assignee",
+	"reporter":  "reporter",
+	"priority":  "priority",
+	"labels":    "labels",
+	"summary":   "summary",
+	"text":      "text",
+	"sprint":    "sprint",
+	"parent":    "parent",
+}
+
+// matches reports whether an issue satisfies every clause. s.mu must be held.
+func (q *jqlQuery) matches(s *Server, stored *storedIssue) bool {
+	for _, clause := range q.clauses {
+		if !clause.matches(s, stored) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *jqlClause) matches(s *Server, stored *storedIssue) bool {
+	actual := s.jqlValuesLocked(stored, c.field)
+
+	switch c.operator {
+	case "is empty":
+		return len(actual) == 0
+	case "is not empty":
+		return len(actual) > 0
+	case "~", "!~":
+		contains := false
+		for _, want := range c.values {
+			for _, value := range actual {
+				if strings.Contains(strings.ToLower(value), strings.ToLower(want)) {
+					contains = true
+				}
+			}
+		}
+		return contains == (c.operator == "~")
+	}
+
+	equal := false
+	for _, want := range c.values {
+		if want == "currentUser()" {
+			want = s.currentUser
+		}
+		for _, value := range actual {
+			if strings.EqualFold(value, want) {
+				equal = true
+			}
+		}
+	}
+	if c.operator == "!=" || c.operator == "not in" {
+		// Like Jira, negative operators never match issues where the field is empty
+		return !equal && len(actual) > 0
+	}
+	return equal
+}
+
+// jqlValuesLocked returns the values a JQL clause on field compares against.
+// s.mu must be held.
+func (s *Server) jqlValuesLocked(stored *storedIssue, field string) []string {
+	ref := func(name string, keys ...string) []string {
+		m, _ := stored.fields[name].(map[string]interface{})
+		var values []string
+		for _, key := range keys {
+			if value, ok := m[key].(string); ok && value != "" {
+				values = append(values, value)
+			}
+		}
+		return values
+	}
+
+	switch field {
+	case "project":
+		return ref("project", "key", "id", "name")
+	case "key":
+		return []string{stored.key, strconv.FormatInt(stored.id, 10)}
+	case "status", "issuetype", "priority":
+		return ref(field, "name", "id")
+	case "assignee", "reporter":
+		return ref(field, "accountId", "displayName", "emailAddress")
+	case "parent":
+		return ref("parent", "key", "id")
+	case "labels":
+		labels, _ := stored.fields["labels"].([]interface{})
+		values := make([]string, 0, len(labels))
+		for _, label := range labels {
+			if value, ok := label.(string); ok {
+				values = append(values, value)
+			}
+		}
+		return values
+	case "summary":
+		summary, _ := stored.fields["summary"].(string)
+		return []string{summary}
+	case "text":
+		summary, _ := stored.fields["summary"].(string)
+		values := []string{summary}
+		for _, name := range []string{"description", "environment"} {
+			if text := adfText(stored.fields[name]); text != "" {
+				values = append(values, text)
+			}
+		}
+		for _, comment := range stored.comments {
+			values = append(values, comment.Body.ToText())
+		}
+		return values
+	case "sprint":
+		for _, sprint := range s.sprints {
+			if sprint.ID == stored.sprintID {
+				return []string{strconv.FormatInt(sprint.ID, 10), sprint.Name}
+			}
+		}
+	}
+	return nil
+}
+
+// adfText extracts the plain text of a decoded ADF document.
+func adfText(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	var doc issue.ADF
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return ""
+	}
+	return doc.ToText()
+}
+
+// jqlToken is a lexical token of a JQL query.
+type jqlToken struct {
+	text   string
+	quoted bool
+}
+
+// tokenizeJQL splits a query into words, quoted strings, operators and punctuation.
+func tokenizeJQL(jql string) ([]jqlToken, error) {
+	var tokens []jqlToken
+	runes := []rune(jql)
+
+	for i := 0; i < len(runes); {
+		ch := runes[i]
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+		case ch == '"' || ch == '\'':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != ch; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				sb.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("Error in the JQL Query: The quoted string %s has not been completed.", string(runes[i:]))
+			}
+			tokens = append(tokens, jqlToken{text: sb.String(), quoted: true})
+			i = j + 1
+		case ch == '(' || ch == ')' || ch == ',' || ch == '=' || ch == '~':
+			tokens = append(tokens, jqlToken{text: string(ch)})
+			i++
+		case ch == '!':
+			if i+1 < len(runes) && (runes[i+1] == '=' || runes[i+1] == '~') {
+				tokens = append(tokens, jqlToken{text: string(runes[i : i+2])})
+				i += 2
+				continue
+			}
+			return nil, fmt.Errorf("Error in the JQL Query: The character '!' is a reserved JQL character. (line 1, character %d)", i+1)
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune(`()",'=~!`, runes[j]) {
+				j++
+			}
+			tokens = append(tokens, jqlToken{text: string(runes[i:j])})
+			i = j
+		}
+	}
+
+	return tokens, nil
+}
+
+// jqlParser is a recursive-descent parser over the JQL subset the fake supports.
+type jqlParser struct {
+	tokens []jqlToken
+	pos    int
+}
+
+// parseJQL parses a query. An empty query matches every issue.
+func parseJQL(jql string) (*jqlQuery, error) {
+	tokens, err := tokenizeJQL(jql)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &jqlParser{tokens: tokens}
+	query := &jqlQuery{}
+
+	for !p.done() && !p.peekKeyword("order") {
+		if len(query.clauses) > 0 {
+			if !p.acceptKeyword("and") {
+				return nil, p.errorf("Expecting either 'OR' or 'AND' but got '%s'.", p.peek().text)
+			}
+		}
+		clause, err := p.parseClause()
+		if err != nil {
+			return nil, err
+		}
+		query.clauses = append(query.clauses, clause)
+	}
+
+	// ORDER BY is accepted but results are always in creation order
+	if p.acceptKeyword("order") && !p.acceptKeyword("by") {
+		return nil, p.errorf("Expecting 'by' after 'order'.")
+	}
+
+	return query, nil
+}
+
+func (p *jqlParser) parseClause() (jqlClause, error) {
+	fieldToken := p.next()
+	if fieldToken.text == "" || fieldToken.text == "(" {
+		return jqlClause{}, p.errorf("Expecting a field name but got '%s'.", fieldToken.text)
+	}
+	field, ok := jqlFields[strings.ToLower(fieldToken.text)]
+	if !ok {
+		return jqlClause{}, fmt.Errorf("Field '%s' does not exist or you do not have permission to view it.", fieldToken.text)
+	}
+	clause := jqlClause{field: field}
+
+	switch op := p.next(); {
+	case op.text == "=" || op.text == "!=" || op.text == "~" || op.text == "!~":
+		clause.operator = op.text
+		value, err := p.parseValue()
+		if err != nil {
+			return jqlClause{}, err
+		}
+		clause.values = []string{value}
+	case strings.EqualFold(op.text, "in"):
+		clause.operator = "in"
+	case strings.EqualFold(op.text, "not") && p.acceptKeyword("in"):
+		clause.operator = "not in"
+	case strings.EqualFold(op.text, "is"):
+		clause.operator = "is empty"
+		if p.acceptKeyword("not") {
+			clause.operator = "is not empty"
+		}
+		if !p.acceptKeyword("empty") && !p.acceptKeyword("null") {
+			return jqlClause{}, p.errorf("Expecting 'EMPTY' or 'NULL' but got '%s'.", p.peek().text)
+		}
+	default:
+		return jqlClause{}, p.errorf("Expecting operator but got '%s'.", op.text)
+	}
+
+	if clause.operator == "in" || clause.operator == "not in" {
+		if p.next().text != "(" {
+			return jqlClause{}, p.errorf("Expecting '(' after '%s'.", strings.ToUpper(clause.operator))
+		}
+		for {
+			value, err := p.parseValue()
+			if err != nil {
+				return jqlClause{}, err
+			}
+			clause.values = append(clause.values, value)
+			if sep := p.next(); sep.text == ")" {
+				break
+			} else if sep.text != "," {
+				return jqlClause{}, p.errorf("Expecting ',' or ')' but got '%s'.", sep.text)
+			}
+		}
+	}
+
+	return clause, nil
+}
+
+// parseValue reads an operand: a word, a quoted string or a function call.
+func (p *jqlParser) parseValue() (string, error) {
+	token := p.next()
+	if token.text == "" && !token.quoted {
+		return "", p.errorf("Expecting a value but reached the end of the query.")
+	}
+	if !token.quoted && strings.ContainsAny(token.text, "(),") {
+		return "", p.errorf("Expecting a value but got '%s'.", token.text)
+	}
+	if !token.quoted && p.peek().text == "(" {
+		p.next()
+		if p.next().text != ")" {
+			return "", p.errorf("Function '%s' does not take arguments.", token.text)
+		}
+		return token.text + "()", nil
+	}
+	return token.text, nil
+}
+
+func (p *jqlParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *jqlParser) peek() jqlToken {
+	if p.done() {
+		return jqlToken{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *jqlParser) next() jqlToken {
+	token := p.peek()
+	if !p.done() {
+		p.pos++
+	}
+	return token
+}
+
+func (p *jqlParser) peekKeyword(keyword string) bool {
+	token := p.peek()
+	return !token.quoted && strings.EqualFold(token.text, keyword)
+}
+
+func (p *jqlParser) acceptKeyword(keyword string) bool {
+	if p.peekKeyword(keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *jqlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("Error in the JQL Query: "+format, args...)
+}
@@ -0,0 +1,294 @@
+// Package jiratest provides an in-memory fake Jira server for integration tests.
+//
+// The server emulates the REST v3 and Agile 1.0 endpoints called by this SDK:
+// issues (CRUD, transitions, comments, worklogs and links), projects,
+// versions, users, boards, sprints, search/jql with token pagination, and
+// serverInfo, which reports a Jira Cloud site.
+// State lives in memory and can be seeded from the SDK's own types. Errors are
+// returned in Jira's error payload format, so callers see the same
+// *transport.ErrorResponse values they would get from a real site.
+//
+// Example usage:
+//
+//	srv := jiratest.NewServer()
+//	defer srv.Close()
+//
+//	srv.AddIssue(&issue.Issue{Fields: &issue.IssueFields{
+//		Project:   &issue.Project{Key: "PROJ"},
+//		Summary:   "Seeded issue",
+//		IssueType: &issue.IssueType{Name: "Task"},
+//	}})
+//
+//	client, err := srv.Client()
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//
+//	got, err := client.Issue.Get(ctx, "PROJ-1", nil)
+//
+// The fake supports a subset of JQL: clauses joined with AND on project, key,
+// status, issuetype, assignee, reporter, priority, labels, sprint, summary and
+// text, using =, !=, ~, IN, NOT IN and IS [NOT] EMPTY. ORDER BY is accepted
+// and ignored; results are returned in creation order.
+package jiratest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	jira "github.com/felixgeelhaar/jirasdk"
+	"github.com/felixgeelhaar/jirasdk/core/agile"
+	"github.com/felixgeelhaar/jirasdk/core/issue"
+	"github.com/felixgeelhaar/jirasdk/core/project"
+	"github.com/felixgeelhaar/jirasdk/core/user"
+)
+
+// Server is a fake Jira site backed by an httptest.Server.
+//
+// All methods are safe for concurrent use.
+type Server struct {
+	// URL is the base URL of the fake site, for use with jira.WithBaseURL
+	URL string
+
+	srv       *httptest.Server
+	routes    []route
+	requestID atomic.Int64
+
+	mu          sync.Mutex
+	nextID      int64
+	issues      []*storedIssue
+	issueKeys   map[string]*storedIssue
+	issueSeq    map[string]int
+	links       []*storedLink
+	linkTypes   []*issue.IssueLinkType
+	projects    []*project.Project
+	versions    []*storedVersion
+	users       []*user.User
+	currentUser string
+	boards      []*agile.Board
+	sprints     []*agile.Sprint
+}
+
+// NewServer starts a fake Jira server with an empty site.
+//
+// The site has one user, "jiratest", who is the authenticated user for every
+// request, the standard issue link types, and a "To Do" → "In Progress" →
+// "Done" workflow shared by all issues.
+func NewServer() *Server {
+	s := &Server{
+		nextID:    10000,
+		issueKeys: make(map[string]*storedIssue),
+		issueSeq:  make(map[string]int),
+		linkTypes: defaultLinkTypes(),
+	}
+
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	s.registerRoutes()
+
+	s.AddUser(&user.User{
+		AccountID:    "jiratest",
+		AccountType:  "atlassian",
+		DisplayName:  "Jira Test",
+		EmailAddress: "jiratest@example.com",
+		Active:       true,
+		TimeZone:     "UTC",
+	})
+	s.currentUser = "jiratest"
+
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns a jira.Client configured for the fake site.
+//
+// Retries are disabled so that error responses surface immediately; pass
+// options to override this or add others.
+func (s *Server) Client(opts ...jira.Option) (*jira.Client, error) {
+	defaults := []jira.Option{
+		jira.WithBaseURL(s.URL),
+		jira.WithAPIToken("jiratest@example.com", "jiratest-token"),
+		jira.WithHTTPClient(s.srv.Client()),
+		jira.WithMaxRetries(0),
+	}
+	return jira.NewClient(append(defaults, opts...)...)
+}
+
+// SetCurrentUser sets the account returned by /myself and recorded as the
+// author of new issues, comments and worklogs. The user must have been added.
+func (s *Server) SetCurrentUser(accountID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.currentUser = accountID
+}
+
+// newIDLocked returns a fresh numeric ID. s.mu must be held.
+func (s *Server) newIDLocked() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+// selfURL returns the absolute URL of a resource path.
+func (s *Server) selfURL(format string, args ...interface{}) string {
+	return s.URL + fmt.Sprintf(format, args...)
+}
+
+// route is a request pattern such as GET /rest/api/3/issue/{issueIdOrKey}.
+type route struct {
+	method   string
+	segments []string
+	handler  func(w http.ResponseWriter, r *http.Request, params map[string]string)
+}
+
+// handle registers a handler for method and pattern.
+func (s *Server) handle(method, pattern string, handler func(w http.ResponseWriter, r *http.Request, params map[string]string)) {
+	s.routes = append(s.routes, route{
+		method:   method,
+		segments: strings.Split(strings.Trim(pattern, "/"), "/"),
+		handler:  handler,
+	})
+}
+
+// match reports whether path matches the route and extracts its parameters.
+func (rt *route) match(path string) (map[string]string, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) != len(rt.segments) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, segment := range rt.segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params[segment[1:len(segment)-1]] = segments[i]
+			continue
+		}
+		if segment != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// serveHTTP authenticates and dispatches a request.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-AREQUESTID", strconv.FormatInt(s.requestID.Add(1), 10))
+
+	if r.Header.Get("Authorization") == "" {
+		writeError(w, http.StatusUnauthorized, "You are not authenticated. Authentication required to perform this operation.")
+		return
+	}
+
+	methodAllowed := false
+	for i := range s.routes {
+		params, ok := s.routes[i].match(r.URL.Path)
+		if !ok {
+			continue
+		}
+		if s.routes[i].method != r.Method {
+			methodAllowed = true
+			continue
+		}
+		s.routes[i].handler(w, r, params)
+		return
+	}
+
+	if methodAllowed {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("Method %s is not supported for %s", r.Method, r.URL.Path))
+		return
+	}
+	writeError(w, http.StatusNotFound, fmt.Sprintf("jiratest: no handler for %s %s", r.Method, r.URL.Path))
+}
+
+// registerRoutes installs the handlers for every emulated endpoint.
+func (s *Server) registerRoutes() {
+	s.registerIssueRoutes()
+	s.registerSearchRoutes()
+	s.registerProjectRoutes()
+	s.registerUserRoutes()
+	s.registerAgileRoutes()
+	s.handle(http.MethodGet, "/rest/api/3/serverInfo", s.getServerInfo)
+}
+
+// getServerInfo reports a Jira Cloud site, so that the client picks the
+// Cloud endpoints the fake emulates.
+func (s *Server) getServerInfo(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"baseUrl":        s.URL,
+		"version":        "1001.0.0-SNAPSHOT",
+		"versionNumbers": []int{1001, 0, 0},
+		"deploymentType": "Cloud",
+		"buildNumber":    100000,
+		"serverTitle":    "jiratest",
+	})
+}
+
+// errorBody is Jira's error payload.
+type errorBody struct {
+	ErrorMessages []string          `json:"errorMessages"`
+	Errors        map[string]string `json:"errors"`
+}
+
+// writeError writes a Jira error payload with top-level messages.
+func writeError(w http.ResponseWriter, status int, messages ...string) {
+	if messages == nil {
+		messages = []string{}
+	}
+	writeJSON(w, status, errorBody{ErrorMessages: messages, Errors: map[string]string{}})
+}
+
+// writeFieldErrors writes a Jira error payload with field-level validation errors.
+func writeFieldErrors(w http.ResponseWriter, errors map[string]string) {
+	writeJSON(w, http.StatusBadRequest, errorBody{ErrorMessages: []string{}, Errors: errors})
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v) // Explicit ignore, the client may have gone away
+}
+
+// decodeBody decodes the JSON request body, writing a 400 on failure.
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "Unexpected character in request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// pageParams reads startAt and maxResults query parameters.
+func pageParams(r *http.Request, defaultMax int) (startAt, maxResults int) {
+	startAt, _ = strconv.Atoi(r.URL.Query().Get("startAt"))
+	maxResults, _ = strconv.Atoi(r.URL.Query().Get("maxResults"))
+	if startAt < 0 {
+		startAt = 0
+	}
+	if maxResults <= 0 {
+		maxResults = defaultMax
+	}
+	return startAt, maxResults
+}
+
+// pageBounds clamps a page to n items.
+func pageBounds(n, startAt, maxResults int) (start, end int) {
+	start = startAt
+	if start > n {
+		start = n
+	}
+	end = start + maxResults
+	if end > n {
+		end = n
+	}
+	return start, end
+}
@@ -0,0 +1,436 @@
+package jiratest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	jira "github.com/felixgeelhaar/jirasdk"
+	"github.com/felixgeelhaar/jirasdk/core/agile"
+	"github.com/felixgeelhaar/jirasdk/core/issue"
+	"github.com/felixgeelhaar/jirasdk/core/project"
+	"github.com/felixgeelhaar/jirasdk/core/search"
+	"github.com/felixgeelhaar/jirasdk/core/user"
+	"github.com/felixgeelhaar/jirasdk/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer starts a server and returns it with a client for it.
+func newTestServer(t *testing.T) (*Server, *jira.Client) {
+	t.Helper()
+
+	srv := NewServer()
+	t.Cleanup(srv.Close)
+
+	client, err := srv.Client()
+	require.NoError(t, err)
+	return srv, client
+}
+
+// seedIssue adds a task with the given summary to project PROJ.
+func seedIssue(srv *Server, summary string) *issue.Issue {
+	return srv.AddIssue(&issue.Issue{Fields: &issue.IssueFields{
+		Project:   &issue.Project{Key: "PROJ"},
+		Summary:   summary,
+		IssueType: &issue.IssueType{Name: "Task"},
+	}})
+}
+
+// getJSON fetches a path from the server as the test user and decodes the body.
+func getJSON(t *testing.T, srv *Server, path string, v interface{}) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+	require.NoError(t, err)
+	req.SetBasicAuth("jiratest@example.com", "jiratest-token")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+}
+
+func TestServer_IssueLifecycle(t *testing.T) {
+	srv, client := newTestServer(t)
+	ctx := context.Background()
+
+	created, err := client.Issue.Create(ctx, &issue.CreateInput{Fields: &issue.IssueFields{
+		Project:   &issue.Project{Key: "PROJ"},
+		Summary:   "New issue",
+		IssueType: &issue.IssueType{Name: "Bug"},
+		Labels:    []string{"backend"},
+	}})
+	require.Error(t, err, "project must exist before issues can be created over the API")
+	assert.Nil(t, created)
+
+	srv.AddProject(&project.Project{Key: "PROJ", Name: "Project"})
+	created, err = client.Issue.Create(ctx, &issue.CreateInput{Fields: &issue.IssueFields{
+		Project:   &issue.Project{Key: "PROJ"},
+		Summary:   "New issue",
+		IssueType: &issue.IssueType{Name: "Bug"},
+		Labels:    []string{"backend"},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "PROJ-1", created.Key)
+
+	got, err := client.Issue.Get(ctx, "PROJ-1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "New issue", got.GetSummary())
+	assert.Equal(t, "To Do", got.GetStatusName())
+	assert.Equal(t, "PROJ", got.Fields.Project.Key)
+	assert.Equal(t, "jiratest", got.Fields.Reporter.AccountID)
+	assert.NotNil(t, got.Fields.Created)
+
+	err = client.Issue.Update(ctx, "PROJ-1", &issue.UpdateInput{Fields: map[string]interface{}{
+		"summary": "Renamed issue",
+	}})
+	require.NoError(t, err)
+
+	stored, ok := srv.Issue("PROJ-1")
+	require.True(t, ok)
+	assert.Equal(t, "Renamed issue", stored.GetSummary())
+	assert.Equal(t, []string{"backend"}, stored.Fields.Labels)
+
+	require.NoError(t, client.Issue.Delete(ctx, "PROJ-1"))
+	_, ok = srv.Issue("PROJ-1")
+	assert.False(t, ok)
+
+	_, err = client.Issue.Get(ctx, "PROJ-1", nil)
+	assert.True(t, transport.IsNotFound(err))
+}
+
+func TestServer_CreateIssueValidation(t *testing.T) {
+	_, client := newTestServer(t)
+
+	_, err := client.Issue.Create(context.Background(), &issue.CreateInput{Fields: &issue.IssueFields{
+		Project:   &issue.Project{Key: "NOPE"},
+		Summary:   "   ",
+		IssueType: &issue.IssueType{},
+	}})
+	require.Error(t, err)
+	assert.True(t, transport.IsValidation(err))
+
+	var apiErr *transport.ErrorResponse
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, "valid project is required", apiErr.Errors["project"])
+	assert.Equal(t, "You must specify a summary of the issue.", apiErr.Errors["summary"])
+	assert.Equal(t, "Specify an issue type", apiErr.Errors["issuetype"])
+	assert.NotEmpty(t, apiErr.RequestID)
+}
+
+func TestServer_Transitions(t *testing.T) {
+	srv, client := newTestServer(t)
+	ctx := context.Background()
+	seedIssue(srv, "Transition me")
+
+	err := client.Issue.DoTransition(ctx, "PROJ-1", &issue.TransitionInput{Transition: &issue.Transition{ID: "31"}})
+	require.NoError(t, err)
+
+	got, err := client.Issue.Get(ctx, "PROJ-1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Done", got.GetStatusName())
+	require.NotNil(t, got.Fields.Resolution)
+	assert.Equal(t, "Done", got.Fields.Resolution.Name)
+
+	err = client.Issue.DoTransition(ctx, "PROJ-1", &issue.TransitionInput{Transition: &issue.Transition{ID: "99"}})
+	var apiErr *transport.ErrorResponse
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, []string{"Transition id '99' is not valid for this issue."}, apiErr.ErrorMessages)
+}
+
+func TestServer_CommentsAndWorklogs(t *testing.T) {
+	srv, client := newTestServer(t)
+	ctx := context.Background()
+	seedIssue(srv, "Discuss me")
+
+	input := &issue.AddCommentInput{}
+	input.SetBodyText("First!")
+	comment, err := client.Issue.AddComment(ctx, "PROJ-1", input)
+	require.NoError(t, err)
+	assert.Equal(t, "First!", comment.GetBodyText())
+	assert.Equal(t, "jiratest", comment.Author.AccountID)
+
+	update := &issue.UpdateCommentInput{}
+	update.SetBodyText("Edited")
+	_, err = client.Issue.UpdateComment(ctx, "PROJ-1", comment.ID, update)
+	require.NoError(t, err)
+
+	comments, err := client.Issue.ListComments(ctx, "PROJ-1")
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.Equal(t, "Edited", comments[0].GetBodyText())
+
+	require.NoError(t, client.Issue.DeleteComment(ctx, "PROJ-1", comment.ID))
+	err = client.Issue.DeleteComment(ctx, "PROJ-1", comment.ID)
+	assert.True(t, transport.IsNotFound(err))
+
+	worklog, err := client.Issue.AddWorklog(ctx, "PROJ-1", &issue.AddWorklogInput{TimeSpent: "1d 2h"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(10*3600), worklog.TimeSpentSeconds)
+
+	worklogs, err := client.Issue.ListWorklogs(ctx, "PROJ-1", nil)
+	require.NoError(t, err)
+	require.Len(t, worklogs, 1)
+	assert.Equal(t, "1d 2h", worklogs[0].TimeSpent)
+
+	_, err = client.Issue.AddWorklog(ctx, "PROJ-1", &issue.AddWorklogInput{TimeSpent: "a while"})
+	assert.True(t, transport.IsValidation(err))
+}
+
+func TestServer_IssueLinks(t *testing.T) {
+	srv, client := newTestServer(t)
+	ctx := context.Background()
+	seedIssue(srv, "Blocker")
+	seedIssue(srv, "Blocked")
+
+	types, err := client.Issue.ListIssueLinkTypes(ctx)
+	require.NoError(t, err)
+	assert.Len(t, types, 4)
+
+	err = client.Issue.CreateIssueLink(ctx, &issue.CreateIssueLinkInput{
+		Type:         &issue.IssueLinkType{Name: "Blocks"},
+		InwardIssue:  &issue.IssueRef{Key: "PROJ-2"},
+		OutwardIssue: &issue.IssueRef{Key: "PROJ-1"},
+	})
+	require.NoError(t, err)
+
+	var blocker struct {
+		Fields struct {
+			IssueLinks []*issue.IssueLink `json:"issuelinks"`
+		} `json:"fields"`
+	}
+	getJSON(t, srv, "/rest/api/3/issue/PROJ-1?fields=issuelinks", &blocker)
+	require.Len(t, blocker.Fields.IssueLinks, 1)
+	link := blocker.Fields.IssueLinks[0]
+	assert.Equal(t, "blocks", link.Type.Outward)
+	require.NotNil(t, link.InwardIssue)
+	assert.Equal(t, "PROJ-2", link.InwardIssue.Key)
+
+	require.NoError(t, client.Issue.DeleteIssueLink(ctx, link.ID))
+	getJSON(t, srv, "/rest/api/3/issue/PROJ-1?fields=issuelinks", &blocker)
+	assert.Empty(t, blocker.Fields.IssueLinks)
+
+	err = client.Issue.CreateIssueLink(ctx, &issue.CreateIssueLinkInput{
+		Type:         &issue.IssueLinkType{Name: "Blocks"},
+		InwardIssue:  &issue.IssueRef{Key: "PROJ-2"},
+		OutwardIssue: &issue.IssueRef{Key: "PROJ-404"},
+	})
+	assert.True(t, transport.IsNotFound(err))
+}
+
+func TestServer_SearchJQL(t *testing.T) {
+	srv, client := newTestServer(t)
+	ctx := context.Background()
+
+	for i := 1; i <= 5; i++ {
+		seedIssue(srv, fmt.Sprintf("Issue %d", i))
+	}
+	srv.AddIssue(&issue.Issue{Fields: &issue.IssueFields{
+		Project:   &issue.Project{Key: "OTHER"},
+		Summary:   "Elsewhere",
+		IssueType: &issue.IssueType{Name: "Bug"},
+		Labels:    []string{"urgent"},
+	}})
+	require.NoError(t, client.Issue.DoTransition(ctx, "PROJ-2", &issue.TransitionInput{Transition: &issue.Transition{ID: "21"}}))
+
+	tests := []struct {
+		name string
+		jql  string
+		want []string
+	}{
+		{name: "project", jql: "project = OTHER", want: []string{"OTHER-1"}},
+		{name: "quoted status", jql: `project = PROJ AND status = "In Progress"`, want: []string{"PROJ-2"}},
+		{name: "in list", jql: "key IN (PROJ-1, PROJ-3) ORDER BY created DESC", want: []string{"PROJ-1", "PROJ-3"}},
+		{name: "not in", jql: "project = PROJ AND key NOT IN (PROJ-1, PROJ-2, PROJ-3)", want: []string{"PROJ-4", "PROJ-5"}},
+		{name: "contains", jql: "summary ~ elsewhere", want: []string{"OTHER-1"}},
+		{name: "labels empty", jql: "project = OTHER AND labels IS NOT EMPTY", want: []string{"OTHER-1"}},
+		{name: "current user", jql: "reporter = currentUser() AND issuetype = Bug", want: []string{"OTHER-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := client.Search.SearchJQL(ctx, &search.SearchJQLOptions{JQL: tt.jql, Fields: []string{"summary"}})
+			require.NoError(t, err)
+
+			var keys []string
+			for _, i := range result.Issues {
+				keys = append(keys, i.Key)
+			}
+			assert.Equal(t, tt.want, keys)
+		})
+	}
+}
+
+func TestServer_SearchJQLPagination(t *testing.T) {
+	srv, client := newTestServer(t)
+	for i := 1; i <= 5; i++ {
+		seedIssue(srv, fmt.Sprintf("Issue %d", i))
+	}
+
+	var keys []string
+	iter := client.Search.NewSearchJQLIterator(context.Background(), &search.SearchJQLOptions{
+		JQL:        "project = PROJ",
+		MaxResults: 2,
+	})
+	for iter.Next() {
+		keys = append(keys, iter.Issue().Key)
+	}
+	require.NoError(t, iter.Err())
+	assert.Equal(t, []string{"PROJ-1", "PROJ-2", "PROJ-3", "PROJ-4", "PROJ-5"}, keys)
+}
+
+func TestServer_SearchJQLErrors(t *testing.T) {
+	_, client := newTestServer(t)
+
+	tests := []struct {
+		jql  string
+		want string
+	}{
+		{jql: "colour = red", want: "Field 'colour' does not exist or you do not have permission to view it."},
+		{jql: `summary = "unterminated`, want: "Error in the JQL Query"},
+		{jql: "project = PROJ OR project = OTHER", want: "Error in the JQL Query"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.jql, func(t *testing.T) {
+			_, err := client.Search.SearchJQL(context.Background(), &search.SearchJQLOptions{JQL: tt.jql})
+
+			var apiErr *transport.ErrorResponse
+			require.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+			require.NotEmpty(t, apiErr.ErrorMessages)
+			assert.Contains(t, apiErr.ErrorMessages[0], tt.want)
+		})
+	}
+}
+
+func TestServer_ProjectsAndVersions(t *testing.T) {
+	srv, client := newTestServer(t)
+	ctx := context.Background()
+
+	_, err := client.Project.Create(ctx, &project.CreateInput{Key: "NEW", Name: "New project", ProjectTypeKey: "software"})
+	require.NoError(t, err)
+	srv.AddProject(&project.Project{Key: "SEED", Name: "Seeded"})
+
+	projects, err := client.Project.List(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, projects, 2)
+	assert.Equal(t, "NEW", projects[0].Key)
+
+	_, err = client.Project.Create(ctx, &project.CreateInput{Key: "NEW", Name: "Duplicate", ProjectTypeKey: "software"})
+	assert.True(t, transport.IsValidation(err))
+
+	version, err := client.Project.CreateVersion(ctx, &project.CreateVersionInput{Name: "1.0", Project: "NEW"})
+	require.NoError(t, err)
+	srv.AddVersion("NEW", &project.Version{Name: "2.0"})
+
+	released := true
+	_, err = client.Project.UpdateVersion(ctx, version.ID, &project.UpdateVersionInput{Released: &released})
+	require.NoError(t, err)
+
+	versions, err := client.Project.ListProjectVersions(ctx, "NEW")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.True(t, versions[0].Released)
+
+	require.NoError(t, client.Project.DeleteVersion(ctx, version.ID))
+	_, err = client.Project.GetVersion(ctx, version.ID)
+	assert.True(t, transport.IsNotFound(err))
+
+	require.NoError(t, client.Project.Delete(ctx, "NEW"))
+	_, err = client.Project.Get(ctx, "NEW", nil)
+	assert.True(t, transport.IsNotFound(err))
+}
+
+func TestServer_Users(t *testing.T) {
+	srv, client := newTestServer(t)
+	ctx := context.Background()
+
+	srv.AddUser(&user.User{AccountID: "alice", DisplayName: "Alice Example", EmailAddress: "alice@example.com", Active: true})
+
+	me, err := client.User.GetMyself(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "jiratest", me.AccountID)
+
+	srv.SetCurrentUser("alice")
+	me, err = client.User.GetMyself(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice Example", me.DisplayName)
+
+	found, err := client.User.Search(ctx, &user.SearchOptions{Query: "alice"})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "alice", found[0].AccountID)
+
+	_, err = client.User.Get(ctx, "nobody", nil)
+	assert.True(t, transport.IsNotFound(err))
+}
+
+func TestServer_BoardsAndSprints(t *testing.T) {
+	srv, client := newTestServer(t)
+	ctx := context.Background()
+	seedIssue(srv, "In sprint")
+	seedIssue(srv, "In backlog")
+
+	board := srv.AddBoard(&agile.Board{Name: "PROJ board", Location: &agile.Location{ProjectKey: "PROJ"}})
+	srv.AddBoard(&agile.Board{Name: "Kanban", Type: "kanban"})
+
+	boards, err := client.Agile.GetBoards(ctx, &agile.BoardsOptions{Type: "scrum"})
+	require.NoError(t, err)
+	require.Len(t, boards, 1)
+	assert.Equal(t, board.ID, boards[0].ID)
+
+	sprint, err := client.Agile.CreateSprint(ctx, &agile.CreateSprintInput{Name: "Sprint 1", OriginBoardID: board.ID})
+	require.NoError(t, err)
+	assert.Equal(t, "future", sprint.State)
+
+	_, err = client.Agile.UpdateSprint(ctx, sprint.ID, &agile.UpdateSprintInput{State: "active"})
+	var apiErr *transport.ErrorResponse
+	require.True(t, errors.As(err, &apiErr), "starting a sprint requires dates")
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+
+	sprint, err = client.Agile.UpdateSprint(ctx, sprint.ID, &agile.UpdateSprintInput{
+		State:     "active",
+		StartDate: "2024-01-01T00:00:00.000Z",
+		EndDate:   "2024-01-14T00:00:00.000Z",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "active", sprint.State)
+	assert.Equal(t, "Sprint 1", sprint.Name)
+
+	require.NoError(t, client.Agile.MoveIssuesToSprint(ctx, sprint.ID, &agile.MoveIssuesToSprintInput{Issues: []string{"PROJ-1"}}))
+
+	backlog, err := client.Agile.GetBacklog(ctx, board.ID, nil)
+	require.NoError(t, err)
+	require.Len(t, backlog, 1)
+	assert.Equal(t, "PROJ-2", backlog[0].(map[string]interface{})["key"])
+
+	result, err := client.Search.SearchJQL(ctx, &search.SearchJQLOptions{JQL: `sprint = "Sprint 1"`})
+	require.NoError(t, err)
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, "PROJ-1", result.Issues[0].Key)
+
+	sprints, err := client.Agile.GetBoardSprints(ctx, board.ID, &agile.SprintsOptions{State: "active"})
+	require.NoError(t, err)
+	assert.Len(t, sprints, 1)
+
+	_, err = client.Agile.GetBoard(ctx, 999)
+	assert.True(t, transport.IsNotFound(err))
+}
+
+func TestServer_RequiresAuthentication(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	resp, err := http.Get(srv.URL + "/rest/api/3/myself")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
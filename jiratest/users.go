@@ -0,0 +1,105 @@
+package jiratest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/felixgeelhaar/jirasdk/core/user"
+)
+
+// AddUser stores a user and returns it with its self link filled in.
+func (s *Server) AddUser(u *user.User) *user.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u.AccountID == "" {
+		panic("jiratest: user account ID is required")
+	}
+	if s.userLocked(u.AccountID) != nil {
+		panic(fmt.Sprintf("jiratest: user %s already exists", u.AccountID))
+	}
+
+	stored := *u
+	if stored.AccountType == "" {
+		stored.AccountType = "atlassian"
+	}
+	stored.Self = s.selfURL("/rest/api/3/user?accountId=%s", stored.AccountID)
+	s.users = append(s.users, &stored)
+
+	return &stored
+}
+
+// userLocked finds a user by account ID. s.mu must be held.
+func (s *Server) userLocked(accountID string) *user.User {
+	for _, u := range s.users {
+		if u.AccountID == accountID {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *Server) registerUserRoutes() {
+	s.handle(http.MethodGet, "/rest/api/3/user", s.getUser)
+	s.handle(http.MethodGet, "/rest/api/3/user/search", s.searchUsers)
+	s.handle(http.MethodGet, "/rest/api/3/myself", s.getMyself)
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	accountID := r.URL.Query().Get("accountId")
+	if accountID == "" {
+		writeError(w, http.StatusBadRequest, "The query parameter 'accountId' is required.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.userLocked(accountID)
+	if u == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Specified user does not exist or you do not have required permissions: %s", accountID))
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+func (s *Server) searchUsers(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	startAt, maxResults := pageParams(r, 50)
+	query := strings.ToLower(r.URL.Query().Get("query"))
+	accountID := r.URL.Query().Get("accountId")
+	if query == "" && accountID == "" {
+		writeError(w, http.StatusBadRequest, "One of 'query' or 'accountId' query parameters must be provided.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matched := []*user.User{}
+	for _, u := range s.users {
+		if accountID != "" && u.AccountID != accountID {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(u.DisplayName), query) &&
+			!strings.HasPrefix(strings.ToLower(u.EmailAddress), query) {
+			continue
+		}
+		matched = append(matched, u)
+	}
+
+	start, end := pageBounds(len(matched), startAt, maxResults)
+	writeJSON(w, http.StatusOK, matched[start:end])
+}
+
+func (s *Server) getMyself(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.userLocked(s.currentUser)
+	if u == nil {
+		writeError(w, http.StatusUnauthorized, "You are not authenticated. Authentication required to perform this operation.")
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
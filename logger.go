@@ -3,6 +3,8 @@ package jirasdk
 import (
 	"context"
 	"time"
+
+	"github.com/felixgeelhaar/jirasdk/transport"
 )
 
 // Logger is the interface for structured logging.
@@ -82,3 +84,37 @@ func (n *noopLogger) With(fields ...Field) Logger                            { r
 func NewNoopLogger() Logger {
 	return &noopLogger{}
 }
+
+// transportLogger adapts a Logger to the transport.Logger interface, whose
+// fields use the transport package's Field type.
+type transportLogger struct {
+	logger Logger
+}
+
+func (t transportLogger) Debug(ctx context.Context, msg string, fields ...transport.Field) {
+	t.logger.Debug(ctx, msg, fromTransportFields(fields)...)
+}
+
+func (t transportLogger) Info(ctx context.Context, msg string, fields ...transport.Field) {
+	t.logger.Info(ctx, msg, fromTransportFields(fields)...)
+}
+
+func (t transportLogger) Warn(ctx context.Context, msg string, fields ...transport.Field) {
+	t.logger.Warn(ctx, msg, fromTransportFields(fields)...)
+}
+
+func (t transportLogger) Error(ctx context.Context, msg string, fields ...transport.Field) {
+	t.logger.Error(ctx, msg, fromTransportFields(fields)...)
+}
+
+func (t transportLogger) With(fields ...transport.Field) transport.Logger {
+	return transportLogger{logger: t.logger.With(fromTransportFields(fields)...)}
+}
+
+func fromTransportFields(fields []transport.Field) []Field {
+	result := make([]Field, len(fields))
+	for i, f := range fields {
+		result[i] = Field{Key: f.Key, Value: f.Value}
+	}
+	return result
+}
@@ -0,0 +1,32 @@
+package jirasdk
+
+import (
+	"context"
+
+	"github.com/felixgeelhaar/jirasdk/transport"
+)
+
+// Metrics is the interface for recording client-side metrics.
+// This allows users to plug in their own metrics backend or use the provided
+// OpenTelemetry implementation in metrics/otel.
+//
+// RecordRequest is called once per API call, after all retries and rate limit
+// waits, including calls served from the response cache.
+type Metrics interface {
+	// RecordRequest records the outcome of one API call
+	RecordRequest(ctx context.Context, m RequestMetrics)
+}
+
+// RequestMetrics describes one API call: its labels (service, operation,
+// endpoint template and status class), duration, retries and rate limit wait.
+type RequestMetrics = transport.RequestMetrics
+
+// noopMetrics is a metrics implementation that does nothing
+type noopMetrics struct{}
+
+func (n *noopMetrics) RecordRequest(ctx context.Context, m RequestMetrics) {}
+
+// NewNoopMetrics creates a metrics implementation that does nothing.
+func NewNoopMetrics() Metrics {
+	return &noopMetrics{}
+}
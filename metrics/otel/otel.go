@@ -0,0 +1,151 @@
+// Package otel provides an OpenTelemetry metrics implementation for jirasdk.
+//
+// It records request counts, error counts, a latency histogram, retries and
+// rate limit wait time for every API call, labelled by Jira service,
+// operation, endpoint template and status class.
+//
+// Example usage:
+//
+//	metrics, err := otelmetrics.New(meterProvider)
+//	if err != nil {
+//		return err
+//	}
+//	client, err := jira.NewClient(
+//		jira.WithBaseURL("https://your-domain.atlassian.net"),
+//		jira.WithAPIToken("email", "token"),
+//		jira.WithMetrics(metrics),
+//	)
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	jira "github.com/felixgeelhaar/jirasdk"
+	otelglobal "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// MeterName is the instrumentation scope name of the meter.
+const MeterName = "github.com/felixgeelhaar/jirasdk"
+
+// Instrument names.
+const (
+	// MetricRequests counts API calls
+	MetricRequests = "jira.client.requests"
+
+	// MetricErrors counts API calls that failed or ended with a 4xx or 5xx status
+	MetricErrors = "jira.client.errors"
+
+	// MetricDuration is a histogram of API call durations in seconds
+	MetricDuration = "jira.client.request.duration"
+
+	// MetricRetries counts retries
+	MetricRetries = "jira.client.retries"
+
+	// MetricRateLimitWait is the total time spent waiting for rate limits, in seconds
+	MetricRateLimitWait = "jira.client.rate_limit.wait"
+)
+
+// Attribute keys used as labels on every instrument.
+const (
+	// AttrService is the Jira service, e.g. "issue" or "agile"
+	AttrService = attribute.Key("jira.service")
+
+	// AttrOperation is the HTTP method
+	AttrOperation = attribute.Key("jira.operation")
+
+	// AttrEndpoint is the endpoint template, e.g. "/rest/api/3/issue/{issueIdOrKey}"
+	AttrEndpoint = attribute.Key("url.template")
+
+	// AttrStatusClass is "2xx", "3xx", "4xx", "5xx" or "error"
+	AttrStatusClass = attribute.Key("jira.status_class")
+)
+
+// durationBuckets are the histogram boundaries in seconds, from 5ms to 30s.
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Metrics records jirasdk request metrics with OpenTelemetry instruments.
+type Metrics struct {
+	requests      metric.Int64Counter
+	errors        metric.Int64Counter
+	duration      metric.Float64Histogram
+	retries       metric.Int64Counter
+	rateLimitWait metric.Float64Counter
+}
+
+var _ jira.Metrics = (*Metrics)(nil)
+
+// New creates the instruments on a meter from provider.
+//
+// A nil provider uses the global meter provider from otel.GetMeterProvider.
+func New(provider metric.MeterProvider) (*Metrics, error) {
+	if provider == nil {
+		provider = otelglobal.GetMeterProvider()
+	}
+	meter := provider.Meter(MeterName)
+
+	m := &Metrics{}
+	var err error
+
+	if m.requests, err = meter.Int64Counter(MetricRequests,
+		metric.WithDescription("Number of Jira API calls."),
+		metric.WithUnit("{request}"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create %s counter: %w", MetricRequests, err)
+	}
+
+	if m.errors, err = meter.Int64Counter(MetricErrors,
+		metric.WithDescription("Number of Jira API calls that failed or returned a 4xx or 5xx status."),
+		metric.WithUnit("{request}"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create %s counter: %w", MetricErrors, err)
+	}
+
+	if m.duration, err = meter.Float64Histogram(MetricDuration,
+		metric.WithDescription("Duration of Jira API calls, including retries and rate limit waits."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create %s histogram: %w", MetricDuration, err)
+	}
+
+	if m.retries, err = meter.Int64Counter(MetricRetries,
+		metric.WithDescription("Number of retried Jira API requests."),
+		metric.WithUnit("{retry}"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create %s counter: %w", MetricRetries, err)
+	}
+
+	if m.rateLimitWait, err = meter.Float64Counter(MetricRateLimitWait,
+		metric.WithDescription("Time spent waiting for Jira rate limits."),
+		metric.WithUnit("s"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create %s counter: %w", MetricRateLimitWait, err)
+	}
+
+	return m, nil
+}
+
+// RecordRequest records the outcome of one API call.
+func (m *Metrics) RecordRequest(ctx context.Context, rm jira.RequestMetrics) {
+	attrs := metric.WithAttributes(
+		AttrService.String(rm.Service),
+		AttrOperation.String(rm.Operation),
+		AttrEndpoint.String(rm.Endpoint),
+		AttrStatusClass.String(rm.StatusClass),
+	)
+
+	m.requests.Add(ctx, 1, attrs)
+	if rm.Failed() {
+		m.errors.Add(ctx, 1, attrs)
+	}
+	m.duration.Record(ctx, rm.Duration.Seconds(), attrs)
+	if rm.Retries > 0 {
+		m.retries.Add(ctx, int64(rm.Retries), attrs)
+	}
+	if rm.RateLimitWait > 0 {
+		m.rateLimitWait.Add(ctx, rm.RateLimitWait.Seconds(), attrs)
+	}
+}
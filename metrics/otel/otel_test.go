@@ -0,0 +1,119 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	jira "github.com/felixgeelhaar/jirasdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// collect reads all metrics from reader, keyed by instrument name.
+func collect(t *testing.T, reader sdkmetric.Reader) map[string]metricdata.Aggregation {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	out := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		assert.Equal(t, MeterName, sm.Scope.Name)
+		for _, m := range sm.Metrics {
+			out[m.Name] = m.Data
+		}
+	}
+	return out
+}
+
+func TestMetrics_RecordRequest(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	metrics, err := New(provider)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	metrics.RecordRequest(ctx, jira.RequestMetrics{
+		Service:       "issue",
+		Operation:     "GET",
+		Endpoint:      "/rest/api/3/issue/{issueIdOrKey}",
+		StatusClass:   "2xx",
+		StatusCode:    200,
+		Duration:      120 * time.Millisecond,
+		Retries:       2,
+		RateLimitWait: 1500 * time.Millisecond,
+	})
+	metrics.RecordRequest(ctx, jira.RequestMetrics{
+		Service:     "issue",
+		Operation:   "GET",
+		Endpoint:    "/rest/api/3/issue/{issueIdOrKey}",
+		StatusClass: "2xx",
+		StatusCode:  200,
+		Duration:    80 * time.Millisecond,
+	})
+	metrics.RecordRequest(ctx, jira.RequestMetrics{
+		Service:     "search",
+		Operation:   "POST",
+		Endpoint:    "/rest/api/3/search/jql",
+		StatusClass: "error",
+		Duration:    time.Second,
+		Err:         errors.New("connection reset"),
+	})
+
+	data := collect(t, reader)
+
+	okAttrs := attribute.NewSet(
+		AttrService.String("issue"),
+		AttrOperation.String("GET"),
+		AttrEndpoint.String("/rest/api/3/issue/{issueIdOrKey}"),
+		AttrStatusClass.String("2xx"),
+	)
+
+	requests := data[MetricRequests].(metricdata.Sum[int64])
+	require.Len(t, requests.DataPoints, 2)
+	for _, dp := range requests.DataPoints {
+		if dp.Attributes.Equals(&okAttrs) {
+			assert.Equal(t, int64(2), dp.Value)
+		} else {
+			assert.Equal(t, int64(1), dp.Value)
+		}
+	}
+
+	errs := data[MetricErrors].(metricdata.Sum[int64])
+	require.Len(t, errs.DataPoints, 1)
+	status, _ := errs.DataPoints[0].Attributes.Value(AttrStatusClass)
+	assert.Equal(t, "error", status.AsString())
+
+	duration := data[MetricDuration].(metricdata.Histogram[float64])
+	require.Len(t, duration.DataPoints, 2)
+	for _, dp := range duration.DataPoints {
+		if dp.Attributes.Equals(&okAttrs) {
+			assert.Equal(t, uint64(2), dp.Count)
+			assert.InDelta(t, 0.2, dp.Sum, 1e-9)
+			assert.Equal(t, durationBuckets, dp.Bounds)
+		}
+	}
+
+	retries := data[MetricRetries].(metricdata.Sum[int64])
+	require.Len(t, retries.DataPoints, 1)
+	assert.Equal(t, int64(2), retries.DataPoints[0].Value)
+
+	wait := data[MetricRateLimitWait].(metricdata.Sum[float64])
+	require.Len(t, wait.DataPoints, 1)
+	assert.InDelta(t, 1.5, wait.DataPoints[0].Value, 1e-9)
+}
+
+func TestNew_NilProvider(t *testing.T) {
+	metrics, err := New(nil)
+	require.NoError(t, err)
+
+	assert.NotPanics(t, func() {
+		metrics.RecordRequest(context.Background(), jira.RequestMetrics{Service: "issue", StatusClass: "2xx"})
+	})
+}
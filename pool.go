@@ -0,0 +1,411 @@
+package jirasdk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/felixgeelhaar/jirasdk/auth"
+	"github.com/felixgeelhaar/jirasdk/transport"
+)
+
+// DefaultPoolIdleTimeout is how long a tenant's client may stay unused before
+// the pool evicts it.
+const DefaultPoolIdleTimeout = 30 * time.Minute
+
+// TenantConfig describes how to reach one tenant's Jira site.
+type TenantConfig struct {
+	// BaseURL is the tenant's Jira URL (e.g., https://customer.atlassian.net)
+	BaseURL string
+
+	// Authenticator authenticates requests to the tenant's site
+	Authenticator auth.Authenticator
+
+	// Options are additional client options for this tenant only
+	Options []Option
+}
+
+// TenantConfigProvider supplies the configuration of a tenant.
+//
+// The pool calls TenantConfig once per tenant when it builds the tenant's
+// client, and again only after the client has been evicted.
+type TenantConfigProvider interface {
+	TenantConfig(ctx context.Context, tenantID string) (*TenantConfig, error)
+}
+
+// TenantConfigProviderFunc adapts a function to a TenantConfigProvider.
+type TenantConfigProviderFunc func(ctx context.Context, tenantID string) (*TenantConfig, error)
+
+// TenantConfig calls f(ctx, tenantID).
+func (f TenantConfigProviderFunc) TenantConfig(ctx context.Context, tenantID string) (*TenantConfig, error) {
+	return f(ctx, tenantID)
+}
+
+// Pool lazily builds and caches one Client per tenant.
+//
+// All clients share one http.Transport, and therefore one connection pool.
+// Each client gets its own rate limit governor, so one tenant exhausting its
+// quota does not slow down the others, and its own Resilience from the
+// factory set with WithPoolResilience, so circuit breakers trip per tenant.
+// Clients unused for longer than the idle timeout are evicted.
+//
+// A Pool is safe for concurrent use.
+//
+// Example:
+//
+//	pool, err := jira.NewPool(jira.TenantConfigProviderFunc(
+//		func(ctx context.Context, tenantID string) (*jira.TenantConfig, error) {
+//			site, err := store.Site(ctx, tenantID)
+//			if err != nil {
+//				return nil, err
+//			}
+//			return &jira.TenantConfig{
+//				BaseURL:       site.URL,
+//				Authenticator: auth.NewAPITokenAuth(site.Email, site.Token),
+//			}, nil
+//		}),
+//		jira.WithPoolResilience(func(string) jira.Resilience {
+//			return fortify.NewAdapter(jira.DefaultResilienceConfig())
+//		}),
+//	)
+//	defer pool.Close()
+//
+//	client, err := pool.Client(ctx, "tenant-42")
+type Pool struct {
+	provider      TenantConfigProvider
+	options       []Option
+	newResilience func(tenantID string) Resilience
+	idleTimeout   time.Duration
+	transport     *http.Transport
+	timeout       time.Duration
+
+	mu      sync.Mutex
+	clients map[string]*poolEntry
+	stop    chan struct{}
+	closed  bool
+}
+
+// poolEntry is a tenant's client, or the build in progress.
+type poolEntry struct {
+	ready      chan struct{} // closed once client or err is set
+	client     *Client
+	resilience Resilience // created by the pool for client, if any
+	err        error
+	lastUsed   atomic.Int64 // unix nanoseconds
+}
+
+// close releases the resources the pool created for the entry's client. An
+// entry still building is closed once its build is done.
+func (e *poolEntry) close() {
+	select {
+	case <-e.ready:
+	default:
+		go func() {
+			<-e.ready
+			e.close()
+		}()
+		return
+	}
+	if closer, ok := e.resilience.(io.Closer); ok {
+		_ = closer.Close() // Explicit ignore, the client is no longer pooled
+	}
+}
+
+// PoolOption is a functional option for configuring a Pool.
+type PoolOption func(*Pool) error
+
+// NewPool creates a pool that builds clients from provider's configuration.
+//
+// Example:
+//
+//	pool, err := NewPool(provider, WithPoolIdleTimeout(time.Hour))
+func NewPool(provider TenantConfigProvider, opts ...PoolOption) (*Pool, error) {
+	if provider == nil {
+		return nil, fmt.Errorf("tenant config provider is required")
+	}
+
+	p := &Pool{
+		provider:    provider,
+		idleTimeout: DefaultPoolIdleTimeout,
+		timeout:     DefaultTimeout,
+		clients:     make(map[string]*poolEntry),
+		stop:        make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			return nil, fmt.Errorf("failed to apply pool option: %w", err)
+		}
+	}
+
+	if p.transport == nil {
+		p.transport = http.DefaultTransport.(*http.Transport).Clone()
+		p.transport.MaxIdleConnsPerHost = 8
+	}
+
+	if p.idleTimeout > 0 {
+		go p.evictLoop()
+	}
+
+	return p, nil
+}
+
+// WithPoolClientOptions sets options applied to every tenant's client, before
+// the tenant's own options.
+//
+// Example:
+//
+//	WithPoolClientOptions(WithMaxRetries(5), WithLogger(logger))
+func WithPoolClientOptions(opts ...Option) PoolOption {
+	return func(p *Pool) error {
+		p.options = append(p.options, opts...)
+		return nil
+	}
+}
+
+// WithPoolResilience sets a factory that creates the Resilience for each
+// tenant, so that every tenant gets its own circuit breaker and bulkhead.
+//
+// Example:
+//
+//	WithPoolResilience(func(tenantID string) Resilience {
+//		return fortify.NewAdapter(DefaultResilienceConfig())
+//	})
+func WithPoolResilience(factory func(tenantID string) Resilience) PoolOption {
+	return func(p *Pool) error {
+		if factory == nil {
+			return fmt.Errorf("resilience factory cannot be nil")
+		}
+		p.newResilience = factory
+		return nil
+	}
+}
+
+// WithPoolIdleTimeout sets how long an unused client is kept. The default is
+// DefaultPoolIdleTimeout; zero disables eviction.
+//
+// Example:
+//
+//	WithPoolIdleTimeout(time.Hour)
+func WithPoolIdleTimeout(timeout time.Duration) PoolOption {
+	return func(p *Pool) error {
+		if timeout < 0 {
+			return fmt.Errorf("idle timeout must be non-negative")
+		}
+		p.idleTimeout = timeout
+		return nil
+	}
+}
+
+// WithPoolHTTPTransport sets the http.Transport shared by all tenants.
+//
+// By default a clone of http.DefaultTransport is used.
+//
+// Example:
+//
+//	WithPoolHTTPTransport(&http.Transport{MaxIdleConnsPerHost: 4})
+func WithPoolHTTPTransport(rt *http.Transport) PoolOption {
+	return func(p *Pool) error {
+		if rt == nil {
+			return fmt.Errorf("HTTP transport cannot be nil")
+		}
+		p.transport = rt
+		return nil
+	}
+}
+
+// WithPoolTimeout sets the HTTP timeout of every tenant's client.
+//
+// Example:
+//
+//	WithPoolTimeout(60 * time.Second)
+func WithPoolTimeout(timeout time.Duration) PoolOption {
+	return func(p *Pool) error {
+		if timeout <= 0 {
+			return fmt.Errorf("timeout must be positive")
+		}
+		p.timeout = timeout
+		return nil
+	}
+}
+
+// Client returns the client for tenantID, building it on first use.
+//
+// Concurrent calls for the same tenant share one build. The build does not
+// stop when the caller that started it gives up; every caller waits until the
+// build is done or its own ctx is. A failed build is not cached, so the next
+// call asks the provider again.
+func (p *Pool) Client(ctx context.Context, tenantID string) (*Client, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("pool is closed")
+	}
+	entry, ok := p.clients[tenantID]
+	if !ok {
+		entry = &poolEntry{ready: make(chan struct{})}
+		// Count the entry as used from creation, so idle eviction cannot
+		// remove it between the end of the build and the use recorded below
+		entry.lastUsed.Store(time.Now().UnixNano())
+		p.clients[tenantID] = entry
+	}
+	p.mu.Unlock()
+
+	if !ok {
+		buildCtx := context.WithoutCancel(ctx)
+		go func() {
+			entry.client, entry.resilience, entry.err = p.build(buildCtx, tenantID)
+			close(entry.ready)
+
+			if entry.err != nil {
+				p.mu.Lock()
+				if p.clients[tenantID] == entry {
+					delete(p.clients, tenantID)
+				}
+				p.mu.Unlock()
+			}
+		}()
+	}
+
+	select {
+	case <-entry.ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if entry.err != nil {
+		return nil, entry.err
+	}
+	entry.lastUsed.Store(time.Now().UnixNano())
+	return entry.client, nil
+}
+
+// build creates the client for tenantID, and the Resilience it created for it.
+func (p *Pool) build(ctx context.Context, tenantID string) (*Client, Resilience, error) {
+	tenant, err := p.provider.TenantConfig(ctx, tenantID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get config for tenant %q: %w", tenantID, err)
+	}
+	if tenant == nil {
+		return nil, nil, fmt.Errorf("no config for tenant %q", tenantID)
+	}
+
+	opts := []Option{
+		WithHTTPClient(&http.Client{Transport: p.transport, Timeout: p.timeout}),
+	}
+	opts = append(opts, p.options...)
+	opts = append(opts, tenant.Options...)
+	opts = append(opts,
+		WithBaseURL(tenant.BaseURL),
+		WithAuthenticator(tenant.Authenticator),
+		WithRateLimitGovernor(transport.NewRateLimitGovernor()),
+	)
+	var resilience Resilience
+	if p.newResilience != nil {
+		resilience = p.newResilience(tenantID)
+		opts = append(opts, WithResilience(resilience))
+	}
+
+	client, err := NewClient(opts...)
+	if err != nil {
+		if closer, ok := resilience.(io.Closer); ok {
+			_ = closer.Close() // Explicit ignore, the build already failed
+		}
+		return nil, nil, fmt.Errorf("failed to create client for tenant %q: %w", tenantID, err)
+	}
+	return client, resilience, nil
+}
+
+// Evict removes the client for tenantID, for example after its credentials
+// changed. The next call to Client builds a new one. The Resilience created
+// for the client by the WithPoolResilience factory is closed if it implements
+// io.Closer.
+func (p *Pool) Evict(tenantID string) {
+	p.mu.Lock()
+	entry, ok := p.clients[tenantID]
+	delete(p.clients, tenantID)
+	p.mu.Unlock()
+
+	if ok {
+		entry.close()
+	}
+}
+
+// Len returns the number of cached clients.
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.clients)
+}
+
+// Close evicts all clients, stops idle eviction and closes idle connections.
+// Like Evict, it closes the Resilience of every evicted client, so clients
+// handed out by the pool should not be kept beyond it.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	evicted := p.clients
+	p.clients = make(map[string]*poolEntry)
+	close(p.stop)
+	p.mu.Unlock()
+
+	for _, entry := range evicted {
+		entry.close()
+	}
+	p.transport.CloseIdleConnections()
+}
+
+// evictLoop evicts idle clients until the pool is closed.
+func (p *Pool) evictLoop() {
+	interval := p.idleTimeout / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case now := <-ticker.C:
+			p.evictIdle(now)
+		}
+	}
+}
+
+// evictIdle removes clients last used more than the idle timeout before now.
+func (p *Pool) evictIdle(now time.Time) {
+	cutoff := now.Add(-p.idleTimeout).UnixNano()
+
+	var evicted []*poolEntry
+	p.mu.Lock()
+	for tenantID, entry := range p.clients {
+		select {
+		case <-entry.ready:
+		default:
+			continue // still building
+		}
+		if entry.lastUsed.Load() < cutoff {
+			delete(p.clients, tenantID)
+			evicted = append(evicted, entry)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, entry := range evicted {
+		entry.close()
+	}
+}
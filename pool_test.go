@@ -0,0 +1,291 @@
+package jirasdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/jirasdk/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingProvider returns a config for known tenants and counts the lookups.
+type countingProvider struct {
+	baseURL string
+	calls   atomic.Int32
+}
+
+func (p *countingProvider) TenantConfig(_ context.Context, tenantID string) (*TenantConfig, error) {
+	p.calls.Add(1)
+	if tenantID == "unknown" {
+		return nil, errors.New("tenant not found")
+	}
+	return &TenantConfig{
+		BaseURL:       p.baseURL,
+		Authenticator: auth.NewPATAuth("token-" + tenantID),
+	}, nil
+}
+
+func TestNewPool(t *testing.T) {
+	_, err := NewPool(nil)
+	assert.Error(t, err)
+
+	_, err = NewPool(&countingProvider{}, WithPoolIdleTimeout(-time.Second))
+	assert.Error(t, err)
+
+	_, err = NewPool(&countingProvider{}, WithPoolResilience(nil))
+	assert.Error(t, err)
+}
+
+func TestPool_Client(t *testing.T) {
+	var tokens sync.Map
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens.Store(r.Header.Get("Authorization"), true)
+		_, _ = w.Write([]byte(`{"accountId":"abc"}`))
+	}))
+	defer server.Close()
+
+	provider := &countingProvider{baseURL: server.URL}
+	var resilienceFor []string
+	var mu sync.Mutex
+	pool, err := NewPool(provider,
+		WithPoolClientOptions(WithMaxRetries(1)),
+		WithPoolResilience(func(tenantID string) Resilience {
+			mu.Lock()
+			resilienceFor = append(resilienceFor, tenantID)
+			mu.Unlock()
+			return NewNoopResilience()
+		}),
+	)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	ctx := context.Background()
+
+	// Concurrent first use builds the client once
+	var wg sync.WaitGroup
+	clients := make([]*Client, 10)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c, err := pool.Client(ctx, "acme")
+			assert.NoError(t, err)
+			clients[i] = c
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), provider.calls.Load())
+	for _, c := range clients {
+		assert.Same(t, clients[0], c)
+	}
+
+	other, err := pool.Client(ctx, "globex")
+	require.NoError(t, err)
+	assert.NotSame(t, clients[0], other)
+	assert.Equal(t, 2, pool.Len())
+	assert.ElementsMatch(t, []string{"acme", "globex"}, resilienceFor)
+
+	// All tenants share one connection pool
+	assert.Same(t, clients[0].HTTPClient.Transport, other.HTTPClient.Transport)
+
+	_, err = clients[0].Myself.Get(ctx)
+	require.NoError(t, err)
+	_, err = other.Myself.Get(ctx)
+	require.NoError(t, err)
+
+	_, ok := tokens.Load("Bearer token-acme")
+	assert.True(t, ok)
+	_, ok = tokens.Load("Bearer token-globex")
+	assert.True(t, ok)
+}
+
+func TestPool_ProviderError(t *testing.T) {
+	provider := &countingProvider{baseURL: "https://example.atlassian.net"}
+	pool, err := NewPool(provider)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	for i := 0; i < 2; i++ {
+		_, err = pool.Client(context.Background(), "unknown")
+		assert.ErrorContains(t, err, "tenant not found")
+	}
+	assert.Equal(t, int32(2), provider.calls.Load(), "failed builds are not cached")
+	assert.Equal(t, 0, pool.Len())
+
+	_, err = pool.Client(context.Background(), "")
+	assert.Error(t, err)
+}
+
+func TestPool_Eviction(t *testing.T) {
+	provider := &countingProvider{baseURL: "https://example.atlassian.net"}
+	pool, err := NewPool(provider, WithPoolIdleTimeout(time.Minute))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	ctx := context.Background()
+	first, err := pool.Client(ctx, "acme")
+	require.NoError(t, err)
+	_, err = pool.Client(ctx, "globex")
+	require.NoError(t, err)
+
+	pool.evictIdle(time.Now().Add(30 * time.Second))
+	assert.Equal(t, 2, pool.Len())
+
+	pool.evictIdle(time.Now().Add(2 * time.Minute))
+	assert.Equal(t, 0, pool.Len())
+
+	rebuilt, err := pool.Client(ctx, "acme")
+	require.NoError(t, err)
+	assert.NotSame(t, first, rebuilt)
+
+	pool.Evict("acme")
+	assert.Equal(t, 0, pool.Len())
+	assert.Equal(t, int32(3), provider.calls.Load())
+}
+
+// probeProvider calls probe before returning a config.
+type probeProvider struct {
+	countingProvider
+	probe func(tenantID string)
+}
+
+func (p *probeProvider) TenantConfig(ctx context.Context, tenantID string) (*TenantConfig, error) {
+	p.probe(tenantID)
+	return p.countingProvider.TenantConfig(ctx, tenantID)
+}
+
+func TestPool_NewEntryCountsAsUsed(t *testing.T) {
+	provider := &probeProvider{countingProvider: countingProvider{baseURL: "https://example.atlassian.net"}}
+	pool, err := NewPool(provider, WithPoolIdleTimeout(time.Minute))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	var lastUsed int64
+	provider.probe = func(tenantID string) {
+		pool.mu.Lock()
+		defer pool.mu.Unlock()
+		lastUsed = pool.clients[tenantID].lastUsed.Load()
+	}
+
+	before := time.Now()
+	_, err = pool.Client(context.Background(), "acme")
+	require.NoError(t, err)
+
+	// Without a creation time the entry would be older than any cutoff once
+	// its build finished, until the caller recorded its use
+	assert.GreaterOrEqual(t, lastUsed, before.UnixNano())
+	pool.evictIdle(time.Now())
+	assert.Equal(t, 1, pool.Len())
+}
+
+func TestPool_Close(t *testing.T) {
+	pool, err := NewPool(&countingProvider{baseURL: "https://example.atlassian.net"})
+	require.NoError(t, err)
+
+	_, err = pool.Client(context.Background(), "acme")
+	require.NoError(t, err)
+
+	pool.Close()
+	pool.Close()
+
+	assert.Equal(t, 0, pool.Len())
+	_, err = pool.Client(context.Background(), "acme")
+	assert.Error(t, err)
+}
+
+// closingResilience counts how often it is closed.
+type closingResilience struct {
+	noopResilience
+	closed atomic.Int32
+}
+
+func (r *closingResilience) Close() error {
+	r.closed.Add(1)
+	return nil
+}
+
+func TestPool_ClosesEvictedResilience(t *testing.T) {
+	provider := &countingProvider{baseURL: "https://example.atlassian.net"}
+	var mu sync.Mutex
+	created := map[string]*closingResilience{}
+	pool, err := NewPool(provider,
+		WithPoolIdleTimeout(time.Minute),
+		WithPoolResilience(func(tenantID string) Resilience {
+			mu.Lock()
+			defer mu.Unlock()
+			r := &closingResilience{}
+			created[tenantID] = r
+			return r
+		}),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, tenantID := range []string{"acme", "globex", "initech"} {
+		_, err = pool.Client(ctx, tenantID)
+		require.NoError(t, err)
+	}
+
+	pool.Evict("acme")
+	assert.Equal(t, int32(1), created["acme"].closed.Load())
+	assert.Equal(t, int32(0), created["globex"].closed.Load())
+
+	pool.evictIdle(time.Now().Add(2 * time.Minute))
+	assert.Equal(t, int32(1), created["globex"].closed.Load())
+	assert.Equal(t, int32(1), created["initech"].closed.Load())
+
+	_, err = pool.Client(ctx, "acme")
+	require.NoError(t, err)
+	rebuilt := created["acme"]
+	pool.Close()
+	assert.Equal(t, int32(1), rebuilt.closed.Load())
+}
+
+func TestPool_BuildOutlivesCanceledCaller(t *testing.T) {
+	provider := &probeProvider{countingProvider: countingProvider{baseURL: "https://example.atlassian.net"}}
+	pool, err := NewPool(provider)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var buildErr atomic.Value
+	provider.probe = func(string) {
+		close(started)
+		<-release
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := pool.Client(ctx, "acme")
+		first <- err
+	}()
+	<-started
+
+	second := make(chan *Client, 1)
+	go func() {
+		c, err := pool.Client(context.Background(), "acme")
+		if err != nil {
+			buildErr.Store(err)
+		}
+		second <- c
+	}()
+
+	// The first caller gives up without failing the shared build
+	cancel()
+	assert.ErrorIs(t, <-first, context.Canceled)
+
+	close(release)
+	assert.NotNil(t, <-second)
+	assert.Nil(t, buildErr.Load())
+	assert.Equal(t, int32(1), provider.calls.Load())
+}
@@ -8,6 +8,11 @@ import (
 
 // Resilience defines the interface for resilience patterns.
 // Implementations can use fortify or custom resilience strategies.
+//
+// ExecuteRequest is called once per request attempt, inside the built-in
+// retry middleware. Implementations that retry on their own should also
+// provide a HandlesRetries() bool method (see transport.RetryingResilience)
+// so that the built-in retries are switched off.
 type Resilience interface {
 	// ExecuteRequest wraps an HTTP request with resilience patterns
 	ExecuteRequest(ctx context.Context, req *http.Request, do func(context.Context, *http.Request) (*http.Response, error)) (*http.Response, error)
@@ -15,9 +15,14 @@ package fortify
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"sync"
+	"time"
 
 	jira "github.com/felixgeelhaar/jirasdk"
+	"github.com/felixgeelhaar/jirasdk/transport"
 	"go.klarlabs.de/fortify/bulkhead"
 	"go.klarlabs.de/fortify/circuitbreaker"
 	"go.klarlabs.de/fortify/ratelimit"
@@ -63,7 +68,8 @@ func NewAdapter(config jira.ResilienceConfig) *Adapter {
 			Multiplier:    config.RetryMultiplier,
 			Jitter:        config.RetryJitter,
 			IsRetryable: func(err error) bool {
-				// Retry on network errors and 5xx status codes
+				// Retry on network errors and 5xx status codes of idempotent
+				// requests, and on 429
 				return isRetryableError(err)
 			},
 		})
@@ -97,11 +103,41 @@ func NewAdapter(config jira.ResilienceConfig) *Adapter {
 	return adapter
 }
 
-// ExecuteRequest wraps an HTTP request with all enabled resilience patterns
+// ExecuteRequest wraps an HTTP request with all enabled resilience patterns.
+//
+// Failures of requests that are not idempotent, such as issue creation, are
+// never retried, following transport.IsIdempotent, since repeating them after
+// a 5xx or a network error could apply them twice. The circuit breaker still
+// counts them. A 429 is retried for every request, after its Retry-After
+// delay; a delay beyond transport.DefaultMaxRetryAfter returns the response
+// instead. transport.CallMaxRetries limits the retries of a call, and every
+// retry is reported to tracing and metrics with transport.RecordRetry.
 func (a *Adapter) ExecuteRequest(ctx context.Context, req *http.Request, do func(context.Context, *http.Request) (*http.Response, error)) (*http.Response, error) {
-	// Build the execution function with all enabled patterns
+	idempotent := transport.IsIdempotent(req, nil)
+
+	// Retryable responses are reported to the patterns as failures so the
+	// circuit breaker counts them and the retrier retries them. The most
+	// recent failed response is kept and returned if no attempt succeeds.
+	failed := &failedResponse{}
 	execute := func(ctx context.Context) (*http.Response, error) {
-		return do(ctx, req)
+		resp, err := do(ctx, req)
+		if err != nil {
+			if !idempotent {
+				return nil, &permanentError{err: err}
+			}
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusTooManyRequests || isServerError(resp.StatusCode) {
+			failed.set(resp)
+			retryAfter, _ := transport.RetryAfter(resp)
+			return nil, &statusError{
+				statusCode: resp.StatusCode,
+				// Jira rejects a 429 before processing the request
+				retryable:  idempotent || resp.StatusCode == http.StatusTooManyRequests,
+				retryAfter: retryAfter,
+			}
+		}
+		return resp, nil
 	}
 
 	// Apply patterns in order: Bulkhead -> RateLimit -> Timeout -> CircuitBreaker -> Retry
@@ -145,13 +181,165 @@ func (a *Adapter) ExecuteRequest(ctx context.Context, req *http.Request, do func
 
 	// 5. Retry - Handle transient failures
 	if a.retrier != nil {
+		execute = a.attempts(execute)
+
 		retryExecute := execute
 		execute = func(ctx context.Context) (*http.Response, error) {
 			return a.retrier.Execute(ctx, retryExecute)
 		}
 	}
 
-	return execute(ctx)
+	resp, err := execute(ctx)
+
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		if last := failed.take(); last != nil {
+			return last, nil
+		}
+		return nil, statusErr
+	}
+
+	// Release a failed response from an earlier attempt
+	failed.release()
+
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return nil, permanent.err
+	}
+	return resp, err
+}
+
+// attempts wraps each attempt made by the retrier. After a failure that will
+// be retried, it reports the retry and waits out any Retry-After delay. Once
+// the call's retries are used up, or the delay is too long, it marks the
+// failure permanent so the retrier stops.
+func (a *Adapter) attempts(execute func(context.Context) (*http.Response, error)) func(context.Context) (*http.Response, error) {
+	var attempt int
+	return func(ctx context.Context) (*http.Response, error) {
+		attempt++
+		resp, err := execute(ctx)
+		if err == nil || !isRetryableError(err) {
+			return resp, err
+		}
+
+		maxAttempts := a.config.RetryMaxAttempts
+		if maxRetries, ok := transport.CallMaxRetriesFrom(ctx); ok && maxRetries+1 < maxAttempts {
+			maxAttempts = maxRetries + 1
+		}
+		if attempt >= maxAttempts {
+			return nil, &permanentError{err: err}
+		}
+
+		var wait time.Duration
+		statusCode := 0
+		reported := err
+		var statusErr *statusError
+		if errors.As(err, &statusErr) {
+			wait = statusErr.retryAfter
+			statusCode = statusErr.statusCode
+			reported = nil
+		}
+		if wait > transport.DefaultMaxRetryAfter {
+			return nil, &permanentError{err: err}
+		}
+
+		transport.RecordRetry(ctx, attempt, wait, statusCode, reported)
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				return nil, &permanentError{err: ctx.Err()}
+			}
+		}
+		return nil, err
+	}
+}
+
+// HandlesRetries reports whether the adapter retries failed requests itself.
+//
+// The transport omits its built-in retry middleware when this returns true,
+// so retries configured here do not stack with the client's WithMaxRetries.
+func (a *Adapter) HandlesRetries() bool {
+	return a.retrier != nil
+}
+
+// statusError reports a retryable response to the resilience patterns.
+type statusError struct {
+	statusCode int
+	retryable  bool
+
+	// retryAfter is the delay requested by a Retry-After header, or zero
+	retryAfter time.Duration
+}
+
+// Error implements the error interface.
+func (e *statusError) Error() string {
+	return fmt.Sprintf("server error: HTTP %d", e.statusCode)
+}
+
+// permanentError marks a failed attempt that must not be retried.
+type permanentError struct {
+	err error
+}
+
+// Error implements the error interface.
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// failedResponse holds the most recent server error response.
+//
+// Patterns such as timeout may run attempts on another goroutine,
+// so access is guarded by a mutex.
+type failedResponse struct {
+	mu   sync.Mutex
+	resp *http.Response
+}
+
+// set stores resp, closing the body of any previously stored response.
+func (f *failedResponse) set(resp *http.Response) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.resp != nil && f.resp.Body != nil {
+		_ = f.resp.Body.Close() // Explicit ignore, superseded by a later attempt
+	}
+	f.resp = resp
+}
+
+// take returns the stored response and clears it.
+func (f *failedResponse) take() *http.Response {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := f.resp
+	f.resp = nil
+	return resp
+}
+
+// release closes the body of the stored response, if any.
+func (f *failedResponse) release() {
+	if resp := f.take(); resp != nil && resp.Body != nil {
+		_ = resp.Body.Close() // Explicit ignore in cleanup path
+	}
+}
+
+// isServerError returns true for 5xx status codes worth retrying.
+func isServerError(statusCode int) bool {
+	switch statusCode {
+	case http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
 }
 
 // isRetryableError determines if an error should trigger a retry
@@ -160,8 +348,15 @@ func isRetryableError(err error) bool {
 		return false
 	}
 
-	// Retry on specific HTTP status codes if we can determine them
-	// For now, retry on all errors - can be made more sophisticated
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.retryable
+	}
 	return true
 }
 
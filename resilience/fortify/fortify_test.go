@@ -0,0 +1,168 @@
+package fortify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	jira "github.com/felixgeelhaar/jirasdk"
+	"github.com/felixgeelhaar/jirasdk/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRetryAdapter returns an adapter with only retries enabled.
+func newRetryAdapter() *Adapter {
+	return NewAdapter(jira.ResilienceConfig{
+		RetryEnabled:     true,
+		RetryMaxAttempts: 3,
+	})
+}
+
+func TestAdapter_RetriesOnlyIdempotentRequests(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		wantAttempts int
+	}{
+		{name: "issue create", method: http.MethodPost, path: "/rest/api/3/issue", wantAttempts: 1},
+		{name: "issue get", method: http.MethodGet, path: "/rest/api/3/issue/PROJ-1", wantAttempts: 3},
+		{name: "read-only POST", method: http.MethodPost, path: "/rest/api/3/search/jql", wantAttempts: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, "https://example.atlassian.net"+tt.path, nil)
+			require.NoError(t, err)
+
+			attempts := 0
+			resp, err := newRetryAdapter().ExecuteRequest(context.Background(), req,
+				func(context.Context, *http.Request) (*http.Response, error) {
+					attempts++
+					return &http.Response{
+						StatusCode: http.StatusInternalServerError,
+						Body:       io.NopCloser(strings.NewReader(`{"errorMessages":["boom"]}`)),
+					}, nil
+				})
+
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+			assert.Equal(t, tt.wantAttempts, attempts)
+		})
+	}
+}
+
+func TestAdapter_DoesNotRetryNetworkErrorsOfNonIdempotentRequests(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://example.atlassian.net/rest/api/3/issue", nil)
+	require.NoError(t, err)
+
+	errReset := errors.New("connection reset by peer")
+	attempts := 0
+	_, err = newRetryAdapter().ExecuteRequest(context.Background(), req,
+		func(context.Context, *http.Request) (*http.Response, error) {
+			attempts++
+			return nil, errReset
+		})
+
+	assert.Equal(t, errReset, err)
+	assert.Equal(t, 1, attempts)
+}
+
+// statusSequence returns a do function answering with statuses in turn,
+// repeating the last one, and counting the attempts.
+func statusSequence(attempts *int, header http.Header, statuses ...int) func(context.Context, *http.Request) (*http.Response, error) {
+	return func(context.Context, *http.Request) (*http.Response, error) {
+		status := statuses[min(*attempts, len(statuses)-1)]
+		*attempts++
+		return &http.Response{
+			StatusCode: status,
+			Header:     header.Clone(),
+			Body:       io.NopCloser(strings.NewReader(`{}`)),
+		}, nil
+	}
+}
+
+func TestAdapter_RetriesRateLimitedRequests(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://example.atlassian.net/rest/api/3/issue", nil)
+	require.NoError(t, err)
+
+	attempts := 0
+	resp, err := newRetryAdapter().ExecuteRequest(context.Background(), req,
+		statusSequence(&attempts, http.Header{"Retry-After": {"0"}}, http.StatusTooManyRequests, http.StatusCreated))
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestAdapter_LongRetryAfterIsNotWaitedFor(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.atlassian.net/rest/api/3/myself", nil)
+	require.NoError(t, err)
+
+	attempts := 0
+	resp, err := newRetryAdapter().ExecuteRequest(context.Background(), req,
+		statusSequence(&attempts, http.Header{"Retry-After": {"3600"}}, http.StatusServiceUnavailable))
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestAdapter_HonorsCallMaxRetries(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.atlassian.net/rest/api/3/myself", nil)
+	require.NoError(t, err)
+
+	ctx := transport.ContextWithCallOptions(context.Background(), transport.CallMaxRetries(1))
+	attempts := 0
+	resp, err := newRetryAdapter().ExecuteRequest(ctx, req,
+		statusSequence(&attempts, nil, http.StatusInternalServerError))
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, 2, attempts)
+}
+
+// recordingMetrics keeps every RequestMetrics it is given.
+type recordingMetrics struct {
+	records []transport.RequestMetrics
+}
+
+func (r *recordingMetrics) RecordRequest(_ context.Context, m transport.RequestMetrics) {
+	r.records = append(r.records, m)
+}
+
+func TestAdapter_ReportsRetries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	metrics := &recordingMetrics{}
+	baseURL, _ := url.Parse(server.URL)
+	tr := transport.New(server.Client(), baseURL,
+		transport.WithResilience(newRetryAdapter()),
+		transport.WithMetrics(metrics),
+	)
+
+	req, err := tr.NewRequest(context.Background(), http.MethodGet, "/rest/api/3/myself", nil)
+	require.NoError(t, err)
+	resp, err := tr.Do(context.Background(), req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	require.Len(t, metrics.records, 1)
+	assert.Equal(t, http.StatusOK, metrics.records[0].StatusCode)
+	assert.Equal(t, 1, metrics.records[0].Retries)
+}
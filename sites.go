@@ -0,0 +1,64 @@
+package jirasdk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/felixgeelhaar/jirasdk/auth"
+)
+
+// SiteClient is a client for one Jira Cloud site an OAuth 2.0 token can
+// reach.
+type SiteClient struct {
+	*Client
+
+	// Resource describes the site, with the scopes granted for it
+	Resource auth.AccessibleResource
+}
+
+// NewSiteClients creates one client for every Jira site the OAuth 2.0 token
+// can reach, routed through the Atlassian API gateway with WithCloudID.
+//
+// Sites the token was granted no Jira scope for are skipped. The clients
+// share the authenticator, so one refresh serves them all. opts apply to
+// every client.
+//
+// Example:
+//
+//	sites, err := jira.NewSiteClients(ctx, oauth, jira.WithTimeout(30*time.Second))
+//	if err != nil {
+//		return err
+//	}
+//	for _, site := range sites {
+//		me, err := site.Myself.Get(ctx)
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Printf("%s: signed in as %s\n", site.Resource.URL, me.DisplayName)
+//	}
+func NewSiteClients(ctx context.Context, oauth *auth.OAuth2Authenticator, opts ...Option) ([]*SiteClient, error) {
+	if oauth == nil {
+		return nil, fmt.Errorf("OAuth 2.0 authenticator is required")
+	}
+
+	resources, err := oauth.AccessibleResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var sites []*SiteClient
+	for _, resource := range resources {
+		if !resource.IsJira() {
+			continue
+		}
+
+		siteOpts := append([]Option{WithOAuth2(oauth)}, opts...)
+		siteOpts = append(siteOpts, WithCloudID(resource.ID))
+		client, err := NewClient(siteOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client for %s: %w", resource.URL, err)
+		}
+		sites = append(sites, &SiteClient{Client: client, Resource: resource})
+	}
+	return sites, nil
+}
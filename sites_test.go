@@ -0,0 +1,71 @@
+package jirasdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/jirasdk/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestWithCloudID(t *testing.T) {
+	cfg := &Config{}
+	require.NoError(t, WithCloudID("1324a887-45db-1bf4-1e99-ef0ff456d421")(cfg))
+	assert.Equal(t, "https://api.atlassian.com/ex/jira/1324a887-45db-1bf4-1e99-ef0ff456d421", cfg.baseURL.String())
+
+	assert.Error(t, WithCloudID("")(cfg))
+	assert.Error(t, WithCloudID("../admin")(cfg))
+}
+
+func TestClient_GatewayBasePath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/ex/jira/cloud-1/rest/api/3/myself", r.URL.Path)
+		_, _ = w.Write([]byte(`{"accountId":"abc"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(
+		WithBaseURL(server.URL+"/ex/jira/cloud-1"),
+		WithAPIToken("user@example.com", "token"),
+	)
+	require.NoError(t, err)
+
+	me, err := client.Myself.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "abc", me.AccountID)
+}
+
+func TestNewSiteClients(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"id":"cloud-1","url":"https://one.atlassian.net","name":"one","scopes":["read:jira-work"]},
+			{"id":"cloud-2","url":"https://wiki.atlassian.net","name":"wiki","scopes":["read:confluence-content.all"]},
+			{"id":"cloud-3","url":"https://three.atlassian.net","name":"three","scopes":["read:jira-user"]}
+		]`))
+	}))
+	defer server.Close()
+
+	oauth := auth.NewOAuth2Authenticator(&auth.OAuth2Config{
+		ClientID:               "id",
+		ClientSecret:           "secret",
+		AccessibleResourcesURL: server.URL,
+	})
+	oauth.SetToken(&oauth2.Token{AccessToken: "token", Expiry: time.Now().Add(time.Hour)})
+
+	sites, err := NewSiteClients(context.Background(), oauth, WithUserAgent("sites-test"))
+	require.NoError(t, err)
+	require.Len(t, sites, 2, "Confluence-only sites are skipped")
+
+	assert.Equal(t, "https://one.atlassian.net", sites[0].Resource.URL)
+	assert.Equal(t, "https://api.atlassian.com/ex/jira/cloud-1", sites[0].BaseURL.String())
+	assert.Equal(t, "https://api.atlassian.com/ex/jira/cloud-3", sites[1].BaseURL.String())
+	assert.Same(t, oauth, sites[0].Authenticator)
+
+	_, err = NewSiteClients(context.Background(), nil)
+	assert.Error(t, err)
+}
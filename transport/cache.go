@@ -0,0 +1,260 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/jirasdk/auth"
+)
+
+// CacheStatusHeader is set on responses handled by the cache middleware.
+// Its value is "HIT" for responses served from the cache without contacting
+// Jira and "REVALIDATED" for cached responses confirmed by a 304.
+const CacheStatusHeader = "X-Jirasdk-Cache"
+
+// Cache stores GET responses for the caching middleware.
+//
+// Implementations must be safe for concurrent use. Caching is best effort:
+// implementations should drop entries they cannot store rather than fail.
+type Cache interface {
+	// Get returns the entry stored under key, if any
+	Get(key string) (*CacheEntry, bool)
+
+	// Set stores entry under key
+	Set(key string, entry *CacheEntry)
+
+	// Delete removes the entry stored under key
+	Delete(key string)
+}
+
+// CacheEntry is a stored GET response.
+type CacheEntry struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	StoredAt   time.Time   `json:"storedAt"`
+	ExpiresAt  time.Time   `json:"expiresAt"`
+}
+
+// fresh reports whether the entry can be served without revalidation.
+func (e *CacheEntry) fresh(now time.Time) bool {
+	return now.Before(e.ExpiresAt)
+}
+
+// CacheRule sets how long responses from an endpoint are served without revalidation.
+//
+// Endpoint is matched against the endpoint template with the API prefix
+// removed, so "/field" matches both /rest/api/3/field and /rest/api/2/field,
+// and "/project/{projectIdOrKey}" matches /rest/api/3/project/PROJ.
+// A rule overrides the Cache-Control headers Jira sends for that endpoint.
+type CacheRule struct {
+	Endpoint string
+	TTL      time.Duration
+}
+
+// DefaultCacheRules caches reference data that rarely changes.
+var DefaultCacheRules = []CacheRule{
+	{Endpoint: "/field", TTL: time.Hour},
+	{Endpoint: "/priority", TTL: time.Hour},
+	{Endpoint: "/resolution", TTL: time.Hour},
+	{Endpoint: "/status", TTL: time.Hour},
+	{Endpoint: "/statuscategory", TTL: time.Hour},
+	{Endpoint: "/issuetype", TTL: time.Hour},
+	{Endpoint: "/issueLinkType", TTL: time.Hour},
+	{Endpoint: "/serverInfo", TTL: 5 * time.Minute},
+}
+
+// cacheMiddleware serves GET responses from cache and revalidates stale ones.
+//
+// Responses from endpoints matching a rule are kept for the rule's TTL.
+// Other responses are stored only if they carry an ETag or Last-Modified
+// validator and allow storing, and are revalidated on every use. Cache keys
+// include a hash of the caller's credentials, so clients with different
+// authenticators never share entries. A successful non-GET request evicts
+// the GET entry for the same URL. CallCachePolicy overrides this per call.
+func cacheMiddleware(cache Cache, rules []CacheRule, authenticator auth.Authenticator) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodGet {
+				resp, err := next(ctx, req)
+				if err == nil && resp.StatusCode < 400 {
+					if identity, err := authIdentity(authenticator, req); err == nil {
+						cache.Delete(cacheKey(identity, req))
+					}
+				}
+				return resp, err
+			}
+
+			identity, err := authIdentity(authenticator, req)
+			if err != nil {
+				return nil, fmt.Errorf("authentication failed: %w", err)
+			}
+
+			policy := CacheDefault
+			if opts := callOptionsFrom(ctx); opts != nil {
+				policy = opts.cachePolicy
+			}
+			if policy == CacheBypass {
+				return next(ctx, req)
+			}
+
+			key := cacheKey(identity, req)
+			now := time.Now()
+
+			var entry *CacheEntry
+			found := false
+			if policy != CacheRefresh {
+				entry, found = cache.Get(key)
+			}
+			if found && entry.fresh(now) {
+				return entry.response(req, "HIT"), nil
+			}
+
+			if found {
+				if etag := entry.Header.Get("ETag"); etag != "" {
+					req.Header.Set("If-None-Match", etag)
+				}
+				if lastModified := entry.Header.Get("Last-Modified"); lastModified != "" {
+					req.Header.Set("If-Modified-Since", lastModified)
+				}
+			}
+
+			resp, err := next(ctx, req)
+			if err != nil {
+				return resp, err
+			}
+
+			ttl, matched := cacheTTL(rules, req.URL.Path)
+
+			if found && resp.StatusCode == http.StatusNotModified {
+				_ = resp.Body.Close() // Explicit ignore, a 304 has no body
+
+				// Entries may be shared with concurrent readers, so refresh a copy
+				refreshed := *entry
+				refreshed.Header = entry.Header.Clone()
+				for _, name := range []string{"ETag", "Last-Modified", "Date"} {
+					if value := resp.Header.Get(name); value != "" {
+						refreshed.Header.Set(name, value)
+					}
+				}
+				refreshed.StoredAt = now
+				refreshed.ExpiresAt = now.Add(ttl)
+				cache.Set(key, &refreshed)
+				return refreshed.response(req, "REVALIDATED"), nil
+			}
+
+			if resp.StatusCode != http.StatusOK || !cacheable(resp, matched) {
+				return resp, nil
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			_ = resp.Body.Close() // Explicit ignore, the body is replaced below
+			if err != nil {
+				return nil, fmt.Errorf("failed to read response body: %w", err)
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+
+			cache.Set(key, &CacheEntry{
+				StatusCode: resp.StatusCode,
+				Header:     resp.Header.Clone(),
+				Body:       body,
+				StoredAt:   now,
+				ExpiresAt:  now.Add(ttl),
+			})
+
+			return resp, nil
+		}
+	}
+}
+
+// response builds an HTTP response from the cache entry.
+func (e *CacheEntry) response(req *http.Request, status string) *http.Response {
+	header := e.Header.Clone()
+	header.Set(CacheStatusHeader, status)
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// cacheable reports whether a 200 response may be stored.
+func cacheable(resp *http.Response, matched bool) bool {
+	if matched {
+		return true
+	}
+
+	if strings.Contains(strings.ToLower(resp.Header.Get("Cache-Control")), "no-store") {
+		return false
+	}
+
+	return resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""
+}
+
+// cacheTTL returns the TTL of the first rule matching path.
+func cacheTTL(rules []CacheRule, path string) (time.Duration, bool) {
+	endpoint := trimAPIPrefix(EndpointTemplate(path))
+	for _, rule := range rules {
+		if rule.Endpoint == endpoint {
+			return rule.TTL, true
+		}
+	}
+	return 0, false
+}
+
+// trimAPIPrefix removes the /rest/<api>/<version> prefix, and any base path
+// before it, from a path.
+func trimAPIPrefix(path string) string {
+	segments := strings.SplitN(strings.TrimPrefix(trimBasePath(path), "/"), "/", 4)
+	if len(segments) == 4 && segments[0] == "rest" {
+		return "/" + segments[3]
+	}
+	return path
+}
+
+// cacheKey identifies a GET response for one set of credentials.
+func cacheKey(identity string, req *http.Request) string {
+	return identity + " " + http.MethodGet + " " + req.URL.String() + " " + req.Header.Get("Accept-Language")
+}
+
+// authIdentity returns a stable, non-reversible identifier for the credentials
+// used on req.
+//
+// Authenticators that implement auth.Identifier, including all of the
+// built-in ones, are asked directly. For other authenticators the
+// Authorization header they would set on a probe request is hashed, as a last
+// resort.
+func authIdentity(authenticator auth.Authenticator, req *http.Request) (string, error) {
+	if authenticator == nil {
+		return "anonymous", nil
+	}
+
+	if identifier, ok := authenticator.(auth.Identifier); ok {
+		return identifier.Identity(req), nil
+	}
+
+	probe, err := http.NewRequestWithContext(req.Context(), req.Method, req.URL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if err := authenticator.Authenticate(probe); err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(authenticator.Type() + "\x00" + probe.Header.Get("Authorization")))
+	return hex.EncodeToString(sum[:]), nil
+}
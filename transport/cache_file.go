@@ -0,0 +1,88 @@
+package transport
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileCache is a Cache that stores each entry as a JSON file in a directory.
+//
+// It survives process restarts, which suits CLI tools that make the same
+// reference-data calls on every run. File names are hashes of the cache key,
+// so credentials never appear on disk, but cached response bodies do; the
+// directory and files are created readable by the owner only.
+type FileCache struct {
+	dir string
+}
+
+// NewFileCache creates a file cache in dir, creating the directory if needed.
+//
+// Example:
+//
+//	dir, _ := os.UserCacheDir()
+//	cache, err := transport.NewFileCache(filepath.Join(dir, "my-tool", "jira"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	return &FileCache{dir: dir}, nil
+}
+
+// Get implements Cache. Unreadable or corrupt entries are treated as misses.
+func (c *FileCache) Get(key string) (*CacheEntry, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+
+	return &entry, true
+}
+
+// Set implements Cache. Entries that cannot be written are dropped.
+func (c *FileCache) Set(key string, entry *CacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	// Write to a temporary file and rename so readers never see a partial entry
+	tmp, err := os.CreateTemp(c.dir, ".entry-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close() // Explicit ignore, the write already failed
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+
+	_ = os.Rename(tmp.Name(), c.path(key)) // Explicit ignore, caching is best effort
+}
+
+// Delete implements Cache.
+func (c *FileCache) Delete(key string) {
+	_ = os.Remove(c.path(key)) // Explicit ignore, the entry may not exist
+}
+
+// path returns the file that stores key.
+func (c *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
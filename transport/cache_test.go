@@ -0,0 +1,340 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/jirasdk/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getBody(t *testing.T, tr *Transport, path string) (*http.Response, string) {
+	t.Helper()
+
+	req, err := tr.NewRequest(context.Background(), http.MethodGet, path, nil)
+	require.NoError(t, err)
+
+	resp, err := tr.Do(context.Background(), req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+func TestCacheMiddleware_RuleServesFreshHits(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(`[{"id":"1","name":"High"}]`))
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL, WithCache(NewMemoryCache(10)))
+
+	resp, body := getBody(t, tr, "/rest/api/3/priority")
+	assert.Empty(t, resp.Header.Get(CacheStatusHeader))
+
+	resp, cached := getBody(t, tr, "/rest/api/3/priority")
+	assert.Equal(t, "HIT", resp.Header.Get(CacheStatusHeader))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, body, cached)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestCacheMiddleware_Revalidates(t *testing.T) {
+	tests := []struct {
+		name      string
+		validator string
+		value     string
+		condition string
+	}{
+		{name: "etag", validator: "ETag", value: `"v1"`, condition: "If-None-Match"},
+		{name: "last modified", validator: "Last-Modified", value: "Mon, 02 Jan 2006 15:04:05 GMT", condition: "If-Modified-Since"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				if r.Header.Get(tt.condition) == tt.value {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				w.Header().Set(tt.validator, tt.value)
+				_, _ = w.Write([]byte(`{"key":"PROJ-1"}`))
+			}))
+			defer server.Close()
+
+			baseURL, _ := url.Parse(server.URL)
+			tr := New(server.Client(), baseURL, WithCache(NewMemoryCache(10)))
+
+			_, body := getBody(t, tr, "/rest/api/3/issue/PROJ-1")
+
+			resp, revalidated := getBody(t, tr, "/rest/api/3/issue/PROJ-1")
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "REVALIDATED", resp.Header.Get(CacheStatusHeader))
+			assert.Equal(t, body, revalidated)
+			assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+func TestCacheMiddleware_SkipsUncacheableResponses(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		header map[string]string
+	}{
+		{name: "no validator", path: "/rest/api/3/issue/PROJ-1", status: http.StatusOK},
+		{name: "no-store", path: "/rest/api/3/issue/PROJ-1", status: http.StatusOK,
+			header: map[string]string{"ETag": `"v1"`, "Cache-Control": "no-store"}},
+		{name: "error status", path: "/rest/api/3/field", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			cache := NewMemoryCache(10)
+			baseURL, _ := url.Parse(server.URL)
+			tr := New(server.Client(), baseURL, WithCache(cache))
+
+			req, err := tr.NewRequest(context.Background(), http.MethodGet, tt.path, nil)
+			require.NoError(t, err)
+			resp, err := tr.Do(context.Background(), req)
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+
+			assert.Equal(t, 0, cache.Len())
+		})
+	}
+}
+
+func TestCacheMiddleware_SeparatesAuthenticators(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	cache := NewMemoryCache(10)
+	baseURL, _ := url.Parse(server.URL)
+	alice := New(server.Client(), baseURL, WithCache(cache), WithAuthenticator(auth.NewPATAuth("alice")))
+	bob := New(server.Client(), baseURL, WithCache(cache), WithAuthenticator(auth.NewPATAuth("bob")))
+
+	_, aliceBody := getBody(t, alice, "/rest/api/3/field")
+	resp, bobBody := getBody(t, bob, "/rest/api/3/field")
+
+	assert.Empty(t, resp.Header.Get(CacheStatusHeader))
+	assert.Equal(t, "Bearer alice", aliceBody)
+	assert.Equal(t, "Bearer bob", bobBody)
+	assert.Equal(t, 2, cache.Len())
+}
+
+func TestCacheMiddleware_ConnectJWTAuth(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(`[{"id":"1","name":"High"}]`))
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL, WithCache(NewMemoryCache(10)),
+		WithAuthenticator(auth.NewConnectJWTAuth("com.example.app", "shared-secret")))
+
+	get := func(ctx context.Context) *http.Response {
+		req, err := tr.NewRequest(ctx, http.MethodGet, "/rest/api/3/priority", nil)
+		require.NoError(t, err)
+		resp, err := tr.Do(ctx, req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp
+	}
+
+	// Each request carries a freshly signed token, but the caller is the same
+	assert.Empty(t, get(context.Background()).Header.Get(CacheStatusHeader))
+	assert.Equal(t, "HIT", get(context.Background()).Header.Get(CacheStatusHeader))
+
+	// Impersonating a user is a different caller
+	userCtx := auth.ContextWithConnectSubject(context.Background(), "5b10ac8d82e05b22cc7d4ef5")
+	assert.Empty(t, get(userCtx).Header.Get(CacheStatusHeader))
+	assert.Equal(t, "HIT", get(userCtx).Header.Get(CacheStatusHeader))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+// countingAuth counts the requests it authenticates.
+type countingAuth struct {
+	calls atomic.Int32
+	token string
+}
+
+func (a *countingAuth) Authenticate(req *http.Request) error {
+	a.calls.Add(1)
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+func (a *countingAuth) Type() string { return "counting" }
+
+// identifiedAuth is a countingAuth that implements auth.Identifier.
+type identifiedAuth struct{ countingAuth }
+
+func (a *identifiedAuth) Identity(_ *http.Request) string { return a.token }
+
+func TestCacheMiddleware_AuthenticatesOncePerRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	authenticator := &identifiedAuth{countingAuth{token: "alice"}}
+	tr := New(server.Client(), baseURL, WithCache(NewMemoryCache(10)), WithCoalescing(true),
+		WithAuthenticator(authenticator))
+
+	getBody(t, tr, "/rest/api/3/myself")
+	req, err := tr.NewRequest(context.Background(), http.MethodPut, "/rest/api/3/issue/PROJ-1", map[string]string{})
+	require.NoError(t, err)
+	resp, err := tr.Do(context.Background(), req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, int32(2), authenticator.calls.Load(), "no probe requests")
+}
+
+func TestCacheMiddleware_ProbesUnknownAuthenticators(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	cache := NewMemoryCache(10)
+	baseURL, _ := url.Parse(server.URL)
+	alice := New(server.Client(), baseURL, WithCache(cache), WithAuthenticator(&countingAuth{token: "alice"}))
+	bob := New(server.Client(), baseURL, WithCache(cache), WithAuthenticator(&countingAuth{token: "bob"}))
+
+	_, aliceBody := getBody(t, alice, "/rest/api/3/field")
+	_, bobBody := getBody(t, bob, "/rest/api/3/field")
+	resp, cached := getBody(t, alice, "/rest/api/3/field")
+
+	assert.Equal(t, "Bearer alice", aliceBody)
+	assert.Equal(t, "Bearer bob", bobBody)
+	assert.Equal(t, "HIT", resp.Header.Get(CacheStatusHeader))
+	assert.Equal(t, "Bearer alice", cached)
+}
+
+func TestCacheMiddleware_WriteEvictsEntry(t *testing.T) {
+	var version int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			atomic.AddInt32(&version, 1)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"version":%d}`, atomic.LoadInt32(&version))
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL,
+		WithCache(NewMemoryCache(10), CacheRule{Endpoint: "/project/{projectIdOrKey}", TTL: time.Hour}))
+
+	_, before := getBody(t, tr, "/rest/api/3/project/PROJ")
+	assert.Equal(t, `{"version":0}`, before)
+
+	req, err := tr.NewRequest(context.Background(), http.MethodPut, "/rest/api/3/project/PROJ", map[string]string{"name": "Renamed"})
+	require.NoError(t, err)
+	resp, err := tr.Do(context.Background(), req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	_, after := getBody(t, tr, "/rest/api/3/project/PROJ")
+	assert.Equal(t, `{"version":1}`, after)
+}
+
+func TestCacheTTL(t *testing.T) {
+	tests := []struct {
+		path    string
+		ttl     time.Duration
+		matched bool
+	}{
+		{path: "/rest/api/3/field", ttl: time.Hour, matched: true},
+		{path: "/rest/api/2/field", ttl: time.Hour, matched: true},
+		{path: "/rest/api/3/serverInfo", ttl: 5 * time.Minute, matched: true},
+		{path: "/ex/jira/abc/rest/api/3/field", ttl: time.Hour, matched: true},
+		{path: "/rest/api/3/field/customfield_10000/context", matched: false},
+		{path: "/rest/api/3/issue/PROJ-1", matched: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			ttl, matched := cacheTTL(DefaultCacheRules, tt.path)
+			assert.Equal(t, tt.ttl, ttl)
+			assert.Equal(t, tt.matched, matched)
+		})
+	}
+}
+
+func TestMemoryCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewMemoryCache(2)
+	cache.Set("a", &CacheEntry{StatusCode: http.StatusOK})
+	cache.Set("b", &CacheEntry{StatusCode: http.StatusOK})
+
+	_, ok := cache.Get("a")
+	require.True(t, ok)
+
+	cache.Set("c", &CacheEntry{StatusCode: http.StatusOK})
+
+	_, ok = cache.Get("b")
+	assert.False(t, ok)
+	_, ok = cache.Get("a")
+	assert.True(t, ok)
+	_, ok = cache.Get("c")
+	assert.True(t, ok)
+
+	cache.Delete("a")
+	assert.Equal(t, 1, cache.Len())
+}
+
+func TestFileCache(t *testing.T) {
+	cache, err := NewFileCache(t.TempDir())
+	require.NoError(t, err)
+
+	entry := &CacheEntry{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Etag": []string{`"v1"`}},
+		Body:       []byte(`{"id":"1"}`),
+		ExpiresAt:  time.Now().Add(time.Hour).Round(0),
+	}
+	cache.Set("key", entry)
+
+	got, ok := cache.Get("key")
+	require.True(t, ok)
+	assert.Equal(t, entry.Body, got.Body)
+	assert.Equal(t, `"v1"`, got.Header.Get("ETag"))
+	assert.True(t, got.ExpiresAt.Equal(entry.ExpiresAt))
+
+	cache.Delete("key")
+	_, ok = cache.Get("key")
+	assert.False(t, ok)
+}
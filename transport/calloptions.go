@@ -0,0 +1,186 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// CachePolicy controls how a single call uses the response cache.
+type CachePolicy int
+
+const (
+	// CacheDefault uses the cache as configured with WithCache
+	CacheDefault CachePolicy = iota
+
+	// CacheBypass neither reads from nor writes to the cache
+	CacheBypass
+
+	// CacheRefresh skips cached entries but stores the fresh response
+	CacheRefresh
+)
+
+// CallOption overrides a client setting for the calls made with a context.
+// See ContextWithCallOptions.
+type CallOption func(*callOptions)
+
+// callOptions holds the per-call overrides attached to a context.
+type callOptions struct {
+	timeout       time.Duration
+	maxRetries    int
+	hasMaxRetries bool
+	header        http.Header
+	cachePolicy   CachePolicy
+}
+
+type callOptionsKey struct{}
+
+// ContextWithCallOptions returns a context whose calls use opts instead of
+// the client's settings.
+//
+// Options already attached to ctx are kept unless opts override them, so
+// contexts can be refined step by step.
+//
+// Example:
+//
+//	ctx = transport.ContextWithCallOptions(ctx,
+//		transport.CallTimeout(2*time.Second),
+//		transport.CallMaxRetries(1),
+//		transport.CallHeader("Accept-Language", "de-DE"),
+//	)
+func ContextWithCallOptions(ctx context.Context, opts ...CallOption) context.Context {
+	merged := &callOptions{}
+	if existing := callOptionsFrom(ctx); existing != nil {
+		*merged = *existing
+		merged.header = existing.header.Clone()
+	}
+	for _, opt := range opts {
+		opt(merged)
+	}
+	return context.WithValue(ctx, callOptionsKey{}, merged)
+}
+
+// callOptionsFrom returns the call options attached to ctx, or nil.
+func callOptionsFrom(ctx context.Context) *callOptions {
+	opts, _ := ctx.Value(callOptionsKey{}).(*callOptions)
+	return opts
+}
+
+// CallTimeout limits the call, including all retries and reading the
+// response body, to timeout. It cannot extend the timeout of the client's
+// http.Client.
+func CallTimeout(timeout time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = timeout
+	}
+}
+
+// CallMaxRetries sets the maximum number of retries after the first attempt.
+//
+// With the default retry policy this replaces the client's retry count; a
+// custom RetryPolicy, or a Resilience layer that handles retries, can only be
+// limited further.
+func CallMaxRetries(maxRetries int) CallOption {
+	return func(o *callOptions) {
+		o.maxRetries = maxRetries
+		o.hasMaxRetries = true
+	}
+}
+
+// CallMaxRetriesFrom returns the retry count set with CallMaxRetries for the
+// calls made with ctx, and whether one is set. Resilience implementations that
+// retry requests themselves use it to honor the per-call limit.
+func CallMaxRetriesFrom(ctx context.Context) (int, bool) {
+	opts := callOptionsFrom(ctx)
+	if opts == nil || !opts.hasMaxRetries {
+		return 0, false
+	}
+	return opts.maxRetries, true
+}
+
+// CallHeader sets a request header, such as Accept-Language or
+// X-Atlassian-Force-Account-Id, replacing any value set by the client.
+func CallHeader(key, value string) CallOption {
+	return func(o *callOptions) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+		o.header.Set(key, value)
+	}
+}
+
+// CallCachePolicy sets how the call uses the response cache.
+func CallCachePolicy(policy CachePolicy) CallOption {
+	return func(o *callOptions) {
+		o.cachePolicy = policy
+	}
+}
+
+// applyCallOptions applies the timeout and headers of the call options in ctx
+// to req. If a timeout is set, the returned cancel function is not nil and
+// must be called once the call is done.
+func applyCallOptions(ctx context.Context, req *http.Request) (context.Context, *http.Request, context.CancelFunc) {
+	opts := callOptionsFrom(ctx)
+	if opts == nil {
+		return ctx, req, nil
+	}
+
+	var cancel context.CancelFunc
+	if opts.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+	}
+
+	if cancel != nil || len(opts.header) > 0 {
+		req = req.Clone(ctx)
+		for key, values := range opts.header {
+			req.Header[key] = append([]string(nil), values...)
+		}
+	}
+
+	return ctx, req, cancel
+}
+
+// callRetryPolicy returns policy adjusted to the retry count of the call
+// options in ctx.
+func callRetryPolicy(ctx context.Context, policy RetryPolicy) RetryPolicy {
+	opts := callOptionsFrom(ctx)
+	if opts == nil || !opts.hasMaxRetries {
+		return policy
+	}
+
+	if p, ok := policy.(*DefaultRetryPolicy); ok {
+		adjusted := *p
+		adjusted.MaxRetries = opts.maxRetries
+		return &adjusted
+	}
+	return maxRetriesPolicy{policy: policy, maxRetries: opts.maxRetries}
+}
+
+// maxRetriesPolicy stops a RetryPolicy after maxRetries retries.
+type maxRetriesPolicy struct {
+	policy     RetryPolicy
+	maxRetries int
+}
+
+// Retry implements RetryPolicy.
+func (p maxRetriesPolicy) Retry(req *http.Request, resp *http.Response, err error, attempt int) (bool, time.Duration) {
+	retry, backoff := p.policy.Retry(req, resp, err, attempt)
+	if attempt >= p.maxRetries {
+		return false, 0
+	}
+	return retry, backoff
+}
+
+// cancelOnClose cancels the call's context once its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the context.
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
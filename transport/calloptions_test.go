@@ -0,0 +1,188 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// doWith sends a GET for path with ctx and returns the status code.
+func doWith(t *testing.T, ctx context.Context, tr *Transport, path string) (int, error) {
+	t.Helper()
+
+	req, err := tr.NewRequest(ctx, http.MethodGet, path, nil)
+	require.NoError(t, err)
+	resp, err := tr.Do(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, err = io.ReadAll(resp.Body)
+	return resp.StatusCode, err
+}
+
+func TestContextWithCallOptions_Merges(t *testing.T) {
+	ctx := ContextWithCallOptions(context.Background(), CallMaxRetries(1), CallHeader("Accept-Language", "de-DE"))
+	refined := ContextWithCallOptions(ctx, CallTimeout(time.Second), CallHeader("Accept-Language", "fr-FR"))
+
+	opts := callOptionsFrom(refined)
+	require.NotNil(t, opts)
+	assert.Equal(t, time.Second, opts.timeout)
+	assert.True(t, opts.hasMaxRetries)
+	assert.Equal(t, 1, opts.maxRetries)
+	assert.Equal(t, "fr-FR", opts.header.Get("Accept-Language"))
+
+	// The parent context is unchanged
+	assert.Equal(t, "de-DE", callOptionsFrom(ctx).header.Get("Accept-Language"))
+	assert.Nil(t, callOptionsFrom(context.Background()))
+
+	maxRetries, ok := CallMaxRetriesFrom(refined)
+	assert.True(t, ok)
+	assert.Equal(t, 1, maxRetries)
+	_, ok = CallMaxRetriesFrom(ContextWithCallOptions(context.Background(), CallTimeout(time.Second)))
+	assert.False(t, ok)
+}
+
+func TestCallOptions_MaxRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    RetryPolicy
+		opts      []CallOption
+		wantCalls int32
+	}{
+		{name: "client default", policy: NewDefaultRetryPolicy(2), wantCalls: 3},
+		{name: "fewer retries", policy: NewDefaultRetryPolicy(2), opts: []CallOption{CallMaxRetries(0)}, wantCalls: 1},
+		{name: "more retries", policy: NewDefaultRetryPolicy(0), opts: []CallOption{CallMaxRetries(3)}, wantCalls: 4},
+		{name: "custom policy is capped", policy: alwaysRetry{}, opts: []CallOption{CallMaxRetries(1)}, wantCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			baseURL, _ := url.Parse(server.URL)
+			tr := New(server.Client(), baseURL, WithRetryPolicy(tt.policy))
+
+			ctx := ContextWithCallOptions(context.Background(), tt.opts...)
+			_, _ = doWith(t, ctx, tr, "/rest/api/3/myself")
+			assert.Equal(t, tt.wantCalls, atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+// alwaysRetry retries every failure immediately.
+type alwaysRetry struct{}
+
+func (alwaysRetry) Retry(_ *http.Request, resp *http.Response, err error, _ int) (bool, time.Duration) {
+	return err != nil || resp.StatusCode >= 500, time.Millisecond
+}
+
+func TestCallOptions_Header(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL)
+
+	ctx := ContextWithCallOptions(context.Background(),
+		CallHeader("X-Atlassian-Force-Account-Id", "true"),
+		CallHeader("Accept-Language", "de-DE"),
+	)
+	req, err := tr.NewRequest(ctx, http.MethodGet, "/rest/api/3/myself", nil)
+	require.NoError(t, err)
+	resp, err := tr.Do(ctx, req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, "true", got.Get("X-Atlassian-Force-Account-Id"))
+	assert.Equal(t, "de-DE", got.Get("Accept-Language"))
+	assert.Empty(t, req.Header.Get("Accept-Language"), "the caller's request is not modified")
+}
+
+func TestCallOptions_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rest/api/3/slow" {
+			select {
+			case <-time.After(2 * time.Second):
+			case <-r.Context().Done():
+			}
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL, WithMaxRetries(0))
+
+	ctx := ContextWithCallOptions(context.Background(), CallTimeout(50*time.Millisecond))
+
+	start := time.Now()
+	_, err := doWith(t, ctx, tr, "/rest/api/3/slow")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+
+	// The timeout keeps running while the body is read, and not after
+	status, err := doWith(t, ctx, tr, "/rest/api/3/fast")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func TestCallOptions_CachePolicy(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(`[{"id":"1","name":"High"}]`))
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	cache := NewMemoryCache(10)
+	tr := New(server.Client(), baseURL, WithCache(cache))
+
+	bypass := ContextWithCallOptions(context.Background(), CallCachePolicy(CacheBypass))
+	refresh := ContextWithCallOptions(context.Background(), CallCachePolicy(CacheRefresh))
+
+	// Bypassed calls are neither served from nor stored in the cache
+	_, err := doWith(t, bypass, tr, "/rest/api/3/priority")
+	require.NoError(t, err)
+	_, found := cache.Get(cacheKey("anonymous", mustRequest(t, tr, "/rest/api/3/priority")))
+	assert.False(t, found)
+
+	getBody(t, tr, "/rest/api/3/priority")
+	getBody(t, tr, "/rest/api/3/priority")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "second default call is a hit")
+
+	_, err = doWith(t, bypass, tr, "/rest/api/3/priority")
+	require.NoError(t, err)
+	_, err = doWith(t, refresh, tr, "/rest/api/3/priority")
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+
+	getBody(t, tr, "/rest/api/3/priority")
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls), "refreshed entry is served")
+}
+
+// mustRequest builds a GET request for path.
+func mustRequest(t *testing.T, tr *Transport, path string) *http.Request {
+	t.Helper()
+
+	req, err := tr.NewRequest(context.Background(), http.MethodGet, path, nil)
+	require.NoError(t, err)
+	return req
+}
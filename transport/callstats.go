@@ -0,0 +1,124 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Reasons reported for rate limit waits.
+const (
+	// RateLimitReasonGovernor is a proactive wait because the learned quota ran out
+	RateLimitReasonGovernor = "governor"
+
+	// RateLimitReasonRetryAfter is a wait requested by a 429 Retry-After header
+	RateLimitReasonRetryAfter = "retry_after"
+)
+
+// callStats collects what happened to one logical call inside the middleware
+// chain: how often it was retried and how long it waited for rate limits.
+//
+// The outer instrumentation middleware (tracing, metrics) attaches it to the
+// context; the retry and rate limit middleware report into it. Observers are
+// notified synchronously as events happen.
+type callStats struct {
+	mu            sync.Mutex
+	retries       int
+	rateLimitWait time.Duration
+	observers     []callObserver
+}
+
+// callObserver receives the events of a logical call as they happen.
+type callObserver interface {
+	// retry is called before waiting backoff ahead of retry number attempt (1-based)
+	retry(attempt int, backoff time.Duration, statusCode int, err error)
+
+	// rateLimitWait is called when the call waits for a rate limit
+	rateLimitWait(wait time.Duration, reason string)
+}
+
+type callStatsKey struct{}
+
+// withCallStats returns a context carrying call statistics, reusing any
+// already attached by an outer middleware, and registers observer on it.
+func withCallStats(ctx context.Context, observer callObserver) (context.Context, *callStats) {
+	stats, ok := ctx.Value(callStatsKey{}).(*callStats)
+	if !ok {
+		stats = &callStats{}
+		ctx = context.WithValue(ctx, callStatsKey{}, stats)
+	}
+	if observer != nil {
+		stats.mu.Lock()
+		stats.observers = append(stats.observers, observer)
+		stats.mu.Unlock()
+	}
+	return ctx, stats
+}
+
+// callStatsFrom returns the call statistics attached to ctx, or nil.
+func callStatsFrom(ctx context.Context) *callStats {
+	stats, _ := ctx.Value(callStatsKey{}).(*callStats)
+	return stats
+}
+
+// RecordRetry reports to tracing and metrics that the call made with ctx is
+// about to be retried after backoff. attempt is the 1-based retry number, and
+// statusCode or err describe the failed attempt.
+//
+// The built-in retry middleware reports its own retries. Resilience
+// implementations that retry requests themselves (see RetryingResilience) call
+// it so retries are counted the same way.
+//
+// Example:
+//
+//	transport.RecordRetry(ctx, attempt, delay, resp.StatusCode, nil)
+func RecordRetry(ctx context.Context, attempt int, backoff time.Duration, statusCode int, err error) {
+	recordRetry(ctx, attempt, backoff, statusCode, err)
+}
+
+// recordRetry reports that the call is about to be retried. Waiting out a 429
+// also counts as a rate limit wait.
+func recordRetry(ctx context.Context, attempt int, backoff time.Duration, statusCode int, err error) {
+	stats := callStatsFrom(ctx)
+	if stats == nil {
+		return
+	}
+
+	stats.mu.Lock()
+	stats.retries++
+	observers := stats.observers
+	stats.mu.Unlock()
+
+	for _, o := range observers {
+		o.retry(attempt, backoff, statusCode, err)
+	}
+
+	if statusCode == http.StatusTooManyRequests {
+		recordRateLimitWait(ctx, backoff, RateLimitReasonRetryAfter)
+	}
+}
+
+// recordRateLimitWait reports that the call waited for a rate limit.
+func recordRateLimitWait(ctx context.Context, wait time.Duration, reason string) {
+	stats := callStatsFrom(ctx)
+	if stats == nil || wait <= 0 {
+		return
+	}
+
+	stats.mu.Lock()
+	stats.rateLimitWait += wait
+	observers := stats.observers
+	stats.mu.Unlock()
+
+	for _, o := range observers {
+		o.rateLimitWait(wait, reason)
+	}
+}
+
+// snapshot returns the retry count and total rate limit wait so far.
+func (s *callStats) snapshot() (retries int, rateLimitWait time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.retries, s.rateLimitWait
+}
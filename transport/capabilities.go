@@ -0,0 +1,237 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Feature is an API feature whose availability depends on the Jira
+// deployment and version.
+type Feature string
+
+const (
+	// FeatureEnhancedSearch is JQL search with token pagination
+	// (search/jql). It is only available on Jira Cloud.
+	FeatureEnhancedSearch Feature = "search/jql"
+
+	// FeatureLegacySearch is JQL search with offset pagination (search).
+	// It has been removed from Jira Cloud.
+	FeatureLegacySearch Feature = "search"
+
+	// FeatureExpressionEvaluate is Jira expression evaluation
+	// (expression/evaluate). It is only available on Jira Cloud.
+	FeatureExpressionEvaluate Feature = "expression/evaluate"
+
+	// FeatureLegacyExpressionEval is the original Jira expression endpoint
+	// (expression/eval). It has been removed from Jira Cloud and was never
+	// available on Server/Data Center.
+	FeatureLegacyExpressionEval Feature = "expression/eval"
+)
+
+// Deployment types reported by serverInfo.
+const (
+	DeploymentCloud      = "Cloud"
+	DeploymentServer     = "Server"
+	DeploymentDataCenter = "DataCenter"
+)
+
+// featureSupport records which deployments support each feature.
+var featureSupport = map[Feature]struct{ cloud, selfManaged bool }{
+	FeatureEnhancedSearch:       {cloud: true},
+	FeatureLegacySearch:         {selfManaged: true},
+	FeatureExpressionEvaluate:   {cloud: true},
+	FeatureLegacyExpressionEval: {},
+}
+
+// capabilitiesRetryInterval is how long a failed detection is remembered
+// before serverInfo is asked again.
+const capabilitiesRetryInterval = time.Minute
+
+// ErrUnsupported matches *UnsupportedError.
+var ErrUnsupported = errors.New("jira: not supported by this Jira instance")
+
+// UnsupportedError is returned, before any request is sent, for calls that
+// the Jira instance cannot serve.
+type UnsupportedError struct {
+	// Feature is the unsupported feature
+	Feature Feature
+
+	// DeploymentType is the deployment type of the Jira instance
+	DeploymentType string
+
+	// Version is the version of the Jira instance, if known
+	Version string
+}
+
+// Error implements the error interface.
+func (e *UnsupportedError) Error() string {
+	target := "Jira " + e.DeploymentType
+	if e.Version != "" {
+		target += " " + e.Version
+	}
+	return fmt.Sprintf("jira: %s is not supported by %s", e.Feature, target)
+}
+
+// Is reports whether target is ErrUnsupported.
+func (e *UnsupportedError) Is(target error) bool {
+	return target == ErrUnsupported
+}
+
+// IsUnsupported returns true if the error is an *UnsupportedError.
+func IsUnsupported(err error) bool {
+	return errors.Is(err, ErrUnsupported)
+}
+
+// Capabilities describes the Jira instance a transport talks to.
+type Capabilities struct {
+	// DeploymentType is "Cloud", "Server" or "DataCenter"
+	DeploymentType string
+
+	// Version is the Jira version, such as "9.12.2"
+	Version string
+
+	// VersionNumbers are the numeric parts of Version
+	VersionNumbers []int
+
+	// BuildNumber is the Jira build number
+	BuildNumber int
+}
+
+// IsCloud reports whether the instance is Jira Cloud.
+func (c *Capabilities) IsCloud() bool {
+	return c.DeploymentType == DeploymentCloud
+}
+
+// VersionAtLeast reports whether the version is at least the given version,
+// compared part by part.
+//
+// Example:
+//
+//	caps.VersionAtLeast(9, 12) // Jira 9.12 or later
+func (c *Capabilities) VersionAtLeast(version ...int) bool {
+	for i, want := range version {
+		have := 0
+		if i < len(c.VersionNumbers) {
+			have = c.VersionNumbers[i]
+		}
+		if have != want {
+			return have > want
+		}
+	}
+	return true
+}
+
+// Supports reports whether the instance supports feature. Features this
+// package does not know are assumed to be supported.
+func (c *Capabilities) Supports(feature Feature) bool {
+	support, ok := featureSupport[feature]
+	if !ok {
+		return true
+	}
+	if c.IsCloud() {
+		return support.cloud
+	}
+	return support.selfManaged
+}
+
+// capabilitiesCache holds the result of capability detection.
+type capabilitiesCache struct {
+	mu       sync.Mutex
+	caps     *Capabilities
+	err      error
+	failedAt time.Time
+}
+
+// Capabilities detects the deployment type and version of the Jira instance
+// with serverInfo.
+//
+// The result is cached for the life of the transport. A failed detection is
+// retried on a later call, at most once a minute.
+func (t *Transport) Capabilities(ctx context.Context) (*Capabilities, error) {
+	c := &t.capabilities
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.caps != nil {
+		return c.caps, nil
+	}
+	if c.err != nil && time.Since(c.failedAt) < capabilitiesRetryInterval {
+		return nil, c.err
+	}
+
+	caps, err := t.detectCapabilities(ctx)
+	if err != nil {
+		// A canceled call says nothing about the instance
+		if ctx.Err() == nil {
+			c.err, c.failedAt = err, time.Now()
+		}
+		return nil, err
+	}
+
+	c.caps, c.err = caps, nil
+	return caps, nil
+}
+
+// detectCapabilities asks serverInfo for the deployment type and version.
+func (t *Transport) detectCapabilities(ctx context.Context) (*Capabilities, error) {
+	req, err := t.NewRequest(ctx, http.MethodGet, "/rest/api/3/serverInfo", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := t.Do(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect capabilities: %w", err)
+	}
+
+	var info struct {
+		DeploymentType string `json:"deploymentType"`
+		Version        string `json:"version"`
+		VersionNumbers []int  `json:"versionNumbers"`
+		BuildNumber    int    `json:"buildNumber"`
+	}
+	if err := t.DecodeResponse(resp, &info); err != nil {
+		return nil, fmt.Errorf("failed to detect capabilities: %w", err)
+	}
+
+	if info.DeploymentType == "" {
+		// Treat an instance that reports no deployment type as self-managed
+		info.DeploymentType = DeploymentServer
+	}
+
+	return &Capabilities{
+		DeploymentType: info.DeploymentType,
+		Version:        info.Version,
+		VersionNumbers: info.VersionNumbers,
+		BuildNumber:    info.BuildNumber,
+	}, nil
+}
+
+// CheckFeature returns an *UnsupportedError when the Jira instance does not
+// support feature.
+//
+// Services call it to pick endpoints and to fail early. When detection fails
+// the API flavor decides: APIFlavorDataCenter is treated as Server, and
+// otherwise every feature is assumed to be supported.
+func (t *Transport) CheckFeature(ctx context.Context, feature string) error {
+	caps, err := t.Capabilities(ctx)
+	if err != nil {
+		if t.flavor != APIFlavorDataCenter {
+			return nil
+		}
+		caps = &Capabilities{DeploymentType: DeploymentServer}
+	}
+
+	if caps.Supports(Feature(feature)) {
+		return nil
+	}
+	return &UnsupportedError{
+		Feature:        Feature(feature),
+		DeploymentType: caps.DeploymentType,
+		Version:        caps.Version,
+	}
+}
@@ -0,0 +1,168 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newServerInfoTransport returns a transport whose server answers serverInfo
+// with status and body, counting the calls.
+func newServerInfoTransport(t *testing.T, status int, body string, opts ...TransportOption) (*Transport, *int32) {
+	t.Helper()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, strings.HasSuffix(r.URL.Path, "/serverInfo"), r.URL.Path)
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	baseURL, _ := url.Parse(server.URL)
+	return New(server.Client(), baseURL, opts...), &calls
+}
+
+func TestTransport_Capabilities(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		want     Capabilities
+		isCloud  bool
+		features map[Feature]bool
+	}{
+		{
+			name:    "cloud",
+			body:    `{"deploymentType":"Cloud","version":"1001.0.0-SNAPSHOT","versionNumbers":[1001,0,0],"buildNumber":100245}`,
+			want:    Capabilities{DeploymentType: DeploymentCloud, Version: "1001.0.0-SNAPSHOT", VersionNumbers: []int{1001, 0, 0}, BuildNumber: 100245},
+			isCloud: true,
+			features: map[Feature]bool{
+				FeatureEnhancedSearch:       true,
+				FeatureLegacySearch:         false,
+				FeatureExpressionEvaluate:   true,
+				FeatureLegacyExpressionEval: false,
+				Feature("something/new"):    true,
+			},
+		},
+		{
+			name: "data center",
+			body: `{"deploymentType":"DataCenter","version":"9.12.2","versionNumbers":[9,12,2],"buildNumber":9120002}`,
+			want: Capabilities{DeploymentType: DeploymentDataCenter, Version: "9.12.2", VersionNumbers: []int{9, 12, 2}, BuildNumber: 9120002},
+			features: map[Feature]bool{
+				FeatureEnhancedSearch:       false,
+				FeatureLegacySearch:         true,
+				FeatureExpressionEvaluate:   false,
+				FeatureLegacyExpressionEval: false,
+			},
+		},
+		{
+			name: "no deployment type",
+			body: `{"version":"7.13.0","versionNumbers":[7,13,0]}`,
+			want: Capabilities{DeploymentType: DeploymentServer, Version: "7.13.0", VersionNumbers: []int{7, 13, 0}},
+			features: map[Feature]bool{
+				FeatureLegacySearch: true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, calls := newServerInfoTransport(t, http.StatusOK, tt.body)
+
+			caps, err := tr.Capabilities(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, *caps)
+			assert.Equal(t, tt.isCloud, caps.IsCloud())
+			for feature, want := range tt.features {
+				assert.Equal(t, want, caps.Supports(feature), feature)
+			}
+
+			// Detection runs once
+			again, err := tr.Capabilities(context.Background())
+			require.NoError(t, err)
+			assert.Same(t, caps, again)
+			assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+		})
+	}
+}
+
+func TestCapabilities_VersionAtLeast(t *testing.T) {
+	caps := &Capabilities{VersionNumbers: []int{9, 12, 2}}
+
+	assert.True(t, caps.VersionAtLeast(9))
+	assert.True(t, caps.VersionAtLeast(9, 12))
+	assert.True(t, caps.VersionAtLeast(9, 12, 2))
+	assert.True(t, caps.VersionAtLeast(8, 20))
+	assert.False(t, caps.VersionAtLeast(9, 12, 3))
+	assert.False(t, caps.VersionAtLeast(10))
+	assert.False(t, caps.VersionAtLeast(9, 12, 2, 1))
+	assert.True(t, caps.VersionAtLeast())
+}
+
+func TestTransport_CheckFeature(t *testing.T) {
+	tr, calls := newServerInfoTransport(t, http.StatusOK, `{"deploymentType":"DataCenter","version":"9.12.2"}`)
+
+	assert.NoError(t, tr.CheckFeature(context.Background(), string(FeatureLegacySearch)))
+
+	err := tr.CheckFeature(context.Background(), string(FeatureEnhancedSearch))
+	require.Error(t, err)
+	assert.True(t, IsUnsupported(err))
+	assert.True(t, errors.Is(err, ErrUnsupported))
+	assert.EqualError(t, err, "jira: search/jql is not supported by Jira DataCenter 9.12.2")
+
+	var unsupported *UnsupportedError
+	require.ErrorAs(t, err, &unsupported)
+	assert.Equal(t, FeatureEnhancedSearch, unsupported.Feature)
+	assert.Equal(t, DeploymentDataCenter, unsupported.DeploymentType)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestTransport_CheckFeature_DetectionFailed(t *testing.T) {
+	t.Run("cloud flavor assumes support", func(t *testing.T) {
+		tr, calls := newServerInfoTransport(t, http.StatusNotFound, `{"errorMessages":["not found"]}`)
+
+		_, err := tr.Capabilities(context.Background())
+		assert.Error(t, err)
+
+		assert.NoError(t, tr.CheckFeature(context.Background(), string(FeatureEnhancedSearch)))
+		assert.NoError(t, tr.CheckFeature(context.Background(), string(FeatureLegacySearch)))
+
+		// The failure is remembered rather than retried on every call
+		assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+	})
+
+	t.Run("data center flavor assumes server", func(t *testing.T) {
+		tr, _ := newServerInfoTransport(t, http.StatusNotFound, `{"errorMessages":["not found"]}`, WithAPIFlavor(APIFlavorDataCenter))
+
+		assert.NoError(t, tr.CheckFeature(context.Background(), string(FeatureLegacySearch)))
+
+		err := tr.CheckFeature(context.Background(), string(FeatureEnhancedSearch))
+		assert.True(t, IsUnsupported(err))
+		assert.EqualError(t, err, "jira: search/jql is not supported by Jira Server")
+	})
+
+	t.Run("canceled context is not remembered", func(t *testing.T) {
+		tr, calls := newServerInfoTransport(t, http.StatusOK, `{"deploymentType":"Cloud"}`)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := tr.Capabilities(ctx)
+		assert.Error(t, err)
+
+		caps, err := tr.Capabilities(context.Background())
+		require.NoError(t, err)
+		assert.True(t, caps.IsCloud())
+		assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+	})
+}
@@ -0,0 +1,110 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/felixgeelhaar/jirasdk/auth"
+)
+
+// inflightCall is a GET request shared by concurrent callers.
+type inflightCall struct {
+	done chan struct{}
+
+	// Set before done is closed
+	resp *http.Response
+	body []byte
+	err  error
+}
+
+// inflightGroup tracks the GET requests currently in flight.
+type inflightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*inflightCall
+}
+
+// coalesceMiddleware merges identical concurrent GET requests into one upstream call.
+//
+// Requests are identical when they have the same URL, credentials and
+// conditional headers. The first caller makes the request and buffers the
+// response body; every caller, including the first, receives its own copy.
+// If the shared call fails because the first caller's context was cancelled,
+// waiting callers whose contexts are still live make their own request.
+func coalesceMiddleware(authenticator auth.Authenticator) Middleware {
+	group := &inflightGroup{calls: make(map[string]*inflightCall)}
+
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodGet {
+				return next(ctx, req)
+			}
+
+			identity, err := authIdentity(authenticator, req)
+			if err != nil {
+				return nil, fmt.Errorf("authentication failed: %w", err)
+			}
+			key := cacheKey(identity, req) + " " + req.Header.Get("If-None-Match") + " " +
+				req.Header.Get("If-Modified-Since")
+
+			group.mu.Lock()
+			if call, ok := group.calls[key]; ok {
+				group.mu.Unlock()
+
+				select {
+				case <-call.done:
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+
+				if call.err != nil && isContextError(call.err) && ctx.Err() == nil {
+					return next(ctx, req)
+				}
+				return call.response(req)
+			}
+
+			call := &inflightCall{done: make(chan struct{})}
+			group.calls[key] = call
+			group.mu.Unlock()
+
+			call.resp, call.err = next(ctx, req)
+			if call.err == nil {
+				call.body, call.err = io.ReadAll(call.resp.Body)
+				_ = call.resp.Body.Close() // Explicit ignore, the body is buffered
+				if call.err != nil {
+					call.err = fmt.Errorf("failed to read response body: %w", call.err)
+				}
+			}
+
+			group.mu.Lock()
+			delete(group.calls, key)
+			group.mu.Unlock()
+			close(call.done)
+
+			return call.response(req)
+		}
+	}
+}
+
+// response returns a copy of the shared response for req.
+func (c *inflightCall) response(req *http.Request) (*http.Response, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	resp := *c.resp
+	resp.Header = c.resp.Header.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(c.body))
+	resp.ContentLength = int64(len(c.body))
+	resp.Request = req
+	return &resp, nil
+}
+
+// isContextError reports whether err comes from a cancelled or expired context.
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
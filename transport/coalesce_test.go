@@ -0,0 +1,171 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/jirasdk/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingServer counts requests and holds each one until release is closed.
+func blockingServer(t *testing.T, calls *int32, release chan struct{}) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		<-release
+		_, _ = w.Write([]byte(`{"key":"PROJ-1","auth":"` + r.Header.Get("Authorization") + `"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// waitForCalls polls until the server has seen n requests.
+func waitForCalls(t *testing.T, calls *int32, n int32) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(calls) >= n
+	}, time.Second, time.Millisecond)
+}
+
+func TestCoalesceMiddleware_MergesIdenticalGets(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	server := blockingServer(t, &calls, release)
+
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL, WithCoalescing(true))
+
+	const callers = 10
+	bodies := make([]string, callers)
+	var wg sync.WaitGroup
+
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req, err := tr.NewRequest(context.Background(), http.MethodGet, "/rest/api/3/issue/PROJ-1", nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			resp, err := tr.Do(context.Background(), req)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			bodies[i] = string(body)
+		}(i)
+	}
+
+	waitForCalls(t, &calls, 1)
+	// Give the remaining callers time to join the in-flight request
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, body := range bodies {
+		assert.Equal(t, `{"key":"PROJ-1","auth":""}`, body)
+	}
+}
+
+func TestCoalesceMiddleware_KeepsRequestsApart(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		pathA  string
+		pathB  string
+		authB  auth.Authenticator
+	}{
+		{name: "different paths", method: http.MethodGet, pathA: "/rest/api/3/issue/PROJ-1", pathB: "/rest/api/3/issue/PROJ-2"},
+		{name: "different credentials", method: http.MethodGet, pathA: "/rest/api/3/issue/PROJ-1", pathB: "/rest/api/3/issue/PROJ-1",
+			authB: auth.NewPATAuth("other")},
+		{name: "non-GET", method: http.MethodDelete, pathA: "/rest/api/3/issue/PROJ-1", pathB: "/rest/api/3/issue/PROJ-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			release := make(chan struct{})
+			server := blockingServer(t, &calls, release)
+
+			baseURL, _ := url.Parse(server.URL)
+			trA := New(server.Client(), baseURL, WithCoalescing(true), WithAuthenticator(auth.NewPATAuth("token")))
+			trB := trA
+			if tt.authB != nil {
+				trB = New(server.Client(), baseURL, WithCoalescing(true), WithAuthenticator(tt.authB))
+			}
+
+			var wg sync.WaitGroup
+			for _, c := range []struct {
+				tr   *Transport
+				path string
+			}{{trA, tt.pathA}, {trB, tt.pathB}} {
+				wg.Add(1)
+				go func(tr *Transport, path string) {
+					defer wg.Done()
+					req, err := tr.NewRequest(context.Background(), tt.method, path, nil)
+					if !assert.NoError(t, err) {
+						return
+					}
+					resp, err := tr.Do(context.Background(), req)
+					if assert.NoError(t, err) {
+						_ = resp.Body.Close()
+					}
+				}(c.tr, c.path)
+			}
+
+			waitForCalls(t, &calls, 2)
+			close(release)
+			wg.Wait()
+
+			assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+func TestCoalesceMiddleware_LeaderCancelled(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	server := blockingServer(t, &calls, release)
+
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL, WithCoalescing(true), WithMaxRetries(0))
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderDone := make(chan error, 1)
+	go func() {
+		req, _ := tr.NewRequest(leaderCtx, http.MethodGet, "/rest/api/3/issue/PROJ-1", nil)
+		_, err := tr.Do(leaderCtx, req)
+		leaderDone <- err
+	}()
+	waitForCalls(t, &calls, 1)
+
+	followerDone := make(chan error, 1)
+	go func() {
+		req, _ := tr.NewRequest(context.Background(), http.MethodGet, "/rest/api/3/issue/PROJ-1", nil)
+		resp, err := tr.Do(context.Background(), req)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		followerDone <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	assert.Error(t, <-leaderDone)
+
+	close(release)
+	assert.NoError(t, <-followerDone)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
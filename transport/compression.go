@@ -0,0 +1,171 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// compressionMiddleware negotiates compressed responses and optionally
+// compresses request bodies.
+//
+// When enabled it advertises gzip and deflate and decodes compressed response
+// bodies as they are read. When disabled it asks for identity encoding, which
+// also turns off the transparent gzip handling of net/http.
+//
+// Request bodies of at least minRequestSize bytes are gzipped. A minRequestSize
+// of zero leaves request bodies untouched.
+func compressionMiddleware(enabled bool, minRequestSize int) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			if !enabled {
+				req.Header.Set("Accept-Encoding", "identity")
+				return next(ctx, req)
+			}
+
+			if minRequestSize > 0 && req.ContentLength >= int64(minRequestSize) && req.GetBody != nil &&
+				req.Header.Get("Content-Encoding") == "" {
+				compressed, err := gzipRequestBody(req)
+				if err != nil {
+					return nil, err
+				}
+				req = compressed
+			}
+
+			req.Header.Set("Accept-Encoding", "gzip, deflate")
+
+			resp, err := next(ctx, req)
+			if err != nil {
+				return resp, err
+			}
+
+			decodeResponseBody(resp)
+			return resp, nil
+		}
+	}
+}
+
+// gzipRequestBody returns a copy of req with a gzip-compressed, replayable body.
+func gzipRequestBody(req *http.Request) (*http.Request, error) {
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	defer body.Close()
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := io.Copy(zw, body); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+
+	payload := buf.Bytes()
+	compressed := req.Clone(req.Context())
+	compressed.Body = io.NopCloser(bytes.NewReader(payload))
+	compressed.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(payload)), nil
+	}
+	compressed.ContentLength = int64(len(payload))
+	compressed.Header.Set("Content-Encoding", "gzip")
+
+	return compressed, nil
+}
+
+// decodeResponseBody replaces a gzip or deflate encoded body with a decoding reader.
+func decodeResponseBody(resp *http.Response) {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if encoding != "gzip" && encoding != "deflate" {
+		return
+	}
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return
+	}
+
+	resp.Body = &decompressingBody{body: resp.Body, encoding: encoding}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// decompressingBody decodes a compressed body on demand.
+//
+// The decoder is created on the first Read so that empty bodies (for example
+// on 204 responses that still carry a Content-Encoding header) do not fail.
+type decompressingBody struct {
+	body     io.ReadCloser
+	encoding string
+	reader   io.ReadCloser
+	err      error
+}
+
+// Read implements io.Reader.
+func (d *decompressingBody) Read(p []byte) (int, error) {
+	if d.reader == nil && d.err == nil {
+		switch d.encoding {
+		case "gzip":
+			zr, err := gzip.NewReader(d.body)
+			if err != nil {
+				d.err = decodeError("gzip", err)
+			} else {
+				d.reader = zr
+			}
+		case "deflate":
+			d.reader, d.err = newDeflateReader(d.body)
+		}
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	return d.reader.Read(p)
+}
+
+// newDeflateReader decodes a deflate body.
+//
+// RFC 9110 defines deflate as zlib-wrapped data, but some servers send raw
+// deflate streams, so the zlib header is checked before choosing a decoder.
+func newDeflateReader(body io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(body)
+	header, err := br.Peek(2)
+	if err != nil {
+		return nil, decodeError("deflate", err)
+	}
+
+	// A zlib header has compression method 8 and a checksum that divides by 31
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		zr, err := zlib.NewReader(br)
+		if err != nil {
+			return nil, decodeError("deflate", err)
+		}
+		return zr, nil
+	}
+
+	return flate.NewReader(br), nil
+}
+
+// decodeError reports a decoder setup failure; an empty body is a clean EOF.
+func decodeError(encoding string, err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.EOF
+	}
+	return fmt.Errorf("failed to decode %s response: %w", encoding, err)
+}
+
+// Close closes the decoder and the underlying body.
+func (d *decompressingBody) Close() error {
+	if d.reader != nil {
+		_ = d.reader.Close() // Explicit ignore, the underlying body close is what matters
+	}
+	return d.body.Close()
+}
@@ -0,0 +1,163 @@
+package transport
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compress(t *testing.T, encoding, data string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		fw, err := flate.NewWriter(&buf, flate.DefaultCompression)
+		require.NoError(t, err)
+		w = fw
+	}
+	_, err := w.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestCompressionMiddleware_DecodesResponses(t *testing.T) {
+	payload := `{"issues":[{"key":"PROJ-1"}]}`
+
+	tests := []struct {
+		name     string
+		encoding string
+		header   string
+	}{
+		{name: "gzip", encoding: "gzip", header: "gzip"},
+		{name: "zlib deflate", encoding: "deflate", header: "deflate"},
+		{name: "raw deflate", encoding: "raw-deflate", header: "deflate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "gzip, deflate", r.Header.Get("Accept-Encoding"))
+				w.Header().Set("Content-Encoding", tt.header)
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write(compress(t, tt.encoding, payload))
+			}))
+			defer server.Close()
+
+			baseURL, _ := url.Parse(server.URL)
+			tr := New(server.Client(), baseURL)
+
+			req, err := tr.NewRequest(context.Background(), http.MethodGet, "/rest/api/3/search/jql", nil)
+			require.NoError(t, err)
+
+			resp, err := tr.Do(context.Background(), req)
+			require.NoError(t, err)
+
+			assert.Empty(t, resp.Header.Get("Content-Encoding"))
+			assert.True(t, resp.Uncompressed)
+
+			var result map[string]interface{}
+			require.NoError(t, tr.DecodeResponse(resp, &result))
+			assert.Len(t, result["issues"], 1)
+		})
+	}
+}
+
+func TestCompressionMiddleware_EmptyBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL)
+
+	req, err := tr.NewRequest(context.Background(), http.MethodDelete, "/rest/api/3/issue/PROJ-1", nil)
+	require.NoError(t, err)
+
+	resp, err := tr.Do(context.Background(), req)
+	require.NoError(t, err)
+	assert.NoError(t, tr.DecodeResponse(resp, nil))
+}
+
+func TestCompressionMiddleware_Disabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "identity", r.Header.Get("Accept-Encoding"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL, WithCompression(false))
+
+	req, err := tr.NewRequest(context.Background(), http.MethodGet, "/rest/api/3/myself", nil)
+	require.NoError(t, err)
+
+	resp, err := tr.Do(context.Background(), req)
+	require.NoError(t, err)
+	resp.Body.Close()
+}
+
+func TestCompressionMiddleware_RequestBodies(t *testing.T) {
+	large := map[string]string{"description": strings.Repeat("a", 2048)}
+	small := map[string]string{"summary": "short"}
+
+	tests := []struct {
+		name         string
+		body         interface{}
+		wantEncoding string
+	}{
+		{name: "large body is gzipped", body: large, wantEncoding: "gzip"},
+		{name: "small body is sent as is", body: small, wantEncoding: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tt.wantEncoding, r.Header.Get("Content-Encoding"))
+
+				var reader io.Reader = r.Body
+				if tt.wantEncoding == "gzip" {
+					zr, err := gzip.NewReader(r.Body)
+					require.NoError(t, err)
+					reader = zr
+				}
+				body, err := io.ReadAll(reader)
+				require.NoError(t, err)
+				assert.Contains(t, string(body), "{")
+
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer server.Close()
+
+			baseURL, _ := url.Parse(server.URL)
+			tr := New(server.Client(), baseURL, WithRequestCompression(1024))
+
+			req, err := tr.NewRequest(context.Background(), http.MethodPost, "/rest/api/3/issue/bulk", tt.body)
+			require.NoError(t, err)
+
+			resp, err := tr.Do(context.Background(), req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,495 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Redacted replaces redacted header, query and field values in debug dumps.
+const Redacted = "REDACTED"
+
+// DefaultDebugMaxBodySize is the number of body bytes a debug dump shows
+// before truncating.
+const DefaultDebugMaxBodySize = 8 * 1024
+
+// alwaysRedactedHeaders are redacted in every debug dump.
+var alwaysRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// alwaysRedactedFields are OAuth credentials, redacted in every debug dump
+// wherever they appear as JSON fields or query parameters.
+var alwaysRedactedFields = []string{"access_token", "refresh_token", "id_token", "client_secret"}
+
+// DebugOption configures the debug dump middleware.
+type DebugOption func(*debugConfig)
+
+type debugConfig struct {
+	writer          io.Writer
+	redactedHeaders []string
+	redactedFields  [][]string
+	maxBodySize     int
+	sampleRate      float64
+}
+
+// DebugToWriter writes dumps to w instead of the configured Logger.
+func DebugToWriter(w io.Writer) DebugOption {
+	return func(cfg *debugConfig) {
+		cfg.writer = w
+	}
+}
+
+// DebugRedactHeaders redacts these headers in addition to Authorization,
+// Proxy-Authorization, Cookie and Set-Cookie.
+func DebugRedactHeaders(headers ...string) DebugOption {
+	return func(cfg *debugConfig) {
+		cfg.redactedHeaders = append(cfg.redactedHeaders, headers...)
+	}
+}
+
+// DebugRedactFields redacts JSON fields in request and response bodies.
+//
+// A path is a field name or a dot-separated chain of field names, matched
+// against the end of each field's path with array indexes ignored:
+// "emailAddress" redacts that field at any depth, "reporter.emailAddress"
+// only the reporter's. OAuth tokens are always redacted.
+func DebugRedactFields(paths ...string) DebugOption {
+	return func(cfg *debugConfig) {
+		for _, path := range paths {
+			cfg.redactedFields = append(cfg.redactedFields, strings.Split(path, "."))
+		}
+	}
+}
+
+// DebugMaxBodySize truncates dumped bodies after n bytes. The default is
+// DefaultDebugMaxBodySize; zero or less disables truncation.
+//
+// Only the first n bytes of a response body are read for the dump, so large
+// responses are not buffered in memory; the caller still reads the full body.
+func DebugMaxBodySize(n int) DebugOption {
+	return func(cfg *debugConfig) {
+		cfg.maxBodySize = n
+	}
+}
+
+// DebugSampleRate dumps only a fraction of successful exchanges, between 0
+// and 1. Failed exchanges (transport errors and 4xx or 5xx responses) are
+// always dumped. The default is 1.
+func DebugSampleRate(rate float64) DebugOption {
+	return func(cfg *debugConfig) {
+		cfg.sampleRate = rate
+	}
+}
+
+// debugDumper formats and emits redacted dumps of HTTP exchanges.
+type debugDumper struct {
+	cfg    debugConfig
+	logger Logger
+	mu     sync.Mutex // serialises writes to cfg.writer
+}
+
+func newDebugDumper(opts []DebugOption, logger Logger) *debugDumper {
+	cfg := debugConfig{
+		redactedHeaders: append([]string{}, alwaysRedactedHeaders...),
+		maxBodySize:     DefaultDebugMaxBodySize,
+		sampleRate:      1,
+	}
+	for _, field := range alwaysRedactedFields {
+		cfg.redactedFields = append(cfg.redactedFields, []string{field})
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &debugDumper{cfg: cfg, logger: logger}
+}
+
+// debugMiddleware dumps every request attempt and its response, with
+// credentials and configured fields redacted.
+//
+// It sits closest to the network, so each retry is dumped separately and the
+// headers are exactly those sent, including the (redacted) Authorization.
+// Compressed bodies are decoded for the dump only.
+func debugMiddleware(dumper *debugDumper) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			if dumper.cfg.writer == nil && dumper.logger == nil {
+				return next(ctx, req)
+			}
+
+			start := time.Now()
+			resp, err := next(ctx, req)
+			duration := time.Since(start)
+
+			if !dumper.shouldDump(resp, err) {
+				return resp, err
+			}
+
+			var respBody []byte
+			if resp != nil && resp.Body != nil && resp.Body != http.NoBody {
+				respBody = dumper.peekBody(resp)
+			}
+
+			dumper.dump(ctx, req, resp, respBody, duration, err)
+			return resp, err
+		}
+	}
+}
+
+// peekBody reads the uncompressed response body for the dump, at most one
+// byte past the dump limit, and puts the bytes it consumed back in front of
+// the rest of the body so the caller still reads all of it.
+//
+// A read error ends the dump's body early; the caller gets the same error
+// when it reaches that point of the body.
+func (d *debugDumper) peekBody(resp *http.Response) []byte {
+	var consumed bytes.Buffer
+	body := resp.Body
+
+	peek := &http.Response{Header: resp.Header.Clone(), Body: io.NopCloser(io.TeeReader(body, &consumed))}
+	decodeResponseBody(peek)
+
+	var reader io.Reader = peek.Body
+	if d.cfg.maxBodySize > 0 {
+		reader = io.LimitReader(reader, int64(d.cfg.maxBodySize)+1)
+	}
+	data, _ := io.ReadAll(reader) // Explicit ignore, the caller sees the error when reading the body
+
+	resp.Body = &splicedBody{Reader: io.MultiReader(&consumed, body), Closer: body}
+	return data
+}
+
+// splicedBody reads the bytes consumed by the dump followed by the rest of
+// the original body, and closes the original body.
+type splicedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// shouldDump applies sampling to successful exchanges.
+func (d *debugDumper) shouldDump(resp *http.Response, err error) bool {
+	if err != nil || resp == nil || resp.StatusCode >= 400 {
+		return true
+	}
+	if d.cfg.sampleRate >= 1 {
+		return true
+	}
+	return rand.Float64() < d.cfg.sampleRate // #nosec G404 -- Weak random OK for sampling, doesn't need crypto/rand
+}
+
+// dump emits one exchange to the writer or logger.
+func (d *debugDumper) dump(ctx context.Context, req *http.Request, resp *http.Response, respBody []byte, duration time.Duration, err error) {
+	reqURL := d.redactURL(req.URL)
+	reqHeaders := d.formatHeader(req.Header)
+	reqBody := d.formatBody(requestBodyForDump(req))
+
+	if d.cfg.writer != nil {
+		var b strings.Builder
+		fmt.Fprintf(&b, "--> %s %s\n%s\n", req.Method, reqURL, reqHeaders)
+		if reqBody != "" {
+			fmt.Fprintf(&b, "%s\n", reqBody)
+		}
+		if err != nil {
+			fmt.Fprintf(&b, "<-- error: %v (%s)\n\n", err, duration)
+		} else {
+			fmt.Fprintf(&b, "<-- %s (%s)\n%s\n", resp.Status, duration, d.formatHeader(resp.Header))
+			if body := d.formatBody(respBody); body != "" {
+				fmt.Fprintf(&b, "%s\n", body)
+			}
+			b.WriteString("\n")
+		}
+
+		d.mu.Lock()
+		_, _ = io.WriteString(d.cfg.writer, b.String()) // Explicit ignore, dumping must not fail the request
+		d.mu.Unlock()
+		return
+	}
+
+	fields := []Field{
+		String("method", req.Method),
+		String("url", reqURL),
+		Duration("duration", duration),
+		String("request_headers", reqHeaders),
+		String("request_body", reqBody),
+	}
+	if err != nil {
+		fields = append(fields, Err(err))
+	} else {
+		fields = append(fields,
+			Int("status", resp.StatusCode),
+			String("response_headers", d.formatHeader(resp.Header)),
+			String("response_body", d.formatBody(respBody)),
+		)
+	}
+	d.logger.Debug(ctx, "jira_http_dump", fields...)
+}
+
+// redactURL returns u with OAuth tokens in the query redacted.
+func (d *debugDumper) redactURL(u *url.URL) string {
+	query := u.Query()
+	redacted := false
+	for _, name := range alwaysRedactedFields {
+		if query.Has(name) {
+			query.Set(name, Redacted)
+			redacted = true
+		}
+	}
+	if !redacted {
+		return u.String()
+	}
+
+	clean := *u
+	clean.RawQuery = query.Encode()
+	return clean.String()
+}
+
+// formatHeader renders header one "Name: value" per line, sorted, with
+// sensitive values redacted.
+func (d *debugDumper) formatHeader(header http.Header) string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		value := strings.Join(header[name], ", ")
+		for _, redacted := range d.cfg.redactedHeaders {
+			if strings.EqualFold(name, redacted) {
+				value = Redacted
+				break
+			}
+		}
+		fmt.Fprintf(&b, "%s: %s\n", name, value)
+	}
+	return b.String()
+}
+
+// formatBody redacts and truncates a body for display.
+func (d *debugDumper) formatBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	// The body was cut off when it was read, so it cannot be decoded whole
+	if d.cfg.maxBodySize > 0 && len(body) > d.cfg.maxBodySize {
+		return fmt.Sprintf("%s... (truncated after %d bytes)", d.redactPartial(body[:d.cfg.maxBodySize]), d.cfg.maxBodySize)
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err == nil {
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(d.redactValue(value, nil)); err == nil {
+			body = bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+		}
+	}
+
+	if d.cfg.maxBodySize > 0 && len(body) > d.cfg.maxBodySize {
+		return fmt.Sprintf("%s... (truncated, %d bytes total)", body[:d.cfg.maxBodySize], len(body))
+	}
+	return string(body)
+}
+
+// redactPartial re-encodes a truncated JSON body with the configured fields
+// redacted. The value cut off by the truncation is shown as is, unless it
+// belongs to a redacted field. Bodies that are not JSON are returned
+// unchanged.
+func (d *debugDumper) redactPartial(body []byte) []byte {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return body
+	}
+
+	// container is an object or array being re-encoded
+	type container struct {
+		object    bool
+		expectKey bool
+		count     int
+		key       string
+	}
+	var stack []*container
+	path := func() []string {
+		var names []string
+		for _, c := range stack {
+			if c.object {
+				names = append(names, c.key)
+			}
+		}
+		return names
+	}
+	// valueDone marks the value of the innermost object's current key as written
+	valueDone := func() {
+		if len(stack) > 0 && stack[len(stack)-1].object {
+			stack[len(stack)-1].expectKey = true
+		}
+	}
+
+	var out bytes.Buffer
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			// Show what is left of the value that was cut off
+			rest := bytes.TrimLeft(body[decoder.InputOffset():], " \t\r\n:")
+			if comma, ok := bytes.CutPrefix(rest, []byte(",")); ok {
+				out.WriteByte(',')
+				rest = bytes.TrimLeft(comma, " \t\r\n")
+			}
+			out.Write(rest)
+			break
+		}
+
+		var top *container
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		if delim, ok := tok.(json.Delim); ok && (delim == '}' || delim == ']') {
+			out.WriteString(delim.String())
+			stack = stack[:len(stack)-1]
+			valueDone()
+			continue
+		}
+
+		if top != nil && top.object && top.expectKey {
+			key, _ := tok.(string)
+			if top.count > 0 {
+				out.WriteByte(',')
+			}
+			top.count++
+			top.key = key
+			top.expectKey = false
+			writeJSON(&out, key)
+			out.WriteByte(':')
+
+			if d.isRedactedField(path()) {
+				writeJSON(&out, Redacted)
+				var skip json.RawMessage
+				if err := decoder.Decode(&skip); err != nil {
+					break // The redacted value was cut off
+				}
+				top.expectKey = true
+			}
+			continue
+		}
+
+		if top != nil && !top.object {
+			if top.count > 0 {
+				out.WriteByte(',')
+			}
+			top.count++
+		}
+
+		switch tok {
+		case json.Delim('{'):
+			out.WriteByte('{')
+			stack = append(stack, &container{object: true, expectKey: true})
+		case json.Delim('['):
+			out.WriteByte('[')
+			stack = append(stack, &container{})
+		default:
+			writeJSON(&out, tok)
+			valueDone()
+		}
+	}
+	return out.Bytes()
+}
+
+// writeJSON appends the JSON encoding of a scalar value to out.
+func writeJSON(out *bytes.Buffer, value interface{}) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err == nil {
+		out.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	}
+}
+
+// redactValue walks a decoded JSON value and replaces fields matching the
+// configured paths. path holds the field names leading to value.
+func (d *debugDumper) redactValue(value interface{}, path []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := append(path[:len(path):len(path)], key)
+			if d.isRedactedField(childPath) {
+				v[key] = Redacted
+			} else {
+				v[key] = d.redactValue(child, childPath)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = d.redactValue(child, path)
+		}
+	}
+	return value
+}
+
+// isRedactedField reports whether a configured path matches the end of path.
+func (d *debugDumper) isRedactedField(path []string) bool {
+	for _, suffix := range d.cfg.redactedFields {
+		if len(suffix) > len(path) {
+			continue
+		}
+		match := true
+		for i, name := range suffix {
+			if path[len(path)-len(suffix)+i] != name {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// requestBodyForDump returns the uncompressed request body, if it can be
+// read without consuming it.
+func requestBodyForDump(req *http.Request) []byte {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil
+	}
+	return decodeForDump(req.Header, data)
+}
+
+// decodeForDump decodes a gzip or deflate encoded body; other bodies and
+// bodies that fail to decode are returned unchanged.
+func decodeForDump(header http.Header, body []byte) []byte {
+	if len(body) == 0 || header.Get("Content-Encoding") == "" {
+		return body
+	}
+
+	tmp := &http.Response{Header: header.Clone(), Body: io.NopCloser(bytes.NewReader(body))}
+	decodeResponseBody(tmp)
+	decoded, err := io.ReadAll(tmp.Body)
+	if err != nil {
+		return body
+	}
+	return decoded
+}
@@ -0,0 +1,234 @@
+package transport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/felixgeelhaar/jirasdk/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingLogger keeps the debug messages it receives.
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []map[string]interface{}
+}
+
+func (l *recordingLogger) Debug(_ context.Context, msg string, fields ...Field) {
+	entry := map[string]interface{}{"msg": msg}
+	for _, f := range fields {
+		entry[f.Key] = f.Value
+	}
+	l.mu.Lock()
+	l.entries = append(l.entries, entry)
+	l.mu.Unlock()
+}
+func (l *recordingLogger) Info(context.Context, string, ...Field)  {}
+func (l *recordingLogger) Warn(context.Context, string, ...Field)  {}
+func (l *recordingLogger) Error(context.Context, string, ...Field) {}
+func (l *recordingLogger) With(...Field) Logger                    { return l }
+
+func TestDebugMiddleware_Writer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret-session"})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"errors":{"summary":"required"},"reporter":{"emailAddress":"alice@example.com","displayName":"Alice"}}`))
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL,
+		WithAuthenticator(auth.NewPATAuth("secret-token")),
+		WithMaxRetries(0),
+		WithDebug(
+			DebugToWriter(&out),
+			DebugRedactFields("emailAddress", "fields.reporter.displayName"),
+		),
+	)
+
+	body := map[string]interface{}{
+		"fields": map[string]interface{}{
+			"summary":  "",
+			"reporter": map[string]string{"displayName": "Bob", "emailAddress": "bob@example.com"},
+		},
+		"access_token": "oauth-secret",
+	}
+	req, err := tr.NewRequest(context.Background(), http.MethodPost, "/rest/api/3/issue?access_token=query-secret", body)
+	require.NoError(t, err)
+	resp, err := tr.Do(context.Background(), req)
+	require.NoError(t, err)
+
+	// The caller still gets the full response body
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Contains(t, string(data), "alice@example.com")
+
+	dump := out.String()
+	assert.Contains(t, dump, "--> POST ")
+	assert.Contains(t, dump, "/rest/api/3/issue?access_token=REDACTED")
+	assert.Contains(t, dump, "Authorization: REDACTED")
+	assert.Contains(t, dump, "Set-Cookie: REDACTED")
+	assert.Contains(t, dump, "<-- 400 Bad Request")
+	assert.Contains(t, dump, `"summary":"required"`)
+	assert.Contains(t, dump, `"displayName":"Alice"`, "paths only match their own parents")
+
+	for _, secret := range []string{"secret-token", "secret-session", "oauth-secret", "query-secret", "alice@example.com", "bob@example.com", "Bob"} {
+		assert.NotContains(t, dump, secret)
+	}
+}
+
+func TestDebugMiddleware_Logger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"key":"PROJ-1"}`))
+	}))
+	defer server.Close()
+
+	logger := &recordingLogger{}
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL, WithLogger(logger), WithDebug())
+
+	doGet(t, tr, "/rest/api/3/issue/PROJ-1")
+
+	var dumps []map[string]interface{}
+	for _, entry := range logger.entries {
+		if entry["msg"] == "jira_http_dump" {
+			dumps = append(dumps, entry)
+		}
+	}
+	require.Len(t, dumps, 1)
+	assert.Equal(t, http.MethodGet, dumps[0]["method"])
+	assert.Equal(t, http.StatusOK, dumps[0]["status"])
+	assert.Equal(t, `{"key":"PROJ-1"}`, dumps[0]["response_body"])
+}
+
+func TestDebugMiddleware_CompressedAndTruncated(t *testing.T) {
+	large := `{"description":"` + strings.Repeat("x", 500) + `"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		_, _ = zw.Write([]byte(large))
+		_ = zw.Close()
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL, WithDebug(DebugToWriter(&out), DebugMaxBodySize(40)))
+
+	req, err := tr.NewRequest(context.Background(), http.MethodGet, "/rest/api/3/issue/PROJ-1", nil)
+	require.NoError(t, err)
+	resp, err := tr.Do(context.Background(), req)
+	require.NoError(t, err)
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, large, string(data))
+
+	assert.Contains(t, out.String(), `{"description":"xxxxx`)
+	assert.Contains(t, out.String(), "... (truncated after 40 bytes)")
+}
+
+// sizedBody counts the bytes read from a response body.
+type sizedBody struct {
+	io.Reader
+	read int
+}
+
+func (b *sizedBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	b.read += n
+	return n, err
+}
+
+func (b *sizedBody) Close() error { return nil }
+
+func TestDebugMiddleware_ReadsOnlyDumpedBody(t *testing.T) {
+	large := `{"issues":[` + strings.Repeat(`{"key":"PROJ-1"},`, 1000) + `{"key":"PROJ-2"}]}`
+	body := &sizedBody{Reader: strings.NewReader(large)}
+	next := func(ctx context.Context, req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Header: http.Header{}, Body: body}, nil
+	}
+
+	var out bytes.Buffer
+	dumper := newDebugDumper([]DebugOption{DebugToWriter(&out), DebugMaxBodySize(100)}, nil)
+	req := httptest.NewRequest(http.MethodGet, "https://example.atlassian.net/rest/api/3/search/jql", nil)
+	resp, err := debugMiddleware(dumper)(next)(context.Background(), req)
+	require.NoError(t, err)
+
+	assert.Equal(t, 101, body.read, "only the dumped part is read before the caller")
+	assert.Contains(t, out.String(), "... (truncated after 100 bytes)")
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, large, string(data))
+}
+
+func TestDebugDumper_RedactPartial(t *testing.T) {
+	dumper := newDebugDumper([]DebugOption{DebugRedactFields("emailAddress")}, nil)
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "cut inside a redacted value",
+			body: `{"key":"PROJ-1","reporter":{"emailAddress":"alice@exa`,
+			want: `{"key":"PROJ-1","reporter":{"emailAddress":"REDACTED"`,
+		},
+		{
+			name: "redacted value before the cut",
+			body: `[{"emailAddress":"alice@example.com","displayName":"Ali`,
+			want: `[{"emailAddress":"REDACTED","displayName":"Ali`,
+		},
+		{
+			name: "cut inside a key",
+			body: `{"key":"PROJ-1", "emailAdd`,
+			want: `{"key":"PROJ-1","emailAdd`,
+		},
+		{
+			name: "not JSON",
+			body: `<html><body>emailAddress`,
+			want: `<html><body>emailAddress`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, string(dumper.redactPartial([]byte(tt.body))))
+		})
+	}
+}
+
+func TestDebugMiddleware_Sampling(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rest/api/3/issue/MISSING-1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL, WithMaxRetries(0), WithDebug(DebugToWriter(&out), DebugSampleRate(0)))
+
+	doGet(t, tr, "/rest/api/3/issue/PROJ-1")
+	assert.Empty(t, out.String(), "successful calls are sampled out")
+
+	doGet(t, tr, "/rest/api/3/issue/MISSING-1")
+	assert.Contains(t, out.String(), "<-- 404 Not Found", "failed calls are always dumped")
+}
@@ -0,0 +1,261 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DryRunHeader is set on the synthetic responses returned in dry-run mode.
+const DryRunHeader = "X-Jirasdk-Dry-Run"
+
+// dryRunStatus lists endpoints whose callers expect a success status other
+// than 204 No Content. Keys are "<METHOD> <endpoint template>", with the
+// template relative to the API root so they match both /rest/api/2 and
+// /rest/api/3.
+var dryRunStatus = map[string]int{
+	"POST /issueLink":                        http.StatusCreated,
+	"POST /project/{projectIdOrKey}/restore": http.StatusOK,
+}
+
+// PlannedRequest is a mutating request captured in dry-run mode.
+type PlannedRequest struct {
+	// Method is the HTTP method, e.g. "POST"
+	Method string `json:"method"`
+
+	// Path is the request path, e.g. "/rest/api/3/issue/PROJ-123"
+	Path string `json:"path"`
+
+	// Query is the raw query string, if any
+	Query string `json:"query,omitempty"`
+
+	// Endpoint is the endpoint template, e.g. "/rest/api/3/issue/{issueIdOrKey}"
+	Endpoint string `json:"endpoint"`
+
+	// Body is the decoded JSON request body. Bodies that are not JSON are
+	// kept as a string.
+	Body interface{} `json:"body,omitempty"`
+}
+
+// Plan collects the mutating requests that dry-run mode kept from being sent.
+//
+// A Plan is safe for concurrent use. Export it with WriteJSON or Diff once the
+// script has run.
+type Plan struct {
+	mu       sync.Mutex
+	requests []PlannedRequest
+}
+
+// NewPlan creates an empty plan.
+func NewPlan() *Plan {
+	return &Plan{}
+}
+
+// Requests returns the captured requests in the order they were made.
+func (p *Plan) Requests() []PlannedRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]PlannedRequest(nil), p.requests...)
+}
+
+// Len returns the number of captured requests.
+func (p *Plan) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.requests)
+}
+
+// Reset removes all captured requests.
+func (p *Plan) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests = nil
+}
+
+// MarshalJSON encodes the plan as a JSON array of requests.
+func (p *Plan) MarshalJSON() ([]byte, error) {
+	requests := p.Requests()
+	if requests == nil {
+		requests = []PlannedRequest{}
+	}
+	return json.Marshal(requests)
+}
+
+// WriteJSON writes the plan to w as indented JSON.
+func (p *Plan) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write plan: %w", err)
+	}
+	return nil
+}
+
+// Diff returns the plan in a human-readable form.
+//
+// Each request is printed on one line prefixed with "+" for POST (create),
+// "~" for PUT and PATCH (update) and "-" for DELETE, e.g.
+// "- DELETE /rest/api/3/issue/PROJ-7", followed by its indented JSON body.
+func (p *Plan) Diff() string {
+	requests := p.Requests()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Dry run: %d request(s) not sent\n", len(requests))
+
+	for _, r := range requests {
+		target := r.Path
+		if r.Query != "" {
+			target += "?" + r.Query
+		}
+		fmt.Fprintf(&b, "%s %s %s\n", diffMarker(r.Method), r.Method, target)
+
+		if r.Body == nil {
+			continue
+		}
+		body, err := json.MarshalIndent(r.Body, "    ", "  ")
+		if err != nil {
+			body = []byte(fmt.Sprint(r.Body))
+		}
+		fmt.Fprintf(&b, "    %s\n", body)
+	}
+
+	return b.String()
+}
+
+func (p *Plan) add(r PlannedRequest) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests = append(p.requests, r)
+}
+
+// diffMarker returns the diff prefix for a method.
+func diffMarker(method string) string {
+	switch method {
+	case http.MethodPost:
+		return "+"
+	case http.MethodDelete:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+type dryRunKey struct{}
+
+// ContextWithDryRun returns a context that puts the calls made with it in
+// dry-run mode: mutating requests are captured into plan instead of being
+// sent. It takes precedence over a plan set with WithDryRun.
+func ContextWithDryRun(ctx context.Context, plan *Plan) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, plan)
+}
+
+// dryRunMiddleware captures mutating requests into a plan and answers them
+// with a synthetic success.
+//
+// GET requests and POSTs to read-only endpoints such as search/jql are sent
+// as usual, so scripts can still look up what they are about to change.
+func dryRunMiddleware(plan *Plan) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			target := plan
+			if p, ok := ctx.Value(dryRunKey{}).(*Plan); ok {
+				target = p
+			}
+			if target == nil || !isMutating(req) {
+				return next(ctx, req)
+			}
+
+			planned, err := planRequest(req)
+			if err != nil {
+				return nil, err
+			}
+			target.add(planned)
+
+			status := http.StatusNoContent
+			if s, ok := dryRunStatus[req.Method+" "+trimAPIPrefix(planned.Endpoint)]; ok {
+				status = s
+			}
+
+			header := make(http.Header)
+			header.Set("Content-Type", "application/json")
+			header.Set(DryRunHeader, "true")
+
+			// The body is an empty object so that callers decoding a result
+			// get a zero value instead of an error.
+			return &http.Response{
+				Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+				StatusCode:    status,
+				Proto:         "HTTP/1.1",
+				ProtoMajor:    1,
+				ProtoMinor:    1,
+				Header:        header,
+				Body:          io.NopCloser(strings.NewReader("{}")),
+				ContentLength: 2,
+				Request:       req,
+			}, nil
+		}
+	}
+}
+
+// isMutating reports whether req would change data in Jira.
+func isMutating(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	case http.MethodPost:
+		return !isReadOnlyEndpoint(req.URL.Path, DefaultReadOnlyEndpoints)
+	default:
+		return true
+	}
+}
+
+// planRequest captures method, path and decoded body of req.
+func planRequest(req *http.Request) (PlannedRequest, error) {
+	planned := PlannedRequest{
+		Method:   req.Method,
+		Path:     req.URL.Path,
+		Query:    req.URL.RawQuery,
+		Endpoint: EndpointTemplate(req.URL.Path),
+	}
+
+	var body io.ReadCloser
+	switch {
+	case req.GetBody != nil:
+		b, err := req.GetBody()
+		if err != nil {
+			return planned, fmt.Errorf("failed to read request body: %w", err)
+		}
+		body = b
+	case req.Body != nil && req.Body != http.NoBody:
+		body = req.Body
+	default:
+		return planned, nil
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return planned, fmt.Errorf("failed to read request body: %w", err)
+	}
+	if len(data) == 0 {
+		return planned, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		planned.Body = string(data)
+		return planned, nil
+	}
+	planned.Body = decoded
+
+	return planned, nil
+}
@@ -0,0 +1,191 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRun(t *testing.T) {
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.Method+" "+r.URL.Path)
+		_, _ = w.Write([]byte(`{"issues":[]}`))
+	}))
+	defer server.Close()
+
+	plan := NewPlan()
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL, WithDryRun(plan))
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       interface{}
+		wantStatus int
+	}{
+		{"get is sent", http.MethodGet, "/rest/api/3/issue/PROJ-1", nil, http.StatusOK},
+		{"read-only post is sent", http.MethodPost, "/rest/api/3/search/jql", map[string]string{"jql": "project = PROJ"}, http.StatusOK},
+		{"create is planned", http.MethodPost, "/rest/api/3/issue", map[string]interface{}{"fields": map[string]interface{}{"summary": "New", "customfield_10016": 5}}, http.StatusNoContent},
+		{"update is planned", http.MethodPut, "/rest/api/3/issue/PROJ-2", map[string]interface{}{"fields": map[string]string{"summary": "Renamed"}}, http.StatusNoContent},
+		{"delete is planned", http.MethodDelete, "/rest/api/3/issue/PROJ-3?deleteSubtasks=true", nil, http.StatusNoContent},
+		{"link create gets 201", http.MethodPost, "/rest/api/3/issueLink", map[string]string{"type": "Blocks"}, http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := tr.NewRequest(ctx, tt.method, tt.path, tt.body)
+			require.NoError(t, err)
+
+			resp, err := tr.Do(ctx, req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			if tt.wantStatus != http.StatusOK {
+				assert.Equal(t, "true", resp.Header.Get(DryRunHeader))
+				var decoded map[string]interface{}
+				require.NoError(t, tr.DecodeResponse(resp, &decoded))
+			}
+		})
+	}
+
+	assert.Equal(t, []string{"GET /rest/api/3/issue/PROJ-1", "POST /rest/api/3/search/jql"}, sent)
+
+	requests := plan.Requests()
+	require.Len(t, requests, 4)
+
+	assert.Equal(t, http.MethodPost, requests[0].Method)
+	assert.Equal(t, "/rest/api/3/issue", requests[0].Path)
+	fields := requests[0].Body.(map[string]interface{})["fields"].(map[string]interface{})
+	assert.Equal(t, "New", fields["summary"])
+	assert.Equal(t, json.Number("5"), fields["customfield_10016"])
+
+	assert.Equal(t, "/rest/api/3/issue/{issueIdOrKey}", requests[1].Endpoint)
+
+	assert.Equal(t, http.MethodDelete, requests[2].Method)
+	assert.Equal(t, "deleteSubtasks=true", requests[2].Query)
+	assert.Nil(t, requests[2].Body)
+}
+
+func TestDryRun_DataCenter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+
+	plan := NewPlan()
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL, WithAPIFlavor(APIFlavorDataCenter), WithDryRun(plan))
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		path       string
+		wantPath   string
+		wantStatus int
+	}{
+		{"link create gets 201", "/rest/api/3/issueLink", "/rest/api/2/issueLink", http.StatusCreated},
+		{"project restore gets 200", "/rest/api/3/project/PROJ/restore", "/rest/api/2/project/PROJ/restore", http.StatusOK},
+		{"create gets 204", "/rest/api/3/issue", "/rest/api/2/issue", http.StatusNoContent},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := tr.NewRequest(ctx, http.MethodPost, tt.path, map[string]string{"type": "Blocks"})
+			require.NoError(t, err)
+
+			resp, err := tr.Do(ctx, req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			require.Len(t, plan.Requests(), i+1)
+			assert.Equal(t, tt.wantPath, plan.Requests()[i].Path)
+		})
+	}
+}
+
+func TestDryRun_Context(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL)
+
+	plan := NewPlan()
+	ctx := ContextWithDryRun(context.Background(), plan)
+
+	req, err := tr.NewRequest(ctx, http.MethodDelete, "/rest/api/3/version/10000", nil)
+	require.NoError(t, err)
+	resp, err := tr.Do(ctx, req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+	assert.Equal(t, 1, plan.Len())
+
+	// Without the flag the request is sent
+	req, err = tr.NewRequest(context.Background(), http.MethodDelete, "/rest/api/3/version/10000", nil)
+	require.NoError(t, err)
+	resp, err = tr.Do(context.Background(), req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Equal(t, 1, plan.Len())
+}
+
+func TestPlan_Export(t *testing.T) {
+	plan := NewPlan()
+
+	var buf bytes.Buffer
+	require.NoError(t, plan.WriteJSON(&buf))
+	assert.JSONEq(t, `[]`, buf.String())
+
+	plan.add(PlannedRequest{
+		Method:   http.MethodPost,
+		Path:     "/rest/api/3/issue",
+		Endpoint: "/rest/api/3/issue",
+		Body:     map[string]interface{}{"fields": map[string]interface{}{"summary": "New"}},
+	})
+	plan.add(PlannedRequest{
+		Method:   http.MethodDelete,
+		Path:     "/rest/api/3/issue/PROJ-7",
+		Endpoint: "/rest/api/3/issue/{issueIdOrKey}",
+	})
+
+	buf.Reset()
+	require.NoError(t, plan.WriteJSON(&buf))
+	assert.JSONEq(t, `[
+		{"method":"POST","path":"/rest/api/3/issue","endpoint":"/rest/api/3/issue","body":{"fields":{"summary":"New"}}},
+		{"method":"DELETE","path":"/rest/api/3/issue/PROJ-7","endpoint":"/rest/api/3/issue/{issueIdOrKey}"}
+	]`, buf.String())
+
+	want := "Dry run: 2 request(s) not sent\n" +
+		"+ POST /rest/api/3/issue\n" +
+		"    {\n" +
+		"      \"fields\": {\n" +
+		"        \"summary\": \"New\"\n" +
+		"      }\n" +
+		"    }\n" +
+		"- DELETE /rest/api/3/issue/PROJ-7\n"
+	assert.Equal(t, want, plan.Diff())
+
+	plan.Reset()
+	assert.Equal(t, 0, plan.Len())
+}
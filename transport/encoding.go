@@ -14,111 +14,52 @@ func EncodeJSONRequest(body interface{}) (io.Reader, error) {
 		return nil, nil
 	}
 
+	payload, err := encodeJSON(body)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewBuffer(payload), nil
+}
+
+// encodeJSON encodes body as JSON into a byte slice.
+func encodeJSON(body interface{}) ([]byte, error) {
 	buf := new(bytes.Buffer)
 	if err := json.NewEncoder(buf).Encode(body); err != nil {
 		return nil, fmt.Errorf("failed to encode request body: %w", err)
 	}
 
-	return buf, nil
+	return buf.Bytes(), nil
 }
 
 // DecodeJSONResponse decodes a JSON response into the target struct.
+//
+// Error responses (HTTP 400 and above) are returned as *ErrorResponse. A nil
+// target only checks the status code and drains the body, which is useful for
+// endpoints that return 204 No Content. Successful bodies are decoded as they
+// are read rather than buffered first.
 func DecodeJSONResponse(resp *http.Response, target interface{}) error {
-	if target == nil {
-		return nil
-	}
-
 	defer resp.Body.Close()
 
-	// Read response body for error handling
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
-	}
-
 	// Check for error responses
 	if resp.StatusCode >= 400 {
-		return parseErrorResponse(resp.StatusCode, body)
-	}
-
-	// Decode successful response
-	if err := json.Unmarshal(body, target); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	return nil
-}
-
-// ErrorResponse represents a Jira API error response.
-type ErrorResponse struct {
-	StatusCode    int               `json:"-"`
-	ErrorMessages []string          `json:"errorMessages,omitempty"`
-	Errors        map[string]string `json:"errors,omitempty"`
-	Message       string            `json:"message,omitempty"`
-}
-
-// Error implements the error interface.
-func (e *ErrorResponse) Error() string {
-	if e.Message != "" {
-		return fmt.Sprintf("Jira API error (HTTP %d): %s", e.StatusCode, e.Message)
-	}
-
-	if len(e.ErrorMessages) > 0 {
-		return fmt.Sprintf("Jira API error (HTTP %d): %s", e.StatusCode, e.ErrorMessages[0])
-	}
-
-	if len(e.Errors) > 0 {
-		for field, msg := range e.Errors {
-			return fmt.Sprintf("Jira API error (HTTP %d): %s: %s", e.StatusCode, field, msg)
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response body: %w", err)
 		}
+		return newErrorResponse(resp, body)
 	}
 
-	return fmt.Sprintf("Jira API error (HTTP %d)", e.StatusCode)
-}
-
-// parseErrorResponse attempts to parse a Jira error response.
-func parseErrorResponse(statusCode int, body []byte) error {
-	errResp := &ErrorResponse{
-		StatusCode: statusCode,
-	}
-
-	// Try to unmarshal as Jira error format
-	if err := json.Unmarshal(body, errResp); err != nil {
-		// If parsing fails, use raw body as message
-		errResp.Message = string(body)
-	}
-
-	return errResp
-}
-
-// IsNotFound returns true if the error is a 404 Not Found.
-func IsNotFound(err error) bool {
-	if errResp, ok := err.(*ErrorResponse); ok {
-		return errResp.StatusCode == http.StatusNotFound
-	}
-	return false
-}
-
-// IsUnauthorized returns true if the error is a 401 Unauthorized.
-func IsUnauthorized(err error) bool {
-	if errResp, ok := err.(*ErrorResponse); ok {
-		return errResp.StatusCode == http.StatusUnauthorized
+	if target != nil {
+		if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
 	}
-	return false
-}
 
-// IsForbidden returns true if the error is a 403 Forbidden.
-func IsForbidden(err error) bool {
-	if errResp, ok := err.(*ErrorResponse); ok {
-		return errResp.StatusCode == http.StatusForbidden
+	// Drain what is left so the connection can be reused
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
 	}
-	return false
-}
 
-// IsRateLimited returns true if the error is a 429 Too Many Requests.
-func IsRateLimited(err error) bool {
-	if errResp, ok := err.(*ErrorResponse); ok {
-		return errResp.StatusCode == http.StatusTooManyRequests
-	}
-	return false
+	return nil
 }
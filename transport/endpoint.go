@@ -0,0 +1,158 @@
+package transport
+
+import (
+	"regexp"
+	"strings"
+)
+
+// issueKeyPattern matches Jira issue keys such as PROJ-123.
+var issueKeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*-[0-9]+$`)
+
+// pathParameters names the placeholder used for the identifier following a
+// collection segment. Every segment in that position is templated, whatever
+// it looks like, unless it is one of the collection's fixedRoutes.
+var pathParameters = map[string]string{
+	"issue":                  "issueIdOrKey",
+	"project":                "projectIdOrKey",
+	"board":                  "boardId",
+	"sprint":                 "sprintId",
+	"epic":                   "epicIdOrKey",
+	"dashboard":              "dashboardId",
+	"field":                  "fieldId",
+	"fields":                 "fieldId",
+	"screens":                "screenId",
+	"screen":                 "screenId",
+	"tabs":                   "tabId",
+	"properties":             "propertyKey",
+	"application-properties": "propertyKey",
+	"issueLink":              "linkId",
+	"issueLinkType":          "issueLinkTypeId",
+	"permissionscheme":       "schemeId",
+	"notificationscheme":     "schemeId",
+	"workflowscheme":         "schemeId",
+	"role":                   "roleId",
+	"status":                 "idOrName",
+	"gadget":                 "gadgetId",
+}
+
+// fixedRoutes lists the resource names that follow a collection segment in
+// place of an identifier, such as issue/createmeta.
+var fixedRoutes = map[string]map[string]bool{
+	"issue": {
+		"archive": true, "bulk": true, "bulkfetch": true, "createmeta": true, "events": true,
+		"picker": true, "properties": true, "unarchive": true, "watching": true,
+	},
+	"project":                {"recent": true, "remove": true, "search": true, "type": true},
+	"board":                  {"filter": true},
+	"epic":                   {"none": true},
+	"dashboard":              {"bulk": true, "gadgets": true, "search": true},
+	"field":                  {"association": true, "search": true},
+	"screens":                {"addToDefault": true, "tabs": true},
+	"application-properties": {"advanced-settings": true},
+	"notificationscheme":     {"project": true},
+	"workflowscheme":         {"project": true},
+}
+
+// EndpointTemplate replaces the identifiers in a Jira REST path with placeholders.
+//
+// Templates have bounded cardinality, which makes them suitable as metric
+// labels and span names:
+//
+//	EndpointTemplate("/rest/api/3/issue/PROJ-123/properties/my.prop")
+//	// "/rest/api/3/issue/{issueIdOrKey}/properties/{propertyKey}"
+//
+// The segment following a known collection, such as issue, properties or
+// status, is templated by position. Elsewhere a segment is treated as an
+// identifier when it contains a digit or starts with an upper case letter;
+// camelCase resource names such as issueLink are kept. The API prefix
+// (/rest/api/3, /rest/agile/1.0) is kept as is, and any base path in front of
+// it, such as a Server context path or the /ex/jira/<cloudId> prefix of the
+// API gateway, is dropped so templates do not vary by site.
+func EndpointTemplate(path string) string {
+	path = trimBasePath(path)
+	segments := strings.Split(path, "/")
+
+	// Skip the API prefix: "", "rest", "<api>", "<version>"
+	start := 0
+	for i, segment := range segments {
+		if segment == "rest" {
+			start = i + 3
+			break
+		}
+	}
+
+	for i := start; i < len(segments); i++ {
+		segment := segments[i]
+		if segment == "" {
+			continue
+		}
+
+		if issueKeyPattern.MatchString(segment) {
+			segments[i] = "{issueIdOrKey}"
+			continue
+		}
+
+		collection := ""
+		if i > 0 {
+			collection = segments[i-1]
+		}
+		param, ok := pathParameters[collection]
+		switch {
+		case ok && !fixedRoutes[collection][segment]:
+			segments[i] = "{" + param + "}"
+			if collection == "properties" {
+				// Property keys may contain slashes, and nothing follows them
+				segments = segments[:i+1]
+			}
+		case isIdentifier(segment):
+			segments[i] = "{id}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// trimBasePath removes everything in front of the /rest/ API prefix.
+func trimBasePath(path string) string {
+	if i := strings.Index(path, "/rest/"); i > 0 {
+		return path[i:]
+	}
+	return path
+}
+
+// isIdentifier reports whether a path segment looks like a value rather than a resource name.
+func isIdentifier(segment string) bool {
+	if segment[0] >= 'A' && segment[0] <= 'Z' {
+		return true
+	}
+	return strings.ContainsAny(segment, "0123456789")
+}
+
+// EndpointService returns the Jira service an endpoint template belongs to:
+// "agile" for the Jira Software API, and otherwise the first resource segment,
+// such as "issue", "project" or "search".
+func EndpointService(template string) string {
+	segments := strings.Split(strings.TrimPrefix(trimBasePath(template), "/"), "/")
+	if len(segments) < 4 || segments[0] != "rest" {
+		return "unknown"
+	}
+	if segments[1] == "agile" {
+		return "agile"
+	}
+	return segments[3]
+}
+
+// issueKeyFromPath returns the issue key, or the numeric issue ID, addressed
+// by a REST path. Both are empty for paths that do not address an issue.
+func issueKeyFromPath(path string) (key, id string) {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if issueKeyPattern.MatchString(segment) {
+			return segment, ""
+		}
+		if i > 0 && segments[i-1] == "issue" && segment != "" && isIdentifier(segment) {
+			return "", segment
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,240 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Sentinel errors for classifying Jira API failures.
+//
+// An *ErrorResponse unwraps to the sentinel matching its status code, so
+// errors.Is works through any amount of fmt.Errorf wrapping:
+//
+//	if errors.Is(err, transport.ErrNotFound) {
+//		// the issue does not exist
+//	}
+var (
+	// ErrBadRequest matches 400 Bad Request responses
+	ErrBadRequest = errors.New("jira: bad request")
+
+	// ErrValidation matches 400 and 422 responses that carry field-level errors
+	ErrValidation = errors.New("jira: validation failed")
+
+	// ErrUnauthorized matches 401 Unauthorized responses
+	ErrUnauthorized = errors.New("jira: unauthorized")
+
+	// ErrForbidden matches 403 Forbidden responses
+	ErrForbidden = errors.New("jira: forbidden")
+
+	// ErrNotFound matches 404 Not Found responses
+	ErrNotFound = errors.New("jira: not found")
+
+	// ErrConflict matches 409 Conflict responses
+	ErrConflict = errors.New("jira: conflict")
+
+	// ErrRateLimited matches 429 Too Many Requests responses
+	ErrRateLimited = errors.New("jira: rate limited")
+
+	// ErrServer matches 5xx responses
+	ErrServer = errors.New("jira: server error")
+)
+
+// ErrorResponse represents a Jira API error response.
+//
+// Besides the error payload returned by Jira it records the request that
+// failed, which is useful when reporting problems to Atlassian support.
+type ErrorResponse struct {
+	StatusCode    int              `json:"-"`
+	ErrorMessages []string         `json:"errorMessages,omitempty"`
+	Errors        ValidationErrors `json:"errors,omitempty"`
+	Message       string           `json:"message,omitempty"`
+
+	// Method is the HTTP method of the failed request
+	Method string `json:"-"`
+
+	// Path is the request path, e.g. /rest/api/3/issue/PROJ-123
+	Path string `json:"-"`
+
+	// PathTemplate is Path with identifiers replaced by placeholders,
+	// e.g. /rest/api/3/issue/{issueIdOrKey}
+	PathTemplate string `json:"-"`
+
+	// RequestID is the Atlassian request ID (X-AREQUESTID header)
+	RequestID string `json:"-"`
+
+	// Header holds the response headers
+	Header http.Header `json:"-"`
+
+	// RetryAfter is the delay requested by a Retry-After header, or zero
+	RetryAfter time.Duration `json:"-"`
+}
+
+// Error implements the error interface.
+func (e *ErrorResponse) Error() string {
+	var msg string
+	switch {
+	case e.Message != "":
+		msg = fmt.Sprintf("Jira API error (HTTP %d): %s", e.StatusCode, e.Message)
+	case len(e.ErrorMessages) > 0:
+		msg = fmt.Sprintf("Jira API error (HTTP %d): %s", e.StatusCode, e.ErrorMessages[0])
+	case len(e.Errors) > 0:
+		first := e.Errors.Fields()[0]
+		msg = fmt.Sprintf("Jira API error (HTTP %d): %s: %s", e.StatusCode, first.Field, first.Message)
+	default:
+		msg = fmt.Sprintf("Jira API error (HTTP %d)", e.StatusCode)
+	}
+
+	if e.Method == "" {
+		return msg
+	}
+
+	if e.RequestID != "" {
+		return fmt.Sprintf("%s (%s %s, request ID %s)", msg, e.Method, e.Path, e.RequestID)
+	}
+	return fmt.Sprintf("%s (%s %s)", msg, e.Method, e.Path)
+}
+
+// Unwrap returns the sentinel matching the status code and, when present,
+// the field-level validation errors. This makes errors.Is and errors.As work:
+//
+//	var fields transport.ValidationErrors
+//	if errors.As(err, &fields) {
+//		for _, f := range fields.Fields() {
+//			fmt.Printf("%s: %s\n", f.Field, f.Message)
+//		}
+//	}
+func (e *ErrorResponse) Unwrap() []error {
+	var errs []error
+
+	switch {
+	case e.StatusCode == http.StatusBadRequest:
+		errs = append(errs, ErrBadRequest)
+	case e.StatusCode == http.StatusUnauthorized:
+		errs = append(errs, ErrUnauthorized)
+	case e.StatusCode == http.StatusForbidden:
+		errs = append(errs, ErrForbidden)
+	case e.StatusCode == http.StatusNotFound:
+		errs = append(errs, ErrNotFound)
+	case e.StatusCode == http.StatusConflict:
+		errs = append(errs, ErrConflict)
+	case e.StatusCode == http.StatusTooManyRequests:
+		errs = append(errs, ErrRateLimited)
+	case e.StatusCode >= 500:
+		errs = append(errs, ErrServer)
+	}
+
+	if e.StatusCode == http.StatusUnprocessableEntity ||
+		(e.StatusCode == http.StatusBadRequest && len(e.Errors) > 0) {
+		errs = append(errs, ErrValidation)
+	}
+
+	if len(e.Errors) > 0 {
+		errs = append(errs, e.Errors)
+	}
+
+	return errs
+}
+
+// ValidationErrors maps field names to the validation messages Jira returned for them.
+type ValidationErrors map[string]string
+
+// FieldError is the validation failure for a single field.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// Fields returns the validation failures sorted by field name.
+func (v ValidationErrors) Fields() []FieldError {
+	fields := make([]FieldError, 0, len(v))
+	for field, msg := range v {
+		fields = append(fields, FieldError{Field: field, Message: msg})
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Field < fields[j].Field
+	})
+	return fields
+}
+
+// Error implements the error interface.
+func (v ValidationErrors) Error() string {
+	parts := make([]string, 0, len(v))
+	for _, f := range v.Fields() {
+		parts = append(parts, fmt.Sprintf("%s: %s", f.Field, f.Message))
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// parseErrorResponse attempts to parse a Jira error response.
+func parseErrorResponse(statusCode int, body []byte) error {
+	errResp := &ErrorResponse{
+		StatusCode: statusCode,
+	}
+
+	// Try to unmarshal as Jira error format
+	if err := json.Unmarshal(body, errResp); err != nil {
+		// If parsing fails, use raw body as message
+		errResp.Message = string(body)
+	}
+
+	return errResp
+}
+
+// newErrorResponse parses an error response and records the request diagnostics.
+func newErrorResponse(resp *http.Response, body []byte) error {
+	err := parseErrorResponse(resp.StatusCode, body)
+
+	var errResp *ErrorResponse
+	if !errors.As(err, &errResp) {
+		return err
+	}
+
+	errResp.Header = resp.Header
+	errResp.RequestID = resp.Header.Get("X-AREQUESTID")
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		errResp.RetryAfter = parseRetryAfter(retryAfter)
+	}
+
+	if resp.Request != nil && resp.Request.URL != nil {
+		errResp.Method = resp.Request.Method
+		errResp.Path = resp.Request.URL.Path
+		errResp.PathTemplate = EndpointTemplate(resp.Request.URL.Path)
+	}
+
+	return errResp
+}
+
+// IsNotFound returns true if the error is a 404 Not Found.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsUnauthorized returns true if the error is a 401 Unauthorized.
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsForbidden returns true if the error is a 403 Forbidden.
+func IsForbidden(err error) bool {
+	return errors.Is(err, ErrForbidden)
+}
+
+// IsRateLimited returns true if the error is a 429 Too Many Requests.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// IsConflict returns true if the error is a 409 Conflict.
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
+
+// IsValidation returns true if Jira rejected the request with field-level errors.
+func IsValidation(err error) bool {
+	return errors.Is(err, ErrValidation)
+}
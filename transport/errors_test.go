@@ -0,0 +1,220 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorResponse_Is(t *testing.T) {
+	tests := []struct {
+		name    string
+		errResp *ErrorResponse
+		want    []error
+		notWant []error
+	}{
+		{
+			name:    "not found",
+			errResp: &ErrorResponse{StatusCode: http.StatusNotFound},
+			want:    []error{ErrNotFound},
+			notWant: []error{ErrConflict, ErrServer},
+		},
+		{
+			name:    "conflict",
+			errResp: &ErrorResponse{StatusCode: http.StatusConflict},
+			want:    []error{ErrConflict},
+			notWant: []error{ErrNotFound},
+		},
+		{
+			name: "bad request with field errors",
+			errResp: &ErrorResponse{
+				StatusCode: http.StatusBadRequest,
+				Errors:     ValidationErrors{"summary": "Summary is required"},
+			},
+			want: []error{ErrBadRequest, ErrValidation},
+		},
+		{
+			name:    "bad request without field errors",
+			errResp: &ErrorResponse{StatusCode: http.StatusBadRequest},
+			want:    []error{ErrBadRequest},
+			notWant: []error{ErrValidation},
+		},
+		{
+			name:    "unprocessable entity",
+			errResp: &ErrorResponse{StatusCode: http.StatusUnprocessableEntity},
+			want:    []error{ErrValidation},
+		},
+		{
+			name:    "rate limited",
+			errResp: &ErrorResponse{StatusCode: http.StatusTooManyRequests},
+			want:    []error{ErrRateLimited},
+		},
+		{
+			name:    "server error",
+			errResp: &ErrorResponse{StatusCode: http.StatusBadGateway},
+			want:    []error{ErrServer},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := fmt.Errorf("failed to get issue: %w", fmt.Errorf("failed to decode response: %w", tt.errResp))
+
+			for _, target := range tt.want {
+				assert.ErrorIs(t, wrapped, target)
+			}
+			for _, target := range tt.notWant {
+				assert.NotErrorIs(t, wrapped, target)
+			}
+
+			var errResp *ErrorResponse
+			require.ErrorAs(t, wrapped, &errResp)
+			assert.Equal(t, tt.errResp.StatusCode, errResp.StatusCode)
+		})
+	}
+}
+
+func TestIsHelpersThroughWrapping(t *testing.T) {
+	err := fmt.Errorf("failed to get issue: %w", &ErrorResponse{StatusCode: http.StatusNotFound})
+
+	assert.True(t, IsNotFound(err))
+	assert.False(t, IsUnauthorized(err))
+	assert.False(t, IsConflict(err))
+	assert.False(t, IsValidation(err))
+}
+
+func TestValidationErrors(t *testing.T) {
+	fields := ValidationErrors{
+		"summary":  "Summary is required",
+		"priority": "Priority is invalid",
+	}
+
+	assert.Equal(t, []FieldError{
+		{Field: "priority", Message: "Priority is invalid"},
+		{Field: "summary", Message: "Summary is required"},
+	}, fields.Fields())
+	assert.Equal(t, "validation failed: priority: Priority is invalid; summary: Summary is required", fields.Error())
+
+	err := fmt.Errorf("failed to create issue: %w", &ErrorResponse{
+		StatusCode: http.StatusBadRequest,
+		Errors:     fields,
+	})
+
+	var got ValidationErrors
+	require.True(t, errors.As(err, &got))
+	assert.Len(t, got, 2)
+}
+
+func TestErrorResponse_Diagnostics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-AREQUESTID", "abc-123")
+		w.Header().Set("Retry-After", "0")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errorMessages":["Issue does not exist or you do not have permission to see it."]}`))
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL, WithMaxRetries(0))
+
+	req, err := tr.NewRequest(context.Background(), http.MethodGet, "/rest/api/3/issue/PROJ-123", nil)
+	require.NoError(t, err)
+
+	resp, err := tr.Do(context.Background(), req)
+	require.NoError(t, err)
+
+	err = tr.DecodeResponse(resp, &map[string]interface{}{})
+	require.Error(t, err)
+
+	var errResp *ErrorResponse
+	require.ErrorAs(t, err, &errResp)
+	assert.Equal(t, http.MethodGet, errResp.Method)
+	assert.Equal(t, "/rest/api/3/issue/PROJ-123", errResp.Path)
+	assert.Equal(t, "/rest/api/3/issue/{issueIdOrKey}", errResp.PathTemplate)
+	assert.Equal(t, "abc-123", errResp.RequestID)
+	assert.Equal(t, "application/json", errResp.Header.Get("Content-Type"))
+	assert.Equal(t, time.Duration(0), errResp.RetryAfter)
+	assert.Contains(t, errResp.Error(), "GET /rest/api/3/issue/PROJ-123, request ID abc-123")
+	assert.True(t, IsNotFound(err))
+}
+
+func TestDecodeJSONResponse_NilTargetChecksStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL)
+	require.NoError(t, err)
+
+	err = DecodeJSONResponse(resp, nil)
+	assert.True(t, IsForbidden(err))
+}
+
+func TestEndpointTemplate(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/rest/api/3/issue/PROJ-123", "/rest/api/3/issue/{issueIdOrKey}"},
+		{"/rest/api/3/issue/10001/comment/20002", "/rest/api/3/issue/{issueIdOrKey}/comment/{id}"},
+		{"/rest/api/3/issue/createmeta", "/rest/api/3/issue/createmeta"},
+		{"/rest/api/3/project/PROJ/versions", "/rest/api/3/project/{projectIdOrKey}/versions"},
+		{"/rest/api/3/search/jql", "/rest/api/3/search/jql"},
+		{"/rest/api/3/issueLink/10000", "/rest/api/3/issueLink/{linkId}"},
+		{"/rest/api/3/field/customfield_10000/context", "/rest/api/3/field/{fieldId}/context"},
+		{"/rest/agile/1.0/board/42/sprint", "/rest/agile/1.0/board/{boardId}/sprint"},
+		{"/rest/agile/1.0/sprint/7/issue", "/rest/agile/1.0/sprint/{sprintId}/issue"},
+		{"/rest/api/2/serverInfo", "/rest/api/2/serverInfo"},
+		{"/rest/api/3/issue/PROJ-1/properties/my.prop", "/rest/api/3/issue/{issueIdOrKey}/properties/{propertyKey}"},
+		{"/rest/api/3/issue/PROJ-1/properties/my/prop", "/rest/api/3/issue/{issueIdOrKey}/properties/{propertyKey}"},
+		{"/rest/api/3/issue/properties/flagged", "/rest/api/3/issue/properties/{propertyKey}"},
+		{"/rest/api/3/user/properties/settings", "/rest/api/3/user/properties/{propertyKey}"},
+		{"/rest/api/3/status/done", "/rest/api/3/status/{idOrName}"},
+		{"/rest/api/3/project/proj/versions", "/rest/api/3/project/{projectIdOrKey}/versions"},
+		{"/rest/api/3/project/search", "/rest/api/3/project/search"},
+		{"/rest/api/3/screens/1/tabs/2/fields/summary", "/rest/api/3/screens/{screenId}/tabs/{tabId}/fields/{fieldId}"},
+		{"/rest/api/3/field/customfield_10000/context/1/project/remove", "/rest/api/3/field/{fieldId}/context/{id}/project/remove"},
+		{"/rest/api/3/application-properties/jira.home", "/rest/api/3/application-properties/{propertyKey}"},
+		{"/rest/agile/1.0/board/42/epic/none/issue", "/rest/agile/1.0/board/{boardId}/epic/none/issue"},
+		{"/ex/jira/11223344-a1b2-3b33-c444-def123456789/rest/api/3/issue/PROJ-1", "/rest/api/3/issue/{issueIdOrKey}"},
+		{"/ex/jira/0d4a7c2e/rest/agile/1.0/board/42", "/rest/agile/1.0/board/{boardId}"},
+		{"/jira/rest/api/2/project/PROJ", "/rest/api/2/project/{projectIdOrKey}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, EndpointTemplate(tt.path))
+		})
+	}
+}
+
+func TestEndpointService(t *testing.T) {
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"/rest/api/3/issue/{issueIdOrKey}/comment/{id}", "issue"},
+		{"/rest/api/3/search/jql", "search"},
+		{"/rest/api/2/project/{projectIdOrKey}", "project"},
+		{"/rest/agile/1.0/board/{boardId}/sprint", "agile"},
+		{"/status", "unknown"},
+		{"/ex/jira/11223344-a1b2-3b33-c444-def123456789/rest/api/3/issue/PROJ-1", "issue"},
+		{"/jira/rest/agile/1.0/board/42", "agile"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			assert.Equal(t, tt.want, EndpointService(tt.template))
+		})
+	}
+}
@@ -0,0 +1,418 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// APIFlavor selects the Jira REST API the transport talks to.
+type APIFlavor int
+
+const (
+	// APIFlavorCloud is Jira Cloud with REST API v3 (the default)
+	APIFlavorCloud APIFlavor = iota
+
+	// APIFlavorDataCenter is Jira Server/Data Center with REST API v2
+	APIFlavorDataCenter
+)
+
+// String returns the flavor's name.
+func (f APIFlavor) String() string {
+	switch f {
+	case APIFlavorCloud:
+		return "cloud"
+	case APIFlavorDataCenter:
+		return "datacenter"
+	default:
+		return fmt.Sprintf("APIFlavor(%d)", int(f))
+	}
+}
+
+// ParseAPIFlavor parses "cloud" or "datacenter" (also "dc" and "server").
+func ParseAPIFlavor(s string) (APIFlavor, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "cloud", "v3":
+		return APIFlavorCloud, nil
+	case "datacenter", "data-center", "dc", "server", "v2":
+		return APIFlavorDataCenter, nil
+	default:
+		return APIFlavorCloud, fmt.Errorf("unknown API flavor %q", s)
+	}
+}
+
+// ADFRenderer renders an ADF document, given as JSON, as the rich text that
+// Jira Server/Data Center expects, which is wiki markup.
+type ADFRenderer func(doc []byte) (string, error)
+
+// UsernameResolver returns the Jira Server/Data Center username for the
+// account ID a request refers to a user by.
+type UsernameResolver func(ctx context.Context, accountID string) (string, error)
+
+// ErrUnknownUsername matches errors for requests that refer to a user by
+// account ID when no username is known for it.
+var ErrUnknownUsername = errors.New("jira: no username known for account ID")
+
+// dataCenterUserFields are the JSON fields holding user references that are
+// rewritten for REST API v2. Custom fields (customfield_*) are included, as
+// user picker fields take the same shape.
+var dataCenterUserFields = map[string]bool{
+	"assignee":     true,
+	"reporter":     true,
+	"creator":      true,
+	"author":       true,
+	"updateAuthor": true,
+	"user":         true,
+	"users":        true,
+	"lead":         true,
+	"watchers":     true,
+	"approvers":    true,
+}
+
+const (
+	cloudAPIPrefix      = "/rest/api/3"
+	dataCenterAPIPrefix = "/rest/api/2"
+)
+
+// adaptRequest rewrites a REST API v3 request for the flavor.
+//
+// For APIFlavorDataCenter it returns a copy of req with:
+//   - /rest/api/3 paths moved to /rest/api/2
+//   - ADF documents in the JSON body rendered with render, or as plain text
+//     when render is nil
+//   - accountId user references replaced by name, with the username from
+//     resolve, in the query (as username) and in the body fields listed in
+//     dataCenterUserFields; a watcher added by account ID is sent by username
+//
+// A request referring to a user by account ID fails with ErrUnknownUsername
+// when resolve is nil, since an account ID is not a username. A user
+// reference that already carries a name keeps it. Requests for other APIs,
+// such as /rest/agile/1.0, only get the user reference changes.
+func adaptRequest(flavor APIFlavor, render ADFRenderer, resolve UsernameResolver, req *http.Request) (*http.Request, error) {
+	if flavor != APIFlavorDataCenter {
+		return req, nil
+	}
+
+	conv := &dataCenterConverter{ctx: req.Context(), render: render, resolve: resolve}
+
+	adapted := req.Clone(req.Context())
+	adapted.URL.Path = dataCenterPath(adapted.URL.Path)
+	if adapted.URL.RawPath != "" {
+		adapted.URL.RawPath = dataCenterPath(adapted.URL.RawPath)
+	}
+
+	if q := adapted.URL.Query(); q.Has("accountId") {
+		usernames := make([]string, 0, len(q["accountId"]))
+		for _, accountID := range q["accountId"] {
+			username, err := conv.username(accountID)
+			if err != nil {
+				return nil, err
+			}
+			usernames = append(usernames, username)
+		}
+		q["username"] = usernames
+		q.Del("accountId")
+		adapted.URL.RawQuery = q.Encode()
+	}
+
+	if req.GetBody == nil || !strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		return adapted, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	payload, err := io.ReadAll(body)
+	_ = body.Close() // Explicit ignore, the body is in memory
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	payload, err = conv.payload(payload, isWatchersPath(adapted.URL.Path))
+	if err != nil {
+		return nil, err
+	}
+
+	adapted.Body = io.NopCloser(bytes.NewReader(payload))
+	adapted.ContentLength = int64(len(payload))
+	adapted.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(payload)), nil
+	}
+	return adapted, nil
+}
+
+// dataCenterPath moves a REST API v3 path to v2.
+func dataCenterPath(path string) string {
+	i := strings.Index(path, cloudAPIPrefix)
+	if i < 0 {
+		return path
+	}
+	rest := path[i+len(cloudAPIPrefix):]
+	if rest != "" && rest[0] != '/' {
+		return path
+	}
+	return path[:i] + dataCenterAPIPrefix + rest
+}
+
+// isWatchersPath reports whether path is an issue's watcher list, whose POST
+// body is the bare account ID of the watcher to add.
+func isWatchersPath(path string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(path, "/"), "/watchers")
+}
+
+// dataCenterConverter rewrites the values of a REST API v3 request for
+// REST API v2.
+type dataCenterConverter struct {
+	ctx     context.Context
+	render  ADFRenderer
+	resolve UsernameResolver
+}
+
+// username returns the username for accountID.
+func (c *dataCenterConverter) username(accountID string) (string, error) {
+	if c.resolve == nil {
+		return "", fmt.Errorf("%w %q: set a UsernameResolver to use account IDs with Jira Server/Data Center", ErrUnknownUsername, accountID)
+	}
+	username, err := c.resolve(c.ctx, accountID)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve username for account ID %q: %w", accountID, err)
+	}
+	if username == "" {
+		return "", fmt.Errorf("%w %q", ErrUnknownUsername, accountID)
+	}
+	return username, nil
+}
+
+// payload rewrites a JSON request body for REST API v2. bareUser means a
+// body that is a JSON string is an account ID.
+func (c *dataCenterConverter) payload(payload []byte, bareUser bool) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		// Not JSON after all; send it unchanged
+		return payload, nil
+	}
+
+	if accountID, ok := value.(string); ok && bareUser {
+		username, err := c.username(accountID)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(username)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request body: %w", err)
+		}
+		return data, nil
+	}
+
+	converted, changed, err := c.convert(value)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return payload, nil
+	}
+
+	data, err := json.Marshal(converted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request body: %w", err)
+	}
+	return data, nil
+}
+
+// convert converts ADF documents to text and the accountId references in
+// user fields to name references, reporting whether anything changed.
+func (c *dataCenterConverter) convert(value interface{}) (interface{}, bool, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if isADFDocument(v) {
+			text, err := renderADF(v, c.render)
+			return text, true, err
+		}
+
+		changed := false
+		for key, child := range v {
+			convert := c.convert
+			if dataCenterUserFields[key] || strings.HasPrefix(key, "customfield_") {
+				convert = c.convertUsers
+			}
+			converted, childChanged, err := convert(child)
+			if err != nil {
+				return nil, false, err
+			}
+			if childChanged {
+				v[key] = converted
+				changed = true
+			}
+		}
+		return v, changed, nil
+
+	case []interface{}:
+		changed := false
+		for i, child := range v {
+			converted, childChanged, err := c.convert(child)
+			if err != nil {
+				return nil, false, err
+			}
+			if childChanged {
+				v[i] = converted
+				changed = true
+			}
+		}
+		return v, changed, nil
+
+	default:
+		return value, false, nil
+	}
+}
+
+// convertUsers converts the value of a user field: a user reference, a list
+// of them, or edit operations such as {"set": {"accountId": "..."}}.
+func (c *dataCenterConverter) convertUsers(value interface{}) (interface{}, bool, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if accountID, ok := v["accountId"]; ok {
+			if _, hasName := v["name"]; !hasName {
+				id, _ := accountID.(string)
+				username, err := c.username(id)
+				if err != nil {
+					return nil, false, err
+				}
+				v["name"] = username
+			}
+			delete(v, "accountId")
+			return v, true, nil
+		}
+		if isADFDocument(v) {
+			return c.convert(v)
+		}
+
+		changed := false
+		for key, child := range v {
+			converted, childChanged, err := c.convertUsers(child)
+			if err != nil {
+				return nil, false, err
+			}
+			if childChanged {
+				v[key] = converted
+				changed = true
+			}
+		}
+		return v, changed, nil
+
+	case []interface{}:
+		changed := false
+		for i, child := range v {
+			converted, childChanged, err := c.convertUsers(child)
+			if err != nil {
+				return nil, false, err
+			}
+			if childChanged {
+				v[i] = converted
+				changed = true
+			}
+		}
+		return v, changed, nil
+
+	default:
+		return value, false, nil
+	}
+}
+
+// renderADF renders an ADF document with render, or as plain text when
+// render is nil.
+func renderADF(doc map[string]interface{}, render ADFRenderer) (string, error) {
+	if render == nil {
+		return adfText(doc), nil
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode ADF document: %w", err)
+	}
+	text, err := render(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render ADF document: %w", err)
+	}
+	return text, nil
+}
+
+// isADFDocument reports whether v is the root of an ADF document.
+func isADFDocument(v map[string]interface{}) bool {
+	if v["type"] != "doc" {
+		return false
+	}
+	_, hasVersion := v["version"]
+	_, hasContent := v["content"]
+	return hasVersion || hasContent
+}
+
+// adfText renders an ADF document as plain text, one paragraph per block.
+func adfText(doc map[string]interface{}) string {
+	blocks, _ := doc["content"].([]interface{})
+
+	paragraphs := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		var b strings.Builder
+		writeADFText(&b, block)
+		if text := strings.TrimSpace(b.String()); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// writeADFText writes the text of an ADF node and its children.
+func writeADFText(b *strings.Builder, node interface{}) {
+	n, ok := node.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	switch n["type"] {
+	case "text":
+		text, _ := n["text"].(string)
+		b.WriteString(text)
+		return
+	case "hardBreak":
+		b.WriteString("\n")
+		return
+	case "mention":
+		if attrs, ok := n["attrs"].(map[string]interface{}); ok {
+			text, _ := attrs["text"].(string)
+			b.WriteString(text)
+		}
+		return
+	}
+
+	children, _ := n["content"].([]interface{})
+	for i, child := range children {
+		if i > 0 && isBlockNode(child) {
+			b.WriteString("\n")
+		}
+		writeADFText(b, child)
+	}
+}
+
+// isBlockNode reports whether node is an ADF block such as a paragraph or
+// list item, which starts on a new line.
+func isBlockNode(node interface{}) bool {
+	n, ok := node.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	switch n["type"] {
+	case "text", "hardBreak", "mention", "emoji", "inlineCard":
+		return false
+	default:
+		return true
+	}
+}
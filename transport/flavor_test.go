@@ -0,0 +1,275 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAPIFlavor(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    APIFlavor
+		wantErr bool
+	}{
+		{input: "cloud", want: APIFlavorCloud},
+		{input: "v3", want: APIFlavorCloud},
+		{input: "datacenter", want: APIFlavorDataCenter},
+		{input: " DC ", want: APIFlavorDataCenter},
+		{input: "server", want: APIFlavorDataCenter},
+		{input: "v2", want: APIFlavorDataCenter},
+		{input: "mainframe", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseAPIFlavor(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	assert.Equal(t, "cloud", APIFlavorCloud.String())
+	assert.Equal(t, "datacenter", APIFlavorDataCenter.String())
+}
+
+func TestDataCenterPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/rest/api/3/issue/PROJ-1", want: "/rest/api/2/issue/PROJ-1"},
+		{path: "/rest/api/3", want: "/rest/api/2"},
+		{path: "/jira/rest/api/3/myself", want: "/jira/rest/api/2/myself"},
+		{path: "/rest/api/30/issue", want: "/rest/api/30/issue"},
+		{path: "/rest/agile/1.0/board", want: "/rest/agile/1.0/board"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, dataCenterPath(tt.path))
+		})
+	}
+}
+
+// flavorRequest is a request seen by a flavor test server.
+type flavorRequest struct {
+	path  string
+	query url.Values
+	body  map[string]interface{}
+}
+
+// sendWithFlavor sends a request through a transport with flavor and returns
+// what the server received.
+func sendWithFlavor(t *testing.T, flavor APIFlavor, method, path string, body interface{}, opts ...TransportOption) flavorRequest {
+	t.Helper()
+
+	var got flavorRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.path = r.URL.Path
+		got.query = r.URL.Query()
+		data, _ := io.ReadAll(r.Body)
+		if len(data) > 0 {
+			require.NoError(t, json.Unmarshal(data, &got.body))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL, append([]TransportOption{WithAPIFlavor(flavor)}, opts...)...)
+	assert.Equal(t, flavor, tr.APIFlavor())
+
+	req, err := tr.NewRequest(context.Background(), method, path, body)
+	require.NoError(t, err)
+	resp, err := tr.Do(context.Background(), req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	return got
+}
+
+func TestAPIFlavor_DataCenterRequests(t *testing.T) {
+	description := map[string]interface{}{
+		"type":    "doc",
+		"version": 1,
+		"content": []interface{}{
+			map[string]interface{}{"type": "paragraph", "content": []interface{}{
+				map[string]interface{}{"type": "text", "text": "First line"},
+				map[string]interface{}{"type": "hardBreak"},
+				map[string]interface{}{"type": "text", "text": "second line"},
+			}},
+			map[string]interface{}{"type": "bulletList", "content": []interface{}{
+				map[string]interface{}{"type": "listItem", "content": []interface{}{
+					map[string]interface{}{"type": "paragraph", "content": []interface{}{
+						map[string]interface{}{"type": "text", "text": "one"},
+					}},
+				}},
+				map[string]interface{}{"type": "listItem", "content": []interface{}{
+					map[string]interface{}{"type": "paragraph", "content": []interface{}{
+						map[string]interface{}{"type": "text", "text": "two"},
+					}},
+				}},
+			}},
+		},
+	}
+	body := map[string]interface{}{
+		"fields": map[string]interface{}{
+			"summary":     "Summary",
+			"description": description,
+			"assignee":    map[string]interface{}{"accountId": "5b10a2844c20165700ede21g"},
+		},
+	}
+
+	got := sendWithFlavor(t, APIFlavorDataCenter, http.MethodPost, "/rest/api/3/issue", body, resolver)
+	assert.Equal(t, "/rest/api/2/issue", got.path)
+
+	fields := got.body["fields"].(map[string]interface{})
+	assert.Equal(t, "Summary", fields["summary"])
+	assert.Equal(t, "First line\nsecond line\n\none\ntwo", fields["description"])
+	assert.Equal(t, map[string]interface{}{"name": "jdoe"}, fields["assignee"])
+
+	got = sendWithFlavor(t, APIFlavorDataCenter, http.MethodDelete, "/rest/api/3/issue/PROJ-1/watchers?accountId=5b10a2844c20165700ede21g", nil, resolver)
+	assert.Equal(t, "/rest/api/2/issue/PROJ-1/watchers", got.path)
+	assert.Equal(t, "jdoe", got.query.Get("username"))
+	assert.False(t, got.query.Has("accountId"))
+}
+
+// resolver maps the account ID used in flavor tests to a username.
+var resolver = WithUsernameResolver(func(_ context.Context, accountID string) (string, error) {
+	if accountID == "5b10a2844c20165700ede21g" {
+		return "jdoe", nil
+	}
+	return "", nil
+})
+
+func TestAPIFlavor_DataCenterUserReferences(t *testing.T) {
+	body := map[string]interface{}{
+		"fields": map[string]interface{}{
+			"reporter":          map[string]interface{}{"accountId": "5b10a2844c20165700ede21g", "name": "jane"},
+			"customfield_10007": []interface{}{map[string]interface{}{"accountId": "5b10a2844c20165700ede21g"}},
+		},
+		"update": map[string]interface{}{
+			"assignee": []interface{}{map[string]interface{}{"set": map[string]interface{}{"accountId": "5b10a2844c20165700ede21g"}}},
+		},
+		"properties": []interface{}{map[string]interface{}{"key": "owner", "value": map[string]interface{}{"accountId": "5b10a2844c20165700ede21g"}}},
+	}
+
+	got := sendWithFlavor(t, APIFlavorDataCenter, http.MethodPost, "/rest/api/3/issue", body, resolver)
+
+	fields := got.body["fields"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"name": "jane"}, fields["reporter"], "a known name is kept")
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "jdoe"}}, fields["customfield_10007"])
+
+	update := got.body["update"].(map[string]interface{})
+	assert.Equal(t, []interface{}{map[string]interface{}{"set": map[string]interface{}{"name": "jdoe"}}}, update["assignee"])
+
+	property := got.body["properties"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"accountId": "5b10a2844c20165700ede21g"}, property["value"], "only user fields are rewritten")
+}
+
+func TestAPIFlavor_DataCenterWatcher(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL, WithAPIFlavor(APIFlavorDataCenter), resolver)
+
+	req, err := tr.NewRequest(context.Background(), http.MethodPost, "/rest/api/3/issue/PROJ-1/watchers", "5b10a2844c20165700ede21g")
+	require.NoError(t, err)
+	resp, err := tr.Do(context.Background(), req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, "jdoe", got)
+}
+
+func TestAPIFlavor_DataCenterUnknownUsername(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL)
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+
+	assignee := func(accountID string) interface{} {
+		return map[string]interface{}{"fields": map[string]interface{}{"assignee": map[string]string{"accountId": accountID}}}
+	}
+
+	tests := []struct {
+		name string
+		opts []TransportOption
+		path string
+		body interface{}
+	}{
+		{name: "no resolver", path: "/rest/api/3/issue/PROJ-1", body: assignee("5b10a2844c20165700ede21g")},
+		{name: "unknown account", opts: []TransportOption{resolver}, path: "/rest/api/3/issue/PROJ-1", body: assignee("unknown")},
+		{name: "no resolver for query", path: "/rest/api/3/user?accountId=5b10a2844c20165700ede21g"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := New(server.Client(), baseURL, append([]TransportOption{WithAPIFlavor(APIFlavorDataCenter)}, tt.opts...)...)
+
+			req, err := tr.NewRequest(context.Background(), http.MethodPut, tt.path, tt.body)
+			require.NoError(t, err)
+			_, err = tr.Do(context.Background(), req)
+			assert.ErrorIs(t, err, ErrUnknownUsername)
+		})
+	}
+}
+
+func TestAPIFlavor_CloudRequestsUnchanged(t *testing.T) {
+	body := map[string]interface{}{
+		"body":     map[string]interface{}{"type": "doc", "version": 1, "content": []interface{}{}},
+		"assignee": map[string]interface{}{"accountId": "5b10a2844c20165700ede21g"},
+	}
+
+	got := sendWithFlavor(t, APIFlavorCloud, http.MethodPost, "/rest/api/3/issue/PROJ-1/comment?accountId=x", body)
+	assert.Equal(t, "/rest/api/3/issue/PROJ-1/comment", got.path)
+	assert.Equal(t, "x", got.query.Get("accountId"))
+	assert.Equal(t, body["assignee"], got.body["assignee"])
+	assert.Equal(t, "doc", got.body["body"].(map[string]interface{})["type"])
+}
+
+func TestAPIFlavor_ADFRenderer(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	var rendered []byte
+	render := func(doc []byte) (string, error) {
+		rendered = doc
+		return "h1. Rendered", nil
+	}
+
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL, WithAPIFlavor(APIFlavorDataCenter), WithADFRenderer(render))
+
+	body := map[string]interface{}{"body": map[string]interface{}{"type": "doc", "version": 1, "content": []interface{}{}}}
+	req, err := tr.NewRequest(context.Background(), http.MethodPost, "/rest/api/3/issue/PROJ-1/comment", body)
+	require.NoError(t, err)
+	resp, err := tr.Do(context.Background(), req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, "h1. Rendered", got["body"])
+	assert.JSONEq(t, `{"type":"doc","version":1,"content":[]}`, string(rendered))
+}
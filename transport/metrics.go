@@ -0,0 +1,100 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Status classes reported in RequestMetrics.
+const (
+	// StatusClassError marks calls that failed without a response
+	StatusClassError = "error"
+)
+
+// Metrics records client-side metrics for each logical call.
+// This interface matches the jirasdk Metrics interface to avoid circular dependencies.
+type Metrics interface {
+	RecordRequest(ctx context.Context, m RequestMetrics)
+}
+
+// RequestMetrics describes one logical call, after all retries and rate limit
+// waits.
+//
+// Service, Operation, Endpoint and StatusClass are meant to be used as metric
+// labels; their cardinality is bounded because Endpoint is the endpoint
+// template, never the raw path.
+type RequestMetrics struct {
+	// Service is the Jira service, e.g. "issue", "project" or "agile"
+	Service string
+
+	// Operation is the HTTP method of the call, e.g. "GET"
+	Operation string
+
+	// Endpoint is the endpoint template, e.g. "/rest/api/3/issue/{issueIdOrKey}"
+	Endpoint string
+
+	// StatusClass is "2xx", "3xx", "4xx", "5xx" or StatusClassError
+	StatusClass string
+
+	// StatusCode is the final HTTP status code, or 0 if the call failed without a response
+	StatusCode int
+
+	// Duration is the wall time of the call, including retries and rate limit waits
+	Duration time.Duration
+
+	// Retries is the number of retries the call needed
+	Retries int
+
+	// RateLimitWait is the total time spent waiting for rate limits
+	RateLimitWait time.Duration
+
+	// Err is the transport error, if the call failed without a response
+	Err error
+}
+
+// Failed reports whether the call counts as an error: it failed without a
+// response or ended with a 4xx or 5xx status.
+func (m RequestMetrics) Failed() bool {
+	return m.Err != nil || m.StatusCode >= 400
+}
+
+// metricsMiddleware reports one RequestMetrics per logical call.
+func metricsMiddleware(metrics Metrics) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			template := EndpointTemplate(req.URL.Path)
+			ctx, stats := withCallStats(ctx, nil)
+
+			start := time.Now()
+			resp, err := next(ctx, req)
+
+			m := RequestMetrics{
+				Service:   EndpointService(template),
+				Operation: req.Method,
+				Endpoint:  template,
+				Duration:  time.Since(start),
+				Err:       err,
+			}
+			m.Retries, m.RateLimitWait = stats.snapshot()
+			if err != nil || resp == nil {
+				m.StatusClass = StatusClassError
+			} else {
+				m.StatusCode = resp.StatusCode
+				m.StatusClass = statusClass(resp.StatusCode)
+			}
+			metrics.RecordRequest(ctx, m)
+
+			return resp, err
+		}
+	}
+}
+
+// statusClass returns the class of an HTTP status code, e.g. "4xx".
+func statusClass(code int) string {
+	if code < 100 || code > 599 {
+		return StatusClassError
+	}
+	return strconv.Itoa(code/100) + "xx"
+}
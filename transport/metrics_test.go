@@ -0,0 +1,113 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingMetrics keeps every RequestMetrics it is given.
+type recordingMetrics struct {
+	mu      sync.Mutex
+	records []RequestMetrics
+}
+
+func (r *recordingMetrics) RecordRequest(_ context.Context, m RequestMetrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, m)
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/api/3/issue/PROJ-123":
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	metrics := &recordingMetrics{}
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL,
+		WithMetrics(metrics),
+		WithRetryPolicy(fixedRetryPolicy{max: 2, backoff: time.Millisecond}),
+	)
+
+	doGet(t, tr, "/rest/api/3/issue/PROJ-123")
+	doGet(t, tr, "/rest/agile/1.0/sprint/7")
+
+	require.Len(t, metrics.records, 2)
+
+	ok := metrics.records[0]
+	assert.Equal(t, "issue", ok.Service)
+	assert.Equal(t, "GET", ok.Operation)
+	assert.Equal(t, "/rest/api/3/issue/{issueIdOrKey}", ok.Endpoint)
+	assert.Equal(t, "2xx", ok.StatusClass)
+	assert.Equal(t, http.StatusOK, ok.StatusCode)
+	assert.Equal(t, 1, ok.Retries)
+	assert.Positive(t, ok.Duration)
+	assert.False(t, ok.Failed())
+
+	notFound := metrics.records[1]
+	assert.Equal(t, "agile", notFound.Service)
+	assert.Equal(t, "/rest/agile/1.0/sprint/{sprintId}", notFound.Endpoint)
+	assert.Equal(t, "4xx", notFound.StatusClass)
+	assert.Equal(t, 0, notFound.Retries)
+	assert.True(t, notFound.Failed())
+}
+
+func TestMetricsMiddleware_TransportError(t *testing.T) {
+	metrics := &recordingMetrics{}
+	failing := func(ctx context.Context, req *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "https://example.atlassian.net/rest/api/3/project/PROJ", nil)
+	_, err := metricsMiddleware(metrics)(failing)(context.Background(), req)
+	require.Error(t, err)
+
+	require.Len(t, metrics.records, 1)
+	m := metrics.records[0]
+	assert.Equal(t, "project", m.Service)
+	assert.Equal(t, "DELETE", m.Operation)
+	assert.Equal(t, "/rest/api/3/project/{projectIdOrKey}", m.Endpoint)
+	assert.Equal(t, StatusClassError, m.StatusClass)
+	assert.Zero(t, m.StatusCode)
+	assert.True(t, m.Failed())
+}
+
+func TestStatusClass(t *testing.T) {
+	tests := []struct {
+		code int
+		want string
+	}{
+		{200, "2xx"},
+		{204, "2xx"},
+		{304, "3xx"},
+		{429, "4xx"},
+		{503, "5xx"},
+		{0, StatusClassError},
+		{999, StatusClassError},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, statusClass(tt.code), "code %d", tt.code)
+	}
+}
@@ -35,14 +35,23 @@ func userAgentMiddleware(userAgent string) Middleware {
 	}
 }
 
-// retryMiddleware implements exponential backoff retry logic.
-func retryMiddleware(maxRetries int) Middleware {
+// retryMiddleware retries failed attempts as directed by the retry policy.
+//
+// Each retry sends a fresh copy of the request body obtained from GetBody.
+// Requests whose body cannot be replayed are never retried.
+func retryMiddleware(policy RetryPolicy) Middleware {
 	return func(next RoundTripFunc) RoundTripFunc {
 		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
-			var lastErr error
-			var resp *http.Response
+			// A request that cannot be replayed is never retried, so the policy
+			// is not consulted and its retry budget is left untouched
+			if !canReplay(req) {
+				return next(ctx, req)
+			}
+
+			attemptReq := req
+			callPolicy := callRetryPolicy(ctx, policy)
 
-			for attempt := 0; attempt <= maxRetries; attempt++ {
+			for attempt := 0; ; attempt++ {
 				// Check if context is cancelled
 				select {
 				case <-ctx.Done():
@@ -50,21 +59,36 @@ func retryMiddleware(maxRetries int) Middleware {
 				default:
 				}
 
+				// Rewind the body consumed by the previous attempt
+				if attempt > 0 {
+					var err error
+					attemptReq, err = rewindBody(req)
+					if err != nil {
+						return nil, err
+					}
+				}
+
 				// Execute request
-				resp, lastErr = next(ctx, req)
+				resp, err := next(ctx, attemptReq)
 
-				// If no error and status is not retry-able, return immediately
-				if lastErr == nil && !isRetryableStatus(resp.StatusCode) {
-					return resp, nil
+				retry, backoff := callPolicy.Retry(req, resp, err, attempt)
+				if !retry {
+					if err != nil && attempt > 0 {
+						return nil, fmt.Errorf("request failed after %d retries: %w", attempt, err)
+					}
+					return resp, err
 				}
 
-				// If this was the last attempt, break
-				if attempt == maxRetries {
-					break
+				// Close the response body if present to avoid resource leaks
+				statusCode := 0
+				if resp != nil {
+					statusCode = resp.StatusCode
+					if resp.Body != nil {
+						_ = resp.Body.Close() // Explicit ignore in cleanup path
+					}
 				}
 
-				// Calculate backoff duration with jitter
-				backoff := calculateBackoff(attempt)
+				recordRetry(ctx, attempt+1, backoff, statusCode, err)
 
 				// Wait for backoff duration or context cancellation
 				select {
@@ -73,70 +97,36 @@ func retryMiddleware(maxRetries int) Middleware {
 				case <-ctx.Done():
 					return nil, ctx.Err()
 				}
-
-				// Close the response body if present to avoid resource leaks
-				if resp != nil && resp.Body != nil {
-					_ = resp.Body.Close() // Explicit ignore in cleanup path
-				}
 			}
-
-			// All retries exhausted
-			if lastErr != nil {
-				return nil, fmt.Errorf("request failed after %d retries: %w", maxRetries, lastErr)
-			}
-
-			return resp, nil
 		}
 	}
 }
 
-// rateLimitMiddleware handles rate limiting responses.
-func rateLimitMiddleware(buffer time.Duration) Middleware {
+// rateLimitMiddleware throttles requests proactively and learns from rate limiting responses.
+//
+// Before each request it waits on the governor, which delays the call once the
+// quota learned from previous responses runs out. Every response is fed back to
+// the governor. A 429 is returned to the retry policy, which retries it after
+// its Retry-After delay; the governor holds further requests for the buffer on
+// top of that delay, so the retry does not hit the limit again immediately.
+func rateLimitMiddleware(buffer time.Duration, governor *RateLimitGovernor) Middleware {
 	return func(next RoundTripFunc) RoundTripFunc {
 		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			waited, err := governor.Wait(ctx)
+			recordRateLimitWait(ctx, waited, RateLimitReasonGovernor)
+			if err != nil {
+				return nil, err
+			}
+
 			resp, err := next(ctx, req)
 			if err != nil {
 				return resp, err
 			}
 
-			// Check for rate limiting (429 Too Many Requests)
+			governor.Observe(resp)
 			if resp.StatusCode == http.StatusTooManyRequests {
-				// Parse Retry-After header
-				retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
-
-				// Add buffer to avoid hitting the limit again immediately
-				waitDuration := retryAfter + buffer
-
-				// Close the response body
-				if resp.Body != nil {
-					_ = resp.Body.Close() // Explicit ignore before retry
-				}
-
-				// Wait for the specified duration or context cancellation
-				select {
-				case <-time.After(waitDuration):
-					// Retry the request
-					return next(ctx, req)
-				case <-ctx.Done():
-					return nil, ctx.Err()
-				}
+				governor.pause(parseRetryAfter(resp.Header.Get("Retry-After")) + buffer)
 			}
-
-			// Check for rate limit headers (X-RateLimit-*)
-			if remaining := resp.Header.Get("X-RateLimit-Remaining"); remaining == "0" {
-				// Traditional rate limit exhausted — next request may be throttled
-			}
-
-			// Check for beta rate limit headers (points-based quota, CHANGE-3045)
-			if betaPolicy := resp.Header.Get("Beta-RateLimit-Policy"); betaPolicy != "" {
-				if betaRL := resp.Header.Get("Beta-RateLimit"); betaRL != "" {
-					remaining := parseBetaRateLimit(betaRL)
-					if remaining == 0 {
-						// Points exhausted — consider backing off
-					}
-				}
-			}
-
 			return resp, nil
 		}
 	}
@@ -180,6 +170,16 @@ func calculateBackoff(attempt int) time.Duration {
 	return finalDelay
 }
 
+// RetryAfter returns the delay requested by the Retry-After header of resp, and
+// whether the header is set.
+func RetryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	return parseRetryAfter(value), true
+}
+
 // parseRetryAfter parses the Retry-After header value.
 //
 // The Retry-After header can be either:
@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"net/http"
 	"testing"
 	"time"
 
@@ -85,6 +86,15 @@ func TestParseRetryAfter(t *testing.T) {
 	}
 }
 
+func TestRetryAfter(t *testing.T) {
+	_, ok := RetryAfter(&http.Response{Header: http.Header{}})
+	assert.False(t, ok)
+
+	delay, ok := RetryAfter(&http.Response{Header: http.Header{"Retry-After": {"30"}}})
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, delay)
+}
+
 func TestIsRetryableStatus(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -0,0 +1,224 @@
+package transport
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitBudget is a snapshot of the rate limit quota learned from Jira responses.
+type RateLimitBudget struct {
+	// Known reports whether any rate limit headers have been observed yet
+	Known bool
+
+	// Limit is the number of points (or requests) allowed per window
+	Limit int
+
+	// Remaining is the estimated number of points left in the current window
+	Remaining int
+
+	// Window is the quota window, or zero if Jira did not advertise one
+	Window time.Duration
+
+	// BlockedUntil is set when Jira asked the client to pause (429 or exhausted quota)
+	BlockedUntil time.Time
+
+	// UpdatedAt is the time the budget was last learned from a response
+	UpdatedAt time.Time
+}
+
+// RateLimitGovernor is a token bucket that learns its size and refill rate
+// from the rate limit headers Jira returns.
+//
+// The governor is shared by every request made through a Transport, and can be
+// shared across transports that talk to the same Jira site. Until Jira has
+// advertised a quota the governor lets every request through.
+//
+// Headers understood:
+//   - Beta-RateLimit-Policy ("100;w=60") sets the bucket size and window
+//   - Beta-RateLimit ("r=85") sets the remaining points
+//   - X-RateLimit-Limit and X-RateLimit-Remaining set the size and remaining requests
+//   - X-RateLimit-Reset (RFC 3339) pauses requests until the reset when nothing remains
+//   - Retry-After on a 429 pauses requests for the advertised duration
+type RateLimitGovernor struct {
+	mu           sync.Mutex
+	limit        int
+	window       time.Duration
+	tokens       float64
+	refilledAt   time.Time
+	blockedUntil time.Time
+	updatedAt    time.Time
+	known        bool
+
+	// now is overridable for tests
+	now func() time.Time
+}
+
+// NewRateLimitGovernor creates a governor with no learned quota.
+func NewRateLimitGovernor() *RateLimitGovernor {
+	return &RateLimitGovernor{
+		now: time.Now,
+	}
+}
+
+// Budget returns a snapshot of the current quota estimate.
+//
+// Example:
+//
+//	if b := client.RateLimitBudget(); b.Known && b.Remaining < 50 {
+//		// pause the batch job
+//	}
+func (g *RateLimitGovernor) Budget() RateLimitBudget {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.refillLocked(g.now())
+
+	return RateLimitBudget{
+		Known:        g.known,
+		Limit:        g.limit,
+		Remaining:    int(g.tokens),
+		Window:       g.window,
+		BlockedUntil: g.blockedUntil,
+		UpdatedAt:    g.updatedAt,
+	}
+}
+
+// Wait blocks until the governor allows another request and takes one token.
+//
+// It returns the time spent waiting, or the context error if ctx is done first.
+func (g *RateLimitGovernor) Wait(ctx context.Context) (time.Duration, error) {
+	var waited time.Duration
+
+	for {
+		delay := g.reserve()
+		if delay <= 0 {
+			return waited, nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+			waited += delay
+		case <-ctx.Done():
+			timer.Stop()
+			return waited, ctx.Err()
+		}
+	}
+}
+
+// reserve takes a token if one is available, or returns how long to wait for one.
+func (g *RateLimitGovernor) reserve() time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+
+	if now.Before(g.blockedUntil) {
+		return g.blockedUntil.Sub(now)
+	}
+
+	// Without a window there is no refill rate to throttle against
+	if g.limit <= 0 || g.window <= 0 {
+		return 0
+	}
+
+	g.refillLocked(now)
+
+	if g.tokens >= 1 {
+		g.tokens--
+		return 0
+	}
+
+	return time.Duration(math.Ceil((1 - g.tokens) * float64(g.window) / float64(g.limit)))
+}
+
+// pause holds requests for d from now, unless they are already held longer.
+func (g *RateLimitGovernor) pause(d time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if until := g.now().Add(d); until.After(g.blockedUntil) {
+		g.blockedUntil = until
+	}
+}
+
+// refillLocked adds the tokens accrued since the last refill. g.mu must be held.
+func (g *RateLimitGovernor) refillLocked(now time.Time) {
+	if g.limit <= 0 || g.window <= 0 {
+		return
+	}
+
+	elapsed := now.Sub(g.refilledAt)
+	if elapsed <= 0 {
+		return
+	}
+
+	g.tokens += float64(elapsed) * float64(g.limit) / float64(g.window)
+	if g.tokens > float64(g.limit) {
+		g.tokens = float64(g.limit)
+	}
+	g.refilledAt = now
+}
+
+// Observe updates the governor from the headers of a Jira response.
+func (g *RateLimitGovernor) Observe(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	h := resp.Header
+	learned := false
+
+	// Points-based quota (CHANGE-3045)
+	if limit, windowSeconds := parseBetaRateLimitPolicy(h.Get("Beta-RateLimit-Policy")); limit > 0 {
+		g.limit = limit
+		if windowSeconds > 0 {
+			g.window = time.Duration(windowSeconds) * time.Second
+		}
+		learned = true
+	}
+	if remaining := parseBetaRateLimit(h.Get("Beta-RateLimit")); remaining >= 0 {
+		g.tokens = float64(remaining)
+		learned = true
+	}
+
+	// Traditional request-count quota
+	if limit, err := strconv.Atoi(h.Get("X-RateLimit-Limit")); err == nil && limit > 0 {
+		g.limit = limit
+		learned = true
+	}
+	if remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining")); err == nil && remaining >= 0 {
+		g.tokens = float64(remaining)
+		learned = true
+
+		if remaining == 0 {
+			if reset, err := time.Parse(time.RFC3339, h.Get("X-RateLimit-Reset")); err == nil && reset.After(g.blockedUntil) {
+				g.blockedUntil = reset
+			}
+		}
+	}
+
+	// Server-directed pause
+	if resp.StatusCode == http.StatusTooManyRequests {
+		until := now.Add(parseRetryAfter(h.Get("Retry-After")))
+		if until.After(g.blockedUntil) {
+			g.blockedUntil = until
+		}
+		g.tokens = 0
+		learned = true
+	}
+
+	if learned {
+		g.known = true
+		g.refilledAt = now
+		g.updatedAt = now
+	}
+}
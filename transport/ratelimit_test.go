@@ -0,0 +1,142 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestGovernor returns a governor driven by a manually advanced clock.
+func newTestGovernor(now *time.Time) *RateLimitGovernor {
+	g := NewRateLimitGovernor()
+	g.now = func() time.Time { return *now }
+	return g
+}
+
+func responseWithHeaders(status int, headers map[string]string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}}
+	for k, v := range headers {
+		resp.Header.Set(k, v)
+	}
+	return resp
+}
+
+func TestRateLimitGovernor_Unknown(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := newTestGovernor(&now)
+
+	assert.False(t, g.Budget().Known)
+	assert.Zero(t, g.reserve())
+
+	g.Observe(responseWithHeaders(http.StatusOK, nil))
+	assert.False(t, g.Budget().Known)
+}
+
+func TestRateLimitGovernor_BetaHeaders(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := newTestGovernor(&now)
+
+	g.Observe(responseWithHeaders(http.StatusOK, map[string]string{
+		"Beta-RateLimit-Policy": "60;w=60",
+		"Beta-RateLimit":        `r=2;policy="60;w=60"`,
+	}))
+
+	budget := g.Budget()
+	assert.True(t, budget.Known)
+	assert.Equal(t, 60, budget.Limit)
+	assert.Equal(t, 2, budget.Remaining)
+	assert.Equal(t, time.Minute, budget.Window)
+
+	// Two tokens left, then the bucket refills at one point per second
+	assert.Zero(t, g.reserve())
+	assert.Zero(t, g.reserve())
+	assert.Equal(t, time.Second, g.reserve())
+
+	now = now.Add(time.Second)
+	assert.Zero(t, g.reserve())
+	assert.Equal(t, 0, g.Budget().Remaining)
+
+	// Refill is capped at the limit
+	now = now.Add(time.Hour)
+	assert.Equal(t, 60, g.Budget().Remaining)
+}
+
+func TestRateLimitGovernor_XRateLimitReset(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := newTestGovernor(&now)
+
+	reset := now.Add(30 * time.Second)
+	g.Observe(responseWithHeaders(http.StatusOK, map[string]string{
+		"X-RateLimit-Limit":     "100",
+		"X-RateLimit-Remaining": "0",
+		"X-RateLimit-Reset":     reset.Format(time.RFC3339),
+	}))
+
+	budget := g.Budget()
+	assert.True(t, budget.Known)
+	assert.Equal(t, 100, budget.Limit)
+	assert.Equal(t, 0, budget.Remaining)
+	assert.Equal(t, reset, budget.BlockedUntil)
+	assert.Equal(t, 30*time.Second, g.reserve())
+
+	now = reset
+	assert.Zero(t, g.reserve())
+}
+
+func TestRateLimitGovernor_TooManyRequests(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := newTestGovernor(&now)
+
+	g.Observe(responseWithHeaders(http.StatusTooManyRequests, map[string]string{
+		"Retry-After": "5",
+	}))
+
+	assert.Equal(t, 5*time.Second, g.reserve())
+	now = now.Add(5 * time.Second)
+	assert.Zero(t, g.reserve())
+}
+
+func TestRateLimitGovernor_WaitCancelled(t *testing.T) {
+	g := NewRateLimitGovernor()
+	g.Observe(responseWithHeaders(http.StatusTooManyRequests, map[string]string{
+		"Retry-After": "60",
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := g.Wait(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRateLimitMiddleware_LearnsBudget(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Beta-RateLimit-Policy", "100;w=60")
+		w.Header().Set("Beta-RateLimit", "r=85")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	governor := NewRateLimitGovernor()
+	tr := New(server.Client(), baseURL, WithRateLimitGovernor(governor))
+
+	req, err := tr.NewRequest(context.Background(), http.MethodGet, "/rest/api/3/myself", nil)
+	require.NoError(t, err)
+
+	resp, err := tr.Do(context.Background(), req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	budget := tr.RateLimitBudget()
+	assert.True(t, budget.Known)
+	assert.Equal(t, 100, budget.Limit)
+	assert.InDelta(t, 85, budget.Remaining, 1)
+	assert.Equal(t, 100, governor.Budget().Limit)
+}
@@ -0,0 +1,558 @@
+// Package recorder records Jira API traffic to cassette files and replays it.
+//
+// In record mode the recorder sends requests to Jira and stores each request
+// and response pair. Authorization headers, credential query parameters and
+// configured JSON fields are redacted before anything is written. In replay mode no network calls are
+// made: responses are served from the cassette, which makes integration tests
+// deterministic and runnable offline.
+//
+// Example usage:
+//
+//	rec, err := recorder.New("testdata/get_issue.json", recorder.ModeReplay,
+//		recorder.WithRedactedFields("emailAddress"),
+//	)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer rec.Stop()
+//
+//	client, err := jira.NewClient(
+//		jira.WithBaseURL("https://your-domain.atlassian.net"),
+//		jira.WithAPIToken("email", "token"),
+//		jira.WithRecorder(rec),
+//	)
+package recorder
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Mode selects whether the recorder records or replays traffic.
+type Mode int
+
+const (
+	// ModeRecord sends requests to Jira and records them
+	ModeRecord Mode = iota
+
+	// ModeReplay serves responses from the cassette without network access
+	ModeReplay
+)
+
+// String returns the mode name.
+func (m Mode) String() string {
+	switch m {
+	case ModeRecord:
+		return "record"
+	case ModeReplay:
+		return "replay"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+// MatchOn selects the request attributes compared during replay.
+type MatchOn int
+
+const (
+	// MatchMethod compares the HTTP method
+	MatchMethod MatchOn = 1 << iota
+
+	// MatchPath compares the URL path
+	MatchPath
+
+	// MatchQuery compares the query parameters, ignoring their order
+	MatchQuery
+
+	// MatchBody compares the request body, as JSON when both sides are JSON
+	MatchBody
+
+	// DefaultMatch compares method, path and query
+	DefaultMatch = MatchMethod | MatchPath | MatchQuery
+)
+
+// Redacted replaces redacted header and field values.
+const Redacted = "REDACTED"
+
+// ErrNoInteraction is returned in replay mode when no recorded interaction matches a request.
+var ErrNoInteraction = errors.New("recorder: no matching interaction in cassette")
+
+// DefaultRedactedHeaders are redacted in every recorded request and response.
+var DefaultRedactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// DefaultRedactedQueryParams are redacted in every recorded request URL, such
+// as the jwt parameter of Atlassian Connect requests.
+var DefaultRedactedQueryParams = []string{"jwt", "access_token", "token"}
+
+// Cassette is the on-disk format of recorded traffic.
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the recorded part of an HTTP request.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is the recorded part of an HTTP response.
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// cassetteVersion is the version written to new cassettes.
+const cassetteVersion = 1
+
+// Recorder records or replays HTTP traffic.
+//
+// A Recorder is safe for concurrent use. In record mode the cassette is
+// written by Stop.
+type Recorder struct {
+	mu              sync.Mutex
+	path            string
+	mode            Mode
+	match           MatchOn
+	redactedHeaders []string
+	redactedParams  []string
+	redactedFields  map[string]bool
+	transport       http.RoundTripper
+	cassette        *Cassette
+	used            []bool
+}
+
+// Option configures a Recorder.
+type Option func(*Recorder)
+
+// WithMatching sets the request attributes compared during replay.
+//
+// Example:
+//
+//	recorder.WithMatching(recorder.DefaultMatch | recorder.MatchBody)
+func WithMatching(match MatchOn) Option {
+	return func(r *Recorder) {
+		r.match = match
+	}
+}
+
+// WithRedactedFields redacts JSON object fields with these names, at any
+// depth, in recorded request and response bodies.
+//
+// Example:
+//
+//	recorder.WithRedactedFields("emailAddress", "avatarUrls")
+func WithRedactedFields(fields ...string) Option {
+	return func(r *Recorder) {
+		for _, field := range fields {
+			r.redactedFields[field] = true
+		}
+	}
+}
+
+// WithRedactedHeaders redacts these headers in addition to DefaultRedactedHeaders.
+func WithRedactedHeaders(headers ...string) Option {
+	return func(r *Recorder) {
+		r.redactedHeaders = append(r.redactedHeaders, headers...)
+	}
+}
+
+// WithRedactedQueryParams redacts these query parameters in addition to
+// DefaultRedactedQueryParams. Replay ignores them when matching queries.
+//
+// Example:
+//
+//	recorder.WithRedactedQueryParams("apiKey")
+func WithRedactedQueryParams(params ...string) Option {
+	return func(r *Recorder) {
+		r.redactedParams = append(r.redactedParams, params...)
+	}
+}
+
+// WithTransport sets the transport used to reach Jira in record mode.
+//
+// It defaults to the transport of the client the recorder is attached to.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(r *Recorder) {
+		r.transport = transport
+	}
+}
+
+// New creates a recorder for the cassette at path.
+//
+// In replay mode the cassette must exist. In record mode any existing
+// cassette is replaced when Stop is called.
+func New(path string, mode Mode, opts ...Option) (*Recorder, error) {
+	r := &Recorder{
+		path:            path,
+		mode:            mode,
+		match:           DefaultMatch,
+		redactedHeaders: append([]string{}, DefaultRedactedHeaders...),
+		redactedParams:  append([]string{}, DefaultRedactedQueryParams...),
+		redactedFields:  make(map[string]bool),
+		cassette:        &Cassette{Version: cassetteVersion},
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	switch mode {
+	case ModeRecord:
+	case ModeReplay:
+		cassette, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = cassette
+		r.used = make([]bool, len(cassette.Interactions))
+	default:
+		return nil, fmt.Errorf("recorder: unknown mode %v", mode)
+	}
+
+	return r, nil
+}
+
+// Load reads a cassette file.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+
+	return &cassette, nil
+}
+
+// Mode returns the recorder mode.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Stop writes the cassette in record mode. It is a no-op in replay mode.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+
+	return nil
+}
+
+// Client returns a copy of base whose requests go through the recorder.
+//
+// In record mode requests are forwarded to the transport of base (or the one
+// set with WithTransport). base is not modified.
+func (r *Recorder) Client(base *http.Client) *http.Client {
+	client := &http.Client{}
+	if base != nil {
+		*client = *base
+	}
+
+	next := r.transport
+	if next == nil {
+		next = client.Transport
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	client.Transport = &roundTripper{recorder: r, next: next}
+	return client
+}
+
+// roundTripper binds a recorder to the transport used for real requests.
+type roundTripper struct {
+	recorder *Recorder
+	next     http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if rt.recorder.mode == ModeReplay {
+		return rt.recorder.replay(req, plainBody(req, body))
+	}
+	return rt.recorder.record(req, body, rt.next)
+}
+
+// record forwards req and stores the exchange.
+func (r *Recorder) record(req *http.Request, body []byte, next http.RoundTripper) (*http.Response, error) {
+	// Ask for an uncompressed body so it can be stored and redacted as text
+	forwarded := req.Clone(req.Context())
+	forwarded.Header.Set("Accept-Encoding", "identity")
+	forwarded.Body = io.NopCloser(bytes.NewReader(body))
+
+	resp, err := next.RoundTrip(forwarded)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close() // Explicit ignore, the body is replaced below
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    r.redactURL(req.URL),
+			Header: r.redactHeader(req.Header),
+			Body:   string(r.redactBody(plainBody(req, body))),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     r.redactHeader(resp.Header),
+			Body:       string(r.redactBody(respBody)),
+		},
+	}
+	interaction.Response.Header.Del("Content-Length")
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// replay serves req from the cassette.
+//
+// Interactions are used in recorded order: the first unused match is
+// returned. Once every match has been used the last one is served again, so
+// polling loops and retries keep working.
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+	for i := range r.cassette.Interactions {
+		if !r.matches(&r.cassette.Interactions[i].Request, req, body) {
+			continue
+		}
+		last = i
+		if !r.used[i] {
+			r.used[i] = true
+			return r.cassette.Interactions[i].Response.response(req), nil
+		}
+	}
+
+	if last >= 0 {
+		return r.cassette.Interactions[last].Response.response(req), nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL.RequestURI())
+}
+
+// matches compares a recorded request with req.
+func (r *Recorder) matches(recorded *RecordedRequest, req *http.Request, body []byte) bool {
+	if r.match&MatchMethod != 0 && recorded.Method != req.Method {
+		return false
+	}
+
+	if r.match&(MatchPath|MatchQuery) != 0 {
+		recordedURL, err := url.Parse(recorded.URL)
+		if err != nil {
+			return false
+		}
+		if r.match&MatchPath != 0 && recordedURL.Path != req.URL.Path {
+			return false
+		}
+		if r.match&MatchQuery != 0 && r.matchQuery(recordedURL) != r.matchQuery(req.URL) {
+			return false
+		}
+	}
+
+	if r.match&MatchBody != 0 && !equalBodies([]byte(recorded.Body), r.redactBody(body)) {
+		return false
+	}
+
+	return true
+}
+
+// response builds an HTTP response from the recording.
+func (rr *RecordedResponse) response(req *http.Request) *http.Response {
+	header := rr.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rr.StatusCode, http.StatusText(rr.StatusCode)),
+		StatusCode:    rr.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(rr.Body)),
+		ContentLength: int64(len(rr.Body)),
+		Request:       req,
+	}
+}
+
+// redactHeader returns a copy of header with sensitive values replaced.
+func (r *Recorder) redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	if redacted == nil {
+		return nil
+	}
+	for _, name := range r.redactedHeaders {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, Redacted)
+		}
+	}
+	return redacted
+}
+
+// redactURL returns u as a string with the values of redacted query
+// parameters replaced.
+func (r *Recorder) redactURL(u *url.URL) string {
+	query := u.Query()
+	redacted := false
+	for _, name := range r.redactedParams {
+		if values, ok := query[name]; ok {
+			for i := range values {
+				values[i] = Redacted
+			}
+			redacted = true
+		}
+	}
+	if !redacted {
+		return u.String()
+	}
+
+	clone := *u
+	clone.RawQuery = query.Encode()
+	return clone.String()
+}
+
+// matchQuery returns the query of u in canonical order, without the redacted
+// parameters, whose recorded values are never the real ones.
+func (r *Recorder) matchQuery(u *url.URL) string {
+	query := u.Query()
+	for _, name := range r.redactedParams {
+		query.Del(name)
+	}
+	return query.Encode()
+}
+
+// redactBody replaces configured JSON fields. Non-JSON bodies are returned unchanged.
+func (r *Recorder) redactBody(body []byte) []byte {
+	if len(r.redactedFields) == 0 || len(body) == 0 {
+		return body
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return body
+	}
+
+	redacted, err := json.Marshal(r.redactValue(value))
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+// redactValue walks a decoded JSON value and replaces configured fields.
+func (r *Recorder) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if r.redactedFields[key] {
+				v[key] = Redacted
+			} else {
+				v[key] = r.redactValue(child)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = r.redactValue(child)
+		}
+	}
+	return value
+}
+
+// equalBodies compares two bodies, semantically when both are JSON.
+func equalBodies(a, b []byte) bool {
+	var av, bv interface{}
+	if json.Unmarshal(a, &av) == nil && json.Unmarshal(b, &bv) == nil {
+		ac, _ := json.Marshal(av)
+		bc, _ := json.Marshal(bv)
+		return bytes.Equal(ac, bc)
+	}
+	return bytes.Equal(a, b)
+}
+
+// readRequestBody reads and closes the request body.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close() // Explicit ignore, the body has been read
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	return body, nil
+}
+
+// plainBody returns the request body with gzip content encoding removed, so
+// that compressed requests are recorded and matched as text.
+func plainBody(req *http.Request, body []byte) []byte {
+	if !strings.EqualFold(req.Header.Get("Content-Encoding"), "gzip") {
+		return body
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return body
+	}
+	plain, err := io.ReadAll(zr)
+	if err != nil {
+		return body
+	}
+	return plain
+}
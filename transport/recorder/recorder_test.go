@@ -0,0 +1,216 @@
+package recorder
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordCassette records the given requests against a server that echoes the
+// request body and returns a user object, and returns the cassette path.
+func recordCassette(t *testing.T, opts []Option, requests ...*http.Request) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		if len(body) > 0 {
+			_, _ = w.Write(body)
+			return
+		}
+		_, _ = w.Write([]byte(`{"path":"` + r.URL.Path + `","emailAddress":"alice@example.com"}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassettes", "test.json")
+	rec, err := New(path, ModeRecord, opts...)
+	require.NoError(t, err)
+
+	client := rec.Client(server.Client())
+	for _, req := range requests {
+		req.URL.Scheme = "http"
+		req.URL.Host = strings.TrimPrefix(server.URL, "http://")
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		_, _ = io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+	}
+
+	require.NoError(t, rec.Stop())
+	return path
+}
+
+func newRequest(t *testing.T, method, target, body string) *http.Request {
+	t.Helper()
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, "http://jira.example.com"+target, reader)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	return req
+}
+
+func TestRecorder_RecordRedacts(t *testing.T) {
+	path := recordCassette(t, []Option{WithRedactedFields("emailAddress"), WithRedactedHeaders("X-Custom-Secret")},
+		newRequest(t, http.MethodGet, "/rest/api/3/myself", ""),
+	)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret-token")
+	assert.NotContains(t, string(data), "session=secret")
+	assert.NotContains(t, string(data), "alice@example.com")
+
+	cassette, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, cassette.Interactions, 1)
+
+	interaction := cassette.Interactions[0]
+	assert.Equal(t, http.MethodGet, interaction.Request.Method)
+	assert.Equal(t, Redacted, interaction.Request.Header.Get("Authorization"))
+	assert.Equal(t, http.StatusOK, interaction.Response.StatusCode)
+	assert.JSONEq(t, `{"path":"/rest/api/3/myself","emailAddress":"REDACTED"}`, interaction.Response.Body)
+}
+
+func TestRecorder_RedactsQueryParams(t *testing.T) {
+	path := recordCassette(t, []Option{WithRedactedQueryParams("apiKey")},
+		newRequest(t, http.MethodGet, "/panel?jwt=eyJ.secret.sig&lic=active&apiKey=k3y&access_token=at0k", ""),
+	)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	for _, secret := range []string{"eyJ.secret.sig", "k3y", "at0k"} {
+		assert.NotContains(t, string(data), secret)
+	}
+
+	cassette, err := Load(path)
+	require.NoError(t, err)
+	recorded, err := url.Parse(cassette.Interactions[0].Request.URL)
+	require.NoError(t, err)
+	assert.Equal(t, url.Values{
+		"jwt":          {Redacted},
+		"lic":          {"active"},
+		"apiKey":       {Redacted},
+		"access_token": {Redacted},
+	}, recorded.Query())
+
+	// A fresh token still matches, other parameters still count
+	rec, err := New(path, ModeReplay, WithRedactedQueryParams("apiKey"))
+	require.NoError(t, err)
+	resp, err := rec.Client(nil).Do(newRequest(t, http.MethodGet, "/panel?lic=active&jwt=eyJ.fresh.sig&apiKey=other&access_token=new", ""))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err = rec.Client(nil).Do(newRequest(t, http.MethodGet, "/panel?lic=none&jwt=eyJ.fresh.sig&apiKey=other&access_token=new", ""))
+	assert.ErrorIs(t, err, ErrNoInteraction)
+}
+
+func TestRecorder_RecordDecodesGzipRequests(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(`{"summary":"Compressed"}`))
+	require.NoError(t, zw.Close())
+
+	req := newRequest(t, http.MethodPost, "/rest/api/3/issue", "")
+	req.Body = io.NopCloser(bytes.NewReader(buf.Bytes()))
+	req.Header.Set("Content-Encoding", "gzip")
+
+	path := recordCassette(t, nil, req)
+
+	cassette, err := Load(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"summary":"Compressed"}`, cassette.Interactions[0].Request.Body)
+}
+
+func TestRecorder_Replay(t *testing.T) {
+	path := recordCassette(t, nil,
+		newRequest(t, http.MethodGet, "/rest/api/3/issue/PROJ-1?fields=summary&expand=names", ""),
+		newRequest(t, http.MethodPost, "/rest/api/3/issue", `{"summary":"first"}`),
+		newRequest(t, http.MethodPost, "/rest/api/3/issue", `{"summary":"second"}`),
+	)
+
+	tests := []struct {
+		name    string
+		match   MatchOn
+		method  string
+		target  string
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{name: "query order ignored", match: DefaultMatch, method: http.MethodGet,
+			target: "/rest/api/3/issue/PROJ-1?expand=names&fields=summary", want: "/rest/api/3/issue/PROJ-1"},
+		{name: "query mismatch", match: DefaultMatch, method: http.MethodGet,
+			target: "/rest/api/3/issue/PROJ-1?fields=status", wantErr: true},
+		{name: "query ignored", match: MatchMethod | MatchPath, method: http.MethodGet,
+			target: "/rest/api/3/issue/PROJ-1", want: "/rest/api/3/issue/PROJ-1"},
+		{name: "method mismatch", match: DefaultMatch, method: http.MethodDelete,
+			target: "/rest/api/3/issue/PROJ-1?fields=summary&expand=names", wantErr: true},
+		{name: "body selects interaction", match: DefaultMatch | MatchBody, method: http.MethodPost,
+			target: "/rest/api/3/issue", body: `{ "summary": "second" }`, want: `"second"`},
+		{name: "body mismatch", match: DefaultMatch | MatchBody, method: http.MethodPost,
+			target: "/rest/api/3/issue", body: `{"summary":"third"}`, wantErr: true},
+		{name: "recorded order without body matching", match: DefaultMatch, method: http.MethodPost,
+			target: "/rest/api/3/issue", body: `{"summary":"third"}`, want: `"first"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := New(path, ModeReplay, WithMatching(tt.match))
+			require.NoError(t, err)
+
+			resp, err := rec.Client(nil).Do(newRequest(t, tt.method, tt.target, tt.body))
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrNoInteraction))
+				return
+			}
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Contains(t, string(body), tt.want)
+			assert.NoError(t, rec.Stop())
+		})
+	}
+}
+
+func TestRecorder_ReplaySequence(t *testing.T) {
+	path := recordCassette(t, nil,
+		newRequest(t, http.MethodPost, "/rest/api/3/issue", `{"summary":"first"}`),
+		newRequest(t, http.MethodPost, "/rest/api/3/issue", `{"summary":"second"}`),
+	)
+
+	rec, err := New(path, ModeReplay)
+	require.NoError(t, err)
+	client := rec.Client(nil)
+
+	for _, want := range []string{"first", "second", "second"} {
+		resp, err := client.Do(newRequest(t, http.MethodPost, "/rest/api/3/issue", `{}`))
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		assert.Contains(t, string(body), want)
+	}
+}
+
+func TestNew_ReplayMissingCassette(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing.json"), ModeReplay)
+	assert.Error(t, err)
+}
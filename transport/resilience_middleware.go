@@ -0,0 +1,63 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+)
+
+// Resilience wraps request execution with resilience patterns such as
+// circuit breakers, bulkheads and timeouts.
+// This interface matches the jirasdk Resilience interface to avoid circular dependencies.
+type Resilience interface {
+	ExecuteRequest(ctx context.Context, req *http.Request, do func(context.Context, *http.Request) (*http.Response, error)) (*http.Response, error)
+}
+
+// RetryingResilience is implemented by Resilience implementations that retry
+// failed requests themselves.
+//
+// When HandlesRetries reports true, the built-in retry middleware is left out
+// of the chain so that the two retry loops do not multiply each other. The
+// implementation then takes over its duties: retrying 429 after Retry-After
+// (see RetryAfter), honoring CallMaxRetriesFrom, and reporting each retry with
+// RecordRetry.
+type RetryingResilience interface {
+	Resilience
+
+	// HandlesRetries reports whether the implementation retries failed requests
+	HandlesRetries() bool
+}
+
+// resilienceMiddleware runs each attempt through the configured Resilience.
+//
+// It sits between the retry and rate limiting middleware: every attempt made
+// by the built-in retry loop passes through the circuit breaker, bulkhead and
+// timeout individually, while 429 handling stays closest to the wire.
+// Implementations that retry may call do repeatedly; each repeat gets a fresh
+// copy of the request body.
+func resilienceMiddleware(resilience Resilience) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			var calls int32
+			do := func(ctx context.Context, r *http.Request) (*http.Response, error) {
+				if atomic.AddInt32(&calls, 1) > 1 {
+					rewound, err := rewindBody(r)
+					if err != nil {
+						return nil, err
+					}
+					r = rewound
+				}
+				return next(ctx, r)
+			}
+			return resilience.ExecuteRequest(ctx, req, do)
+		}
+	}
+}
+
+// handlesRetries reports whether the resilience implementation retries requests itself.
+func handlesRetries(resilience Resilience) bool {
+	if r, ok := resilience.(RetryingResilience); ok {
+		return r.HandlesRetries()
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingResilience records how often ExecuteRequest is invoked.
+type countingResilience struct {
+	calls   int32
+	retries bool
+}
+
+func (c *countingResilience) ExecuteRequest(ctx context.Context, req *http.Request, do func(context.Context, *http.Request) (*http.Response, error)) (*http.Response, error) {
+	atomic.AddInt32(&c.calls, 1)
+	return do(ctx, req)
+}
+
+func (c *countingResilience) HandlesRetries() bool {
+	return c.retries
+}
+
+// plainResilience implements Resilience without RetryingResilience.
+type plainResilience struct {
+	calls int32
+}
+
+func (p *plainResilience) ExecuteRequest(ctx context.Context, req *http.Request, do func(context.Context, *http.Request) (*http.Response, error)) (*http.Response, error) {
+	atomic.AddInt32(&p.calls, 1)
+	return do(ctx, req)
+}
+
+func TestResilienceMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		resilience     Resilience
+		calls          func(Resilience) int32
+		wantAttempts   int32
+		wantResilience int32
+	}{
+		{
+			name:           "wraps every built-in retry attempt",
+			resilience:     &plainResilience{},
+			calls:          func(r Resilience) int32 { return atomic.LoadInt32(&r.(*plainResilience).calls) },
+			wantAttempts:   3,
+			wantResilience: 3,
+		},
+		{
+			name:           "retrying resilience reporting false keeps built-in retries",
+			resilience:     &countingResilience{retries: false},
+			calls:          func(r Resilience) int32 { return atomic.LoadInt32(&r.(*countingResilience).calls) },
+			wantAttempts:   3,
+			wantResilience: 3,
+		},
+		{
+			name:           "retrying resilience disables built-in retries",
+			resilience:     &countingResilience{retries: true},
+			calls:          func(r Resilience) int32 { return atomic.LoadInt32(&r.(*countingResilience).calls) },
+			wantAttempts:   1,
+			wantResilience: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&attempts, 1)
+				w.WriteHeader(http.StatusBadGateway)
+			}))
+			defer server.Close()
+
+			baseURL, _ := url.Parse(server.URL)
+			tr := New(server.Client(), baseURL,
+				WithMaxRetries(2),
+				WithResilience(tt.resilience),
+			)
+
+			req, err := tr.NewRequest(context.Background(), http.MethodGet, "/rest/api/3/myself", nil)
+			require.NoError(t, err)
+
+			resp, err := tr.Do(context.Background(), req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+			assert.Equal(t, tt.wantAttempts, atomic.LoadInt32(&attempts))
+			assert.Equal(t, tt.wantResilience, tt.calls(tt.resilience))
+		})
+	}
+}
+
+func TestWithResilience(t *testing.T) {
+	cfg := &Config{}
+	resilience := &plainResilience{}
+
+	opt := WithResilience(resilience)
+	opt(cfg)
+
+	assert.Equal(t, resilience, cfg.resilience)
+}
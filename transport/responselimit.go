@@ -0,0 +1,94 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrResponseTooLarge matches *ResponseTooLargeError.
+var ErrResponseTooLarge = errors.New("jira: response too large")
+
+// ResponseTooLargeError is returned when a response body exceeds the limit
+// set with WithMaxResponseSize.
+type ResponseTooLargeError struct {
+	// Limit is the configured maximum size in bytes
+	Limit int64
+
+	// Method is the HTTP method of the request
+	Method string
+
+	// Path is the request path
+	Path string
+}
+
+// Error implements the error interface.
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("jira: response to %s %s exceeds %d bytes", e.Method, e.Path, e.Limit)
+}
+
+// Is reports whether target is ErrResponseTooLarge.
+func (e *ResponseTooLargeError) Is(target error) bool {
+	return target == ErrResponseTooLarge
+}
+
+// IsResponseTooLarge returns true if the error is a *ResponseTooLargeError.
+func IsResponseTooLarge(err error) bool {
+	return errors.Is(err, ErrResponseTooLarge)
+}
+
+// responseLimitMiddleware fails responses whose decoded body exceeds limit bytes.
+//
+// A Content-Length above the limit fails the call immediately; otherwise the
+// body fails with a *ResponseTooLargeError as soon as reading passes the limit.
+func responseLimitMiddleware(limit int64) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			resp, err := next(ctx, req)
+			if err != nil || resp.Body == nil || resp.Body == http.NoBody {
+				return resp, err
+			}
+
+			tooLarge := &ResponseTooLargeError{Limit: limit, Method: req.Method, Path: req.URL.Path}
+			if resp.ContentLength > limit {
+				_ = resp.Body.Close() // Explicit ignore, the response is rejected
+				return nil, tooLarge
+			}
+
+			resp.Body = &limitedBody{body: resp.Body, remaining: limit, err: tooLarge}
+			return resp, nil
+		}
+	}
+}
+
+// limitedBody returns err once more than remaining bytes have been read.
+type limitedBody struct {
+	body      io.ReadCloser
+	remaining int64
+	err       error
+}
+
+// Read implements io.Reader.
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, l.err
+	}
+
+	// Read one byte past the limit to tell "exactly at the limit" from "over it"
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.body.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), l.err
+	}
+	return n, err
+}
+
+// Close closes the underlying body.
+func (l *limitedBody) Close() error {
+	return l.body.Close()
+}
@@ -0,0 +1,95 @@
+package transport
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseLimitMiddleware(t *testing.T) {
+	small := `{"key":"PROJ-1"}`
+	large := `{"description":"` + strings.Repeat("x", 200) + `"}`
+
+	tests := []struct {
+		name    string
+		body    string
+		chunked bool
+		gzip    bool
+		wantErr bool
+	}{
+		{name: "under the limit", body: small},
+		{name: "exactly at the limit", body: strings.Repeat(" ", 100-len(small)) + small},
+		{name: "content length over the limit", body: large, wantErr: true},
+		{name: "chunked body over the limit", body: large, chunked: true, wantErr: true},
+		{name: "decompressed body over the limit", body: large, gzip: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.gzip {
+					w.Header().Set("Content-Encoding", "gzip")
+					zw := gzip.NewWriter(w)
+					_, _ = zw.Write([]byte(tt.body))
+					_ = zw.Close()
+					return
+				}
+				if tt.chunked {
+					_, _ = w.Write([]byte(tt.body[:10]))
+					w.(http.Flusher).Flush()
+					_, _ = w.Write([]byte(tt.body[10:]))
+					return
+				}
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			baseURL, _ := url.Parse(server.URL)
+			tr := New(server.Client(), baseURL, WithMaxRetries(0), WithMaxResponseSize(100))
+
+			req, err := tr.NewRequest(context.Background(), http.MethodGet, "/rest/api/3/issue/PROJ-1", nil)
+			require.NoError(t, err)
+			resp, err := tr.Do(context.Background(), req)
+			if err == nil {
+				var target map[string]interface{}
+				err = tr.DecodeResponse(resp, &target)
+			}
+
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.True(t, IsResponseTooLarge(err))
+
+			var tooLarge *ResponseTooLargeError
+			require.True(t, errors.As(err, &tooLarge))
+			assert.Equal(t, int64(100), tooLarge.Limit)
+			assert.Equal(t, http.MethodGet, tooLarge.Method)
+			assert.Equal(t, "/rest/api/3/issue/PROJ-1", tooLarge.Path)
+		})
+	}
+}
+
+func TestLimitedBody(t *testing.T) {
+	tooLarge := &ResponseTooLargeError{Limit: 5}
+
+	body := &limitedBody{body: io.NopCloser(strings.NewReader("12345")), remaining: 5, err: tooLarge}
+	data, err := io.ReadAll(body)
+	assert.NoError(t, err)
+	assert.Equal(t, "12345", string(data))
+
+	body = &limitedBody{body: io.NopCloser(strings.NewReader("123456")), remaining: 5, err: tooLarge}
+	data, err = io.ReadAll(body)
+	assert.ErrorIs(t, err, ErrResponseTooLarge)
+	assert.Equal(t, "12345", string(data))
+}
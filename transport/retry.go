@@ -0,0 +1,230 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RetryPolicy decides whether a request attempt is retried and how long to wait first.
+//
+// Retry is called after every attempt, successful or not, so that policies can
+// keep statistics such as a retry budget. attempt is zero-based. Requests whose
+// body cannot be replayed are never retried and are not passed to Retry.
+type RetryPolicy interface {
+	Retry(req *http.Request, resp *http.Response, err error, attempt int) (bool, time.Duration)
+}
+
+// DefaultReadOnlyEndpoints lists POST endpoints that only read data and are
+// therefore safe to retry. Entries are matched against the end of the request path.
+var DefaultReadOnlyEndpoints = []string{
+	"/search",
+	"/search/jql",
+	"/search/approximate-count",
+	"/issue/bulkfetch",
+	"/jql/parse",
+	"/jql/match",
+	"/jql/sanitize",
+	"/expression/eval",
+	"/expression/evaluate",
+	"/expression/analyse",
+}
+
+// DefaultMaxRetryAfter is the longest Retry-After delay DefaultRetryPolicy
+// waits for unless MaxRetryAfter is set.
+const DefaultMaxRetryAfter = time.Minute
+
+// DefaultRetryPolicy retries transient failures with exponential backoff.
+//
+// Requests are classified by method and endpoint:
+//   - GET, HEAD, OPTIONS, PUT and DELETE are idempotent and are retried on
+//     network errors and on 429, 500, 502, 503 and 504 responses
+//   - POST requests to read-only endpoints (see DefaultReadOnlyEndpoints) are
+//     treated as idempotent
+//   - Other POST and PATCH requests, such as issue creation, are only retried
+//     on 429, which Jira returns before processing the request
+//
+// A Retry-After header on a 429 or 503 response overrides the backoff delay.
+// A delay longer than MaxRetryAfter is not waited for: the response is
+// returned, and its ErrorResponse carries the requested RetryAfter.
+type DefaultRetryPolicy struct {
+	// MaxRetries is the maximum number of retries after the first attempt
+	MaxRetries int
+
+	// MaxRetryAfter is the longest Retry-After delay that is waited for
+	// (defaults to DefaultMaxRetryAfter)
+	MaxRetryAfter time.Duration
+
+	// ReadOnlyEndpoints overrides DefaultReadOnlyEndpoints when non-nil
+	ReadOnlyEndpoints []string
+
+	// Budget, when set, limits retries across all requests sharing the policy
+	Budget *RetryBudget
+}
+
+// NewDefaultRetryPolicy creates a DefaultRetryPolicy with the given retry limit.
+func NewDefaultRetryPolicy(maxRetries int) *DefaultRetryPolicy {
+	return &DefaultRetryPolicy{
+		MaxRetries: maxRetries,
+	}
+}
+
+// Retry implements RetryPolicy.
+func (p *DefaultRetryPolicy) Retry(req *http.Request, resp *http.Response, err error, attempt int) (bool, time.Duration) {
+	if err == nil && !isRetryableStatus(resp.StatusCode) {
+		if p.Budget != nil {
+			p.Budget.RecordSuccess()
+		}
+		return false, 0
+	}
+
+	if p.Budget != nil {
+		p.Budget.RecordFailure()
+	}
+
+	if attempt >= p.MaxRetries {
+		return false, 0
+	}
+
+	// Never retry a request the caller gave up on
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false, 0
+	}
+
+	rateLimited := err == nil && resp.StatusCode == http.StatusTooManyRequests
+	if !rateLimited && !IsIdempotent(req, p.ReadOnlyEndpoints) {
+		return false, 0
+	}
+
+	if p.Budget != nil && !p.Budget.Allow() {
+		return false, 0
+	}
+
+	if err == nil && resp.Header.Get("Retry-After") != "" &&
+		(resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+		delay := parseRetryAfter(resp.Header.Get("Retry-After"))
+		maxDelay := p.MaxRetryAfter
+		if maxDelay <= 0 {
+			maxDelay = DefaultMaxRetryAfter
+		}
+		if delay > maxDelay {
+			return false, 0
+		}
+		return true, delay
+	}
+
+	return true, calculateBackoff(attempt)
+}
+
+// IsIdempotent reports whether repeating req cannot cause duplicate side
+// effects, using the classification of DefaultRetryPolicy. A nil
+// readOnlyEndpoints means DefaultReadOnlyEndpoints.
+//
+// Resilience implementations that retry requests themselves use it to leave
+// requests such as issue creation alone after a 5xx.
+func IsIdempotent(req *http.Request, readOnlyEndpoints []string) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		if readOnlyEndpoints == nil {
+			readOnlyEndpoints = DefaultReadOnlyEndpoints
+		}
+		return isReadOnlyEndpoint(req.URL.Path, readOnlyEndpoints)
+	default:
+		return false
+	}
+}
+
+// isReadOnlyEndpoint reports whether path ends with one of endpoints.
+func isReadOnlyEndpoint(path string, endpoints []string) bool {
+	path = strings.TrimSuffix(path, "/")
+	for _, endpoint := range endpoints {
+		if strings.HasSuffix(path, endpoint) {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryBudget limits the share of requests that may be retried.
+//
+// It follows the token scheme used by gRPC retry throttling: every failed
+// attempt removes a token, every success adds ratio tokens, and retries are
+// allowed only while more than half of maxTokens remain. During an outage the
+// budget drains and requests fail fast instead of multiplying the load.
+type RetryBudget struct {
+	mu        sync.Mutex
+	maxTokens float64
+	tokens    float64
+	ratio     float64
+}
+
+// NewRetryBudget creates a full retry budget.
+//
+// Example:
+//
+//	// Allow sustained retries for roughly one in ten requests
+//	budget := transport.NewRetryBudget(10, 0.1)
+func NewRetryBudget(maxTokens int, ratio float64) *RetryBudget {
+	return &RetryBudget{
+		maxTokens: float64(maxTokens),
+		tokens:    float64(maxTokens),
+		ratio:     ratio,
+	}
+}
+
+// Allow reports whether a retry may be made.
+func (b *RetryBudget) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens > b.maxTokens/2
+}
+
+// RecordSuccess credits the budget for a successful attempt.
+func (b *RetryBudget) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens += b.ratio
+	if b.tokens > b.maxTokens {
+		b.tokens = b.maxTokens
+	}
+}
+
+// RecordFailure debits the budget for a failed attempt.
+func (b *RetryBudget) RecordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens--
+	if b.tokens < 0 {
+		b.tokens = 0
+	}
+}
+
+// canReplay reports whether the request body can be sent again.
+func canReplay(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewindBody returns a shallow copy of req with a fresh body from GetBody.
+func rewindBody(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	if req.GetBody == nil {
+		return nil, fmt.Errorf("request body cannot be replayed")
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to rewind request body: %w", err)
+	}
+
+	rewound := req.Clone(req.Context())
+	rewound.Body = body
+	return rewound, nil
+}
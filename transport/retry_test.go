@@ -0,0 +1,398 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPolicyRequest(method, path string) *http.Request {
+	req, _ := http.NewRequest(method, "https://example.atlassian.net"+path, nil)
+	return req
+}
+
+func TestDefaultRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		status    int
+		err       error
+		header    http.Header
+		attempt   int
+		wantRetry bool
+		wantDelay time.Duration
+	}{
+		{
+			name:      "GET on 502 is retried",
+			method:    http.MethodGet,
+			path:      "/rest/api/3/issue/PROJ-1",
+			status:    http.StatusBadGateway,
+			wantRetry: true,
+		},
+		{
+			name:      "GET on network error is retried",
+			method:    http.MethodGet,
+			path:      "/rest/api/3/issue/PROJ-1",
+			err:       errors.New("connection reset"),
+			wantRetry: true,
+		},
+		{
+			name:      "GET on 200 is not retried",
+			method:    http.MethodGet,
+			path:      "/rest/api/3/issue/PROJ-1",
+			status:    http.StatusOK,
+			wantRetry: false,
+		},
+		{
+			name:      "GET on 404 is not retried",
+			method:    http.MethodGet,
+			path:      "/rest/api/3/issue/PROJ-1",
+			status:    http.StatusNotFound,
+			wantRetry: false,
+		},
+		{
+			name:      "PUT on 500 is retried",
+			method:    http.MethodPut,
+			path:      "/rest/api/3/issue/PROJ-1",
+			status:    http.StatusInternalServerError,
+			wantRetry: true,
+		},
+		{
+			name:      "issue create on 500 is not retried",
+			method:    http.MethodPost,
+			path:      "/rest/api/3/issue",
+			status:    http.StatusInternalServerError,
+			wantRetry: false,
+		},
+		{
+			name:      "issue create on network error is not retried",
+			method:    http.MethodPost,
+			path:      "/rest/api/3/issue",
+			err:       errors.New("connection reset"),
+			wantRetry: false,
+		},
+		{
+			name:      "issue create on 429 is retried",
+			method:    http.MethodPost,
+			path:      "/rest/api/3/issue",
+			status:    http.StatusTooManyRequests,
+			header:    http.Header{"Retry-After": []string{"7"}},
+			wantRetry: true,
+			wantDelay: 7 * time.Second,
+		},
+		{
+			name:      "read-only search POST on 503 is retried",
+			method:    http.MethodPost,
+			path:      "/rest/api/3/search/jql",
+			status:    http.StatusServiceUnavailable,
+			wantRetry: true,
+		},
+		{
+			name:      "503 honours Retry-After",
+			method:    http.MethodGet,
+			path:      "/rest/api/3/myself",
+			status:    http.StatusServiceUnavailable,
+			header:    http.Header{"Retry-After": []string{"3"}},
+			wantRetry: true,
+			wantDelay: 3 * time.Second,
+		},
+		{
+			name:      "Retry-After at the cap is waited for",
+			method:    http.MethodGet,
+			path:      "/rest/api/3/myself",
+			status:    http.StatusTooManyRequests,
+			header:    http.Header{"Retry-After": []string{"60"}},
+			wantRetry: true,
+			wantDelay: time.Minute,
+		},
+		{
+			name:      "Retry-After beyond the cap is not waited for",
+			method:    http.MethodGet,
+			path:      "/rest/api/3/myself",
+			status:    http.StatusTooManyRequests,
+			header:    http.Header{"Retry-After": []string{"7200"}},
+			wantRetry: false,
+		},
+		{
+			name:      "retries exhausted",
+			method:    http.MethodGet,
+			path:      "/rest/api/3/myself",
+			status:    http.StatusServiceUnavailable,
+			attempt:   3,
+			wantRetry: false,
+		},
+		{
+			name:      "cancelled context is not retried",
+			method:    http.MethodGet,
+			path:      "/rest/api/3/myself",
+			err:       context.Canceled,
+			wantRetry: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := NewDefaultRetryPolicy(3)
+
+			var resp *http.Response
+			if tt.err == nil {
+				resp = &http.Response{StatusCode: tt.status, Header: tt.header}
+				if resp.Header == nil {
+					resp.Header = http.Header{}
+				}
+			}
+
+			retry, delay := policy.Retry(newPolicyRequest(tt.method, tt.path), resp, tt.err, tt.attempt)
+			assert.Equal(t, tt.wantRetry, retry)
+			if tt.wantDelay > 0 {
+				assert.Equal(t, tt.wantDelay, delay)
+			}
+		})
+	}
+}
+
+func TestDefaultRetryPolicy_MaxRetryAfter(t *testing.T) {
+	policy := NewDefaultRetryPolicy(3)
+	policy.MaxRetryAfter = 5 * time.Second
+
+	resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{"Retry-After": []string{"5"}}}
+	retry, delay := policy.Retry(newPolicyRequest(http.MethodGet, "/rest/api/3/myself"), resp, nil, 0)
+	assert.True(t, retry)
+	assert.Equal(t, 5*time.Second, delay)
+
+	resp.Header.Set("Retry-After", "6")
+	retry, _ = policy.Retry(newPolicyRequest(http.MethodGet, "/rest/api/3/myself"), resp, nil, 0)
+	assert.False(t, retry)
+}
+
+func TestRetryMiddleware_TooManyRequestsRetriedByPolicyOnly(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL, WithRateLimitBuffer(time.Millisecond),
+		WithRetryPolicy(fixedRetryPolicy{max: 2, backoff: time.Millisecond}))
+
+	req, err := tr.NewRequest(context.Background(), http.MethodGet, "/rest/api/3/myself", nil)
+	require.NoError(t, err)
+	resp, err := tr.Do(context.Background(), req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls), "one attempt plus two retries")
+}
+
+func TestRetryMiddleware_LongRetryAfterFailsFast(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"errorMessages":["Rate limit exceeded"]}`))
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL)
+
+	req, err := tr.NewRequest(context.Background(), http.MethodGet, "/rest/api/3/myself", nil)
+	require.NoError(t, err)
+	resp, err := tr.Do(context.Background(), req)
+	require.NoError(t, err)
+
+	err = tr.DecodeResponse(resp, nil)
+	var errResp *ErrorResponse
+	require.ErrorAs(t, err, &errResp)
+	assert.Equal(t, time.Hour, errResp.RetryAfter)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestDefaultRetryPolicy_ReadOnlyEndpoints(t *testing.T) {
+	policy := NewDefaultRetryPolicy(3)
+	policy.ReadOnlyEndpoints = []string{"/custom/lookup"}
+
+	resp := &http.Response{StatusCode: http.StatusBadGateway, Header: http.Header{}}
+
+	retry, _ := policy.Retry(newPolicyRequest(http.MethodPost, "/rest/custom/lookup"), resp, nil, 0)
+	assert.True(t, retry)
+
+	retry, _ = policy.Retry(newPolicyRequest(http.MethodPost, "/rest/api/3/search/jql"), resp, nil, 0)
+	assert.False(t, retry)
+}
+
+func TestIsIdempotent(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{http.MethodGet, "/rest/api/3/issue/PROJ-1", true},
+		{http.MethodPut, "/rest/api/3/issue/PROJ-1", true},
+		{http.MethodDelete, "/rest/api/3/issue/PROJ-1", true},
+		{http.MethodPost, "/rest/api/3/issue", false},
+		{http.MethodPost, "/rest/api/3/search/jql", true},
+		{http.MethodPost, "/ex/jira/abc/rest/api/3/search/jql/", true},
+		{http.MethodPatch, "/rest/api/3/issue/PROJ-1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsIdempotent(newPolicyRequest(tt.method, tt.path), nil))
+		})
+	}
+
+	assert.True(t, IsIdempotent(newPolicyRequest(http.MethodPost, "/rest/custom/lookup"), []string{"/custom/lookup"}))
+}
+
+func TestRetryBudget(t *testing.T) {
+	budget := NewRetryBudget(4, 0.5)
+	policy := NewDefaultRetryPolicy(10)
+	policy.Budget = budget
+
+	req := newPolicyRequest(http.MethodGet, "/rest/api/3/myself")
+	failure := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}
+	success := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+
+	// Tokens: 4 -> 3 -> 2; retries stop once half the budget is gone
+	retry, _ := policy.Retry(req, failure, nil, 0)
+	assert.True(t, retry)
+	retry, _ = policy.Retry(req, failure, nil, 0)
+	assert.False(t, retry)
+
+	// Successes refill the budget
+	policy.Retry(req, success, nil, 0)
+	policy.Retry(req, success, nil, 0)
+	policy.Retry(req, success, nil, 0)
+	assert.True(t, budget.Allow())
+}
+
+func TestRetryMiddleware_ReplaysBody(t *testing.T) {
+	var attempts int32
+	var bodies []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL, WithMaxRetries(3))
+
+	req, err := tr.NewRequest(context.Background(), http.MethodPut, "/rest/api/3/issue/PROJ-1", map[string]string{"summary": "updated"})
+	require.NoError(t, err)
+	require.NotNil(t, req.GetBody)
+
+	resp, err := tr.Do(context.Background(), req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	require.Len(t, bodies, 3)
+	for _, body := range bodies {
+		assert.JSONEq(t, `{"summary":"updated"}`, body)
+	}
+}
+
+func TestRetryMiddleware_NonIdempotentNotRetried(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL, WithMaxRetries(3))
+
+	req, err := tr.NewRequest(context.Background(), http.MethodPost, "/rest/api/3/issue", map[string]string{"summary": "new"})
+	require.NoError(t, err)
+
+	resp, err := tr.Do(context.Background(), req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+}
+
+func TestRetryMiddleware_UnreplayableBodyNotRetried(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL, WithMaxRetries(3))
+
+	req, err := http.NewRequest(http.MethodPut, server.URL+"/rest/api/3/issue/PROJ-1", io.NopCloser(strings.NewReader(`{}`)))
+	require.NoError(t, err)
+	require.Nil(t, req.GetBody)
+
+	resp, err := tr.Do(context.Background(), req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+}
+
+func TestRetryMiddleware_UnreplayableBodyKeepsBudget(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	budget := NewRetryBudget(4, 0.5)
+	policy := NewDefaultRetryPolicy(3)
+	policy.Budget = budget
+
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL, WithRetryPolicy(policy))
+
+	for i := 0; i < 3; i++ {
+		req, err := http.NewRequest(http.MethodPut, server.URL+"/rest/api/3/issue/PROJ-1", io.NopCloser(strings.NewReader(`{}`)))
+		require.NoError(t, err)
+
+		resp, err := tr.Do(context.Background(), req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	// None of the failures could have been retried, so none drew on the budget
+	assert.True(t, budget.Allow())
+}
+
+func TestWithRetryPolicy(t *testing.T) {
+	cfg := &Config{}
+	policy := NewDefaultRetryPolicy(1)
+
+	opt := WithRetryPolicy(policy)
+	opt(cfg)
+
+	assert.Equal(t, policy, cfg.retryPolicy)
+}
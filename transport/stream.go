@@ -0,0 +1,169 @@
+package transport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DecodeJSONStream decodes a JSON object response without buffering it,
+// handing the elements of one top-level array field to item one at a time.
+//
+// This keeps large pages, such as a search/jql page with thousands of issues,
+// from being held in memory both as raw bytes and as decoded values. item
+// receives a decode function that decodes the current element into its
+// argument; elements item does not decode are skipped. The other top-level
+// fields (e.g. nextPageToken) are decoded into rest, if not nil, once the
+// object has been read. A response that is a top-level array, as returned by
+// unpaginated list endpoints, is streamed as if it were field, and rest is
+// left untouched.
+//
+// Returning an error from item stops decoding; the error is returned as is.
+// Error responses (HTTP 400 and above) are returned as *ErrorResponse.
+//
+// Example:
+//
+//	var page struct {
+//		NextPageToken string `json:"nextPageToken"`
+//	}
+//	err := transport.DecodeJSONStream(resp, "issues", &page, func(decode func(interface{}) error) error {
+//		var issue Issue
+//		if err := decode(&issue); err != nil {
+//			return err
+//		}
+//		return process(&issue)
+//	})
+func DecodeJSONStream(resp *http.Response, field string, rest interface{}, item func(decode func(target interface{}) error) error) error {
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response body: %w", err)
+		}
+		return newErrorResponse(resp, body)
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if delim, ok := tok.(json.Delim); ok && delim == '[' {
+		if err := decodeElements(dec, item); err != nil {
+			return err
+		}
+		// Drain what is left so the connection can be reused
+		_, _ = io.Copy(io.Discard, resp.Body) // Explicit ignore, the response is complete
+		return nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("failed to decode response: expected object or array, got %v", tok)
+	}
+
+	others := make(map[string]json.RawMessage)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		key, _ := tok.(string)
+
+		if key != field {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return fmt.Errorf("failed to decode response: %w", err)
+			}
+			others[key] = raw
+			continue
+		}
+
+		if err := decodeArray(dec, item); err != nil {
+			return err
+		}
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return err
+	}
+
+	// Drain what is left so the connection can be reused
+	_, _ = io.Copy(io.Discard, resp.Body) // Explicit ignore, the response is complete
+
+	if rest == nil || len(others) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(others)
+	if err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if err := json.Unmarshal(data, rest); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// decodeArray hands each element of the array at the decoder's position to item.
+// A null value is treated as an empty array.
+func decodeArray(dec *json.Decoder, item func(decode func(target interface{}) error) error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if tok == nil {
+		return nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("failed to decode response: expected array, got %v", tok)
+	}
+	return decodeElements(dec, item)
+}
+
+// decodeElements hands each element of an array whose opening bracket has
+// been read to item, then reads the closing bracket.
+func decodeElements(dec *json.Decoder, item func(decode func(target interface{}) error) error) error {
+	for dec.More() {
+		decoded := false
+		decode := func(target interface{}) error {
+			if decoded {
+				return fmt.Errorf("element already decoded")
+			}
+			decoded = true
+			if err := dec.Decode(target); err != nil {
+				return fmt.Errorf("failed to decode response: %w", err)
+			}
+			return nil
+		}
+
+		if err := item(decode); err != nil {
+			return err
+		}
+		if !decoded {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return fmt.Errorf("failed to decode response: %w", err)
+			}
+		}
+	}
+
+	return expectDelim(dec, ']')
+}
+
+// expectDelim reads the next token and checks that it is delim.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if d, ok := tok.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("failed to decode response: expected %v, got %v", delim, tok)
+	}
+	return nil
+}
+
+// DecodeResponseStream decodes a JSON object response, streaming the elements
+// of its field array to item. See DecodeJSONStream.
+func (t *Transport) DecodeResponseStream(resp *http.Response, field string, rest interface{}, item func(decode func(target interface{}) error) error) error {
+	return DecodeJSONStream(resp, field, rest, item)
+}
@@ -0,0 +1,164 @@
+package transport
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type streamItem struct {
+	Key string `json:"key"`
+}
+
+type streamPage struct {
+	NextPageToken string `json:"nextPageToken"`
+	IsLast        bool   `json:"isLast"`
+}
+
+func TestDecodeJSONStream(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		skip       string
+		wantKeys   []string
+		wantPage   streamPage
+		wantErr    bool
+	}{
+		{
+			name:       "items and other fields",
+			statusCode: http.StatusOK,
+			body:       `{"nextPageToken":"abc","issues":[{"key":"PROJ-1"},{"key":"PROJ-2","fields":{"x":[1,2]}}],"isLast":true}`,
+			wantKeys:   []string{"PROJ-1", "PROJ-2"},
+			wantPage:   streamPage{NextPageToken: "abc", IsLast: true},
+		},
+		{
+			name:       "skipped items",
+			statusCode: http.StatusOK,
+			body:       `{"issues":[{"key":"PROJ-1"},{"key":"PROJ-2"},{"key":"PROJ-3"}]}`,
+			skip:       "PROJ-2",
+			wantKeys:   []string{"PROJ-1", "PROJ-3"},
+		},
+		{
+			name:       "null array",
+			statusCode: http.StatusOK,
+			body:       `{"issues":null,"nextPageToken":"abc"}`,
+			wantPage:   streamPage{NextPageToken: "abc"},
+		},
+		{
+			name:       "missing array",
+			statusCode: http.StatusOK,
+			body:       `{"isLast":true}`,
+			wantPage:   streamPage{IsLast: true},
+		},
+		{
+			name:       "top-level array",
+			statusCode: http.StatusOK,
+			body:       `[{"key":"PROJ-1"},{"key":"PROJ-2"}]`,
+			wantKeys:   []string{"PROJ-1", "PROJ-2"},
+		},
+		{
+			name:       "not an object or array",
+			statusCode: http.StatusOK,
+			body:       `"PROJ-1"`,
+			wantErr:    true,
+		},
+		{
+			name:       "field is not an array",
+			statusCode: http.StatusOK,
+			body:       `{"issues":{"key":"PROJ-1"}}`,
+			wantErr:    true,
+		},
+		{
+			name:       "truncated body",
+			statusCode: http.StatusOK,
+			body:       `{"issues":[{"key":"PROJ-1"},{"key":`,
+			wantKeys:   []string{"PROJ-1"},
+			wantErr:    true,
+		},
+		{
+			name:       "error response",
+			statusCode: http.StatusBadRequest,
+			body:       `{"errorMessages":["bad JQL"]}`,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: tt.statusCode,
+				Body:       io.NopCloser(bytes.NewBufferString(tt.body)),
+			}
+
+			var keys []string
+			var page streamPage
+			err := DecodeJSONStream(resp, "issues", &page, func(decode func(interface{}) error) error {
+				if tt.skip != "" && len(keys) == 1 {
+					tt.skip = ""
+					return nil
+				}
+				var item streamItem
+				if err := decode(&item); err != nil {
+					return err
+				}
+				keys = append(keys, item.Key)
+				return nil
+			})
+
+			assert.Equal(t, tt.wantKeys, keys)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPage, page)
+		})
+	}
+}
+
+func TestDecodeJSONStream_ErrorResponse(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusNotFound,
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewBufferString(`{"errorMessages":["not found"]}`)),
+	}
+
+	err := DecodeJSONStream(resp, "issues", nil, func(func(interface{}) error) error {
+		t.Fatal("item called for an error response")
+		return nil
+	})
+	assert.True(t, IsNotFound(err))
+}
+
+func TestDecodeJSONStream_StopEarly(t *testing.T) {
+	stop := errors.New("stop")
+	body := &closeTracker{Reader: bytes.NewBufferString(`{"issues":[{"key":"PROJ-1"},{"key":"PROJ-2"}]}`)}
+	resp := &http.Response{StatusCode: http.StatusOK, Body: body}
+
+	calls := 0
+	err := DecodeJSONStream(resp, "issues", nil, func(decode func(interface{}) error) error {
+		calls++
+		return stop
+	})
+
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+	assert.True(t, body.closed)
+}
+
+// closeTracker records whether the body was closed.
+type closeTracker struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed = true
+	return nil
+}
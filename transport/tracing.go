@@ -0,0 +1,169 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation scope name of the spans the SDK creates.
+const TracerName = "github.com/felixgeelhaar/jirasdk"
+
+// Span attributes set by the tracing middleware, in addition to the OpenTelemetry
+// HTTP client conventions (http.request.method, server.address, url.template,
+// http.response.status_code and error.type). Their names and meaning are stable.
+const (
+	// AttrService is the Jira service, e.g. "issue", "project" or "agile"
+	AttrService = attribute.Key("jira.service")
+
+	// AttrIssueKey is the issue key addressed by the request, e.g. "PROJ-123"
+	AttrIssueKey = attribute.Key("jira.issue.key")
+
+	// AttrIssueID is the numeric issue ID, for requests that address an issue by ID
+	AttrIssueID = attribute.Key("jira.issue.id")
+
+	// AttrRetryCount is the number of retries the call needed
+	AttrRetryCount = attribute.Key("jira.retry.count")
+
+	// AttrRateLimitWait is the total time spent waiting for rate limits, in milliseconds
+	AttrRateLimitWait = attribute.Key("jira.rate_limit.wait_ms")
+
+	// AttrRequestID is the Atlassian request ID (X-AREQUESTID header)
+	AttrRequestID = attribute.Key("jira.request_id")
+
+	// AttrCache is the cache outcome for responses served by the cache (HIT or REVALIDATED)
+	AttrCache = attribute.Key("jira.cache")
+
+	// AttrRetryAttempt is the 1-based number of the retry on a retry event
+	AttrRetryAttempt = attribute.Key("jira.retry.attempt")
+
+	// AttrRetryBackoff is the backoff before the retry on a retry event, in milliseconds
+	AttrRetryBackoff = attribute.Key("jira.retry.backoff_ms")
+
+	// AttrRateLimitReason is RateLimitReasonGovernor or RateLimitReasonRetryAfter on a rate limit event
+	AttrRateLimitReason = attribute.Key("jira.rate_limit.reason")
+)
+
+// Span events added by the tracing middleware.
+const (
+	// EventRetry is added before each retry, with AttrRetryAttempt and AttrRetryBackoff
+	EventRetry = "jira.retry"
+
+	// EventRateLimitWait is added for each rate limit wait, with AttrRateLimitWait and AttrRateLimitReason
+	EventRateLimitWait = "jira.rate_limit.wait"
+)
+
+// tracingMiddleware opens a client span per logical call.
+//
+// The span is named "<METHOD> <endpoint template>", covers all retries and
+// rate limit waits (recorded as span events), and its context is injected
+// into the outgoing request headers.
+func tracingMiddleware(provider trace.TracerProvider, propagator propagation.TextMapPropagator) Middleware {
+	tracer := provider.Tracer(TracerName)
+
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			template := EndpointTemplate(req.URL.Path)
+
+			attrs := []attribute.KeyValue{
+				attribute.String("http.request.method", req.Method),
+				attribute.String("server.address", req.URL.Hostname()),
+				attribute.String("url.template", template),
+				AttrService.String(EndpointService(template)),
+			}
+			if port := req.URL.Port(); port != "" {
+				if n, err := strconv.Atoi(port); err == nil {
+					attrs = append(attrs, attribute.Int("server.port", n))
+				}
+			}
+			if key, id := issueKeyFromPath(req.URL.Path); key != "" {
+				attrs = append(attrs, AttrIssueKey.String(key))
+			} else if id != "" {
+				attrs = append(attrs, AttrIssueID.String(id))
+			}
+
+			ctx, span := tracer.Start(ctx, req.Method+" "+template,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attrs...),
+			)
+			defer span.End()
+
+			ctx, stats := withCallStats(ctx, spanObserver{span: span})
+			propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+			resp, err := next(ctx, req)
+
+			retries, waited := stats.snapshot()
+			span.SetAttributes(
+				AttrRetryCount.Int(retries),
+				AttrRateLimitWait.Int64(waited.Milliseconds()),
+			)
+
+			if err != nil {
+				span.SetAttributes(attribute.String("error.type", errorType(err)))
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return resp, err
+			}
+
+			span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+			if requestID := resp.Header.Get("X-AREQUESTID"); requestID != "" {
+				span.SetAttributes(AttrRequestID.String(requestID))
+			}
+			if cache := resp.Header.Get(CacheStatusHeader); cache != "" {
+				span.SetAttributes(AttrCache.String(cache))
+			}
+			if resp.StatusCode >= 400 {
+				span.SetAttributes(attribute.String("error.type", strconv.Itoa(resp.StatusCode)))
+				span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+			}
+
+			return resp, nil
+		}
+	}
+}
+
+// errorType classifies a transport error for the error.type attribute.
+func errorType(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "_OTHER"
+	}
+}
+
+// spanObserver records retries and rate limit waits as span events.
+type spanObserver struct {
+	span trace.Span
+}
+
+func (o spanObserver) retry(attempt int, backoff time.Duration, statusCode int, err error) {
+	attrs := []attribute.KeyValue{
+		AttrRetryAttempt.Int(attempt),
+		AttrRetryBackoff.Int64(backoff.Milliseconds()),
+	}
+	if statusCode != 0 {
+		attrs = append(attrs, attribute.Int("http.response.status_code", statusCode))
+	}
+	if err != nil {
+		attrs = append(attrs, attribute.String("error.type", errorType(err)))
+	}
+	o.span.AddEvent(EventRetry, trace.WithAttributes(attrs...))
+}
+
+func (o spanObserver) rateLimitWait(wait time.Duration, reason string) {
+	o.span.AddEvent(EventRateLimitWait, trace.WithAttributes(
+		AttrRateLimitWait.Int64(wait.Milliseconds()),
+		AttrRateLimitReason.String(reason),
+	))
+}
@@ -0,0 +1,185 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// fixedRetryPolicy retries retryable statuses up to max times with a fixed backoff.
+type fixedRetryPolicy struct {
+	max     int
+	backoff time.Duration
+}
+
+func (p fixedRetryPolicy) Retry(_ *http.Request, resp *http.Response, err error, attempt int) (bool, time.Duration) {
+	if attempt >= p.max {
+		return false, 0
+	}
+	return err != nil || isRetryableStatus(resp.StatusCode), p.backoff
+}
+
+// tracedTransport returns a transport for server that records its spans.
+func tracedTransport(t *testing.T, server *httptest.Server, opts ...TransportOption) (*Transport, *tracetest.SpanRecorder) {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	baseURL, _ := url.Parse(server.URL)
+	opts = append([]TransportOption{
+		WithTracing(provider),
+		WithPropagator(propagation.TraceContext{}),
+	}, opts...)
+	return New(server.Client(), baseURL, opts...), recorder
+}
+
+// spanAttributes returns the attributes of a span as a map.
+func spanAttributes(attrs []attribute.KeyValue) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value, len(attrs))
+	for _, kv := range attrs {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func doGet(t *testing.T, tr *Transport, path string) *http.Response {
+	t.Helper()
+
+	req, err := tr.NewRequest(context.Background(), http.MethodGet, path, nil)
+	require.NoError(t, err)
+	resp, err := tr.Do(context.Background(), req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	return resp
+}
+
+func TestTracingMiddleware_SpanSchema(t *testing.T) {
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.Header().Set("X-AREQUESTID", "req-42")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	tr, recorder := tracedTransport(t, server)
+	doGet(t, tr, "/rest/api/3/issue/PROJ-123/comment/10001")
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+
+	assert.Equal(t, "GET /rest/api/3/issue/{issueIdOrKey}/comment/{id}", span.Name())
+	assert.Equal(t, TracerName, span.InstrumentationScope().Name)
+	assert.Equal(t, codes.Unset, span.Status().Code)
+
+	attrs := spanAttributes(span.Attributes())
+	assert.Equal(t, "GET", attrs["http.request.method"].AsString())
+	assert.Equal(t, "/rest/api/3/issue/{issueIdOrKey}/comment/{id}", attrs["url.template"].AsString())
+	assert.Equal(t, "issue", attrs[AttrService].AsString())
+	assert.Equal(t, "PROJ-123", attrs[AttrIssueKey].AsString())
+	assert.Equal(t, int64(http.StatusOK), attrs["http.response.status_code"].AsInt64())
+	assert.Equal(t, int64(0), attrs[AttrRetryCount].AsInt64())
+	assert.Equal(t, "req-42", attrs[AttrRequestID].AsString())
+
+	require.NotEmpty(t, traceparent, "trace context should be propagated")
+	assert.Contains(t, traceparent, span.SpanContext().TraceID().String())
+}
+
+func TestTracingMiddleware_RetriesAndRateLimits(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	tr, recorder := tracedTransport(t, server,
+		WithRetryPolicy(fixedRetryPolicy{max: 3, backoff: time.Millisecond}),
+		WithRateLimitBuffer(20*time.Millisecond),
+	)
+	doGet(t, tr, "/rest/agile/1.0/board/42")
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+
+	assert.Equal(t, "GET /rest/agile/1.0/board/{boardId}", span.Name())
+
+	attrs := spanAttributes(span.Attributes())
+	assert.Equal(t, "agile", attrs[AttrService].AsString())
+	assert.Equal(t, int64(2), attrs[AttrRetryCount].AsInt64())
+	assert.GreaterOrEqual(t, attrs[AttrRateLimitWait].AsInt64(), int64(15))
+
+	var names []string
+	for _, event := range span.Events() {
+		names = append(names, event.Name)
+	}
+	assert.Equal(t, []string{EventRetry, EventRetry, EventRateLimitWait, EventRateLimitWait}, names)
+
+	retry := spanAttributes(span.Events()[0].Attributes)
+	assert.Equal(t, int64(1), retry[AttrRetryAttempt].AsInt64())
+	assert.Equal(t, int64(http.StatusServiceUnavailable), retry["http.response.status_code"].AsInt64())
+
+	retry = spanAttributes(span.Events()[1].Attributes)
+	assert.Equal(t, int64(2), retry[AttrRetryAttempt].AsInt64())
+	assert.Equal(t, int64(http.StatusTooManyRequests), retry["http.response.status_code"].AsInt64())
+
+	// The retry waits out the Retry-After delay, then the governor holds the
+	// next attempt for the buffer
+	wait := spanAttributes(span.Events()[2].Attributes)
+	assert.Equal(t, RateLimitReasonRetryAfter, wait[AttrRateLimitReason].AsString())
+	wait = spanAttributes(span.Events()[3].Attributes)
+	assert.Equal(t, RateLimitReasonGovernor, wait[AttrRateLimitReason].AsString())
+}
+
+func TestTracingMiddleware_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	tr, recorder := tracedTransport(t, server, WithMaxRetries(0))
+	doGet(t, tr, "/rest/api/3/issue/10042")
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	attrs := spanAttributes(spans[0].Attributes())
+	assert.Equal(t, "404", attrs["error.type"].AsString())
+	assert.Equal(t, "10042", attrs[AttrIssueID].AsString())
+	assert.NotContains(t, attrs, AttrIssueKey)
+}
+
+func TestTracingMiddleware_Disabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("traceparent"))
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	tr := New(server.Client(), baseURL, WithTracing(nil))
+	doGet(t, tr, "/rest/api/3/myself")
+}
@@ -2,14 +2,19 @@
 package transport
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/felixgeelhaar/jirasdk/auth"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Logger is the interface for structured logging in the transport layer.
@@ -43,6 +48,24 @@ type Transport struct {
 	rateLimitBuffer time.Duration
 	userAgent       string
 	logger          Logger
+	resilience      Resilience
+	governor        *RateLimitGovernor
+	retryPolicy     RetryPolicy
+	compression     bool
+	minGzipSize     int
+	cache           Cache
+	cacheRules      []CacheRule
+	coalesce        bool
+	maxResponseSize int64
+	flavor          APIFlavor
+	adfRenderer     ADFRenderer
+	resolveUsername UsernameResolver
+	capabilities    capabilitiesCache
+	debug           *debugDumper
+	dryRun          *Plan
+	metrics         Metrics
+	tracerProvider  trace.TracerProvider
+	propagator      propagation.TextMapPropagator
 	middlewares     []Middleware
 	roundTripper    RoundTripFunc
 }
@@ -54,6 +77,23 @@ type Config struct {
 	rateLimitBuffer time.Duration
 	userAgent       string
 	logger          Logger
+	resilience      Resilience
+	governor        *RateLimitGovernor
+	retryPolicy     RetryPolicy
+	compression     bool
+	minGzipSize     int
+	cache           Cache
+	cacheRules      []CacheRule
+	coalesce        bool
+	maxResponseSize int64
+	flavor          APIFlavor
+	adfRenderer     ADFRenderer
+	resolveUsername UsernameResolver
+	debug           []DebugOption
+	dryRun          *Plan
+	metrics         Metrics
+	tracerProvider  trace.TracerProvider
+	propagator      propagation.TextMapPropagator
 	middlewares     []Middleware
 }
 
@@ -66,6 +106,7 @@ func New(client *http.Client, baseURL *url.URL, opts ...TransportOption) *Transp
 		maxRetries:      3,
 		rateLimitBuffer: 5 * time.Second,
 		userAgent:       "jira-connect-go/1.0.0",
+		compression:     true,
 		middlewares:     []Middleware{},
 	}
 
@@ -73,6 +114,14 @@ func New(client *http.Client, baseURL *url.URL, opts ...TransportOption) *Transp
 		opt(cfg)
 	}
 
+	if cfg.governor == nil {
+		cfg.governor = NewRateLimitGovernor()
+	}
+
+	if cfg.retryPolicy == nil {
+		cfg.retryPolicy = NewDefaultRetryPolicy(cfg.maxRetries)
+	}
+
 	t := &Transport{
 		client:          client,
 		baseURL:         baseURL,
@@ -81,9 +130,29 @@ func New(client *http.Client, baseURL *url.URL, opts ...TransportOption) *Transp
 		rateLimitBuffer: cfg.rateLimitBuffer,
 		userAgent:       cfg.userAgent,
 		logger:          cfg.logger,
+		resilience:      cfg.resilience,
+		governor:        cfg.governor,
+		retryPolicy:     cfg.retryPolicy,
+		compression:     cfg.compression,
+		minGzipSize:     cfg.minGzipSize,
+		cache:           cfg.cache,
+		cacheRules:      cfg.cacheRules,
+		coalesce:        cfg.coalesce,
+		maxResponseSize: cfg.maxResponseSize,
+		flavor:          cfg.flavor,
+		adfRenderer:     cfg.adfRenderer,
+		resolveUsername: cfg.resolveUsername,
+		dryRun:          cfg.dryRun,
+		metrics:         cfg.metrics,
+		tracerProvider:  cfg.tracerProvider,
+		propagator:      cfg.propagator,
 		middlewares:     cfg.middlewares,
 	}
 
+	if cfg.debug != nil {
+		t.debug = newDebugDumper(cfg.debug, cfg.logger)
+	}
+
 	// Build middleware chain
 	t.buildMiddlewareChain()
 
@@ -104,7 +173,8 @@ func WithMaxRetries(maxRetries int) TransportOption {
 	}
 }
 
-// WithRateLimitBuffer sets the rate limit buffer duration.
+// WithRateLimitBuffer sets how long requests are held after a 429, on top of
+// its Retry-After delay.
 func WithRateLimitBuffer(buffer time.Duration) TransportOption {
 	return func(cfg *Config) {
 		cfg.rateLimitBuffer = buffer
@@ -132,6 +202,184 @@ func WithLogger(logger interface{}) TransportOption {
 	}
 }
 
+// WithResilience sets the resilience implementation that wraps each request attempt.
+//
+// The resilience layer runs inside the built-in retry middleware, so circuit
+// breakers and bulkheads observe every attempt. If the implementation also
+// satisfies RetryingResilience and reports that it handles retries, the
+// built-in retry middleware is omitted. Rate limit (429) handling always
+// stays inside the resilience layer.
+func WithResilience(resilience Resilience) TransportOption {
+	return func(cfg *Config) {
+		cfg.resilience = resilience
+	}
+}
+
+// WithRateLimitGovernor sets the governor used for proactive throttling.
+//
+// Share one governor between transports that talk to the same Jira site so
+// they draw from a single quota. By default each transport gets its own.
+func WithRateLimitGovernor(governor *RateLimitGovernor) TransportOption {
+	return func(cfg *Config) {
+		cfg.governor = governor
+	}
+}
+
+// WithRetryPolicy sets the policy that decides which attempts are retried.
+//
+// It replaces the DefaultRetryPolicy built from WithMaxRetries.
+func WithRetryPolicy(policy RetryPolicy) TransportOption {
+	return func(cfg *Config) {
+		cfg.retryPolicy = policy
+	}
+}
+
+// WithCompression enables or disables compressed responses.
+//
+// When enabled (the default) requests advertise gzip and deflate and
+// compressed responses are decoded as they are read. When disabled requests
+// ask for identity encoding.
+func WithCompression(enabled bool) TransportOption {
+	return func(cfg *Config) {
+		cfg.compression = enabled
+	}
+}
+
+// WithRequestCompression gzips request bodies of at least minSize bytes.
+//
+// Compression of request bodies is off by default; a minSize of zero keeps it
+// off. It has no effect when compression is disabled.
+func WithRequestCompression(minSize int) TransportOption {
+	return func(cfg *Config) {
+		cfg.minGzipSize = minSize
+	}
+}
+
+// WithCache caches GET responses in cache.
+//
+// Responses from endpoints matching rules are served from the cache for the
+// rule's TTL; with no rules, DefaultCacheRules are used. Other responses that
+// carry an ETag or Last-Modified header are stored and revalidated with a
+// conditional request on every use.
+func WithCache(cache Cache, rules ...CacheRule) TransportOption {
+	return func(cfg *Config) {
+		cfg.cache = cache
+		cfg.cacheRules = rules
+		if len(rules) == 0 {
+			cfg.cacheRules = DefaultCacheRules
+		}
+	}
+}
+
+// WithCoalescing merges identical concurrent GET requests into one upstream call.
+//
+// It is off by default. Each caller receives its own copy of the response
+// body, which is buffered in memory.
+func WithCoalescing(enabled bool) TransportOption {
+	return func(cfg *Config) {
+		cfg.coalesce = enabled
+	}
+}
+
+// WithMaxResponseSize fails responses whose body is larger than limit bytes
+// with a *ResponseTooLargeError.
+//
+// The limit applies to the decompressed body. Responses announcing a larger
+// Content-Length fail before their body is read; others fail once reading
+// passes the limit. Zero, the default, means no limit.
+func WithMaxResponseSize(limit int64) TransportOption {
+	return func(cfg *Config) {
+		cfg.maxResponseSize = limit
+	}
+}
+
+// WithAPIFlavor selects the Jira REST API to talk to.
+//
+// Services build REST API v3 requests. With APIFlavorDataCenter, requests are
+// sent to /rest/api/2 instead, ADF documents in request bodies are sent as
+// text, and accountId user references are sent as name (username in query
+// strings), using the usernames from WithUsernameResolver. The default is
+// APIFlavorCloud, which sends requests unchanged.
+func WithAPIFlavor(flavor APIFlavor) TransportOption {
+	return func(cfg *Config) {
+		cfg.flavor = flavor
+	}
+}
+
+// WithADFRenderer sets how APIFlavorDataCenter renders ADF documents in
+// request bodies. Without a renderer they are sent as plain text.
+func WithADFRenderer(render ADFRenderer) TransportOption {
+	return func(cfg *Config) {
+		cfg.adfRenderer = render
+	}
+}
+
+// WithUsernameResolver sets how APIFlavorDataCenter finds the username for an
+// account ID in a request. Without a resolver, requests that refer to users by
+// account ID fail with ErrUnknownUsername.
+func WithUsernameResolver(resolve UsernameResolver) TransportOption {
+	return func(cfg *Config) {
+		cfg.resolveUsername = resolve
+	}
+}
+
+// WithDebug dumps every request attempt and its response, including bodies.
+//
+// Dumps go to the Logger at debug level ("jira_http_dump"), or to the writer
+// set with DebugToWriter. Authorization headers, cookies and OAuth tokens are
+// always redacted; more headers and JSON fields can be redacted with
+// DebugRedactHeaders and DebugRedactFields. Bodies are truncated after
+// DefaultDebugMaxBodySize bytes unless DebugMaxBodySize says otherwise.
+func WithDebug(opts ...DebugOption) TransportOption {
+	return func(cfg *Config) {
+		cfg.debug = append([]DebugOption{}, opts...)
+	}
+}
+
+// WithDryRun captures mutating requests into plan instead of sending them.
+//
+// POST, PUT, PATCH and DELETE requests are recorded with their method, path
+// and decoded JSON body and answered with a synthetic success carrying the
+// DryRunHeader. GET requests and POSTs to read-only endpoints such as
+// search/jql are sent as usual. Use ContextWithDryRun to enable dry-run mode
+// for individual calls instead.
+func WithDryRun(plan *Plan) TransportOption {
+	return func(cfg *Config) {
+		cfg.dryRun = plan
+	}
+}
+
+// WithMetrics reports a RequestMetrics for every call to metrics.
+//
+// Metrics are recorded once per logical call, after all retries, and include
+// cache hits. A nil Metrics disables them.
+func WithMetrics(metrics Metrics) TransportOption {
+	return func(cfg *Config) {
+		cfg.metrics = metrics
+	}
+}
+
+// WithTracing opens an OpenTelemetry client span for every call.
+//
+// Spans are named "<METHOD> <endpoint template>", e.g.
+// "GET /rest/api/3/issue/{issueIdOrKey}", and carry the attributes and events
+// documented on AttrService and EventRetry. Trace context is injected into the request headers with
+// the propagator set by WithPropagator, or the global propagator. A nil
+// provider disables tracing.
+func WithTracing(provider trace.TracerProvider) TransportOption {
+	return func(cfg *Config) {
+		cfg.tracerProvider = provider
+	}
+}
+
+// WithPropagator sets the propagator used to inject trace context into
+// outgoing requests when tracing is enabled.
+func WithPropagator(propagator propagation.TextMapPropagator) TransportOption {
+	return func(cfg *Config) {
+		cfg.propagator = propagator
+	}
+}
+
 // buildMiddlewareChain builds the middleware chain.
 func (t *Transport) buildMiddlewareChain() {
 	// Start with the base round tripper
@@ -139,25 +387,72 @@ func (t *Transport) buildMiddlewareChain() {
 
 	// Apply built-in middleware in order (innermost to outermost):
 
-	// 1. Authentication (closest to the request)
+	// 1. Debug dump (sees each attempt exactly as it goes on the wire)
+	if t.debug != nil {
+		roundTripper = debugMiddleware(t.debug)(roundTripper)
+	}
+
+	// 2. Authentication
 	if t.authenticator != nil {
 		roundTripper = authMiddleware(t.authenticator)(roundTripper)
 	}
 
-	// 2. User agent
+	// 3. User agent
 	roundTripper = userAgentMiddleware(t.userAgent)(roundTripper)
 
-	// 3. Rate limiting
-	roundTripper = rateLimitMiddleware(t.rateLimitBuffer)(roundTripper)
+	// 4. Rate limiting
+	roundTripper = rateLimitMiddleware(t.rateLimitBuffer, t.governor)(roundTripper)
+
+	// 5. Resilience (circuit breaker, bulkhead, timeout per attempt)
+	if t.resilience != nil {
+		roundTripper = resilienceMiddleware(t.resilience)(roundTripper)
+	}
+
+	// 6. Retry logic, unless the resilience layer already retries
+	if !handlesRetries(t.resilience) {
+		roundTripper = retryMiddleware(t.retryPolicy)(roundTripper)
+	}
+
+	// 7. Compression (request bodies are compressed once, before any retry)
+	roundTripper = compressionMiddleware(t.compression, t.minGzipSize)(roundTripper)
+
+	// 8. Response size limit (counts decompressed bytes)
+	if t.maxResponseSize > 0 {
+		roundTripper = responseLimitMiddleware(t.maxResponseSize)(roundTripper)
+	}
+
+	// 9. Coalescing (one upstream call for identical concurrent GETs)
+	if t.coalesce {
+		roundTripper = coalesceMiddleware(t.authenticator)(roundTripper)
+	}
+
+	// 10. Cache (fresh hits skip the network, retries and rate limiting)
+	if t.cache != nil {
+		roundTripper = cacheMiddleware(t.cache, t.cacheRules, t.authenticator)(roundTripper)
+	}
 
-	// 4. Retry logic
-	roundTripper = retryMiddleware(t.maxRetries)(roundTripper)
+	// 11. Dry run (captures mutating requests before they reach the cache or network)
+	roundTripper = dryRunMiddleware(t.dryRun)(roundTripper)
 
-	// 5. Logging (outermost - logs the final result after all retries)
+	// 12. Logging (logs the final result after all retries)
 	if t.logger != nil {
 		roundTripper = loggingMiddleware(t.logger)(roundTripper)
 	}
 
+	// 13. Metrics (one measurement per logical call)
+	if t.metrics != nil {
+		roundTripper = metricsMiddleware(t.metrics)(roundTripper)
+	}
+
+	// 14. Tracing (outermost - one span per logical call)
+	if t.tracerProvider != nil {
+		propagator := t.propagator
+		if propagator == nil {
+			propagator = otel.GetTextMapPropagator()
+		}
+		roundTripper = tracingMiddleware(t.tracerProvider, propagator)(roundTripper)
+	}
+
 	// Apply custom middleware (outermost)
 	for i := len(t.middlewares) - 1; i >= 0; i-- {
 		roundTripper = t.middlewares[i](roundTripper)
@@ -181,14 +476,37 @@ func (t *Transport) baseRoundTrip(ctx context.Context, req *http.Request) (*http
 }
 
 // Do executes an HTTP request through the middleware chain.
+//
+// Call options attached to ctx with ContextWithCallOptions override the
+// transport's timeout, retry, header and cache settings for this call.
 func (t *Transport) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
 	// Ensure request has context
 	if req.Context() != ctx {
 		req = req.Clone(ctx)
 	}
 
+	// Adapt the request to the API flavor
+	req, err := adaptRequest(t.flavor, t.adfRenderer, t.resolveUsername, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply per-call timeout and headers
+	ctx, req, cancel := applyCallOptions(ctx, req)
+	if cancel == nil {
+		return t.roundTripper(ctx, req)
+	}
+
 	// Execute through middleware chain
-	return t.roundTripper(ctx, req)
+	resp, err := t.roundTripper(ctx, req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// Keep the per-call timeout running until the body has been read
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
 }
 
 // NewRequest creates a new HTTP request with the base URL.
@@ -199,13 +517,24 @@ func (t *Transport) NewRequest(ctx context.Context, method, path string, body in
 		return nil, fmt.Errorf("invalid path: %w", err)
 	}
 
+	// Keep the base URL's path, such as /ex/jira/{cloudId} on the OAuth 2.0
+	// gateway or the context path of a Jira Server, in front of API paths
+	if prefix := strings.TrimSuffix(t.baseURL.Path, "/"); prefix != "" && strings.HasPrefix(path, "/") {
+		if u.RawPath != "" {
+			u.RawPath = strings.TrimSuffix(t.baseURL.EscapedPath(), "/") + u.RawPath
+		}
+		u.Path = prefix + u.Path
+	}
+
 	// Encode request body as JSON
+	var payload []byte
 	var bodyReader io.Reader
 	if body != nil {
-		bodyReader, err = EncodeJSONRequest(body)
+		payload, err = encodeJSON(body)
 		if err != nil {
 			return nil, err
 		}
+		bodyReader = bytes.NewReader(payload)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, u.String(), bodyReader)
@@ -213,6 +542,13 @@ func (t *Transport) NewRequest(ctx context.Context, method, path string, body in
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	// Make the body replayable so retries resend the full payload
+	if payload != nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(payload)), nil
+		}
+	}
+
 	// Set default headers
 	req.Header.Set("Accept", "application/json")
 	if body != nil {
@@ -222,6 +558,16 @@ func (t *Transport) NewRequest(ctx context.Context, method, path string, body in
 	return req, nil
 }
 
+// APIFlavor returns the Jira REST API the transport talks to.
+func (t *Transport) APIFlavor() APIFlavor {
+	return t.flavor
+}
+
+// RateLimitBudget returns the rate limit quota learned from recent responses.
+func (t *Transport) RateLimitBudget() RateLimitBudget {
+	return t.governor.Budget()
+}
+
 // DecodeResponse decodes a JSON response into the target.
 func (t *Transport) DecodeResponse(resp *http.Response, target interface{}) error {
 	return DecodeJSONResponse(resp, target)
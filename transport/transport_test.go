@@ -147,6 +147,51 @@ func TestTransport_NewRequest(t *testing.T) {
 	}
 }
 
+func TestTransport_NewRequest_BasePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		path    string
+		want    string
+	}{
+		{
+			name:    "OAuth 2.0 gateway",
+			baseURL: "https://api.atlassian.com/ex/jira/11223344-a1b2-3b33-c444-def123456789",
+			path:    "/rest/api/3/myself",
+			want:    "https://api.atlassian.com/ex/jira/11223344-a1b2-3b33-c444-def123456789/rest/api/3/myself",
+		},
+		{
+			name:    "context path with trailing slash",
+			baseURL: "https://jira.example.com/jira/",
+			path:    "/rest/api/2/issue/PROJ-1?expand=names",
+			want:    "https://jira.example.com/jira/rest/api/2/issue/PROJ-1?expand=names",
+		},
+		{
+			name:    "escaped path",
+			baseURL: "https://jira.example.com/jira",
+			path:    "/rest/api/3/project/A%2FB",
+			want:    "https://jira.example.com/jira/rest/api/3/project/A%2FB",
+		},
+		{
+			name:    "absolute URL",
+			baseURL: "https://api.atlassian.com/ex/jira/abc",
+			path:    "https://other.example.com/rest/api/3/myself",
+			want:    "https://other.example.com/rest/api/3/myself",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseURL, err := url.Parse(tt.baseURL)
+			require.NoError(t, err)
+
+			req, err := New(&http.Client{}, baseURL).NewRequest(context.Background(), http.MethodGet, tt.path, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, req.URL.String())
+		})
+	}
+}
+
 func TestTransport_Do(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Verify authentication header